@@ -0,0 +1,470 @@
+// botctl runs the bot's generation and editing pipeline from the command
+// line, for scripts and local use without GitHub issues or webhooks
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botBlog "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_blog"
+	botCode "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_code"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+const usage = `Usage: botctl <command> [flags]
+
+Commands:
+  changelog        open a PR with release notes for the bot repo's PRs merged since its last tag
+  generate-post    write a blog post the way a request issue would
+  modify-file      edit a local file the way a PR review comment would
+  validate-config  check the environment the bot would start with
+
+Run botctl <command> -h for a command's flags.
+`
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "changelog":
+		err = openChangelog(args)
+	case "generate-post":
+		err = generatePost(args)
+	case "modify-file":
+		err = modifyFile(args)
+	case "validate-config":
+		err = validateConfig(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "botctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// openChangelog opens a PR on the bot repo adding release notes for the PRs
+// merged since its latest tag. The PR is recorded in the bot's state file, so
+// the bot treats it as one of its own
+func openChangelog(args []string) error {
+	flags := flag.NewFlagSet("changelog", flag.ExitOnError)
+	flags.Parse(args)
+
+	for _, name := range []string{"AI_API_KEY", "GITHUB_TOKEN", "GITHUB_OWNER", "GITHUB_REPO_BOT"} {
+		if os.Getenv(name) == "" {
+			return fmt.Errorf("%s isn't set", name)
+		}
+	}
+
+	stateFile := os.Getenv("STATE_FILE")
+	if stateFile == "" {
+		stateFile = "bot_state.json"
+	}
+
+	store, err := botState.NewStore(stateFile)
+	if err != nil {
+		return err
+	}
+
+	transport, err := newTransport()
+	if err != nil {
+		return err
+	}
+
+	aiClient, err := newAiClient()
+	if err != nil {
+		return err
+	}
+
+	config := botConfig.LoadFromEnv("CODE_")
+
+	if config.Labels.Content == "" {
+		config.Labels.Content = "code-change"
+	}
+
+	codeHandler := botCode.NewHandler(
+		botCode.Handler{
+			AiClient:     aiClient,
+			Config:       config,
+			GithubClient: newGithubClient(transport),
+			Owner:        os.Getenv("GITHUB_OWNER"),
+			Repo:         os.Getenv("GITHUB_REPO_BOT"),
+			Store:        store,
+		},
+	)
+
+	prNumber, err := codeHandler.OpenChangelogPR()
+	if err != nil {
+		return fmt.Errorf("generating changelog: %w", err)
+	}
+
+	fmt.Printf("Opened changelog PR #%d\n", prNumber)
+
+	return nil
+}
+
+// generatePost writes a post to stdout, or to --out
+func generatePost(args []string) error {
+	flags := flag.NewFlagSet("generate-post", flag.ExitOnError)
+
+	draft := flags.Bool("draft", true, "write the post as a draft")
+	language := flags.String("language", "", "language code to write in, e.g. es")
+	out := flags.String("out", "", "file to write the post to; stdout when empty")
+	points := flags.String("points", "", "semicolon-separated points the post must cover")
+	tags := flags.String("tags", "", "comma-separated tags")
+	title := flags.String("title", "", "the post's title; the AI picks one when empty")
+	topic := flags.String("topic", "", "what the post should cover (required)")
+
+	flags.Parse(args)
+
+	if strings.TrimSpace(*topic) == "" {
+		return errors.New("generate-post needs --topic")
+	}
+
+	blogHandler, err := newBlogHandler()
+	if err != nil {
+		return err
+	}
+
+	post, err := blogHandler.WritePost(
+		&botBlog.BlogPostRequest{
+			Draft:    *draft,
+			Language: *language,
+			Points:   splitList(*points, ";"),
+			Tags:     splitList(*tags, ","),
+			Title:    *title,
+			Topic:    *topic,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("generating post: %w", err)
+	}
+
+	for _, problem := range post.Problems {
+		slog.Warn("Post needs a look", "problem", problem)
+	}
+
+	slog.Info("Post written", "model", post.Model, "path", post.Path)
+
+	return writeOutput(*out, post.Markdown)
+}
+
+// modifyFile edits a file in place with --write, or prints the edited
+// version. Posts are edited as the blog repo would, anything else as code
+func modifyFile(args []string) error {
+	flags := flag.NewFlagSet("modify-file", flag.ExitOnError)
+
+	instruction := flags.String("instruction", "", "the change to make, as a review comment would ask (required)")
+	path := flags.String("path", "", "the file to edit (required)")
+	write := flags.Bool("write", false, "overwrite the file instead of printing the result")
+
+	flags.Parse(args)
+
+	if *path == "" || strings.TrimSpace(*instruction) == "" {
+		return errors.New("modify-file needs --path and --instruction")
+	}
+
+	content, err := os.ReadFile(*path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", *path, err)
+	}
+
+	var modified string
+
+	if isPost(*path) {
+		blogHandler, err := newBlogHandler()
+		if err != nil {
+			return err
+		}
+
+		var notes []string
+
+		if modified, notes, err = blogHandler.ModifyPost(string(content), *instruction); err != nil {
+			return fmt.Errorf("modifying %s: %w", *path, err)
+		}
+
+		for _, note := range notes {
+			slog.Warn("Post needs a look", "problem", note)
+		}
+	} else {
+		codeHandler, err := newCodeHandler()
+		if err != nil {
+			return err
+		}
+
+		if modified, err = codeHandler.ModifyFile(*path, string(content), *instruction); err != nil {
+			return fmt.Errorf("modifying %s: %w", *path, err)
+		}
+	}
+
+	if !*write {
+		return writeOutput("", modified)
+	}
+
+	return writeOutput(*path, modified)
+}
+
+// validateConfig checks the settings both repos would load, and optionally
+// that the GitHub token and Anthropic key work
+func validateConfig(args []string) error {
+	flags := flag.NewFlagSet("validate-config", flag.ExitOnError)
+
+	isOnline := flags.Bool("online", false, "also check the GitHub token and Anthropic key")
+	repliesDir := flags.String("replies", "", "also check the reply templates in a directory, e.g. a repo's "+botReplies.RepoDir)
+	repoFile := flags.String("repo-file", "", "also check a repo's "+botConfig.RepoFile+", applied over each repo's settings")
+
+	flags.Parse(args)
+
+	var problems []error
+
+	repoFileContent := ""
+	if *repoFile != "" {
+		content, err := os.ReadFile(*repoFile)
+		if err != nil {
+			return fmt.Errorf("reading repo file: %w", err)
+		}
+
+		repoFileContent = string(content)
+	}
+
+	for _, name := range []string{"AI_API_KEY", "GITHUB_TOKEN", "GITHUB_OWNER", "GITHUB_REPO_WEBSITE", "GITHUB_REPO_BOT"} {
+		if os.Getenv(name) == "" {
+			problems = append(problems, fmt.Errorf("%s isn't set", name))
+		}
+	}
+
+	for _, prefix := range []string{"BLOG_", "CODE_"} {
+		config := botConfig.LoadFromEnv(prefix)
+
+		if repoFileContent != "" {
+			withFile, err := config.WithRepoFile(repoFileContent)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", *repoFile, err))
+				continue
+			}
+
+			config = withFile
+		}
+
+		if err := config.Validate(); err != nil {
+			problems = append(problems, fmt.Errorf("%s settings: %w", prefix, err))
+		}
+
+		if _, isKnown := botBlog.LookupFrontmatterFormat(config.Frontmatter); !isKnown {
+			problems = append(problems, fmt.Errorf("%s settings: unknown frontmatter format %q", prefix, config.Frontmatter))
+		}
+
+		if config.Style != "" && !botAi.IsStylePreset(config.Style) {
+			problems = append(problems, fmt.Errorf("%s settings: style %q isn't one of %v", prefix, config.Style, botAi.StylePresetNames()))
+		}
+
+		if config.RepliesDir != "" {
+			if err := checkReplies(config.RepliesDir); err != nil {
+				problems = append(problems, fmt.Errorf("%sREPLIES_DIR: %w", prefix, err))
+			}
+		}
+
+		if config.ReplyLanguage != "" {
+			if err := botReplies.CheckLanguage(config.ReplyLanguage); err != nil {
+				problems = append(problems, fmt.Errorf("%sREPLY_LANGUAGE: %w", prefix, err))
+			}
+		}
+	}
+
+	if *repliesDir != "" {
+		if err := checkReplies(*repliesDir); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", *repliesDir, err))
+		}
+	}
+
+	transport, err := newTransport()
+	if err != nil {
+		problems = append(problems, err)
+	}
+
+	if *isOnline && len(problems) == 0 {
+		if _, err := newGithubClient(transport).CheckRateLimit(); err != nil {
+			problems = append(problems, fmt.Errorf("GitHub: %w", err))
+		}
+
+		aiClient, _ := newAiClient()
+		if err := aiClient.CheckModel(); err != nil {
+			problems = append(problems, fmt.Errorf("Anthropic: %w", err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Join(problems...)
+	}
+
+	fmt.Println("Configuration OK")
+
+	return nil
+}
+
+// checkReplies parses the reply template overrides in a directory
+func checkReplies(dir string) error {
+	sources, err := botReplies.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	return botReplies.Check(sources)
+}
+
+// newTransport builds the proxy and CA settings from the same variables as
+// the server
+func newTransport() (http.RoundTripper, error) {
+	transport, err := sharedUtils.NewTransport(
+		sharedUtils.TransportOptions{
+			CABundle: os.Getenv("CA_BUNDLE"),
+			ProxyURL: os.Getenv("PROXY_URL"),
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("HTTP transport: %w", err)
+	}
+
+	return transport, nil
+}
+
+// newGithubClient builds the GitHub client from the same variables as the
+// server
+func newGithubClient(transport http.RoundTripper) *botGithub.Client {
+	return botGithub.NewClient(
+		os.Getenv("GITHUB_TOKEN"),
+		botGithub.WithBaseURL(os.Getenv("GITHUB_API_URL")),
+		botGithub.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+}
+
+// newAiClient builds the AI client from the same variables as the server
+func newAiClient() (*botAi.Client, error) {
+	transport, err := newTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	return botAi.NewClient(
+		os.Getenv("AI_API_KEY"),
+		botAi.ClientOptions{
+			Model:          os.Getenv("AI_MODEL"),
+			ModelFallbacks: splitList(os.Getenv("AI_MODEL_FALLBACKS"), ","),
+			OnUsage: func(usage botAi.Usage) {
+				slog.Info("AI call", "model", usage.Model, "input_tokens", usage.InputTokens, "output_tokens", usage.OutputTokens, "cost_usd", usage.CostUSD)
+			},
+			Transport: transport,
+		},
+	), nil
+}
+
+// newBlogHandler builds a blog handler with the blog repo's settings and no
+// GitHub client, so only the parts of the pipeline that run locally work
+func newBlogHandler() (*botBlog.Handler, error) {
+	if os.Getenv("AI_API_KEY") == "" {
+		return nil, errors.New("AI_API_KEY isn't set")
+	}
+
+	store, err := botState.NewStore("")
+	if err != nil {
+		return nil, err
+	}
+
+	aiClient, err := newAiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return botBlog.NewHandler(
+		botBlog.Handler{
+			AiClient: aiClient,
+			Config:   botConfig.LoadFromEnv("BLOG_"),
+			Owner:    os.Getenv("GITHUB_OWNER"),
+			Repo:     os.Getenv("GITHUB_REPO_WEBSITE"),
+			Store:    store,
+		},
+	), nil
+}
+
+// newCodeHandler builds a code handler with the code repo's settings and no
+// GitHub client
+func newCodeHandler() (*botCode.Handler, error) {
+	if os.Getenv("AI_API_KEY") == "" {
+		return nil, errors.New("AI_API_KEY isn't set")
+	}
+
+	store, err := botState.NewStore("")
+	if err != nil {
+		return nil, err
+	}
+
+	aiClient, err := newAiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return botCode.NewHandler(
+		botCode.Handler{
+			AiClient: aiClient,
+			Config:   botConfig.LoadFromEnv("CODE_"),
+			Owner:    os.Getenv("GITHUB_OWNER"),
+			Repo:     os.Getenv("GITHUB_REPO_BOT"),
+			Store:    store,
+		},
+	), nil
+}
+
+// isPost reports whether a file is a blog post rather than code
+func isPost(path string) bool {
+	return strings.HasSuffix(path, ".md") || strings.HasSuffix(path, ".mdx")
+}
+
+// writeOutput writes content to path, or to stdout when path is empty
+func writeOutput(path, content string) error {
+	if path == "" {
+		_, err := fmt.Print(content)
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// splitList parses a separated list, dropping blanks
+func splitList(value, separator string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, separator) {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+
+	return items
+}
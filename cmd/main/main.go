@@ -2,15 +2,33 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
 
 	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
 	botBlog "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_blog"
 	botCode "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_code"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
 	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botScheduler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_scheduler"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
 	"github.com/google/go-github/v57/github"
 )
 
@@ -20,6 +38,30 @@ func healthCheck(writer http.ResponseWriter, request *http.Request) {
 }
 
 func main() {
+	logger, err := newLogger(os.Getenv("LOG_FORMAT"), os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring logging: %v\n", err)
+		os.Exit(1)
+	}
+
+	slog.SetDefault(logger)
+
+	stateFile := os.Getenv("STATE_FILE")
+	if stateFile == "" {
+		stateFile = "bot_state.json"
+	}
+
+	store, err := botState.NewStore(stateFile)
+	if err != nil {
+		fatal("Loading state failed", "error", err)
+	}
+
+	// `main report` prints PR lifecycle metrics and exits
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		fmt.Print(botState.FormatLifecycleReport(store.LifecycleSummaries()))
+		return
+	}
+
 	aiAPIKey := os.Getenv("AI_API_KEY")
 	githubToken := os.Getenv("GITHUB_TOKEN")
 	owner := os.Getenv("GITHUB_OWNER")
@@ -28,62 +70,456 @@ func main() {
 	webhookSecret := os.Getenv("GITHUB_WEBHOOK_SECRET")
 
 	if aiAPIKey == "" || githubToken == "" || owner == "" || repoWebsite == "" || repoBot == "" {
-		log.Fatal("Missing required environment variables")
+		fatal("Missing required environment variables")
+	}
+
+	// corporate networks may need a proxy or their own CA to reach GitHub and Anthropic
+	transport, err := sharedUtils.NewTransport(
+		sharedUtils.TransportOptions{
+			CABundle: os.Getenv("CA_BUNDLE"),
+			ProxyURL: os.Getenv("PROXY_URL"),
+		},
+	)
+
+	if err != nil {
+		fatal("Configuring the HTTP transport failed", "error", err)
 	}
 
 	// create vendor client instances
-	githubClient := botGithub.NewClient(githubToken)
-	aiClient := botAi.NewClient(aiAPIKey)
+	githubClient := botGithub.NewClient(
+		githubToken,
+		botGithub.WithBaseURL(os.Getenv("GITHUB_API_URL")),
+		botGithub.WithETagCache(500),
+		botGithub.WithHTTPClient(&http.Client{Transport: transport}),
+		botGithub.WithLogger(logger),
+		botGithub.WithRetryPolicy(botGithub.DefaultRetryPolicy),
+		botGithub.WithUserAgent("frankmeza-anthropic-bot"),
+	)
+	aiClient := botAi.NewClient(
+		aiAPIKey,
+		botAi.ClientOptions{
+			Model:          os.Getenv("AI_MODEL"),
+			ModelFallbacks: splitList(os.Getenv("AI_MODEL_FALLBACKS")),
+			Transport:      transport,
+			OnUsage: func(usage botAi.Usage) {
+				if err := store.RecordUsage(
+					botState.RecordUsageArgs{
+						CostUSD:      usage.CostUSD,
+						InputTokens:  usage.InputTokens,
+						Model:        usage.Model,
+						Number:       usage.Issue,
+						OutputTokens: usage.OutputTokens,
+						Repo:         usage.Repo,
+					},
+				); err != nil {
+					slog.Error("Recording token usage failed", "error", err)
+				}
+			},
+		},
+	)
+
+	// each repo labels its requests and PRs with its own kind of content
+	blogConfig := botConfig.LoadFromEnv("BLOG_")
+
+	if blogConfig.Labels.Content == "" {
+		blogConfig.Labels.Content = "blog"
+	}
+
+	if err := blogConfig.Validate(); err != nil {
+		fatal("Invalid blog configuration", "error", err)
+	}
 
 	blogHandler := botBlog.NewHandler(
 		botBlog.Handler{
 			AiClient:      aiClient,
+			Config:        blogConfig,
 			GithubClient:  githubClient,
 			Owner:         owner,
 			Repo:          repoWebsite,
+			Store:         store,
 			WebhookSecret: webhookSecret,
 		},
 	)
 
+	codeConfig := botConfig.LoadFromEnv("CODE_")
+
+	if codeConfig.Labels.Content == "" {
+		codeConfig.Labels.Content = "code-change"
+	}
+
+	if err := codeConfig.Validate(); err != nil {
+		fatal("Invalid code configuration", "error", err)
+	}
+
 	codeHandler := botCode.NewHandler(
 		botCode.Handler{
 			AiClient:      aiClient,
+			Config:        codeConfig,
+			BlogHandler:   blogHandler,
 			GithubClient:  githubClient,
 			Owner:         owner,
 			Repo:          repoBot,
+			Store:         store,
 			WebhookSecret: webhookSecret,
 		},
 	)
 
+	scheduler := botScheduler.NewScheduler()
+
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Minute,
+			Name:     "scheduled-publish",
+			Run:      blogHandler.PublishScheduledPosts,
+		},
+	)
+
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: 5 * time.Minute,
+			Name:     "publish-due-drafts",
+			Run:      blogHandler.PublishDueDrafts,
+		},
+	)
+
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Hour,
+			Name:     "stale-blog-prs",
+			Run:      blogHandler.HandleStalePRs,
+		},
+	)
+
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Minute,
+			Name:     "code-ci-results",
+			Run:      codeHandler.CheckPendingCI,
+		},
+	)
+
+	// catches approved bot PRs whose checks were still running when the approval came in
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Minute,
+			Name:     "auto-merge-blog-prs",
+			Run:      blogHandler.MergeApprovedPRs,
+		},
+	)
+
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Minute,
+			Name:     "auto-merge-code-prs",
+			Run:      codeHandler.MergeApprovedPRs,
+		},
+	)
+
+	// the digest issue lives in the bot's own repo and covers every repo it works on
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Hour,
+			Name:     "weekly-digest",
+			Run:      codeHandler.PostWeeklyDigest,
+		},
+	)
+
+	// reactions don't trigger webhooks, so confirmed previews are picked up by polling
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Minute,
+			Name:     "apply-approved-blog-changes",
+			Run:      blogHandler.ApplyApprovedChanges,
+		},
+	)
+
+	scheduler.Add(
+		botScheduler.Job{
+			Interval: time.Minute,
+			Name:     "apply-approved-code-changes",
+			Run:      codeHandler.ApplyApprovedChanges,
+		},
+	)
+
+	go scheduler.Start(context.Background())
+
 	router := newRouter(
 		router{
 			blogHandler:   blogHandler,
 			codeHandler:   codeHandler,
+			owner:         owner,
 			repoWebsite:   repoWebsite,
 			repoBot:       repoBot,
+			store:         store,
 			webhookSecret: webhookSecret,
 		},
 	)
 
 	http.HandleFunc("/webhook", router.HandleWebhook)
 	http.HandleFunc("/health", healthCheck)
+	http.HandleFunc("/readyz", readinessCheck(githubClient, aiClient))
+
+	// spend reports are only served with an admin token to check callers against
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		http.HandleFunc("/costs", adminOnly(adminToken, costsReport(store)))
+		http.HandleFunc("/failures", adminOnly(adminToken, failuresReport(store)))
+		http.HandleFunc("/metrics", adminOnly(adminToken, costMetrics(store)))
+		http.HandleFunc("/replay", adminOnly(adminToken, router.ReplayDelivery))
+	}
+
+	// canned webhooks for trying the bot locally; they act as whichever user
+	// they name, the owner included, so they're only served with the admin token
+	if os.Getenv("SIMULATE_WEBHOOKS") == "true" {
+		if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+			http.HandleFunc("/simulate", adminOnly(adminToken, router.SimulateWebhook))
+		} else {
+			slog.Error("Not serving /simulate: SIMULATE_WEBHOOKS needs ADMIN_TOKEN, since simulated webhooks can act as the owner")
+		}
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	log.Printf("AI Blog Bot starting on :%s", port)
-	log.Printf("Monitoring repos: %s/%s (blog), %s/%s (code)", owner, repoWebsite, owner, repoBot)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	slog.Info(
+		"AI Blog Bot starting",
+		"port", port,
+		"blog_repo", owner+"/"+repoWebsite,
+		"code_repo", owner+"/"+repoBot,
+	)
+
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           recoverPanics(http.DefaultServeMux),
+		IdleTimeout:       2 * time.Minute,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		// webhooks are answered before they're handled, so no response
+		// waits on the AI
+		WriteTimeout: 30 * time.Second,
+	}
+
+	// a redeploy stops the server, then lets the webhooks already answered
+	// finish, since GitHub won't deliver them again
+	stopped, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			fatal("Server stopped", "error", err)
+		}
+	}()
+
+	<-stopped.Done()
+	slog.Info("Shutting down, finishing webhooks in progress")
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		slog.Error("Shutting down server failed", "error", err)
+	}
+
+	blogHandler.Wait()
+	codeHandler.Wait()
+}
+
+// recoverPanics answers a request whose handler panicked with a 500 and logs
+// the stack, rather than letting one bad event take down the whole process
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			// the server's own way of aborting a response, not a bug
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			slog.Error(
+				"Handler panicked",
+				"path", request.URL.Path,
+				"delivery", github.DeliveryID(request),
+				"panic", recovered,
+				"stack", string(debug.Stack()),
+			)
+
+			http.Error(writer, "internal error", http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// dependencyStatus is whether the bot can reach one of the APIs it depends on
+type dependencyStatus struct {
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+	OK     bool   `json:"ok"`
 }
 
+// readiness is the /readyz report: ready only when every dependency is
+type readiness struct {
+	Dependencies map[string]dependencyStatus `json:"dependencies"`
+	Ready        bool                        `json:"ready"`
+}
+
+// readinessCheck serves whether the bot can actually do its work, checking
+// the GitHub token and the Anthropic key, rather than only that it's running
+func readinessCheck(githubClient *botGithub.Client, aiClient *botAi.Client) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		report := readiness{
+			Dependencies: map[string]dependencyStatus{
+				"anthropic": checkAnthropic(aiClient),
+				"github":    checkGithub(githubClient),
+			},
+			Ready: true,
+		}
+
+		for _, status := range report.Dependencies {
+			report.Ready = report.Ready && status.OK
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		if !report.Ready {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(writer).Encode(report); err != nil {
+			slog.Error("Writing readiness report failed", "error", err)
+		}
+	}
+}
+
+// checkGithub verifies the GitHub token, which also needs API calls left to be useful
+func checkGithub(githubClient *botGithub.Client) dependencyStatus {
+	rateLimit, err := githubClient.CheckRateLimit()
+	if err != nil {
+		return dependencyStatus{Error: err.Error()}
+	}
+
+	detail := fmt.Sprintf("%d of %d API calls left", rateLimit.Remaining, rateLimit.Limit)
+
+	if rateLimit.Remaining == 0 {
+		return dependencyStatus{
+			Detail: detail,
+			Error:  "rate limited until " + rateLimit.Reset.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return dependencyStatus{Detail: detail, OK: true}
+}
+
+// checkAnthropic verifies the Anthropic key and model
+func checkAnthropic(aiClient *botAi.Client) dependencyStatus {
+	if err := aiClient.CheckModel(); err != nil {
+		return dependencyStatus{Error: err.Error()}
+	}
+
+	return dependencyStatus{OK: true}
+}
+
+// adminOnly serves next only to requests with the admin token as their bearer token
+func adminOnly(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		given := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			http.Error(writer, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(writer, request)
+	}
+}
+
+// costsReport serves the AI spend by model, repo and issue as JSON
+func costsReport(store *botState.Store) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(writer).Encode(store.CostSummary()); err != nil {
+			slog.Error("Writing cost report failed", "error", err)
+		}
+	}
+}
+
+// failuresReport serves the failure a comment's ID names (GET
+// /failures?id=ab12cd), or every kept failure, newest first, without an ID
+func failuresReport(store *botState.Store) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var report any = store.Failures()
+
+		if id := request.URL.Query().Get("id"); id != "" {
+			failure, exists := store.Failure(id)
+			if !exists {
+				http.Error(writer, "no failure with that ID", http.StatusNotFound)
+				return
+			}
+
+			report = failure
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(writer).Encode(report); err != nil {
+			slog.Error("Writing failures report failed", "error", err)
+		}
+	}
+}
+
+// costMetrics serves the AI spend for Prometheus to scrape
+func costMetrics(store *botState.Store) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writer.Write([]byte(botState.FormatCostMetrics(store.CostSummary())))
+	}
+}
+
+// newLogger builds the process logger from LOG_FORMAT ("text", the default,
+// or "json") and LOG_LEVEL ("debug", "info", the default, "warn" or "error")
+func newLogger(format, level string) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{}
+
+	if level != "" {
+		var logLevel slog.Level
+		if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("parsing LOG_LEVEL: %w", err)
+		}
+
+		options.Level = logLevel
+	}
+
+	switch strings.ToLower(format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, options)), nil
+	default:
+		return nil, fmt.Errorf("unknown LOG_FORMAT %q, expected text or json", format)
+	}
+}
+
+// fatal logs an error and exits
+func fatal(message string, args ...any) {
+	slog.Error(message, args...)
+	os.Exit(1)
+}
+
+// maxWebhookBytes is GitHub's cap on webhook payloads; anything bigger
+// didn't come from GitHub
+const maxWebhookBytes = 25 << 20
+
 // router handles routing webhooks to the appropriate handler
 type router struct {
 	blogHandler   *botBlog.Handler
 	codeHandler   *botCode.Handler
+	owner         string
 	repoWebsite   string
 	repoBot       string
+	store         *botState.Store
 	webhookSecret string // Add this
 }
 
@@ -91,25 +527,116 @@ func newRouter(args router) *router {
 	return &router{
 		blogHandler:   args.blogHandler,
 		codeHandler:   args.codeHandler,
+		owner:         args.owner,
 		repoWebsite:   args.repoWebsite,
 		repoBot:       args.repoBot,
+		store:         args.store,
 		webhookSecret: args.webhookSecret,
 	}
 }
 
 func (router *router) HandleWebhook(writer http.ResponseWriter, request *http.Request) {
-	// read entire request body
-	body, err := io.ReadAll(request.Body)
+	id := deliveryID(request)
+
+	// every line logged while handling this delivery carries its ID
+	logger := slog.With("delivery", id)
+
+	// read entire request body, up to the most GitHub sends
+	body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, maxWebhookBytes))
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		logger.Warn("Webhook payload too large", "limit", tooLarge.Limit)
+		http.Error(writer, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
 	if err != nil {
-		log.Printf("Error reading body: %v", err)
+		logger.Error("Reading body failed", "error", err)
 		http.Error(writer, "error reading body", http.StatusBadRequest)
 		return
 	}
 
+	router.saveDelivery(logger, id, request, body)
+	router.route(writer, request, logger, body)
+}
+
+// saveDelivery keeps a webhook's payload, with any secrets redacted, so it can
+// be replayed later; deliveries that aren't signed with the webhook secret
+// didn't come from GitHub and aren't kept
+func (router *router) saveDelivery(logger *slog.Logger, id string, request *http.Request, body []byte) {
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	payload, err := github.ValidatePayload(request, []byte(router.webhookSecret))
+	if err != nil {
+		return
+	}
+
+	if err := router.store.SaveDelivery(
+		botState.Delivery{
+			Event:      github.WebHookType(request),
+			ID:         id,
+			Payload:    sharedUtils.RedactSecrets(string(payload)),
+			ReceivedAt: time.Now(),
+		},
+	); err != nil {
+		logger.Error("Saving delivery failed", "error", err)
+	}
+}
+
+// ReplayDelivery sends a kept webhook through the router again, as if GitHub
+// had redelivered it (POST /replay?delivery=<X-GitHub-Delivery>), and answers
+// with the status the handler returned
+func (router *router) ReplayDelivery(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := request.URL.Query().Get("delivery")
+
+	delivery, exists := router.store.Delivery(id)
+	if !exists {
+		http.Error(writer, "no stored delivery with that ID", http.StatusNotFound)
+		return
+	}
+
+	logger := slog.With("delivery", id, "replay", true)
+	logger.Info("Replaying delivery", "event", delivery.Event, "received_at", delivery.ReceivedAt)
+
+	// the payload was redacted, so it's signed again for the handlers to accept
+	router.redeliver(writer, request, logger, delivery.Event, delivery.ID, []byte(delivery.Payload))
+}
+
+// redeliver routes a payload that didn't come straight from GitHub as if it
+// had, signing it with the webhook secret
+func (router *router) redeliver(
+	writer http.ResponseWriter,
+	request *http.Request,
+	logger *slog.Logger,
+	eventType string,
+	id string,
+	body []byte,
+) {
+	signature := hmac.New(sha256.New, []byte(router.webhookSecret))
+	signature.Write(body)
+
+	webhook := request.Clone(request.Context())
+	webhook.Header = http.Header{}
+	webhook.Header.Set("Content-Type", "application/json")
+	webhook.Header.Set(github.EventTypeHeader, eventType)
+	webhook.Header.Set(github.DeliveryIDHeader, id)
+	webhook.Header.Set(github.SHA256SignatureHeader, "sha256="+hex.EncodeToString(signature.Sum(nil)))
+
+	router.route(writer, webhook, logger, body)
+}
+
+// route parses a webhook and hands it to the handler for its repo
+func (router *router) route(writer http.ResponseWriter, request *http.Request, logger *slog.Logger, body []byte) {
 	// parse the event type of the request
 	event, err := github.ParseWebHook(github.WebHookType(request), body)
 	if err != nil {
-		log.Printf("Webhook parsing failed: %v", err)
+		logger.Warn("Webhook parsing failed", "error", err)
 		http.Error(writer, "parsing failed", http.StatusBadRequest)
 		return
 	}
@@ -118,33 +645,73 @@ func (router *router) HandleWebhook(writer http.ResponseWriter, request *http.Re
 
 	switch eventType := event.(type) {
 	case *github.IssuesEvent:
-		repoName = *eventType.Repo.FullName
+		repoName = eventType.GetRepo().GetFullName()
+	case *github.IssueCommentEvent:
+		repoName = eventType.GetRepo().GetFullName()
 	case *github.PullRequestReviewCommentEvent:
-		repoName = *eventType.Repo.FullName
+		repoName = eventType.GetRepo().GetFullName()
+	case *github.PullRequestEvent:
+		repoName = eventType.GetRepo().GetFullName()
+	case *github.PullRequestReviewEvent:
+		repoName = eventType.GetRepo().GetFullName()
+	case *github.PushEvent:
+		repoName = eventType.GetRepo().GetFullName()
 	default:
-		log.Printf("Unknown repo detected 🛸")
+		logger.Debug("Unknown repo detected 🛸", "event", github.WebHookType(request))
 	}
 
-	log.Printf("Detected repo: %s", repoName)
+	logger = logger.With("repo", repoName, "event", github.WebHookType(request))
+	logger.Debug("Detected repo")
 
 	// Recreate the request body for the handler
 	request.Body = io.NopCloser(bytes.NewBuffer(body))
 
 	switch {
 	case contains(repoName, router.repoWebsite):
-		log.Printf("Routing to blog handler")
-		router.blogHandler.HandleWebhook(writer, request)
+		logger.Debug("Routing to blog handler")
+		router.blogHandler.WithLogger(logger).HandleWebhook(writer, request)
 
 	case contains(repoName, router.repoBot):
-		log.Printf("Routing to code handler")
-		router.codeHandler.HandleWebhook(writer, request)
+		logger.Debug("Routing to code handler")
+		router.codeHandler.WithLogger(logger).HandleWebhook(writer, request)
 
 	default:
-		log.Printf("Unknown repository: %s", repoName)
+		logger.Info("Unknown repository")
 		writer.WriteHeader(http.StatusOK)
 	}
 }
 
+// deliveryID identifies a webhook delivery by GitHub's delivery header, or by
+// a random ID for requests without one
+func deliveryID(request *http.Request) string {
+	if id := github.DeliveryID(request); id != "" {
+		return id
+	}
+
+	return randomID()
+}
+
+// randomID returns 16 random hex characters
+func randomID() string {
+	random := make([]byte, 8)
+	rand.Read(random)
+
+	return hex.EncodeToString(random)
+}
+
+// splitList parses a comma-separated environment value, dropping blanks
+func splitList(value string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+
+	return items
+}
+
 func contains(parentString, childString string) bool {
 	doesParentExist := len(parentString) > 0
 	doesChildExist := len(childString) > 0
@@ -154,6 +721,11 @@ func contains(parentString, childString string) bool {
 	// length of parentString minus the length of childString
 	ideallyThisIsZeroIndex := len(parentString) - len(childString)
 
+	// a child longer than its parent can't be at its end, e.g. an event without a repo
+	if ideallyThisIsZeroIndex < 0 {
+		return false
+	}
+
 	// this value can be understood as using
 	// - the difference in length as the beginning index (to the end with the colon character :)
 	// - to compare that with the childString as-is for equality
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// fixtureFiles are canned webhook payloads named <event>.<variant>.json, e.g.
+// issues.opened.json for an "issues" event
+//
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+var fixtureTemplates = template.Must(
+	template.New("fixtures").
+		Funcs(template.FuncMap{"json": toJSON}).
+		ParseFS(fixtureFiles, "fixtures/*.json"),
+)
+
+// simulation fills in a fixture
+type simulation struct {
+	Body     string
+	Branch   string // the PR's head branch
+	Comment  string
+	FullName string
+	Issue    int // the issue a PR closes
+	Label    string
+	Merged   bool
+	Number   int // the issue or PR the event is about
+	Owner    string
+	Path     string // the file a review comment is on
+	Repo     string
+	Sender   string
+	Title    string
+}
+
+// SimulateWebhook sends a canned webhook through the router, so the bot can
+// be exercised locally without GitHub being able to reach it. GET lists the
+// fixtures; POST /simulate?fixture=issues.opened&repo=blog runs one, with the
+// simulation's fields overridable by query parameters of the same name.
+func (router *router) SimulateWebhook(writer http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodGet {
+		writer.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(writer).Encode(fixtureNames()); err != nil {
+			slog.Error("Writing fixture list failed", "error", err)
+		}
+
+		return
+	}
+
+	if request.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := request.URL.Query()
+	name := query.Get("fixture")
+
+	if fixtureTemplates.Lookup(name+".json") == nil {
+		http.Error(writer, fmt.Sprintf("unknown fixture %q; GET /simulate lists them", name), http.StatusNotFound)
+		return
+	}
+
+	values, err := router.newSimulation(query)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var payload bytes.Buffer
+
+	if err := fixtureTemplates.ExecuteTemplate(&payload, name+".json", values); err != nil {
+		http.Error(writer, fmt.Sprintf("rendering fixture: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	id := "simulated-" + randomID()
+	eventType, _, _ := strings.Cut(name, ".")
+
+	logger := slog.With("delivery", id, "simulated", true)
+	logger.Info("Simulating webhook", "fixture", name)
+
+	router.redeliver(writer, request, logger, eventType, id, payload.Bytes())
+}
+
+// newSimulation fills in a simulation from query parameters, defaulting to a
+// request by the repo owner in the blog repo
+func (router *router) newSimulation(query map[string][]string) (simulation, error) {
+	get := func(key, fallback string) string {
+		if values := query[key]; len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+
+		return fallback
+	}
+
+	values := simulation{
+		Comment: get("comment", "Please make the introduction shorter."),
+		Label:   get("label", "bot"),
+		Merged:  get("merged", "true") == "true",
+		Owner:   router.owner,
+		Path:    get("path", ""),
+		Sender:  get("sender", router.owner),
+	}
+
+	var err error
+
+	if values.Number, err = strconv.Atoi(get("number", "1")); err != nil {
+		return simulation{}, fmt.Errorf("number: %w", err)
+	}
+
+	if values.Issue, err = strconv.Atoi(get("issue", "1")); err != nil {
+		return simulation{}, fmt.Errorf("issue: %w", err)
+	}
+
+	switch get("repo", "blog") {
+	case "blog":
+		values.Repo = router.repoWebsite
+		values.Title = get("title", "Blog post: Notes from a local test run")
+		values.Body = get("body", "A short post about testing a GitHub bot locally with simulated webhooks.")
+		values.Branch = get("branch", fmt.Sprintf("ai-assisted-post-%d", values.Issue))
+	case "code":
+		values.Repo = router.repoBot
+		values.Title = get("title", "Code: Log the bot's version at startup")
+		values.Body = get("body", "Log the module version once when the server starts.")
+		values.Branch = get("branch", fmt.Sprintf("ai-code-change-%d", values.Issue))
+	default:
+		return simulation{}, fmt.Errorf("repo must be blog or code")
+	}
+
+	values.FullName = values.Owner + "/" + values.Repo
+
+	return values, nil
+}
+
+// fixtureNames lists the fixtures SimulateWebhook can send
+func fixtureNames() []string {
+	names := []string{}
+
+	files, _ := fs.Glob(fixtureFiles, "fixtures/*.json")
+
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(path.Base(file), ".json"))
+	}
+
+	return names
+}
+
+// toJSON quotes a value for a fixture, escaping it as JSON
+func toJSON(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+
+	return string(encoded), err
+}
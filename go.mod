@@ -3,9 +3,12 @@ module github.com/frankmeza/frankmeza-anthropic-bot
 go 1.25.1
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/anthropics/anthropic-sdk-go v1.12.0
 	github.com/google/go-github/v57 v57.0.0
 	golang.org/x/oauth2 v0.31.0
+	golang.org/x/text v0.27.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
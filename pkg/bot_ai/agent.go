@@ -0,0 +1,168 @@
+package botai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// maxAgentTurns bounds how many tool round-trips a single code change may take
+const maxAgentTurns = 20
+
+// Workspace is the view of the target repository the code agent works against
+type Workspace interface {
+	ListDirectory(path string) ([]string, error)
+	ReadFile(path string) (string, error)
+	WriteFile(path, content string) error
+}
+
+// agentToolInput covers the arguments of every agent tool
+type agentToolInput struct {
+	Content string `json:"content"`
+	Path    string `json:"path"`
+}
+
+var agentTools = []anthropic.ToolParam{
+	{
+		Name:        "list_directory",
+		Description: anthropic.String("List the files and directories at a path in the repository. Use \"\" for the root."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"path": map[string]any{"type": "string"},
+			},
+			Required: []string{"path"},
+		},
+	},
+	{
+		Name:        "read_file",
+		Description: anthropic.String("Read the full contents of a file in the repository."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"path": map[string]any{"type": "string"},
+			},
+			Required: []string{"path"},
+		},
+	},
+	{
+		Name:        "write_file",
+		Description: anthropic.String("Create or overwrite a file with the complete new contents. Writes are committed together once you finish."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"path":    map[string]any{"type": "string"},
+				"content": map[string]any{"type": "string"},
+			},
+			Required: []string{"path", "content"},
+		},
+	},
+}
+
+// codeAgentSystemPrompt explains the tool loop on top of the usual Go standards
+const codeAgentSystemPrompt = goDeveloperSystemPrompt + `
+
+You are working directly in the repository through tools:
+- Use list_directory and read_file to understand the existing code before changing it
+- Use write_file once per file with its complete contents; a change may span several files
+- Keep package declarations and imports consistent across the files you touch
+
+When the change is complete, stop calling tools and reply with a short summary of what you changed and why.`
+
+// RunCodeAgent lets Claude explore and edit the workspace with tools until it
+// finishes, returning its closing summary
+func (c *Client) RunCodeAgent(request *CodeRequest, workspace Workspace) (*Completion, error) {
+	params := sharedUtils.CreateMessageParams(
+		codeAgentSystemPrompt,
+		buildCodeGenerationPrompt(request),
+	)
+
+	for _, tool := range agentTools {
+		params.Tools = append(params.Tools, anthropic.ToolUnionParam{OfTool: &tool})
+	}
+
+	for turn := 0; turn < maxAgentTurns; turn++ {
+		message, err := c.newMessage(params)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Messages = append(params.Messages, message.ToParam())
+
+		if message.StopReason != anthropic.StopReasonToolUse {
+			return &Completion{
+				Model: string(message.Model),
+				Text:  collectText(message),
+			}, nil
+		}
+
+		toolResults := []anthropic.ContentBlockParamUnion{}
+
+		for _, block := range message.Content {
+			if block.Type != "tool_use" {
+				continue
+			}
+
+			result, err := runAgentTool(workspace, block.Name, block.Input)
+			isError := err != nil
+
+			if isError {
+				result = err.Error()
+			}
+
+			toolResults = append(
+				toolResults,
+				anthropic.NewToolResultBlock(block.ID, result, isError),
+			)
+		}
+
+		params.Messages = append(params.Messages, anthropic.NewUserMessage(toolResults...))
+	}
+
+	return nil, fmt.Errorf("code agent did not finish within %d turns", maxAgentTurns)
+}
+
+// runAgentTool executes a single tool call against the workspace
+func runAgentTool(workspace Workspace, name string, rawInput json.RawMessage) (string, error) {
+	var input agentToolInput
+
+	if err := json.Unmarshal(rawInput, &input); err != nil {
+		return "", fmt.Errorf("invalid %s input: %w", name, err)
+	}
+
+	switch name {
+	case "list_directory":
+		entries, err := workspace.ListDirectory(input.Path)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.Join(entries, "\n"), nil
+
+	case "read_file":
+		return workspace.ReadFile(input.Path)
+
+	case "write_file":
+		if err := workspace.WriteFile(input.Path, input.Content); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("wrote %s", input.Path), nil
+	}
+
+	return "", fmt.Errorf("unknown tool %q", name)
+}
+
+// collectText joins every text block in a response
+func collectText(message *anthropic.Message) string {
+	var text strings.Builder
+
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	return strings.TrimSpace(text.String())
+}
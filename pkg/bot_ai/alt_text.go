@@ -0,0 +1,109 @@
+package botai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// ImageReference is an image in a post that needs alt text, with the text
+// around it for context
+type ImageReference struct {
+	Context string
+	URL     string
+}
+
+// altTextSystemPrompt sets the rules for image descriptions
+const altTextSystemPrompt = `You write alt text for images in posts on a developer's personal website, so screen reader users get what sighted readers do.
+
+Describe what each image shows and why it's there, in one short sentence without "image of" or "picture of". For diagrams and screenshots, say what they show rather than how they look. When an image is attached, describe it; when it isn't, work from its file name and the text around it, and give an empty string if that isn't enough to describe it honestly. Hand the descriptions back by calling the submit_alt_text tool.`
+
+// submitAltTextTool is the tool Claude calls to hand back alt text
+var submitAltTextTool = anthropic.ToolParam{
+	Name:        "submit_alt_text",
+	Description: anthropic.String("Submit alt text for each image, in the order they were listed."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"alt_texts": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "One description per image, in order; an empty string when the image can't be described",
+			},
+		},
+		Required: []string{"alt_texts"},
+	},
+}
+
+// GenerateAltText describes the images in a post, returning one alt text per
+// image in order; an empty one means the AI couldn't tell what it shows.
+// Images at http(s) URLs are sent along so the AI can look at them
+func (client *Client) GenerateAltText(title string, images []ImageReference) ([]string, error) {
+	prompt := buildAltTextPrompt(title, images)
+
+	output := struct {
+		AltTexts []string `json:"alt_texts"`
+	}{}
+
+	params := sharedUtils.CreateMessageParams(altTextSystemPrompt, prompt)
+	blocks := imageBlocks(images)
+
+	if len(blocks) > 0 {
+		params.Messages[0].Content = append(blocks, params.Messages[0].Content...)
+	}
+
+	_, err := client.completeParamsWithTool(params, submitAltTextTool, &output)
+
+	// an image URL the API can't fetch fails the whole request, so fall back to describing from context
+	if err != nil && len(blocks) > 0 {
+		client.logger.Warn("Describing images failed, retrying from context alone", "error", err)
+		_, err = client.completeWithTool(altTextSystemPrompt, prompt, submitAltTextTool, &output)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.AltTexts) != len(images) {
+		return nil, fmt.Errorf("AI returned %d alt texts for %d images", len(output.AltTexts), len(images))
+	}
+
+	return output.AltTexts, nil
+}
+
+// imageBlocks attaches the images the API can fetch itself
+func imageBlocks(images []ImageReference) []anthropic.ContentBlockParamUnion {
+	blocks := []anthropic.ContentBlockParamUnion{}
+
+	for _, image := range images {
+		if isFetchableImage(image.URL) {
+			blocks = append(blocks, anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: image.URL}))
+		}
+	}
+
+	return blocks
+}
+
+func isFetchableImage(url string) bool {
+	return strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")
+}
+
+// buildAltTextPrompt lists the images needing alt text
+func buildAltTextPrompt(title string, images []ImageReference) string {
+	var prompt strings.Builder
+
+	fmt.Fprintf(&prompt, "Post title: %s\n\nImages needing alt text (those marked attached are attached in the same order):\n", title)
+
+	for index, image := range images {
+		attached := ""
+		if isFetchableImage(image.URL) {
+			attached = " (attached)"
+		}
+
+		fmt.Fprintf(&prompt, "\n%d. %s%s\nText around it:\n%s\n", index+1, image.URL, attached, image.Context)
+	}
+
+	return prompt.String()
+}
@@ -0,0 +1,37 @@
+package botai
+
+import "log/slog"
+
+// AIClient is the part of the AI client the handlers use. Client is the
+// real implementation; botaitest.MockClient returns canned content for tests
+type AIClient interface {
+	Explain(request *ExplainRequest) (*Completion, error)
+	FixCode(path, content, problem string) (string, error)
+	FixGoSnippet(code, problem string, validate func(code string) error) (string, error)
+	ForIssue(repo string, number int) AIClient
+	GenerateAltText(title string, images []ImageReference) ([]string, error)
+	GenerateBlogOutline(request *BlogPostRequest) (*BlogOutline, error)
+	GenerateBlogPost(request *BlogPostRequest) (*BlogPostDraft, error)
+	GenerateBlogSection(request *BlogPostRequest, outline *BlogOutline, sectionIndex int, writtenSoFar string) (*Completion, error)
+	GenerateSEOMetadata(title, content string) (*SEOMetadata, error)
+	GenerateSummary(title, content string) (string, error)
+	GenerateTests(path, content string) (*Completion, error)
+	GenerateWriteUp(request *WriteUpRequest) (*Completion, error)
+	ModifyBlogPost(currentContent, changeRequest, diffHunk string, history []Turn) (string, error)
+	ModifyCode(currentContent, changeRequest, diffHunk string, history []Turn) (string, error)
+	Proofread(content string) (string, error)
+	ReviewPullRequest(request *ReviewRequest) (*PullRequestReview, error)
+	RunCodeAgent(request *CodeRequest, workspace Workspace) (*Completion, error)
+	SuggestChange(request *SuggestionRequest) (string, error)
+	SuggestRelatedPosts(request *RelatedPostsRequest) ([]string, error)
+	SuggestTags(title, content string) ([]string, error)
+	Translate(request *TranslateRequest) (*Translation, error)
+	TriageIssue(request *IssueTriageRequest) (*IssueTriage, error)
+	WithBudget(checkBudget func() error) AIClient
+	WithLogger(logger *slog.Logger) AIClient
+	WithModel(model string, fallbacks []string) AIClient
+	WriteReleaseNotes(request *ReleaseNotesRequest) (string, error)
+	WriteSocialPosts(request *SocialPostsRequest) (SocialPosts, error)
+}
+
+var _ AIClient = (*Client)(nil)
@@ -0,0 +1,256 @@
+package botaitest
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"sync"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+)
+
+// MockModel is the model MockClient says wrote everything
+const MockModel = "mock-model"
+
+// goPackagePattern finds the package clause of a Go file
+var goPackagePattern = regexp.MustCompile(`(?m)^package (\w+)`)
+
+// MockClient is a botAi.AIClient for tests that answers every call with short
+// canned content and records which methods were called. It makes no API
+// calls, so budgets, loggers, models and usage reporting don't apply to it
+type MockClient struct {
+	Calls []string          // method names, in call order
+	Files map[string]string // what RunCodeAgent writes; empty writes one file at the request's target path
+
+	mutex sync.Mutex
+}
+
+// NewMockClient returns a mock whose code agent writes files
+func NewMockClient(files map[string]string) *MockClient {
+	return &MockClient{Files: files}
+}
+
+var _ botAi.AIClient = (*MockClient)(nil)
+
+func (mock *MockClient) record(method string) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	mock.Calls = append(mock.Calls, method)
+}
+
+func (mock *MockClient) Explain(request *botAi.ExplainRequest) (*botAi.Completion, error) {
+	mock.record("Explain")
+	return &botAi.Completion{Model: MockModel, Text: "This is a mock explanation."}, nil
+}
+
+func (mock *MockClient) FixCode(path, content, problem string) (string, error) {
+	mock.record("FixCode")
+	return content, nil
+}
+
+func (mock *MockClient) FixGoSnippet(code, problem string, validate func(code string) error) (string, error) {
+	mock.record("FixGoSnippet")
+	return code, nil
+}
+
+func (mock *MockClient) ForIssue(repo string, number int) botAi.AIClient {
+	return mock
+}
+
+func (mock *MockClient) GenerateAltText(title string, images []botAi.ImageReference) ([]string, error) {
+	mock.record("GenerateAltText")
+
+	descriptions := []string{}
+
+	for index := range images {
+		descriptions = append(descriptions, fmt.Sprintf("Mock image %d", index+1))
+	}
+
+	return descriptions, nil
+}
+
+func (mock *MockClient) GenerateBlogOutline(request *botAi.BlogPostRequest) (*botAi.BlogOutline, error) {
+	mock.record("GenerateBlogOutline")
+
+	return &botAi.BlogOutline{
+		Sections: []string{"Introduction", "Wrapping up"},
+		Summary:  "A mock summary.",
+		Title:    mockTitle(request.Title),
+	}, nil
+}
+
+func (mock *MockClient) GenerateBlogPost(request *botAi.BlogPostRequest) (*botAi.BlogPostDraft, error) {
+	mock.record("GenerateBlogPost")
+
+	return &botAi.BlogPostDraft{
+		Body:    fmt.Sprintf("## Introduction\n\nA mock post about %s.\n", request.Topic),
+		Model:   MockModel,
+		Summary: "A mock summary.",
+		Title:   mockTitle(request.Title),
+	}, nil
+}
+
+func (mock *MockClient) GenerateBlogSection(
+	request *botAi.BlogPostRequest,
+	outline *botAi.BlogOutline,
+	sectionIndex int,
+	writtenSoFar string,
+) (*botAi.Completion, error) {
+	mock.record("GenerateBlogSection")
+
+	return &botAi.Completion{
+		Model: MockModel,
+		Text:  fmt.Sprintf("## %s\n\nA mock section.\n", outline.Sections[sectionIndex]),
+	}, nil
+}
+
+func (mock *MockClient) GenerateSEOMetadata(title, content string) (*botAi.SEOMetadata, error) {
+	mock.record("GenerateSEOMetadata")
+
+	return &botAi.SEOMetadata{
+		MetaDescription:      "A mock description.",
+		OpenGraphDescription: "A mock description.",
+		OpenGraphTitle:       title,
+	}, nil
+}
+
+func (mock *MockClient) GenerateSummary(title, content string) (string, error) {
+	mock.record("GenerateSummary")
+	return "A mock summary.", nil
+}
+
+// GenerateTests writes an empty test in the same package as a Go file
+func (mock *MockClient) GenerateTests(path, content string) (*botAi.Completion, error) {
+	mock.record("GenerateTests")
+
+	packageName := "main"
+	if match := goPackagePattern.FindStringSubmatch(content); match != nil {
+		packageName = match[1]
+	}
+
+	return &botAi.Completion{
+		Model: MockModel,
+		Text:  fmt.Sprintf("package %s\n\nimport \"testing\"\n\nfunc TestMock(t *testing.T) {}\n", packageName),
+	}, nil
+}
+
+func (mock *MockClient) GenerateWriteUp(request *botAi.WriteUpRequest) (*botAi.Completion, error) {
+	mock.record("GenerateWriteUp")
+	return &botAi.Completion{Model: MockModel, Text: "## A mock write-up\n\nIt went well.\n"}, nil
+}
+
+// ModifyBlogPost appends the change request to the post
+func (mock *MockClient) ModifyBlogPost(currentContent, changeRequest, diffHunk string, history []botAi.Turn) (string, error) {
+	mock.record("ModifyBlogPost")
+	return fmt.Sprintf("%s\nEdited: %s\n", currentContent, changeRequest), nil
+}
+
+// ModifyCode appends the change request to the file as a line comment
+func (mock *MockClient) ModifyCode(currentContent, changeRequest, diffHunk string, history []botAi.Turn) (string, error) {
+	mock.record("ModifyCode")
+	return fmt.Sprintf("%s\n// Edited: %s\n", currentContent, changeRequest), nil
+}
+
+func (mock *MockClient) Proofread(content string) (string, error) {
+	mock.record("Proofread")
+	return content, nil
+}
+
+func (mock *MockClient) ReviewPullRequest(request *botAi.ReviewRequest) (*botAi.PullRequestReview, error) {
+	mock.record("ReviewPullRequest")
+	return &botAi.PullRequestReview{Model: MockModel, Summary: "A mock review: looks good."}, nil
+}
+
+// RunCodeAgent writes Files to the workspace, or a single file at the
+// request's target path when there are none
+func (mock *MockClient) RunCodeAgent(request *botAi.CodeRequest, workspace botAi.Workspace) (*botAi.Completion, error) {
+	mock.record("RunCodeAgent")
+
+	files := mock.Files
+
+	if len(files) == 0 {
+		targetPath := request.TargetPath
+		if targetPath == "" {
+			targetPath = "docs/mock.md"
+		}
+
+		content := fmt.Sprintf("Mock content for %s\n", request.Title)
+		if path.Ext(targetPath) == ".go" {
+			content = fmt.Sprintf("package %s\n", path.Base(path.Dir(targetPath)))
+		}
+
+		files = map[string]string{targetPath: content}
+	}
+
+	for filePath, content := range files {
+		if err := workspace.WriteFile(filePath, content); err != nil {
+			return nil, err
+		}
+	}
+
+	return &botAi.Completion{Model: MockModel, Text: "A mock change."}, nil
+}
+
+func (mock *MockClient) SuggestChange(request *botAi.SuggestionRequest) (string, error) {
+	mock.record("SuggestChange")
+	return request.Lines, nil
+}
+
+func (mock *MockClient) SuggestRelatedPosts(request *botAi.RelatedPostsRequest) ([]string, error) {
+	mock.record("SuggestRelatedPosts")
+	return nil, nil
+}
+
+func (mock *MockClient) SuggestTags(title, content string) ([]string, error) {
+	mock.record("SuggestTags")
+	return nil, nil
+}
+
+func (mock *MockClient) Translate(request *botAi.TranslateRequest) (*botAi.Translation, error) {
+	mock.record("Translate")
+
+	return &botAi.Translation{
+		Body:    request.Content,
+		Model:   MockModel,
+		Summary: request.Summary,
+		Title:   request.Title,
+	}, nil
+}
+
+func (mock *MockClient) TriageIssue(request *botAi.IssueTriageRequest) (*botAi.IssueTriage, error) {
+	mock.record("TriageIssue")
+	return &botAi.IssueTriage{Acknowledgment: "Thanks for the report!", Category: botAi.IssueCategoryOther}, nil
+}
+
+func (mock *MockClient) WithBudget(checkBudget func() error) botAi.AIClient {
+	return mock
+}
+
+func (mock *MockClient) WithLogger(logger *slog.Logger) botAi.AIClient {
+	return mock
+}
+
+func (mock *MockClient) WithModel(model string, fallbacks []string) botAi.AIClient {
+	return mock
+}
+
+func (mock *MockClient) WriteReleaseNotes(request *botAi.ReleaseNotesRequest) (string, error) {
+	mock.record("WriteReleaseNotes")
+	return "- A mock change\n", nil
+}
+
+func (mock *MockClient) WriteSocialPosts(request *botAi.SocialPostsRequest) (botAi.SocialPosts, error) {
+	mock.record("WriteSocialPosts")
+	return botAi.SocialPosts{}, nil
+}
+
+// mockTitle keeps the requested title, making one up when there isn't one
+func mockTitle(title string) string {
+	if title != "" {
+		return title
+	}
+
+	return "A Mock Post"
+}
@@ -0,0 +1,109 @@
+package botai
+
+import (
+	"fmt"
+	"strings"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// maxReleaseNoteBody keeps long PR descriptions from crowding out the rest
+const maxReleaseNoteBody = 1500
+
+// ReleaseNotesRequest lists the pull requests merged since the last release
+type ReleaseNotesRequest struct {
+	PullRequests []MergedPullRequest
+	Since        string // the tag the notes start from; empty when there is none
+}
+
+// MergedPullRequest is one merged pull request to describe in release notes
+type MergedPullRequest struct {
+	Body   string
+	Labels []string
+	Number int
+	Title  string
+}
+
+// releaseNotesSystemPrompt sets the format for changelog entries
+const releaseNotesSystemPrompt = `You write release notes for a CHANGELOG.md file from a list of merged pull requests.
+
+Format:
+- Group entries under "### " headings, in this order, leaving out empty groups: Features, Fixes, Improvements, Documentation, Internal
+- One bullet per change: a short sentence in the past tense written for users of the project, ending with the PR reference, e.g. "(#42)"
+- Combine pull requests that make one change into a single bullet listing each reference
+- Leave out pull requests with no effect on users, like reverted changes, unless nothing else is left in their group
+
+Reply with only the grouped bullets - no "#" or "##" headings, introduction, or code fences.`
+
+// WriteReleaseNotes groups merged pull requests into changelog entries
+func (client *Client) WriteReleaseNotes(request *ReleaseNotesRequest) (string, error) {
+	completion, err := client.completeChecked(
+		releaseNotesSystemPrompt,
+		nil,
+		buildReleaseNotesPrompt(request),
+		checkReleaseNotes,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return completion.Text, nil
+}
+
+// checkReleaseNotes makes sure a reply is grouped bullets, without headings
+// that would clash with the version heading the notes are filed under
+func checkReleaseNotes(reply string) (string, error) {
+	notes, err := checkNotEmpty(strings.TrimSpace(unwrapCodeBlock(reply)))
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.Contains(notes, "### ") {
+		return "", fmt.Errorf("the notes aren't grouped under \"### \" headings")
+	}
+
+	for line := range strings.SplitSeq(notes, "\n") {
+		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
+			return "", fmt.Errorf("the notes contain a top-level heading: %q", line)
+		}
+	}
+
+	return notes, nil
+}
+
+// unwrapCodeBlock returns the body of a reply wrapped in a single code block
+func unwrapCodeBlock(reply string) string {
+	trimmed := strings.TrimSpace(reply)
+
+	if match := fencedBlockPattern.FindStringSubmatch(trimmed); match != nil && match[0] == trimmed {
+		return match[1]
+	}
+
+	return reply
+}
+
+// buildReleaseNotesPrompt lists each pull request's title, labels and description
+func buildReleaseNotesPrompt(request *ReleaseNotesRequest) string {
+	var prompt strings.Builder
+
+	if request.Since != "" {
+		prompt.WriteString(fmt.Sprintf("Write release notes for the pull requests merged since %s.\n", request.Since))
+	} else {
+		prompt.WriteString("Write release notes for these merged pull requests.\n")
+	}
+
+	for _, pullRequest := range request.PullRequests {
+		prompt.WriteString(fmt.Sprintf("\n---\n**#%d: %s**\n", pullRequest.Number, pullRequest.Title))
+
+		if len(pullRequest.Labels) > 0 {
+			prompt.WriteString(fmt.Sprintf("Labels: %s\n", strings.Join(pullRequest.Labels, ", ")))
+		}
+
+		if body := strings.TrimSpace(pullRequest.Body); body != "" {
+			prompt.WriteString("\n" + sharedUtils.TruncateText(body, maxReleaseNoteBody) + "\n")
+		}
+	}
+
+	return prompt.String()
+}
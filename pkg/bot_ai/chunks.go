@@ -0,0 +1,267 @@
+package botai
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// maxModifyTokens is the largest content rewritten in one reply. Edits return
+// the whole file, so anything bigger would be cut off at MaxTokens and is
+// edited one chunk at a time instead
+const maxModifyTokens = 4000
+
+// ErrContentTooLarge means the part of a file a change is about can't be
+// rewritten within the reply limit
+var ErrContentTooLarge = errors.New("content is too large to edit safely")
+
+// ErrOutputTruncated means a reply hit the token limit and is incomplete
+var ErrOutputTruncated = errors.New("AI reply was cut off at the token limit")
+
+// ContentTooLargeError is an ErrContentTooLarge that says how large the part
+// being changed is, in words fit for a comment
+type ContentTooLargeError struct {
+	Limit  int // the most tokens rewritten in one reply
+	Tokens int // the size of the part being changed; 0 when it couldn't be found
+}
+
+func (tooLargeError *ContentTooLargeError) Error() string {
+	if tooLargeError.Tokens == 0 {
+		return fmt.Sprintf("%v: no section of the file matched the change", ErrContentTooLarge)
+	}
+
+	return fmt.Sprintf("%v: the section being changed is about %d tokens, over %d", ErrContentTooLarge, tooLargeError.Tokens, tooLargeError.Limit)
+}
+
+func (tooLargeError *ContentTooLargeError) Unwrap() error {
+	return ErrContentTooLarge
+}
+
+func (tooLargeError *ContentTooLargeError) UserMessage() string {
+	if tooLargeError.Tokens == 0 {
+		return "I couldn't tell which part of it the change is about."
+	}
+
+	return fmt.Sprintf("The part being changed is about %d tokens, more than the %d I can rewrite at once.", tooLargeError.Tokens, tooLargeError.Limit)
+}
+
+func (tooLargeError *ContentTooLargeError) Category() string {
+	return "validation"
+}
+
+type modifyArgs struct {
+	BuildPrompt    func(currentContent, changeRequest, diffHunk string) string
+	ChangeRequest  string
+	Clean          func(reply string) string // strips wrapping from a reply
+	CurrentContent string
+	DiffHunk       string
+	History        []Turn
+	Split          func(content string) []string
+	SystemPrompt   string
+	Validate       func(content string) error // checks the whole edited content
+}
+
+// modify applies a change request, rewriting the whole content when it fits
+// and only the chunk the change is about when it doesn't
+func (client *Client) modify(args modifyArgs) (string, error) {
+	chunks := []string{args.CurrentContent}
+	index := 0
+	promptPrefix := ""
+
+	if client.countTokens(args.CurrentContent) > maxModifyTokens {
+		chunks = args.Split(args.CurrentContent)
+
+		var isFound bool
+
+		if index, isFound = findHunkChunk(chunks, args.DiffHunk); !isFound {
+			var err error
+
+			if index, err = client.pickChunk(chunks, args.ChangeRequest); err != nil {
+				return "", err
+			}
+		}
+
+		if tokens := client.countTokens(chunks[index]); tokens > maxModifyTokens {
+			return "", &ContentTooLargeError{Limit: maxModifyTokens, Tokens: tokens}
+		}
+
+		promptPrefix = "This is one section of a larger file. Return only this section with the change applied.\n\n"
+	}
+
+	chunk := chunks[index]
+	edited := ""
+
+	check := func(reply string) (string, error) {
+		cleaned, err := checkNotEmpty(args.Clean(reply))
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasSuffix(chunk, "\n") && !strings.HasSuffix(cleaned, "\n") {
+			cleaned += "\n"
+		}
+
+		chunks[index] = cleaned
+		edited = strings.Join(chunks, "")
+
+		return cleaned, args.Validate(edited)
+	}
+
+	if _, err := client.completeChecked(
+		args.SystemPrompt,
+		args.History,
+		promptPrefix+args.BuildPrompt(chunk, args.ChangeRequest, args.DiffHunk),
+		check,
+	); err != nil {
+		return "", err
+	}
+
+	return edited, nil
+}
+
+// countTokens asks the API how many tokens text is, estimating four
+// characters a token if the count fails
+func (client *Client) countTokens(text string) int {
+	count, err := client.anthropic.Messages.CountTokens(
+		client.context,
+		anthropic.MessageCountTokensParams{
+			Messages: []anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock(text)),
+			},
+			Model: anthropic.Model(client.model),
+		},
+	)
+
+	if err != nil {
+		client.logger.Warn("Counting tokens failed, estimating instead", "error", err)
+		return len(text) / 4
+	}
+
+	return int(count.InputTokens)
+}
+
+// findHunkChunk finds the chunk holding the line a review comment was left
+// on, which is the last line of its diff hunk
+func findHunkChunk(chunks []string, diffHunk string) (int, bool) {
+	lines := strings.Split(strings.TrimRight(diffHunk, "\n"), "\n")
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+
+		// only lines that exist in the PR's version of the file can be found in it
+		if line == "" || (line[0] != ' ' && line[0] != '+') {
+			continue
+		}
+
+		text := strings.TrimSpace(line[1:])
+		if text == "" {
+			continue
+		}
+
+		for index, chunk := range chunks {
+			if strings.Contains(chunk, text) {
+				return index, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// pickChunk asks the AI which chunk a change request is about, for comments
+// that weren't left on specific lines
+func (client *Client) pickChunk(chunks []string, changeRequest string) (int, error) {
+	var listing strings.Builder
+
+	for index, chunk := range chunks {
+		firstLine := strings.TrimSpace(strings.SplitN(strings.TrimSpace(chunk), "\n", 2)[0])
+		listing.WriteString(fmt.Sprintf("%d: %s\n", index, sharedUtils.TruncateText(firstLine, 100)))
+	}
+
+	completion, err := client.complete(
+		"",
+		fmt.Sprintf(
+			"A file is split into these numbered sections, shown by their first line:\n\n%s\nWhich one section does this change request apply to? Reply with only its number.\n\n**Requested change:** %q",
+			listing.String(),
+			changeRequest,
+		),
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(completion.Text))
+	if err != nil || index < 0 || index >= len(chunks) {
+		return 0, &ContentTooLargeError{Limit: maxModifyTokens}
+	}
+
+	return index, nil
+}
+
+// splitMarkdownSections splits markdown before each heading outside code
+// fences; frontmatter stays with the first section
+func splitMarkdownSections(content string) []string {
+	isInFence := false
+
+	return splitBefore(content, func(lines []string, index int) bool {
+		line := lines[index]
+
+		if strings.HasPrefix(line, "```") {
+			isInFence = !isInFence
+			return false
+		}
+
+		return !isInFence && strings.HasPrefix(line, "#")
+	})
+}
+
+// splitGoDeclarations splits Go source before each top-level declaration,
+// keeping doc comments with the declaration they document
+func splitGoDeclarations(content string) []string {
+	return splitBefore(content, func(lines []string, index int) bool {
+		line := lines[index]
+
+		isComment := strings.HasPrefix(line, "//")
+		isDeclaration := strings.HasPrefix(line, "func ") ||
+			strings.HasPrefix(line, "type ") ||
+			strings.HasPrefix(line, "var ") ||
+			strings.HasPrefix(line, "const ")
+
+		if !isComment && !isDeclaration {
+			return false
+		}
+
+		// a declaration or comment block starts a chunk unless a comment is right above it
+		return index == 0 || !strings.HasPrefix(lines[index-1], "//")
+	})
+}
+
+// splitBefore cuts content into chunks that start at lines isBoundary picks;
+// joining the chunks gives back the original content
+func splitBefore(content string, isBoundary func(lines []string, index int) bool) []string {
+	lines := strings.SplitAfter(content, "\n")
+	chunks := []string{}
+
+	var current strings.Builder
+
+	for index := range lines {
+		if isBoundary(lines, index) && current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+
+		current.WriteString(lines[index])
+	}
+
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
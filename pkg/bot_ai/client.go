@@ -3,83 +3,272 @@ package botai
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
 	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
 )
 
+// PromptVersion identifies the current prompt set, so PR outcomes can be
+// compared across prompt changes
+const PromptVersion = "v3"
+
 // Client handles all AI operations using Anthropic's Claude
 type Client struct {
-	anthropic *anthropic.Client
-	context   context.Context
+	anthropic      *anthropic.Client
+	checkBudget    func() error
+	context        context.Context
+	logger         *slog.Logger
+	model          string
+	modelFallbacks []string
+	onUsage        func(usage Usage)
+	usageIssue     int
+	usageRepo      string
+}
+
+// ClientOptions configures optional AI client behavior
+type ClientOptions struct {
+	Logger         *slog.Logger // defaults to slog.Default()
+	Model          string       // defaults to Claude 3.7 Sonnet
+	ModelFallbacks []string     // tried in order when the primary model is overloaded
+
+	// Transport sends the client's requests, e.g. through a proxy; nil uses
+	// http.DefaultTransport
+	Transport http.RoundTripper
+
+	// OnUsage is called with what each API call used, e.g. to track spend
+	OnUsage func(usage Usage)
 }
 
 // BlogPostRequest represents the data needed to generate a blog post
 type BlogPostRequest struct {
-	Draft  bool     `json:"draft"`
-	Points []string `json:"points"`
-	Tags   []string `json:"tags"`
-	Title  string   `json:"title"`
-	Topic  string   `json:"topic"`
+	Draft    bool     `json:"draft"`
+	Guidance string   `json:"guidance"` // extra instructions, e.g. from /regenerate
+	Language string   `json:"language"` // language code to write in; empty for English
+	Points   []string `json:"points"`
+	Style    string   `json:"style"` // style preset name; empty for casual
+	Tags     []string `json:"tags"`
+	Title    string   `json:"title"`
+	Topic    string   `json:"topic"`
+	Words    int      `json:"words"` // target word count; 0 leaves the length to the model
+}
+
+// BlogPostDraft is the structured blog post returned by the submit_blog_post tool
+type BlogPostDraft struct {
+	Body    string   `json:"body"`
+	Model   string   `json:"-"`
+	Summary string   `json:"summary"`
+	Tags    []string `json:"tags"`
+	Title   string   `json:"title"`
 }
 
 // NewClient creates a new AI client with the provided API key
-func NewClient(apiKey string) *Client {
-	client := anthropic.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+func NewClient(apiKey string, options ClientOptions) *Client {
+	requestOptions := []option.RequestOption{option.WithAPIKey(apiKey)}
+
+	if options.Transport != nil {
+		requestOptions = append(requestOptions, option.WithHTTPClient(&http.Client{Transport: options.Transport}))
+	}
+
+	client := anthropic.NewClient(requestOptions...)
+
+	model := options.Model
+	if model == "" {
+		model = string(anthropic.ModelClaude3_7Sonnet20250219)
+	}
+
+	logger := options.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
 
 	return &Client{
-		anthropic: &client,
-		context:   context.Background(),
+		anthropic:      &client,
+		context:        context.Background(),
+		logger:         logger,
+		model:          model,
+		modelFallbacks: options.ModelFallbacks,
+		onUsage:        options.OnUsage,
 	}
 }
 
-// GenerateBlogPost creates blog post content based on the request
-func (client *Client) GenerateBlogPost(request *BlogPostRequest) (string, error) {
-	prompt := buildBlogPostPrompt(request)
+// WithLogger returns a copy of the client that logs to logger
+func (client *Client) WithLogger(logger *slog.Logger) AIClient {
+	scoped := *client
+	scoped.logger = logger
 
-	message, err := client.anthropic.Messages.New(
-		context.Background(),
-		sharedUtils.CreateMessageParams(prompt),
-	)
+	return &scoped
+}
+
+// ForIssue returns a copy of the client whose usage is reported as being for
+// an issue or PR in repo (owner/repo); number 0 leaves it tied to the repo only
+func (client *Client) ForIssue(repo string, number int) AIClient {
+	scoped := *client
+	scoped.usageIssue = number
+	scoped.usageRepo = repo
+
+	return &scoped
+}
+
+// WithBudget returns a copy of the client that calls checkBudget before each
+// API call, making no call when it returns an error such as ErrBudgetExhausted
+func (client *Client) WithBudget(checkBudget func() error) AIClient {
+	scoped := *client
+	scoped.checkBudget = checkBudget
+
+	return &scoped
+}
+
+// WithModel returns a copy of the client that calls model, trying fallbacks
+// when it's overloaded; an empty model keeps the client's models
+func (client *Client) WithModel(model string, fallbacks []string) AIClient {
+	if model == "" {
+		return client
+	}
+
+	scoped := *client
+	scoped.model = model
+	scoped.modelFallbacks = fallbacks
+
+	return &scoped
+}
+
+// GenerateBlogPost creates blog post content and frontmatter metadata based on the request
+func (client *Client) GenerateBlogPost(request *BlogPostRequest) (*BlogPostDraft, error) {
+	draft := &BlogPostDraft{}
+
+	params := sharedUtils.CreateMessageParams(blogWriterSystemPrompt(request.Style), buildBlogPostPrompt(request))
+	params.MaxTokens = blogPostMaxTokens(request.Words, params.MaxTokens)
+
+	model, err := client.completeParamsWithTool(params, submitBlogPostTool, draft)
 
 	if err != nil {
-		return "", fmt.Errorf("anthropic API error: %w", err)
+		return nil, err
 	}
 
-	// Extract text from response
-	if len(message.Content) > 0 {
-		textBlock := message.Content[0]
-		return textBlock.Text, nil
+	draft.Model = model
+
+	if draft.Body == "" {
+		return nil, fmt.Errorf("AI returned an empty blog post")
+	}
+
+	return draft, nil
+}
+
+const (
+	// tokensPerWord is a generous estimate for formatted post markdown
+	tokensPerWord = 2
+
+	// maxBlogPostTokens keeps the request short enough to send without streaming
+	maxBlogPostTokens = 16000
+)
+
+// blogPostMaxTokens makes room in the reply for a post of the target length.
+// Markdown, CSS classes and code take more tokens per word than plain prose,
+// so this allows two tokens a word plus headroom for the rest of the tool call
+func blogPostMaxTokens(words int, defaultMaxTokens int64) int64 {
+	if words <= 0 {
+		return defaultMaxTokens
+	}
+
+	return min(max(int64(words)*tokensPerWord+1000, defaultMaxTokens), maxBlogPostTokens)
+}
+
+// GenerateSummary writes a one or two sentence summary of a finished post
+func (client *Client) GenerateSummary(title, content string) (string, error) {
+	completion, err := client.complete(blogSummarySystemPrompt, buildSummaryPrompt(title, content))
+	if err != nil {
+		return "", err
+	}
+
+	summary := strings.Trim(strings.TrimSpace(completion.Text), `"`)
+	if summary == "" {
+		return "", fmt.Errorf("AI returned an empty summary")
+	}
+
+	return summary, nil
+}
+
+// SuggestTags picks topic tags for a finished post, lowercased and without
+// any "#" prefixes
+func (client *Client) SuggestTags(title, content string) ([]string, error) {
+	completion, err := client.complete(blogTagsSystemPrompt, buildTagsPrompt(title, content))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+
+	for tag := range strings.SplitSeq(completion.Text, ",") {
+		cleanTag := strings.ToLower(strings.Trim(strings.TrimSpace(tag), "#`\"'."))
+
+		if cleanTag != "" {
+			tags = append(tags, cleanTag)
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("AI returned no tags")
 	}
 
-	return "", fmt.Errorf("unexpected response format from Anthropic")
+	return tags, nil
 }
 
-// ModifyBlogPost updates existing blog post content based on feedback
+// ModifyBlogPost updates existing blog post content based on feedback;
+// diffHunk, when set, is the part of the post a review comment was left on,
+// and history holds the earlier requests on the same PR
 func (client *Client) ModifyBlogPost(
 	currentContent string,
 	changeRequest string,
+	diffHunk string,
+	history []Turn,
 ) (string, error) {
-	prompt := buildModificationPrompt(currentContent, changeRequest)
-
-	message, err := client.anthropic.Messages.New(
-		context.Background(),
-		sharedUtils.CreateMessageParams(prompt),
+	return client.modify(
+		modifyArgs{
+			BuildPrompt:    buildModificationPrompt,
+			ChangeRequest:  changeRequest,
+			Clean:          keepReply,
+			CurrentContent: currentContent,
+			DiffHunk:       diffHunk,
+			History:        history,
+			Split:          splitMarkdownSections,
+			SystemPrompt:   blogEditorSystemPrompt,
+			Validate:       acceptContent,
+		},
 	)
+}
+
+// complete sends a single-turn prompt and returns the first text block of the reply
+func (client *Client) complete(systemPrompt, prompt string) (*Completion, error) {
+	return client.completeMessage(sharedUtils.CreateMessageParams(systemPrompt, prompt))
+}
+
+// completeMessage sends params and returns the first text block of the reply
+func (client *Client) completeMessage(params anthropic.MessageNewParams) (*Completion, error) {
+	message, err := client.newMessage(params)
 
 	if err != nil {
-		return "", fmt.Errorf("anthropic API error: %w", err)
+		return nil, err
+	}
+
+	if message.StopReason == anthropic.StopReasonMaxTokens {
+		return nil, &botErrors.AIError{Err: ErrOutputTruncated, Message: "My reply was cut off at its length limit."}
 	}
 
 	// Extract text from response
 	if len(message.Content) > 0 {
 		textBlock := message.Content[0]
-		return textBlock.Text, nil
+
+		return &Completion{
+			Model: string(message.Model),
+			Text:  textBlock.Text,
+		}, nil
 	}
 
-	return "", fmt.Errorf("unexpected response format from Anthropic")
+	return nil, fmt.Errorf("unexpected response format from Anthropic")
 }
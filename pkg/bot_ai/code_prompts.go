@@ -1,10 +1,7 @@
 package botai
 
 import (
-	"context"
 	"fmt"
-
-	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
 )
 
 // CodeRequest represents a request to generate code
@@ -16,81 +13,106 @@ type CodeRequest struct {
 	Tags        []string
 }
 
-// GenerateCode creates Go code based on the request
-func (c *Client) GenerateCode(request *CodeRequest) (string, error) {
-	prompt := buildCodeGenerationPrompt(request)
+// goDeveloperSystemPrompt sets the coding standards for generated Go code
+// basically being the ai hype man over here
+const goDeveloperSystemPrompt = `You are an expert Go developer writing code for the frankmeza-anthropic-bot project.
 
-	message, err := c.anthropic.Messages.New(
-		context.Background(),
-		sharedUtils.CreateMessageParams(prompt),
-		// optional third param of options, todo research here
-	)
+**Style Guidelines:**
+- Follow Go best practices and idiomatic patterns
+- Use clear, descriptive variable and function names
+- Add blank lines between logical sections for readability
+- Group related variable declarations at the top of functions
+- Use early returns with blank lines for clarity
+- Include error handling with descriptive error messages
+- Add helpful comments for complex logic
+- Match the existing code style in the project (see the bot_ai, bot_blog, bot_github packages)
 
-	if err != nil {
-		return "", fmt.Errorf("anthropic API error: %w", err)
-	}
+**Code Structure:**
+- If creating a new package, include package declaration
+- Add necessary imports
+- Define clear types and interfaces
+- Implement functions with proper error handling
+- Keep functions focused and single-purpose
 
-	hasTextBlock := len(message.Content) > 0
+Include only the code - no markdown code fences or explanations.`
 
-	if hasTextBlock {
-		textBlock := message.Content[0]
-		return textBlock.Text, nil
-	}
+// goEditorSystemPrompt sets the rules for modifying existing Go code
+const goEditorSystemPrompt = `You are an expert Go developer modifying code for the frankmeza-anthropic-bot project.
 
-	return "", fmt.Errorf("unexpected response format from Anthropic")
+**Modification Guidelines:**
+- Maintain the existing code style and structure
+- Follow Go best practices and idiomatic patterns
+- Preserve blank lines between logical sections
+- Keep error handling patterns consistent
+- Ensure changes are minimal and focused
+- Add comments if the change adds complexity
+- Test that the code compiles and makes sense
+
+Always return the complete modified code file. Include only the code - no markdown code fences or explanations.`
+
+// GenerateCode creates Go code based on the request
+func (c *Client) GenerateCode(request *CodeRequest) (*Completion, error) {
+	return c.completeChecked(
+		goDeveloperSystemPrompt,
+		nil,
+		buildCodeGenerationPrompt(request),
+		checkGoFile,
+	)
+}
+
+// ModifyCode updates existing code based on feedback; diffHunk, when set,
+// is the part of the file a review comment was left on, and history holds
+// the earlier requests on the same PR
+func (c *Client) ModifyCode(currentContent, changeRequest, diffHunk string, history []Turn) (string, error) {
+	return c.modify(
+		modifyArgs{
+			BuildPrompt:    buildCodeModificationPrompt,
+			ChangeRequest:  changeRequest,
+			Clean:          cleanGoReply,
+			CurrentContent: currentContent,
+			DiffHunk:       diffHunk,
+			History:        history,
+			Split:          splitGoDeclarations,
+			SystemPrompt:   goEditorSystemPrompt,
+			Validate:       validateGo,
+		},
+	)
 }
 
-// ModifyCode updates existing code based on feedback
-func (c *Client) ModifyCode(currentContent, changeRequest string) (string, error) {
-	prompt := buildCodeModificationPrompt(currentContent, changeRequest)
+// GenerateTests writes a _test.go companion for a generated Go file
+func (c *Client) GenerateTests(path, content string) (*Completion, error) {
+	return c.completeChecked(
+		goDeveloperSystemPrompt,
+		nil,
+		buildTestGenerationPrompt(path, content),
+		checkGoFile,
+	)
+}
 
-	message, err := c.anthropic.Messages.New(
-		context.Background(),
-		sharedUtils.CreateMessageParams(prompt),
+// FixCode asks for a corrected version of a Go file that failed to compile
+func (c *Client) FixCode(path, content, problem string) (string, error) {
+	completion, err := c.complete(
+		goEditorSystemPrompt,
+		buildCodeFixPrompt(path, content, problem),
 	)
 
 	if err != nil {
-		return "", fmt.Errorf("anthropic API error: %w", err)
+		return "", err
 	}
 
-	if len(message.Content) > 0 {
-		textBlock := message.Content[0]
-		return textBlock.Text, nil
-	}
-
-	return "", fmt.Errorf("unexpected response format from Anthropic")
+	return cleanGoReply(completion.Text), nil
 }
 
 // buildCodeGenerationPrompt creates the prompt for generating new code
 func buildCodeGenerationPrompt(request *CodeRequest) string {
-	// basically being the ai hype man over here
-	return fmt.Sprintf(`You are an expert Go developer writing code for the frankmeza-anthropic-bot project. Generate Go code based on this request.
+	return fmt.Sprintf(`Generate Go code based on this request.
 
 **Request:** %s
 
 **Description:**
 %s
 
-**Target file:** %s
-
-**Style Guidelines:**
-- Follow Go best practices and idiomatic patterns
-- Use clear, descriptive variable and function names
-- Add blank lines between logical sections for readability
-- Group related variable declarations at the top of functions
-- Use early returns with blank lines for clarity
-- Include error handling with descriptive error messages
-- Add helpful comments for complex logic
-- Match the existing code style in the project (see the bot_ai, bot_blog, bot_github packages)
-
-**Code Structure:**
-- If creating a new package, include package declaration
-- Add necessary imports
-- Define clear types and interfaces
-- Implement functions with proper error handling
-- Keep functions focused and single-purpose
-
-Generate complete, working Go code that can be added to the project. Include only the code - no markdown code fences or explanations.`,
+**Target file:** %s`,
 		request.Title,
 		request.Description,
 		request.TargetPath,
@@ -98,25 +120,45 @@ Generate complete, working Go code that can be added to the project. Include onl
 }
 
 // buildCodeModificationPrompt creates the prompt for modifying existing code
-func buildCodeModificationPrompt(currentContent, changeRequest string) string {
-	return fmt.Sprintf(`You are an expert Go developer modifying code for the frankmeza-anthropic-bot project.
+func buildCodeModificationPrompt(currentContent, changeRequest, diffHunk string) string {
+	return fmt.Sprintf(`**Current code:**
+%s
+
+**Requested change:** "%s"%s`,
+		currentContent,
+		changeRequest,
+		buildFocusSection(diffHunk),
+	)
+}
+
+// buildCodeFixPrompt creates the prompt for correcting code that doesn't compile
+func buildCodeFixPrompt(path, content, problem string) string {
+	return fmt.Sprintf(`**File:** %s
 
 **Current code:**
 %s
 
-**Requested change:** "%s"
+**Compiler error:**
+%s
 
-**Modification Guidelines:**
-- Maintain the existing code style and structure
-- Follow Go best practices and idiomatic patterns
-- Preserve blank lines between logical sections
-- Keep error handling patterns consistent
-- Ensure changes are minimal and focused
-- Add comments if the change adds complexity
-- Test that the code compiles and makes sense
+Fix the error with as small a change as possible and return the complete file.`,
+		path,
+		content,
+		problem,
+	)
+}
 
-Return the complete modified code file. Include only the code - no markdown code fences or explanations.`,
-		currentContent,
-		changeRequest,
+// buildTestGenerationPrompt creates the prompt for testing a generated file
+func buildTestGenerationPrompt(path, content string) string {
+	return fmt.Sprintf(`Write the _test.go file for this Go file.
+
+**File:** %s
+
+**Code:**
+%s
+
+Use the same package, the standard testing package only, and table-driven tests for the exported behavior. Don't test anything that needs the network or a real API client.`,
+		path,
+		content,
 	)
 }
@@ -0,0 +1,51 @@
+package botai
+
+import (
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// Turn is an earlier change request on the same PR and the bot's answer to it
+type Turn struct {
+	Path     string
+	Request  string
+	Response string
+}
+
+// completeConversation is complete with earlier turns replayed as prior
+// messages, so a new edit stays consistent with the feedback before it
+func (client *Client) completeConversation(systemPrompt string, history []Turn, prompt string) (*Completion, error) {
+	return client.completeMessage(conversationParams(systemPrompt, history, prompt))
+}
+
+// conversationParams builds the request for prompt with history before it
+func conversationParams(systemPrompt string, history []Turn, prompt string) anthropic.MessageNewParams {
+	if len(history) == 0 {
+		return sharedUtils.CreateMessageParams(systemPrompt, prompt)
+	}
+
+	params := sharedUtils.CreateMessageParams(systemPrompt, "")
+	params.Messages = []anthropic.MessageParam{}
+
+	for _, turn := range history {
+		params.Messages = append(
+			params.Messages,
+			anthropic.NewUserMessage(anthropic.NewTextBlock(
+				fmt.Sprintf("**Requested change to %s:** %q", turn.Path, turn.Request),
+			)),
+			anthropic.NewAssistantMessage(anthropic.NewTextBlock(turn.Response)),
+		)
+	}
+
+	params.Messages = append(
+		params.Messages,
+		anthropic.NewUserMessage(anthropic.NewTextBlock(
+			"Keep this edit consistent with the earlier requests above unless it says otherwise.\n\n"+prompt,
+		)),
+	)
+
+	return params
+}
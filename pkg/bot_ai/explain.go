@@ -0,0 +1,53 @@
+package botai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// explainerSystemPrompt sets the tone for code explanations
+const explainerSystemPrompt = `You are a senior Go developer explaining code to a reviewer of the frankmeza-anthropic-bot project.
+
+**Explanation Guidelines:**
+- Start with what the code does and why, in one or two sentences
+- Then walk through the parts that aren't obvious
+- Point out anything surprising, risky or untested
+- Use short paragraphs or bullets, and keep it under 300 words`
+
+// ExplainRequest is code to explain, with optional focus and question
+type ExplainRequest struct {
+	Code     string // the file or diff to explain
+	Focus    string // the diff hunk a review comment was left on, if any
+	Path     string
+	Question string // what the reviewer wants to know; empty for a general explanation
+}
+
+// Explain describes what a piece of code does for a reviewer
+func (client *Client) Explain(request *ExplainRequest) (*Completion, error) {
+	return client.complete(explainerSystemPrompt, buildExplainPrompt(request))
+}
+
+// buildExplainPrompt creates the prompt for explaining code
+func buildExplainPrompt(request *ExplainRequest) string {
+	question := request.Question
+	if question == "" {
+		question = "What does this code do?"
+	}
+
+	focus := ""
+	if strings.TrimSpace(request.Focus) != "" {
+		focus = fmt.Sprintf("\n**The reviewer is asking about this part:**\n```diff\n%s\n```\n", request.Focus)
+	}
+
+	return fmt.Sprintf(`**Path:** %s
+
+**Code:**
+%s
+%s
+**Question:** %s`,
+		request.Path,
+		request.Code,
+		focus,
+		question,
+	)
+}
@@ -0,0 +1,111 @@
+package botai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+)
+
+// statusOverloaded is Anthropic's non-standard "overloaded" status code
+const statusOverloaded = 529
+
+// Completion is generated text along with the model that produced it
+type Completion struct {
+	Model string
+	Text  string
+}
+
+// newMessage sends params to the primary model, moving down the fallback
+// chain while the API reports rate limiting or overload
+func (client *Client) newMessage(params anthropic.MessageNewParams) (*anthropic.Message, error) {
+	if client.checkBudget != nil {
+		if err := client.checkBudget(); err != nil {
+			return nil, err
+		}
+	}
+
+	models := append([]string{client.model}, client.modelFallbacks...)
+
+	var lastErr error
+
+	for index, model := range models {
+		params.Model = anthropic.Model(model)
+
+		message, err := client.anthropic.Messages.New(client.context, params)
+		if err == nil {
+			if client.onUsage != nil {
+				client.onUsage(
+					Usage{
+						CostUSD:      Cost(model, message.Usage.InputTokens, message.Usage.OutputTokens),
+						InputTokens:  message.Usage.InputTokens,
+						Issue:        client.usageIssue,
+						Model:        model,
+						OutputTokens: message.Usage.OutputTokens,
+						Repo:         client.usageRepo,
+					},
+				)
+			}
+
+			return message, nil
+		}
+
+		lastErr = err
+
+		if !isOverloadedError(err) {
+			break
+		}
+
+		if index < len(models)-1 {
+			client.logger.Warn("Model unavailable, falling back", "model", model, "fallback", models[index+1], "error", err)
+		}
+	}
+
+	return nil, apiError(lastErr)
+}
+
+// apiError wraps a failed call as an AIError, keeping the API's answer for
+// the logs and saying only what the user can act on
+func apiError(err error) error {
+	var anthropicErr *anthropic.Error
+
+	statusCode := 0
+	if errors.As(err, &anthropicErr) {
+		statusCode = anthropicErr.StatusCode
+	}
+
+	message := "I couldn't reach the AI service. Please try again in a few minutes."
+
+	switch {
+	case isOverloadedError(err):
+		message = "The AI service is busy right now. Please try again in a few minutes."
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		message = "The AI service didn't accept my API key, so a maintainer needs to check the bot's configuration."
+	case statusCode == http.StatusBadRequest:
+		message = "The AI service rejected the request, which can happen when it's too large. Try asking for a smaller change."
+	case statusCode >= http.StatusInternalServerError:
+		message = "The AI service is having trouble right now. Please try again in a few minutes."
+	}
+
+	return &botErrors.AIError{
+		Err:        fmt.Errorf("anthropic API error: %w", err),
+		Message:    message,
+		StatusCode: statusCode,
+	}
+}
+
+// isOverloadedError reports whether a request failed because the model is
+// rate limited or overloaded, which a different model may not be
+func isOverloadedError(err error) bool {
+	var apiError *anthropic.Error
+
+	if !errors.As(err, &apiError) {
+		return false
+	}
+
+	return apiError.StatusCode == http.StatusTooManyRequests ||
+		apiError.StatusCode == http.StatusServiceUnavailable ||
+		apiError.StatusCode == statusOverloaded
+}
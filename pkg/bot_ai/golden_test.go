@@ -0,0 +1,309 @@
+package botai
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update rewrites the golden files instead of comparing against them:
+// go test ./pkg/bot_ai/ -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// assertGolden compares got with testdata/golden/<name>.golden
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", "golden", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", goldenPath, err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("reading %s (run with -update to create it): %v", goldenPath, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output doesn't match %s (run with -update to accept it)\n--- got ---\n%s\n--- want ---\n%s", goldenPath, got, want)
+	}
+}
+
+// promptCall is the system prompt and prompt of one AI call, joined the way
+// they're stored in a golden file
+func promptCall(systemPrompt, prompt string) string {
+	return "## System\n\n" + systemPrompt + "\n\n## Prompt\n\n" + prompt + "\n"
+}
+
+var testBlogRequest = &BlogPostRequest{
+	Points: []string{"table tests", "subtests"},
+	Tags:   []string{"go", "testing"},
+	Title:  "Testing Go HTTP handlers",
+	Topic:  "testing Go HTTP handlers with httptest",
+}
+
+var testOutline = &BlogOutline{
+	Sections: []string{"Why test handlers", "Using httptest", "Wrapping up"},
+	Summary:  "How to test handlers without a server.",
+	Title:    "Testing Go HTTP handlers",
+}
+
+const testPost = "## Why test handlers\n\nHandlers are where requests meet your code.\n"
+
+const testGoFile = "package helpers\n\n// Double returns twice n\nfunc Double(n int) int {\n\treturn n * 2\n}\n"
+
+const testDiffHunk = "@@ -1,3 +1,3 @@\n ## Why test handlers\n-Handlers are where requests meet your code.\n+Handlers are where requests meet code.\n"
+
+// promptTest builds the prompts of one AI call for a golden file
+type promptTest struct {
+	name   string
+	prompt func() string
+}
+
+func TestPromptGolden(t *testing.T) {
+	tests := []promptTest{
+		{
+			name: "alt_text",
+			prompt: func() string {
+				return promptCall(altTextSystemPrompt, buildAltTextPrompt(
+					"Testing Go HTTP handlers",
+					[]ImageReference{
+						{Context: "The request flows through the middleware first.", URL: "images/flow.png"},
+						{URL: "https://example.com/chart.svg"},
+					},
+				))
+			},
+		},
+		{
+			name: "blog_edit",
+			prompt: func() string {
+				return promptCall(blogEditorSystemPrompt, buildModificationPrompt(testPost, "Make it shorter", ""))
+			},
+		},
+		{
+			name: "blog_edit_focused",
+			prompt: func() string {
+				return promptCall(blogEditorSystemPrompt, buildModificationPrompt(testPost, "Make it shorter", testDiffHunk))
+			},
+		},
+		{
+			name:   "blog_outline",
+			prompt: func() string { return promptCall(blogOutlineSystemPrompt, buildBlogPostPrompt(testBlogRequest)) },
+		},
+		{
+			name: "blog_post_directives",
+			prompt: func() string {
+				request := *testBlogRequest
+				request.Guidance = "Use a bookstore API for the examples"
+				request.Language = "es"
+				request.Words = 1500
+
+				return promptCall(blogWriterSystemPrompt(request.Style), buildBlogPostPrompt(&request))
+			},
+		},
+		{
+			name: "blog_section",
+			prompt: func() string {
+				request := *testBlogRequest
+				request.Words = 1500
+
+				return promptCall(blogSectionSystemPrompt(""), buildSectionPrompt(&request, testOutline, 1, testPost))
+			},
+		},
+		{
+			name: "blog_summary",
+			prompt: func() string {
+				return promptCall(blogSummarySystemPrompt, buildSummaryPrompt(testOutline.Title, testPost))
+			},
+		},
+		{
+			name:   "blog_tags",
+			prompt: func() string { return promptCall(blogTagsSystemPrompt, buildTagsPrompt(testOutline.Title, testPost)) },
+		},
+		{
+			name: "code_agent",
+			prompt: func() string {
+				return promptCall(codeAgentSystemPrompt, buildCodeGenerationPrompt(
+					&CodeRequest{
+						Description: "Add a helper that doubles a number",
+						FileType:    "go",
+						Tags:        []string{"ai-generated"},
+						TargetPath:  "pkg/helpers/helpers.go",
+						Title:       "Add a doubling helper",
+					},
+				))
+			},
+		},
+		{
+			name: "code_edit",
+			prompt: func() string {
+				return promptCall(goEditorSystemPrompt, buildCodeModificationPrompt(testGoFile, "Handle overflow", "@@ -4,3 +4,3 @@\n func Double(n int) int {\n"))
+			},
+		},
+		{
+			name: "code_fix",
+			prompt: func() string {
+				return promptCall(goEditorSystemPrompt, buildCodeFixPrompt("pkg/helpers/helpers.go", testGoFile, "4:2: expected declaration"))
+			},
+		},
+		{
+			name: "code_tests",
+			prompt: func() string {
+				return promptCall(goDeveloperSystemPrompt, buildTestGenerationPrompt("pkg/helpers/helpers.go", testGoFile))
+			},
+		},
+		{
+			name: "explain",
+			prompt: func() string {
+				return promptCall(explainerSystemPrompt, buildExplainPrompt(
+					&ExplainRequest{
+						Code:     testGoFile,
+						Focus:    "@@ -4,3 +4,3 @@\n func Double(n int) int {\n",
+						Path:     "pkg/helpers/helpers.go",
+						Question: "Why not shift left?",
+					},
+				))
+			},
+		},
+		{
+			name: "go_snippet",
+			prompt: func() string {
+				return promptCall(goSnippetSystemPrompt, buildGoSnippetPrompt("fmt.Println(\"hi\"", "1:17: missing ')'"))
+			},
+		},
+		{
+			name: "related_posts",
+			prompt: func() string {
+				return promptCall(relatedPostsSystemPrompt, buildRelatedPostsPrompt(
+					&RelatedPostsRequest{
+						Candidates: []ExistingPost{
+							{Key: "table-tests", Title: "Table tests in Go"},
+							{Key: "htmx-forms", Title: "Forms with htmx"},
+						},
+						Content: testPost,
+						Title:   testOutline.Title,
+					},
+				))
+			},
+		},
+		{
+			name: "release_notes",
+			prompt: func() string {
+				return promptCall(releaseNotesSystemPrompt, buildReleaseNotesPrompt(
+					&ReleaseNotesRequest{
+						PullRequests: []MergedPullRequest{
+							{Body: "Adds /readyz.", Labels: []string{"enhancement"}, Number: 12, Title: "Add a readiness check"},
+							{Number: 13, Title: "Fix webhook retries"},
+						},
+						Since: "v1.2.0",
+					},
+				))
+			},
+		},
+		{
+			name: "review",
+			prompt: func() string {
+				return promptCall(reviewerSystemPrompt, buildReviewPrompt(
+					&ReviewRequest{
+						Description: "Adds a doubling helper",
+						Files:       []ReviewFile{{Patch: "@@ -0,0 +1,6 @@\n+package helpers\n", Path: "pkg/helpers/helpers.go"}},
+						Title:       "Add a doubling helper",
+					},
+				))
+			},
+		},
+		{
+			name:   "seo",
+			prompt: func() string { return promptCall(seoSystemPrompt, buildSEOPrompt(testOutline.Title, testPost)) },
+		},
+		{
+			name: "social_posts",
+			prompt: func() string {
+				return promptCall(socialPostsSystemPrompt, buildSocialPostsPrompt(
+					&SocialPostsRequest{
+						Content: testPost,
+						Summary: testOutline.Summary,
+						Tags:    []string{"go", "testing"},
+						Title:   testOutline.Title,
+						URL:     "https://example.com/posts/testing-go-http-handlers",
+					},
+				))
+			},
+		},
+		{
+			name: "suggestion",
+			prompt: func() string {
+				return promptCall(suggestionSystemPrompt, buildSuggestionPrompt(
+					&SuggestionRequest{
+						Change:      "Use a shift",
+						FileContent: testGoFile,
+						Lines:       "\treturn n * 2",
+						Path:        "pkg/helpers/helpers.go",
+					},
+				))
+			},
+		},
+		{
+			name: "translate",
+			prompt: func() string {
+				return promptCall(translatorSystemPrompt, buildTranslatePrompt(
+					&TranslateRequest{
+						Content:  testPost,
+						Language: "es",
+						Summary:  testOutline.Summary,
+						Title:    testOutline.Title,
+					},
+				))
+			},
+		},
+		{
+			name: "triage",
+			prompt: func() string {
+				return promptCall(issueTriageSystemPrompt, buildIssueTriagePrompt(
+					&IssueTriageRequest{
+						Body:       "The bot ignores comments on closed PRs.",
+						Repository: "owner/bot",
+						Title:      "Comments on closed PRs are ignored",
+					},
+				))
+			},
+		},
+		{
+			name: "write_up",
+			prompt: func() string {
+				return promptCall(writeUpSystemPrompt, buildWriteUpPrompt(
+					&WriteUpRequest{
+						Description: "Adds a doubling helper",
+						Diff:        "+func Double(n int) int {\n+\treturn n * 2\n+}\n",
+						Discussion:  []string{"Why not a shift?", "Readability wins here."},
+						SourceURL:   "https://github.com/owner/bot/pull/12",
+						Title:       "Add a doubling helper",
+					},
+				))
+			},
+		},
+	}
+
+	for _, style := range StylePresetNames() {
+		tests = append(tests, promptTest{
+			name:   "blog_post_" + style,
+			prompt: func() string { return promptCall(blogWriterSystemPrompt(style), buildBlogPostPrompt(testBlogRequest)) },
+		})
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertGolden(t, test.name, test.prompt())
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package botai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// healthCheckTimeout bounds a connectivity check, so a hung API doesn't hang the caller
+const healthCheckTimeout = 5 * time.Second
+
+// CheckModel verifies the API key by looking up the primary model, which
+// costs no tokens and also catches a misspelled AI_MODEL
+func (client *Client) CheckModel() error {
+	context, cancel := context.WithTimeout(client.context, healthCheckTimeout)
+	defer cancel()
+
+	if _, err := client.anthropic.Models.Get(context, client.model, anthropic.ModelGetParams{}); err != nil {
+		return fmt.Errorf("looking up model %s: %w", client.model, err)
+	}
+
+	return nil
+}
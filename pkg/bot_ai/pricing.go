@@ -0,0 +1,52 @@
+package botai
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrBudgetExhausted means a spending limit was reached, so no more calls
+// are made until it resets or is raised
+var ErrBudgetExhausted = errors.New("the monthly AI budget is used up")
+
+// modelPrice is a model's list price in US dollars per million tokens
+type modelPrice struct {
+	input  float64
+	output float64
+	prefix string // matches the model and its dated snapshots
+}
+
+// modelPrices are checked in order, so more specific prefixes come first
+var modelPrices = []modelPrice{
+	{input: 5, output: 25, prefix: "claude-opus-4-5"},
+	{input: 15, output: 75, prefix: "claude-opus-4"},
+	{input: 15, output: 75, prefix: "claude-3-opus"},
+	{input: 3, output: 15, prefix: "claude-sonnet-4"},
+	{input: 3, output: 15, prefix: "claude-3-7-sonnet"},
+	{input: 3, output: 15, prefix: "claude-3-5-sonnet"},
+	{input: 1, output: 5, prefix: "claude-haiku-4"},
+	{input: 0.8, output: 4, prefix: "claude-3-5-haiku"},
+	{input: 0.25, output: 1.25, prefix: "claude-3-haiku"},
+}
+
+// Usage is what one API call used, along with the issue or PR it was for
+type Usage struct {
+	CostUSD      float64 // estimated from list prices; 0 for models without a known price
+	InputTokens  int64
+	Issue        int // the issue or PR number; 0 for work not tied to one
+	Model        string
+	OutputTokens int64
+	Repo         string // owner/repo; empty when the call wasn't made for a repo
+}
+
+// Cost estimates what a call to model cost in US dollars from its list
+// price, returning 0 for models without a known price
+func Cost(model string, inputTokens, outputTokens int64) float64 {
+	for _, price := range modelPrices {
+		if strings.HasPrefix(model, price.prefix) {
+			return (float64(inputTokens)*price.input + float64(outputTokens)*price.output) / 1_000_000
+		}
+	}
+
+	return 0
+}
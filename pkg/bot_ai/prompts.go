@@ -2,54 +2,251 @@ package botai
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
-// buildBlogPostPrompt creates the prompt for generating new blog posts
-func buildBlogPostPrompt(request *BlogPostRequest) string {
-	return fmt.Sprintf(`You are a technical blog writer with a casual, clear writing style. Write a blog post about %s.
+// Style presets pick the voice a post is written in
+const (
+	StyleAnnouncement = "announcement"
+	StyleCasual       = "casual"
+	StyleDeepDive     = "deep-dive"
+	StyleTutorial     = "tutorial"
+)
 
-Style Guidelines:
+// stylePreset is the voice and rules one style preset writes with
+type stylePreset struct {
+	guidelines string
+	voice      string
+}
+
+// stylePresets maps each preset name to its prompt template; casual is the default
+var stylePresets = map[string]stylePreset{
+	StyleAnnouncement: {
+		voice: "a clear, upbeat writing style for announcing something new",
+		guidelines: `Style Guidelines:
+- Lead with what's new and why readers should care, in the first paragraph
+- Keep it short and scannable: brief sections, bullet lists for features or changes
+- Include a minimal Go example or usage snippet if it shows the change best
+- Use CSS classes in markdown format like: {.text-lg .text-gray-600 .mb-8}
+- Start most paragraphs with appropriate CSS styling classes
+- End with where to get it, how to upgrade, or what's coming next`,
+	},
+	StyleCasual: {
+		voice: "a casual, clear writing style",
+		guidelines: `Style Guidelines:
 - Casual, conversational tone but still informative and clear
 - Include practical code examples in Go where relevant
 - Use CSS classes in markdown format like: {.text-lg .text-gray-600 .mb-8}
 - Start most paragraphs with appropriate CSS styling classes
 - Include concrete, working examples that illustrate your points
 - Keep it engaging and developer-friendly
-- Write as if you're sharing knowledge with a fellow developer
+- Write as if you're sharing knowledge with a fellow developer`,
+	},
+	StyleDeepDive: {
+		voice: "a thorough, precise writing style for experienced developers",
+		guidelines: `Style Guidelines:
+- Go well beyond the basics: explain how things work underneath and why they were designed that way
+- Discuss trade-offs, edge cases, performance and failure modes
+- Include substantial Go examples, and benchmarks or measurements where they help
+- Use CSS classes in markdown format like: {.text-lg .text-gray-600 .mb-8}
+- Start most paragraphs with appropriate CSS styling classes
+- Assume the reader knows the fundamentals; don't pad with introductory material`,
+	},
+	StyleTutorial: {
+		voice: "a patient, step-by-step teaching style",
+		guidelines: `Style Guidelines:
+- Start by saying what the reader will build or learn and what they need beforehand
+- Walk through the work in numbered steps, each with a complete, runnable Go example
+- Explain what each step does and show the expected output
+- Use CSS classes in markdown format like: {.text-lg .text-gray-600 .mb-8}
+- Start most paragraphs with appropriate CSS styling classes
+- Finish with a recap and ideas for taking it further`,
+	},
+}
+
+// IsStylePreset reports whether name is one of the known style presets
+func IsStylePreset(name string) bool {
+	_, isPreset := stylePresets[name]
+	return isPreset
+}
+
+// StylePresetNames lists the style presets, sorted
+func StylePresetNames() []string {
+	return slices.Sorted(maps.Keys(stylePresets))
+}
+
+// lookupStylePreset finds a preset by name, falling back to casual
+func lookupStylePreset(name string) stylePreset {
+	if preset, isPreset := stylePresets[name]; isPreset {
+		return preset
+	}
+
+	return stylePresets[StyleCasual]
+}
+
+// blogWriterSystemPrompt sets the voice and formatting rules for new blog posts
+func blogWriterSystemPrompt(style string) string {
+	preset := lookupStylePreset(style)
+
+	return `You are a technical blog writer with ` + preset.voice + `.
+
+` + preset.guidelines + `
+
+Write complete blog posts that would fit well on a developer's personal website, and hand them back by calling the submit_blog_post tool: the markdown body goes in "body" (no frontmatter), with a short summary and 3-5 lowercase tags alongside it.`
+}
+
+// blogOutlineSystemPrompt asks for a section plan instead of a finished post
+const blogOutlineSystemPrompt = `You are a technical blog writer planning a long-form post for a developer's personal website.
+
+Plan the post as an ordered list of section headings that build on each other, starting with an introduction and ending with a wrap-up. Hand the plan back by calling the submit_outline tool.`
+
+// blogSectionSystemPrompt writes a single section of an outlined post
+func blogSectionSystemPrompt(style string) string {
+	preset := lookupStylePreset(style)
+
+	return `You are a technical blog writer with ` + preset.voice + `, writing a long post one section at a time.
+
+` + preset.guidelines + `
+
+Return only the markdown for the requested section, starting with its "## " heading. Don't repeat material from earlier sections and don't write later ones.`
+}
+
+// blogEditorSystemPrompt sets the rules for editing an existing blog post
+const blogEditorSystemPrompt = `You are helping edit a blog post based on reader feedback.
+
+When modifying a post, maintain the same:
+- Frontmatter structure (don't change the YAML at the top)
+- CSS class formatting like {.text-lg .text-gray-600 .mb-8}
+- Casual, clear writing style
+- Developer-friendly tone
+
+Always return the complete updated blog post including the original frontmatter.`
+
+// blogProofreadSystemPrompt asks for a light copy edit that leaves the writing as it is
+const blogProofreadSystemPrompt = `You are proofreading a section of a blog post for a developer's personal website.
+
+Fix typos, spelling, grammar, punctuation and awkward phrasing. Keep the author's voice, meaning, structure and length: don't add or remove content, and don't reword sentences that are already fine.
+
+Leave code blocks, inline code, link URLs and CSS classes like {.text-lg .text-gray-600 .mb-8} exactly as they are.
+
+Reply with the proofread markdown only: no code fences around it and no explanation of the changes.`
+
+// goSnippetSystemPrompt repairs a single code example from a post
+const goSnippetSystemPrompt = `You fix broken Go code examples in blog posts for a developer's personal website.
+
+Make the smallest change that fixes the problem while keeping what the example is meant to show. A snippet that was a fragment (statements or declarations without a package clause) should stay a fragment.
+
+Reply with the corrected code only: no code fences and no explanation.`
+
+// blogSummarySystemPrompt asks for frontmatter-ready summary text only
+const blogSummarySystemPrompt = `You write the short summaries shown under blog post titles on a developer's personal website.
+
+Reply with the summary text only: no quotes, headings or markdown.`
+
+// blogTagsSystemPrompt asks for a bare tag list
+const blogTagsSystemPrompt = `You tag posts on a developer's personal website so readers can find related posts.
+
+Reply with the tags only, as one comma-separated line of lowercase words or hyphenated phrases.`
+
+// buildBlogPostPrompt creates the prompt for generating new blog posts
+func buildBlogPostPrompt(request *BlogPostRequest) string {
+	prompt := fmt.Sprintf(`Write a blog post about %s.
 
 Topic: %s
 Key points to cover: %s
-Target tags: %s
-
-Write a complete blog post (just the content, no frontmatter) that would fit well on a developer's personal website. Include practical examples and maintain a light but informative tone.`,
+Target tags: %s`,
 		request.Topic,
 		request.Topic,
 		strings.Join(request.Points, ", "),
 		strings.Join(request.Tags, ", "))
+
+	if request.Guidance != "" {
+		prompt += fmt.Sprintf("\n\nAdditional guidance: %s", request.Guidance)
+	}
+
+	return prompt + lengthInstruction(request.Words) + languageInstruction(request.Language)
 }
 
-// buildModificationPrompt creates the prompt for modifying existing blog posts
-func buildModificationPrompt(currentContent, changeRequest string) string {
-	return fmt.Sprintf(`You are helping edit a blog post. A reader has requested a specific change to the content.
+// lengthInstruction asks for a post of about the target word count
+func lengthInstruction(words int) string {
+	if words <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\nTarget length: about %d words, not counting code blocks.", words)
+}
+
+// languageInstruction asks for output in a language other than English
+func languageInstruction(language string) string {
+	if language == "" || language == "en" {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\nWrite in the language with code %q, keeping code, identifiers and CSS classes as they are.", language)
+}
 
-Current blog post:
+// buildSectionPrompt creates the prompt for one section of an outlined post
+func buildSectionPrompt(
+	request *BlogPostRequest,
+	outline *BlogOutline,
+	sectionIndex int,
+	writtenSoFar string,
+) string {
+	return fmt.Sprintf(`Post title: %s
+Topic: %s
+Key points to cover: %s
+
+Outline:
+- %s
+
+Written so far:
 %s
 
-Change requested: "%s"
+Write section %d of %d: "%s"%s%s`,
+		outline.Title,
+		request.Topic,
+		strings.Join(request.Points, ", "),
+		strings.Join(outline.Sections, "\n- "),
+		writtenSoFar,
+		sectionIndex+1,
+		len(outline.Sections),
+		outline.Sections[sectionIndex],
+		lengthInstruction(request.Words/len(outline.Sections)),
+		languageInstruction(request.Language),
+	)
+}
 
-Please modify the blog post to address this request. Maintain the same:
-- Frontmatter structure (don't change the YAML at the top)
-- CSS class formatting like {.text-lg .text-gray-600 .mb-8}
-- Casual, clear writing style
-- Developer-friendly tone
+// buildModificationPrompt creates the prompt for modifying existing blog posts
+func buildModificationPrompt(currentContent, changeRequest, diffHunk string) string {
+	return fmt.Sprintf(`Current blog post:
+%s
 
-Return the complete updated blog post including the original frontmatter.`,
+Change requested: "%s"%s`,
 		currentContent,
 		changeRequest,
+		buildFocusSection(diffHunk),
 	)
 }
 
+// buildFocusSection points the model at the lines a review comment was left on
+func buildFocusSection(diffHunk string) string {
+	if strings.TrimSpace(diffHunk) == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+
+The comment was left on this part of the file, so focus the change there and leave the rest as it is:
+`+"```diff\n%s\n```", diffHunk)
+}
+
+// buildGoSnippetPrompt creates the prompt for repairing one Go example
+func buildGoSnippetPrompt(code, problem string) string {
+	return fmt.Sprintf("Example:\n```go\n%s\n```\n\nProblem: %s", code, problem)
+}
+
 // buildSummaryPrompt creates a prompt for generating blog post summaries
 func buildSummaryPrompt(title, content string) string {
 	return fmt.Sprintf(`Create a brief, engaging summary for this blog post:
@@ -0,0 +1,67 @@
+package botai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxProofreadDrift is how far a proofread chunk's word count may move from
+// the original before the reply is treated as a rewrite rather than a proofread
+const maxProofreadDrift = 0.15
+
+// Proofread fixes typos, grammar and awkward phrasing in a post body while
+// keeping its voice, working section by section when the post is too large
+// to return in one reply
+func (client *Client) Proofread(content string) (string, error) {
+	chunks := []string{content}
+
+	if client.countTokens(content) > maxModifyTokens {
+		chunks = splitMarkdownSections(content)
+	}
+
+	for index, chunk := range chunks {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+
+		check := func(reply string) (string, error) {
+			cleaned, err := checkNotEmpty(reply)
+			if err != nil {
+				return "", err
+			}
+
+			if strings.HasSuffix(chunk, "\n") && !strings.HasSuffix(cleaned, "\n") {
+				cleaned += "\n"
+			}
+
+			return cleaned, checkProofreadDrift(chunk, cleaned)
+		}
+
+		completion, err := client.completeChecked(blogProofreadSystemPrompt, nil, chunk, check)
+		if err != nil {
+			return "", fmt.Errorf("proofreading section %d of %d: %w", index+1, len(chunks), err)
+		}
+
+		chunks[index] = completion.Text
+	}
+
+	return strings.Join(chunks, ""), nil
+}
+
+// checkProofreadDrift rejects a reply that added or dropped too much to be a proofread
+func checkProofreadDrift(original, proofread string) error {
+	originalWords := len(strings.Fields(original))
+	proofreadWords := len(strings.Fields(proofread))
+
+	drift := float64(proofreadWords-originalWords) / float64(max(originalWords, 1))
+
+	if drift > maxProofreadDrift || drift < -maxProofreadDrift {
+		return fmt.Errorf(
+			"the text went from %d to %d words; only fix errors and awkward phrasing, without adding or removing content",
+			originalWords,
+			proofreadWords,
+		)
+	}
+
+	return nil
+}
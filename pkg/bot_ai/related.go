@@ -0,0 +1,102 @@
+package botai
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// maxRelatedPosts is the most posts a new post links to
+const maxRelatedPosts = 3
+
+// ExistingPost is a post already on the site, by key and title
+type ExistingPost struct {
+	Key   string
+	Title string
+}
+
+// RelatedPostsRequest is a new post and the posts it could link to
+type RelatedPostsRequest struct {
+	Candidates []ExistingPost
+	Content    string
+	Title      string
+}
+
+// relatedPostsSystemPrompt sets the rules for picking related reading
+const relatedPostsSystemPrompt = `You pick the "related reading" links for a new post on a developer's personal website.
+
+Only pick posts a reader of the new post would genuinely want next: same topic, a prerequisite, or a natural follow-up. Picking none is fine. Hand the picks back by calling the submit_related_posts tool.`
+
+// submitRelatedPostsTool is the tool Claude calls to hand back related posts
+var submitRelatedPostsTool = anthropic.ToolParam{
+	Name:        "submit_related_posts",
+	Description: anthropic.String("Submit the keys of the existing posts most related to the new one."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"keys": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": fmt.Sprintf("Up to %d keys from the list of existing posts, most related first", maxRelatedPosts),
+			},
+		},
+		Required: []string{"keys"},
+	},
+}
+
+// SuggestRelatedPosts picks which existing posts a new post should link to,
+// returning their keys; keys the AI made up are dropped
+func (client *Client) SuggestRelatedPosts(request *RelatedPostsRequest) ([]string, error) {
+	if len(request.Candidates) == 0 {
+		return []string{}, nil
+	}
+
+	picks := &struct {
+		Keys []string `json:"keys"`
+	}{}
+
+	if _, err := client.completeWithTool(
+		relatedPostsSystemPrompt,
+		buildRelatedPostsPrompt(request),
+		submitRelatedPostsTool,
+		picks,
+	); err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+
+	for _, key := range picks.Keys {
+		isCandidate := slices.ContainsFunc(request.Candidates, func(candidate ExistingPost) bool {
+			return candidate.Key == key
+		})
+
+		if isCandidate && !slices.Contains(keys, key) && len(keys) < maxRelatedPosts {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// buildRelatedPostsPrompt creates the prompt for picking related posts
+func buildRelatedPostsPrompt(request *RelatedPostsRequest) string {
+	var candidates strings.Builder
+
+	for _, candidate := range request.Candidates {
+		fmt.Fprintf(&candidates, "- %s: %s\n", candidate.Key, candidate.Title)
+	}
+
+	return fmt.Sprintf(`New post title: %s
+
+New post content:
+%s
+
+Existing posts (key: title):
+%s`,
+		request.Title,
+		request.Content,
+		candidates.String(),
+	)
+}
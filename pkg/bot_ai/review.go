@@ -0,0 +1,114 @@
+package botai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// maxReviewPatch keeps one file's patch from crowding out the rest of the review prompt
+const maxReviewPatch = 20000
+
+// ReviewFile is one changed file of a PR, with its patch
+type ReviewFile struct {
+	Patch string
+	Path  string
+}
+
+// ReviewRequest is a PR to review
+type ReviewRequest struct {
+	Description string
+	Files       []ReviewFile
+	Title       string
+}
+
+// PullRequestReview is the review returned by the submit_review tool
+type PullRequestReview struct {
+	Comments []ReviewNote `json:"comments"`
+	Model    string       `json:"-"`
+	Summary  string       `json:"summary"`
+}
+
+// ReviewNote is one inline review comment
+type ReviewNote struct {
+	Body string `json:"body"`
+	Line int    `json:"line"`
+	Path string `json:"path"`
+}
+
+// reviewerSystemPrompt sets the bar for AI code review
+const reviewerSystemPrompt = `You are an experienced Go reviewer for the frankmeza-anthropic-bot project.
+
+**Review Guidelines:**
+- Point out bugs, unhandled errors, races and security problems first
+- Mention style only when it departs from the surrounding code
+- Comment on specific lines, using line numbers from the new version of the file
+- Be brief and concrete; suggest the fix when it's short
+- Don't comment on lines that are fine, and don't praise`
+
+// submitReviewTool is the tool Claude calls to hand back a review
+var submitReviewTool = anthropic.ToolParam{
+	Name:        "submit_review",
+	Description: anthropic.String("Submit the review of the pull request."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"summary": map[string]any{
+				"type":        "string",
+				"description": "A short overall assessment of the change",
+			},
+			"comments": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"path": map[string]any{"type": "string", "description": "File path as given in the diff"},
+						"line": map[string]any{"type": "integer", "description": "Line number in the new version of the file"},
+						"body": map[string]any{"type": "string", "description": "The review comment"},
+					},
+					"required": []string{"path", "line", "body"},
+				},
+				"description": "Inline comments on specific lines; empty if there's nothing to flag",
+			},
+		},
+		Required: []string{"summary", "comments"},
+	},
+}
+
+// ReviewPullRequest reviews a PR's diff
+func (client *Client) ReviewPullRequest(request *ReviewRequest) (*PullRequestReview, error) {
+	review := &PullRequestReview{}
+
+	model, err := client.completeWithTool(
+		reviewerSystemPrompt,
+		buildReviewPrompt(request),
+		submitReviewTool,
+		review,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	review.Model = model
+
+	return review, nil
+}
+
+// buildReviewPrompt lays out the PR and each file's patch
+func buildReviewPrompt(request *ReviewRequest) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("Review this pull request.\n\n**Title:** %s\n\n**Description:**\n%s\n", request.Title, request.Description))
+
+	for _, file := range request.Files {
+		patch := file.Patch
+		if len(patch) > maxReviewPatch {
+			patch = patch[:maxReviewPatch] + "\n... (patch truncated)"
+		}
+
+		prompt.WriteString(fmt.Sprintf("\n**File:** %s\n```diff\n%s\n```\n", file.Path, patch))
+	}
+
+	return prompt.String()
+}
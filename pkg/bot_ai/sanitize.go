@@ -0,0 +1,152 @@
+package botai
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// fencedBlockPattern matches a markdown code block, capturing its body
+var fencedBlockPattern = regexp.MustCompile("(?s)```[a-zA-Z]*\n(.*?)\n?```")
+
+// goCodeStarts are the prefixes a Go file or top-level chunk can begin with;
+// anything above the first of them is taken to be chatter
+var goCodeStarts = []string{"package ", "import ", "func ", "type ", "var ", "const ", "//", "/*"}
+
+// replyCheck cleans a reply and reports what's wrong with it, if anything
+type replyCheck func(reply string) (string, error)
+
+// completeChecked sends a prompt and runs check on the reply, giving the AI
+// one corrective round with the problem spelled out before giving up
+func (client *Client) completeChecked(
+	systemPrompt string,
+	history []Turn,
+	prompt string,
+	check replyCheck,
+) (*Completion, error) {
+	params := conversationParams(systemPrompt, history, prompt)
+
+	completion, err := client.completeMessage(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned, checkErr := check(completion.Text)
+	if checkErr == nil {
+		completion.Text = cleaned
+		return completion, nil
+	}
+
+	client.logger.Warn("AI reply failed checks, asking for a correction", "error", checkErr)
+
+	params.Messages = append(
+		params.Messages,
+		anthropic.NewAssistantMessage(anthropic.NewTextBlock(completion.Text)),
+		anthropic.NewUserMessage(anthropic.NewTextBlock(fmt.Sprintf(
+			"That reply can't be used: %v\n\nReply again with only the corrected content - no code fences or explanations.",
+			checkErr,
+		))),
+	)
+
+	completion, err = client.completeMessage(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned, checkErr = check(completion.Text)
+	if checkErr != nil {
+		return nil, fmt.Errorf("AI reply still invalid after a correction: %w", checkErr)
+	}
+
+	completion.Text = cleaned
+
+	return completion, nil
+}
+
+// checkNotEmpty rejects blank replies
+func checkNotEmpty(reply string) (string, error) {
+	if strings.TrimSpace(reply) == "" {
+		return "", fmt.Errorf("the reply was empty")
+	}
+
+	return reply, nil
+}
+
+// checkGoFile cleans a reply meant to be a whole Go file and makes sure it parses
+func checkGoFile(reply string) (string, error) {
+	code, err := checkNotEmpty(cleanGoReply(reply))
+	if err != nil {
+		return "", err
+	}
+
+	return code, validateGo(code)
+}
+
+// validateGo makes sure code parses as a Go file
+func validateGo(code string) error {
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, parser.AllErrors); err != nil {
+		return fmt.Errorf("the Go code doesn't parse: %w", err)
+	}
+
+	return nil
+}
+
+// keepReply leaves prose replies as they are
+func keepReply(reply string) string {
+	return reply
+}
+
+// acceptContent is the Validate for content with no syntax to check
+func acceptContent(content string) error {
+	return nil
+}
+
+// cleanGoReply pulls the code out of a reply that wrapped it in fences or
+// added a preamble like "Here's the code:"
+func cleanGoReply(reply string) string {
+	code := strings.TrimSpace(reply)
+
+	// a reply that already starts as code is left alone, since Go source can
+	// hold fences of its own inside string literals
+	if hasGoCodeStart(code) {
+		return code + "\n"
+	}
+
+	// the largest fenced block is the code; smaller ones are usually examples in chatter
+	largestBlock := ""
+
+	for _, match := range fencedBlockPattern.FindAllStringSubmatch(code, -1) {
+		if len(match[1]) > len(largestBlock) {
+			largestBlock = match[1]
+		}
+	}
+
+	if largestBlock != "" {
+		code = largestBlock
+	}
+
+	lines := strings.Split(code, "\n")
+
+	for index, line := range lines {
+		if hasGoCodeStart(line) {
+			code = strings.Join(lines[index:], "\n")
+			break
+		}
+	}
+
+	return strings.TrimSpace(code) + "\n"
+}
+
+func hasGoCodeStart(line string) bool {
+	for _, start := range goCodeStarts {
+		if strings.HasPrefix(line, start) {
+			return true
+		}
+	}
+
+	return false
+}
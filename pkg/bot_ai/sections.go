@@ -0,0 +1,77 @@
+package botai
+
+import (
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// BlogOutline is the section plan returned by the submit_outline tool
+type BlogOutline struct {
+	Sections []string `json:"sections"`
+	Summary  string   `json:"summary"`
+	Tags     []string `json:"tags"`
+	Title    string   `json:"title"`
+}
+
+// submitOutlineTool is the tool Claude calls to hand back a post outline
+var submitOutlineTool = anthropic.ToolParam{
+	Name:        "submit_outline",
+	Description: anthropic.String("Submit the planned sections of the blog post along with its frontmatter metadata."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"title": map[string]any{
+				"type":        "string",
+				"description": "The post title, without any prefix like 'Blog post:'",
+			},
+			"summary": map[string]any{
+				"type":        "string",
+				"description": "A 1-2 sentence summary of the post for the frontmatter",
+			},
+			"tags": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "3-5 lowercase topic tags",
+			},
+			"sections": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Ordered section headings, without leading #",
+			},
+		},
+		Required: []string{"title", "summary", "tags", "sections"},
+	},
+}
+
+// GenerateBlogOutline plans a long post as a list of sections
+func (client *Client) GenerateBlogOutline(request *BlogPostRequest) (*BlogOutline, error) {
+	outline := &BlogOutline{}
+
+	if _, err := client.completeWithTool(
+		blogOutlineSystemPrompt,
+		buildBlogPostPrompt(request),
+		submitOutlineTool,
+		outline,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(outline.Sections) == 0 {
+		return nil, fmt.Errorf("AI returned an outline with no sections")
+	}
+
+	return outline, nil
+}
+
+// GenerateBlogSection writes one section of an outlined post, given the sections written before it
+func (client *Client) GenerateBlogSection(
+	request *BlogPostRequest,
+	outline *BlogOutline,
+	sectionIndex int,
+	writtenSoFar string,
+) (*Completion, error) {
+	return client.complete(
+		blogSectionSystemPrompt(request.Style),
+		buildSectionPrompt(request, outline, sectionIndex, writtenSoFar),
+	)
+}
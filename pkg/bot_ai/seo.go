@@ -0,0 +1,75 @@
+package botai
+
+import (
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// SEOMetadata is the search and social preview text returned by the
+// submit_seo_metadata tool
+type SEOMetadata struct {
+	MetaDescription      string `json:"meta_description"`
+	OpenGraphDescription string `json:"og_description"`
+	OpenGraphTitle       string `json:"og_title"`
+}
+
+// seoSystemPrompt sets the rules for search and social preview text
+const seoSystemPrompt = `You write the search-result and link-preview text for posts on a developer's personal website.
+
+Describe what the post actually covers, in plain language a developer would click on. No clickbait, no keyword stuffing, no emoji. Hand the text back by calling the submit_seo_metadata tool.`
+
+// submitSEOMetadataTool is the tool Claude calls to hand back SEO metadata
+var submitSEOMetadataTool = anthropic.ToolParam{
+	Name:        "submit_seo_metadata",
+	Description: anthropic.String("Submit the meta description and OpenGraph text for the blog post."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"meta_description": map[string]any{
+				"type":        "string",
+				"description": "The search result description, at most 155 characters",
+			},
+			"og_title": map[string]any{
+				"type":        "string",
+				"description": "The title shown in link previews, at most 60 characters",
+			},
+			"og_description": map[string]any{
+				"type":        "string",
+				"description": "The description shown in link previews, at most 200 characters",
+			},
+		},
+		Required: []string{"meta_description", "og_title", "og_description"},
+	},
+}
+
+// GenerateSEOMetadata writes a meta description and OpenGraph title and
+// description for a finished post
+func (client *Client) GenerateSEOMetadata(title, content string) (*SEOMetadata, error) {
+	metadata := &SEOMetadata{}
+
+	if _, err := client.completeWithTool(
+		seoSystemPrompt,
+		buildSEOPrompt(title, content),
+		submitSEOMetadataTool,
+		metadata,
+	); err != nil {
+		return nil, err
+	}
+
+	if metadata.MetaDescription == "" {
+		return nil, fmt.Errorf("AI returned no meta description")
+	}
+
+	return metadata, nil
+}
+
+// buildSEOPrompt creates the prompt for a post's SEO metadata
+func buildSEOPrompt(title, content string) string {
+	return fmt.Sprintf(`Write the meta description and OpenGraph title and description for this blog post:
+
+Title: %s
+Content: %s`,
+		title,
+		content,
+	)
+}
@@ -0,0 +1,37 @@
+package botai
+
+import "strings"
+
+// FixGoSnippet repairs a Go example from a post, given what's wrong with it.
+// validate runs the caller's checks on the fixed code, and a failing fix gets
+// one corrective round before giving up
+func (client *Client) FixGoSnippet(code, problem string, validate func(code string) error) (string, error) {
+	check := func(reply string) (string, error) {
+		fixed, err := checkNotEmpty(cleanSnippetReply(reply))
+		if err != nil {
+			return "", err
+		}
+
+		return fixed, validate(fixed)
+	}
+
+	completion, err := client.completeChecked(goSnippetSystemPrompt, nil, buildGoSnippetPrompt(code, problem), check)
+	if err != nil {
+		return "", err
+	}
+
+	return completion.Text, nil
+}
+
+// cleanSnippetReply unwraps an example the AI fenced anyway. Unlike
+// cleanGoReply it keeps statements that come before the first declaration,
+// since snippets are often fragments
+func cleanSnippetReply(reply string) string {
+	code := strings.TrimSpace(reply)
+
+	if match := fencedBlockPattern.FindStringSubmatch(code); match != nil {
+		code = match[1]
+	}
+
+	return strings.Trim(code, "\n")
+}
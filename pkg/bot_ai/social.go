@@ -0,0 +1,134 @@
+package botai
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// Social networks WriteSocialPosts drafts announcements for
+const (
+	SocialNetworkBluesky  = "bluesky"
+	SocialNetworkMastodon = "mastodon"
+	SocialNetworkX        = "x"
+)
+
+// SocialNetworks lists the networks in the order drafts are shown
+var SocialNetworks = []string{SocialNetworkMastodon, SocialNetworkBluesky, SocialNetworkX}
+
+// SocialPostLimits are the most characters a post may have on each network
+var SocialPostLimits = map[string]int{
+	SocialNetworkBluesky:  300,
+	SocialNetworkMastodon: 500,
+	SocialNetworkX:        280,
+}
+
+// maxSocialPostContent is how much of the post the drafts are written from
+const maxSocialPostContent = 6000
+
+// SocialPostsRequest describes a newly published post to announce
+type SocialPostsRequest struct {
+	Content string
+	Summary string
+	Tags    []string
+	Title   string
+	URL     string // empty when the site's post links aren't absolute
+}
+
+// SocialPosts holds the candidate announcements for each network
+type SocialPosts map[string][]string
+
+// socialPostsSystemPrompt sets the rules for announcement drafts
+const socialPostsSystemPrompt = `You write social media posts announcing a new post on a personal developer blog, in the author's own voice.
+
+For each network, write 2-3 distinct candidates the author can pick from, e.g. one leading with the problem the post solves, one with a surprising detail, one with a question. Keep them plain and specific: no hype, no "excited to share", at most one emoji, and at most two hashtags (none on Bluesky).
+
+Length limits are hard limits, counted in characters including the link:
+- mastodon: 500
+- bluesky: 300
+- x: 280
+
+Include the link in every candidate when one is given. Hand the candidates back by calling the submit_social_posts tool.`
+
+// submitSocialPostsTool is the tool Claude calls to hand back announcement drafts
+var submitSocialPostsTool = anthropic.ToolParam{
+	Name:        "submit_social_posts",
+	Description: anthropic.String("Submit 2-3 candidate announcement posts for each social network."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			SocialNetworkMastodon: socialPostsProperty("Mastodon posts of at most 500 characters"),
+			SocialNetworkBluesky:  socialPostsProperty("Bluesky posts of at most 300 characters"),
+			SocialNetworkX:        socialPostsProperty("X posts of at most 280 characters"),
+		},
+		Required: SocialNetworks,
+	},
+}
+
+func socialPostsProperty(description string) map[string]any {
+	return map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": description,
+	}
+}
+
+// WriteSocialPosts drafts announcements of a published post for Mastodon,
+// Bluesky and X, dropping any candidate over a network's length limit
+func (client *Client) WriteSocialPosts(request *SocialPostsRequest) (SocialPosts, error) {
+	drafted := SocialPosts{}
+
+	if _, err := client.completeWithTool(
+		socialPostsSystemPrompt,
+		buildSocialPostsPrompt(request),
+		submitSocialPostsTool,
+		&drafted,
+	); err != nil {
+		return nil, err
+	}
+
+	posts := SocialPosts{}
+
+	for _, network := range SocialNetworks {
+		for _, post := range drafted[network] {
+			post = strings.TrimSpace(post)
+
+			if post != "" && utf8.RuneCountInString(post) <= SocialPostLimits[network] {
+				posts[network] = append(posts[network], post)
+			}
+		}
+	}
+
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("AI returned no social posts within the length limits")
+	}
+
+	return posts, nil
+}
+
+// buildSocialPostsPrompt creates the prompt for announcing a post
+func buildSocialPostsPrompt(request *SocialPostsRequest) string {
+	link := request.URL
+	if link == "" {
+		link = "(none - leave the link out)"
+	}
+
+	return fmt.Sprintf(`Announce this blog post:
+
+Title: %s
+Link: %s
+Summary: %s
+Tags: %s
+
+Post:
+%s`,
+		request.Title,
+		link,
+		request.Summary,
+		strings.Join(request.Tags, ", "),
+		sharedUtils.TruncateText(request.Content, maxSocialPostContent),
+	)
+}
@@ -0,0 +1,50 @@
+package botai
+
+import (
+	"fmt"
+)
+
+// suggestionSystemPrompt asks for replacement lines rather than a whole file
+const suggestionSystemPrompt = `You are reviewing a pull request and proposing a replacement for specific lines of a file, which the author can apply with one click.
+
+Keep the file's existing style and indentation. Return only the replacement lines, exactly as they should appear in the file — no code fences, line numbers or explanations.`
+
+// SuggestionRequest describes the lines a review comment asks to change
+type SuggestionRequest struct {
+	Change      string
+	FileContent string
+	Lines       string
+	Path        string
+}
+
+// SuggestChange proposes replacement lines for the selected part of a file
+func (client *Client) SuggestChange(request *SuggestionRequest) (string, error) {
+	completion, err := client.complete(
+		suggestionSystemPrompt,
+		buildSuggestionPrompt(request),
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return completion.Text, nil
+}
+
+// buildSuggestionPrompt creates the prompt for replacing the selected lines
+func buildSuggestionPrompt(request *SuggestionRequest) string {
+	return fmt.Sprintf(`File: %s
+
+Full file for context:
+%s
+
+Lines to replace:
+%s
+
+Change requested: "%s"`,
+		request.Path,
+		request.FileContent,
+		request.Lines,
+		request.Change,
+	)
+}
@@ -0,0 +1,81 @@
+package botai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// submitBlogPostTool is the tool Claude calls to hand back a structured blog post
+var submitBlogPostTool = anthropic.ToolParam{
+	Name:        "submit_blog_post",
+	Description: anthropic.String("Submit the finished blog post along with its frontmatter metadata."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"title": map[string]any{
+				"type":        "string",
+				"description": "The post title, without any prefix like 'Blog post:'",
+			},
+			"summary": map[string]any{
+				"type":        "string",
+				"description": "A 1-2 sentence summary of the post for the frontmatter",
+			},
+			"tags": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "3-5 lowercase topic tags",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "The full markdown content of the post, without frontmatter",
+			},
+		},
+		Required: []string{"title", "summary", "tags", "body"},
+	},
+}
+
+// completeWithTool forces Claude to answer by calling the given tool,
+// decodes the tool input into output, and returns the model that answered
+func (client *Client) completeWithTool(
+	systemPrompt string,
+	prompt string,
+	tool anthropic.ToolParam,
+	output any,
+) (string, error) {
+	return client.completeParamsWithTool(sharedUtils.CreateMessageParams(systemPrompt, prompt), tool, output)
+}
+
+// completeParamsWithTool is completeWithTool for callers that need to adjust
+// the request first, e.g. to raise MaxTokens
+func (client *Client) completeParamsWithTool(
+	params anthropic.MessageNewParams,
+	tool anthropic.ToolParam,
+	output any,
+) (string, error) {
+	params.Tools = []anthropic.ToolUnionParam{{OfTool: &tool}}
+	params.ToolChoice = anthropic.ToolChoiceParamOfTool(tool.Name)
+
+	message, err := client.newMessage(params)
+	if err != nil {
+		return "", err
+	}
+
+	for _, block := range message.Content {
+		isRequestedToolCall := block.Type == "tool_use" && block.Name == tool.Name
+
+		if !isRequestedToolCall {
+			continue
+		}
+
+		if err := json.Unmarshal(block.Input, output); err != nil {
+			return "", fmt.Errorf("decoding %s input: %w", tool.Name, err)
+		}
+
+		return string(message.Model), nil
+	}
+
+	return "", fmt.Errorf("unexpected response format from Anthropic: no %s call", tool.Name)
+}
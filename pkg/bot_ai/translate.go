@@ -0,0 +1,91 @@
+package botai
+
+import (
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// TranslateRequest is a post to translate into another language
+type TranslateRequest struct {
+	Content  string
+	Language string // target language code, e.g. "es" or "pt-BR"
+	Summary  string
+	Title    string
+}
+
+// Translation is the translated post returned by the submit_translation tool
+type Translation struct {
+	Body    string `json:"body"`
+	Model   string `json:"-"`
+	Summary string `json:"summary"`
+	Title   string `json:"title"`
+}
+
+// translatorSystemPrompt sets the rules for translating posts
+const translatorSystemPrompt = `You translate posts on a developer's personal website, keeping the author's casual, clear voice.
+
+Translate the prose, headings and code comments. Keep code, identifiers, URLs, CSS classes like {.text-lg .mb-6} and the markdown structure exactly as they are. Hand the translation back by calling the submit_translation tool.`
+
+// submitTranslationTool is the tool Claude calls to hand back a translated post
+var submitTranslationTool = anthropic.ToolParam{
+	Name:        "submit_translation",
+	Description: anthropic.String("Submit the translated blog post along with its translated frontmatter text."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"title": map[string]any{
+				"type":        "string",
+				"description": "The translated post title",
+			},
+			"summary": map[string]any{
+				"type":        "string",
+				"description": "The translated summary",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "The full translated markdown content, without frontmatter",
+			},
+		},
+		Required: []string{"title", "summary", "body"},
+	},
+}
+
+// Translate translates a post's title, summary and content into another language
+func (client *Client) Translate(request *TranslateRequest) (*Translation, error) {
+	translation := &Translation{}
+
+	model, err := client.completeWithTool(
+		translatorSystemPrompt,
+		buildTranslatePrompt(request),
+		submitTranslationTool,
+		translation,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	translation.Model = model
+
+	if translation.Body == "" {
+		return nil, fmt.Errorf("AI returned an empty translation")
+	}
+
+	return translation, nil
+}
+
+// buildTranslatePrompt creates the prompt for translating a post
+func buildTranslatePrompt(request *TranslateRequest) string {
+	return fmt.Sprintf(`Translate this blog post into the language with code %q.
+
+Title: %s
+Summary: %s
+
+Content:
+%s`,
+		request.Language,
+		request.Title,
+		request.Summary,
+		request.Content,
+	)
+}
@@ -0,0 +1,102 @@
+package botai
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// Issue categories returned by TriageIssue
+const (
+	IssueCategoryBug      = "bug"
+	IssueCategoryFeature  = "feature"
+	IssueCategoryOther    = "other"
+	IssueCategoryQuestion = "question"
+)
+
+// issueCategories lists the categories the triage tool may pick from
+var issueCategories = []string{IssueCategoryBug, IssueCategoryFeature, IssueCategoryOther, IssueCategoryQuestion}
+
+// maxTriageBody keeps long issue bodies, like pasted logs, from crowding the prompt
+const maxTriageBody = 6000
+
+// IssueTriage is the classification returned by the submit_issue_triage tool
+type IssueTriage struct {
+	Acknowledgment string `json:"acknowledgment"`
+	Category       string `json:"category"`
+}
+
+// IssueTriageRequest describes a new issue to classify
+type IssueTriageRequest struct {
+	Body       string
+	Repository string // owner/repo
+	Title      string
+}
+
+// issueTriageSystemPrompt sets the rules for classifying and acknowledging issues
+const issueTriageSystemPrompt = `You triage new GitHub issues for a small open source project maintained by one developer.
+
+Classify the issue:
+- bug: something is broken or behaves differently than documented
+- question: the author wants to know how something works or how to do something
+- feature: a request for new behavior or an improvement
+- other: anything else, like discussions, spam or notes to self
+
+Then write a short acknowledgment of 1-3 sentences to post on the issue: thank the author, restate what you understood in plain words, and, if something a maintainer would obviously need is missing (steps to reproduce, versions, expected behavior), ask for it. Don't promise fixes or timelines, don't answer the question or propose a solution, and don't mention that you are classifying the issue. Hand the result back by calling the submit_issue_triage tool.`
+
+// submitIssueTriageTool is the tool Claude calls to hand back an issue's classification
+var submitIssueTriageTool = anthropic.ToolParam{
+	Name:        "submit_issue_triage",
+	Description: anthropic.String("Submit the issue's category and the acknowledgment to post on it."),
+	InputSchema: anthropic.ToolInputSchemaParam{
+		Properties: map[string]any{
+			"category": map[string]any{
+				"type":        "string",
+				"enum":        issueCategories,
+				"description": "The kind of issue",
+			},
+			"acknowledgment": map[string]any{
+				"type":        "string",
+				"description": "A 1-3 sentence markdown reply acknowledging the issue",
+			},
+		},
+		Required: []string{"category", "acknowledgment"},
+	},
+}
+
+// TriageIssue classifies a new issue as a bug, question, feature or other,
+// and drafts a short acknowledgment
+func (client *Client) TriageIssue(request *IssueTriageRequest) (*IssueTriage, error) {
+	triage := &IssueTriage{}
+
+	if _, err := client.completeWithTool(
+		issueTriageSystemPrompt,
+		buildIssueTriagePrompt(request),
+		submitIssueTriageTool,
+		triage,
+	); err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(issueCategories, triage.Category) {
+		return nil, fmt.Errorf("AI returned an unknown issue category %q", triage.Category)
+	}
+
+	return triage, nil
+}
+
+// buildIssueTriagePrompt creates the prompt for classifying an issue
+func buildIssueTriagePrompt(request *IssueTriageRequest) string {
+	return fmt.Sprintf(`Triage this new issue on %s:
+
+Title: %s
+Body:
+%s`,
+		request.Repository,
+		request.Title,
+		sharedUtils.TruncateText(request.Body, maxTriageBody),
+	)
+}
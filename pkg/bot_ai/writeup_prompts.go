@@ -0,0 +1,59 @@
+package botai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WriteUpRequest represents a merged pull request to be turned into a blog post
+type WriteUpRequest struct {
+	Description string
+	Diff        string
+	Discussion  []string
+	SourceURL   string
+	Title       string
+}
+
+// writeUpSystemPrompt sets the voice for posts that explain a merged code change
+const writeUpSystemPrompt = `You are a technical blog writer with a casual, clear writing style, writing about code changes that were just merged.
+
+Structure each post around three questions:
+- What changed
+- Why it was needed
+- How it was implemented, with short Go excerpts from the diff where they help
+
+Style Guidelines:
+- Casual, conversational tone but still informative and clear
+- Use CSS classes in markdown format like: {.text-lg .text-gray-600 .mb-8}
+- Start most paragraphs with appropriate CSS styling classes
+- Link back to the pull request once near the end
+
+Write complete blog posts (just the content, no frontmatter).`
+
+// GenerateWriteUp drafts a blog post explaining the change made in a pull request
+func (c *Client) GenerateWriteUp(request *WriteUpRequest) (*Completion, error) {
+	return c.complete(writeUpSystemPrompt, buildWriteUpPrompt(request))
+}
+
+// buildWriteUpPrompt creates the prompt for writing a blog post about a code change
+func buildWriteUpPrompt(request *WriteUpRequest) string {
+	return fmt.Sprintf(`Write a blog post about this pull request.
+
+**Pull request:** %s
+**Link:** %s
+
+**Description:**
+%s
+
+**Discussion:**
+%s
+
+**Diff:**
+%s`,
+		request.Title,
+		request.SourceURL,
+		request.Description,
+		strings.Join(request.Discussion, "\n---\n"),
+		request.Diff,
+	)
+}
@@ -0,0 +1,177 @@
+package botblog
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// maxAltTextImages bounds the images described in one AI call; any beyond it are flagged
+const maxAltTextImages = 20
+
+// maxAltTextContext keeps the text sent with each image short
+const maxAltTextContext = 600
+
+var (
+	// emptyAltImagePattern matches a markdown image with blank alt text, capturing its URL
+	emptyAltImagePattern = regexp.MustCompile(`!\[\s*\]\(\s*(<[^>]*>|[^)\s]+)[^)]*\)`)
+
+	// htmlImagePattern matches an <img> tag
+	htmlImagePattern = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+
+	// htmlAltPattern finds an alt attribute, which may be empty on purpose for decorative images
+	htmlAltPattern = regexp.MustCompile(`(?i)\salt\s*=`)
+
+	// htmlSrcPattern captures an <img> tag's src, quoted or not
+	htmlSrcPattern = regexp.MustCompile(`(?i)\ssrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// missingAltImage is an image in markdown content with no alt text
+type missingAltImage struct {
+	End    int // offset in the line
+	IsHTML bool
+	Line   int
+	Start  int // offset in the line
+	URL    string
+}
+
+// fillAltText has the AI describe the images in content that have no alt
+// text, returning the updated content and a note for each image it couldn't describe
+func (handler *Handler) fillAltText(title, content string) (string, []string) {
+	if !handler.Config.AltText {
+		return content, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	images := findMissingAltImages(lines)
+
+	if len(images) == 0 {
+		return content, nil
+	}
+
+	described := images[:min(len(images), maxAltTextImages)]
+	references := make([]botAi.ImageReference, len(described))
+
+	for index, image := range described {
+		references[index] = botAi.ImageReference{Context: imageContext(lines, image), URL: image.URL}
+	}
+
+	altTexts, err := handler.AiClient.GenerateAltText(title, references)
+	if err != nil {
+		handler.logger.Error("Generating alt text failed", "title", title, "error", err)
+		altTexts = nil
+	}
+
+	notes := []string{}
+
+	// last to first, so filling one doesn't move the offsets of those before it on the same line
+	for index := len(images) - 1; index >= 0; index-- {
+		image := images[index]
+		altText := ""
+
+		if index < len(altTexts) {
+			altText = strings.TrimSpace(altTexts[index])
+		}
+
+		if altText == "" {
+			notes = append(notes, fmt.Sprintf("the image `%s` has no alt text; please describe it", image.URL))
+			continue
+		}
+
+		line := lines[image.Line]
+		lines[image.Line] = line[:image.Start] + withAltText(line[image.Start:image.End], image.IsHTML, altText) + line[image.End:]
+	}
+
+	slices.Reverse(notes)
+
+	return strings.Join(lines, "\n"), notes
+}
+
+// findMissingAltImages lists markdown and HTML images outside code blocks
+// that have no alt text, in the order they appear
+func findMissingAltImages(lines []string) []missingAltImage {
+	images := []missingAltImage{}
+	isInFence := false
+
+	for lineIndex, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			isInFence = !isInFence
+			continue
+		}
+
+		if isInFence {
+			continue
+		}
+
+		lineImages := []missingAltImage{}
+
+		for _, match := range emptyAltImagePattern.FindAllStringSubmatchIndex(line, -1) {
+			lineImages = append(lineImages, missingAltImage{
+				End:   match[1],
+				Line:  lineIndex,
+				Start: match[0],
+				URL:   strings.Trim(line[match[2]:match[3]], "<>"),
+			})
+		}
+
+		for _, match := range htmlImagePattern.FindAllStringIndex(line, -1) {
+			tag := line[match[0]:match[1]]
+
+			if htmlAltPattern.MatchString(tag) {
+				continue
+			}
+
+			lineImages = append(lineImages, missingAltImage{
+				End:    match[1],
+				IsHTML: true,
+				Line:   lineIndex,
+				Start:  match[0],
+				URL:    htmlImageSource(tag),
+			})
+		}
+
+		slices.SortFunc(lineImages, func(a, b missingAltImage) int { return a.Start - b.Start })
+		images = append(images, lineImages...)
+	}
+
+	return images
+}
+
+// htmlImageSource reads an <img> tag's src
+func htmlImageSource(tag string) string {
+	match := htmlSrcPattern.FindStringSubmatch(tag)
+	if match == nil {
+		return "(no src)"
+	}
+
+	return match[1] + match[2] + match[3]
+}
+
+// imageContext is the text on and around an image's line, for the AI to describe it from
+func imageContext(lines []string, image missingAltImage) string {
+	nearby := []string{}
+
+	for index := max(image.Line-2, 0); index <= min(image.Line+2, len(lines)-1); index++ {
+		if trimmed := strings.TrimSpace(lines[index]); trimmed != "" {
+			nearby = append(nearby, trimmed)
+		}
+	}
+
+	return sharedUtils.TruncateText(strings.Join(nearby, "\n"), maxAltTextContext)
+}
+
+// withAltText adds alt text to one markdown image or <img> tag
+func withAltText(image string, isHTML bool, altText string) string {
+	if isHTML {
+		return image[:len("<img")] + ` alt="` + html.EscapeString(altText) + `"` + image[len("<img"):]
+	}
+
+	altText = strings.NewReplacer("[", "(", "]", ")", "\n", " ").Replace(altText)
+
+	return "![" + altText + image[strings.Index(image, "]"):]
+}
@@ -1,35 +1,58 @@
 package botblog
 
 import (
-	"bytes"
 	"fmt"
-	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
 	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
 )
 
 // BlogPostRequest represents data needed to create a blog post
 type BlogPostRequest struct {
-	Draft  bool     `json:"draft"`
-	Points []string `json:"points"`
-	Tags   []string `json:"tags"`
-	Title  string   `json:"title"`
-	Topic  string   `json:"topic"`
+	Date          string   `json:"date,omitempty"` // created_at override, YYYY-MM-DD
+	Draft         bool     `json:"draft"`
+	Language      string   `json:"language,omitempty"`       // language code, e.g. "es"; empty for English
+	Outline       []string `json:"outline,omitempty"`        // section headings approved on the issue
+	OutlineReview *bool    `json:"outline_review,omitempty"` // propose an outline first; nil follows the repo config
+	Points        []string `json:"points"`
+	Progressive   bool     `json:"progressive"`     // write section by section, pushing as it goes
+	Style         string   `json:"style,omitempty"` // style preset, e.g. "tutorial"; empty for casual
+	Tags          []string `json:"tags"`
+	Title         string   `json:"title"`
+	TOC           *bool    `json:"toc,omitempty"` // table of contents; nil follows the repo config
+	Topic         string   `json:"topic"`
+	Words         int      `json:"words,omitempty"` // target word count; 0 leaves the length to the model
 }
 
-// Post represents a blog post with frontmatter matching your format
+// postDateLayout is the format of created_at in post frontmatter
+const postDateLayout = "2006-01-02"
+
+// bulletPattern matches the marker of a markdown list item: "- ", "* " or "1. "
+var bulletPattern = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+
+// Post represents a blog post; a FrontmatterFormat decides the field names
+// each site's generator sees
 type Post struct {
-	Content   string   `yaml:"-"`
-	CreatedAt string   `yaml:"created_at"`
-	IsDraft   bool     `yaml:"is_draft"`
-	Key       string   `yaml:"key"`
-	Language  string   `yaml:"language"`
-	Summary   string   `yaml:"summary"`
-	Tags      []string `yaml:"tags"`
-	Title     string   `yaml:"title"`
-	Type      string   `yaml:"type"`
+	AIAssisted           bool
+	Content              string
+	CreatedAt            string
+	Crossposts           map[string]string // platform name to the post's URL there
+	Image                string            // cover image file name, relative to the published post
+	IsDraft              bool
+	Key                  string
+	Language             string
+	License              string
+	MetaDescription      string
+	OpenGraphDescription string
+	OpenGraphTitle       string
+	PublishAt            string // when a draft on main is published, e.g. "2025-03-01 09:00 PST"
+	Summary              string
+	Tags                 []string
+	Title                string
+	Type                 string
 }
 
 // NewPost creates a new blog post with default values
@@ -37,7 +60,7 @@ func NewPost(title, topic string, tags []string, isDraft bool) *Post {
 	key := generateKey(title)
 
 	return &Post{
-		CreatedAt: time.Now().Format("2000-12-31"),
+		CreatedAt: time.Now().Format(postDateLayout),
 		IsDraft:   isDraft,
 		Key:       key,
 		Language:  "en",
@@ -48,39 +71,43 @@ func NewPost(title, topic string, tags []string, isDraft bool) *Post {
 	}
 }
 
-// FilePath returns the correct file path based on draft status
-func (p *Post) GetFilePath() string {
-	filename := fmt.Sprintf("%s.md", p.Key)
-
-	if p.IsDraft {
-		return filepath.Join("pkg", "blog_markdown_content", "drafts", filename)
-	}
-
-	return filepath.Join("pkg", "blog_markdown_content", "posts", filename)
+// FilePath returns the correct file path in the site's layout based on draft status
+func (p *Post) GetFilePath(layout botConfig.ContentLayout, extension string) string {
+	return layout.FilePath(p.Key+extension, p.IsDraft)
 }
 
-// ToMarkdown converts the post to markdown format with frontmatter
+// ToMarkdown converts the post to markdown in the default frontmatter format
 func (p *Post) GenerateMarkdown() string {
-	var buf bytes.Buffer
+	return defaultFrontmatter.Render(p)
+}
 
-	buf.WriteString("---\n")
-	buf.WriteString(fmt.Sprintf("created_at: %s\n", p.CreatedAt))
-	buf.WriteString(fmt.Sprintf("is_draft: %t\n", p.IsDraft))
-	buf.WriteString(fmt.Sprintf("key: %s\n", p.Key))
-	buf.WriteString(fmt.Sprintf("language: %s\n", p.Language))
-	buf.WriteString(fmt.Sprintf("summary: %s\n", p.Summary))
+// ApplyLicensing adds the repo's license and AI disclosure to the post,
+// appending the attribution block to the content when one is configured
+func (p *Post) ApplyLicensing(licensing botConfig.Licensing) {
+	p.AIAssisted = licensing.AIAssisted
+	p.License = licensing.License
 
-	buf.WriteString("tags:\n")
-	for _, tag := range p.Tags {
-		buf.WriteString(fmt.Sprintf("  - %s\n", tag))
+	if licensing.Attribution != "" {
+		p.Content = fmt.Sprintf(
+			"%s\n\n---\n\n{.text-sm .text-gray-500 .italic}\n%s\n",
+			strings.TrimRight(p.Content, "\n"),
+			licensing.Attribution,
+		)
 	}
+}
 
-	buf.WriteString(fmt.Sprintf("title: %s\n", p.Title))
-	buf.WriteString(fmt.Sprintf("type: %s\n", p.Type))
-	buf.WriteString("---\n\n")
-	buf.WriteString(p.Content)
+// ApplyRequestLanguage sets the post's language from the request's language directive, if it had one
+func (p *Post) ApplyRequestLanguage(request *BlogPostRequest) {
+	if request.Language != "" {
+		p.Language = request.Language
+	}
+}
 
-	return buf.String()
+// ApplyRequestDate sets created_at from the request's date directive, if it had one
+func (p *Post) ApplyRequestDate(request *BlogPostRequest) {
+	if request.Date != "" {
+		p.CreatedAt = request.Date
+	}
 }
 
 // UpdateDraftStatus changes the draft status and updates the key if needed
@@ -90,23 +117,32 @@ func (p *Post) UpdateDraftStatus(isDraft bool) {
 
 // generateKey creates a URL-friendly key from the title
 func generateKey(title string) string {
-	key := strings.ToLower(title)
-	key = strings.ReplaceAll(key, " ", "-")
-
-	// Remove special characters, keep only alphanumeric and hyphens
-	var result strings.Builder
-	for _, rune := range key {
-		isAlphaNumericOrDash :=
-			sharedUtils.IsRuneDashCharacter(rune) ||
-				sharedUtils.IsRuneNumerical(rune) ||
-				sharedUtils.IsRuneDashCharacter(rune)
-
-		if isAlphaNumericOrDash {
-			result.WriteRune(rune)
+	if key := sharedUtils.Slugify(title, "-"); key != "" {
+		return key
+	}
+
+	return "post"
+}
+
+// mergeTags combines tag lists, lowercasing and dropping duplicates and blanks
+func mergeTags(tagLists ...[]string) []string {
+	seen := map[string]bool{}
+	merged := []string{}
+
+	for _, tags := range tagLists {
+		for _, tag := range tags {
+			normalized := strings.ToLower(strings.TrimSpace(tag))
+
+			if normalized == "" || seen[normalized] {
+				continue
+			}
+
+			seen[normalized] = true
+			merged = append(merged, normalized)
 		}
 	}
 
-	return result.String()
+	return merged
 }
 
 // ParseIssueForRequest extracts blog post request data from GitHub issue
@@ -114,6 +150,10 @@ func ParseIssueForRequest(title, body string) *BlogPostRequest {
 	// Remove "Blog post:" prefix if present
 	cleanTitle := strings.TrimSpace(strings.TrimPrefix(title, "Blog post:"))
 
+	if form, isForm := sharedUtils.ParseIssueForm(body); isForm {
+		return requestFromForm(cleanTitle, form)
+	}
+
 	request := &BlogPostRequest{
 		Draft: true,                     // start as draft
 		Tags:  []string{"ai-generated"}, // default tag
@@ -141,5 +181,157 @@ func ParseIssueForRequest(title, body string) *BlogPostRequest {
 		}
 	}
 
+	request.Points = parsePoints(body)
+
+	for _, line := range strings.Split(body, "\n") {
+		cleanLine := strings.ToLower(strings.TrimSpace(line))
+
+		switch {
+		// "progressive: true" asks for a long post written and pushed section by section
+		case strings.HasPrefix(cleanLine, "progressive:"):
+			value := strings.TrimSpace(strings.TrimPrefix(cleanLine, "progressive:"))
+			request.Progressive = value == "true" || value == "yes"
+
+		// "draft: false" opens the post straight in posts/ instead of drafts/
+		case strings.HasPrefix(cleanLine, "draft:"):
+			value := strings.TrimSpace(strings.TrimPrefix(cleanLine, "draft:"))
+			request.Draft = value != "false" && value != "no"
+
+		// "date: 2025-03-01" sets the post's created_at
+		case strings.HasPrefix(cleanLine, "date:"):
+			request.Date = parsePostDate(strings.TrimPrefix(cleanLine, "date:"))
+
+		// "language: es" writes the post in another language
+		case strings.HasPrefix(cleanLine, "language:"):
+			request.Language = parseLanguage(strings.TrimPrefix(cleanLine, "language:"))
+
+		// "outline: true" proposes an outline on the issue before writing
+		case strings.HasPrefix(cleanLine, "outline:"):
+			request.OutlineReview = parseBoolDirective(strings.TrimPrefix(cleanLine, "outline:"))
+
+		// "style: tutorial" picks the voice the post is written in
+		case strings.HasPrefix(cleanLine, "style:"):
+			request.Style = parseStyle(strings.TrimPrefix(cleanLine, "style:"))
+
+		// "length: short|medium|long" or "words: 1500" sets a target length
+		case strings.HasPrefix(cleanLine, "length:"):
+			request.Words = parseLength(strings.TrimPrefix(cleanLine, "length:"))
+
+		case strings.HasPrefix(cleanLine, "words:"):
+			request.Words = parseWordCount(strings.TrimPrefix(cleanLine, "words:"))
+
+		// "toc: true" adds a table of contents after the intro
+		case strings.HasPrefix(cleanLine, "toc:"):
+			request.TOC = parseBoolDirective(strings.TrimPrefix(cleanLine, "toc:"))
+		}
+	}
+
+	return request
+}
+
+// requestFromForm builds a request from an issue created with an issue form,
+// where each field has its own "### " section
+func requestFromForm(title string, form sharedUtils.IssueForm) *BlogPostRequest {
+	request := &BlogPostRequest{
+		Draft:  true,
+		Points: form.Lines("points", "key points"),
+		Tags:   mergeTags([]string{"ai-generated"}, form.List("tags")),
+		Title:  title,
+		Topic:  form.Value("topic", "description", "what should the post cover"),
+	}
+
+	if formTitle := form.Value("title"); formTitle != "" {
+		request.Title = formTitle
+	}
+
+	if isDraft, isSet := form.Bool("draft"); isSet {
+		request.Draft = isDraft
+	}
+
+	request.Progressive, _ = form.Bool("progressive")
+
+	if hasTOC, isSet := form.Bool("toc", "table of contents"); isSet {
+		request.TOC = &hasTOC
+	}
+
+	if hasOutlineReview, isSet := form.Bool("outline", "outline first"); isSet {
+		request.OutlineReview = &hasOutlineReview
+	}
+	request.Date = parsePostDate(form.Value("date"))
+	request.Language = parseLanguage(form.Value("language"))
+	request.Style = parseStyle(form.Value("style"))
+
+	if words := parseWordCount(form.Value("words", "word count")); words > 0 {
+		request.Words = words
+	} else {
+		request.Words = parseLength(form.Value("length"))
+	}
+
 	return request
 }
+
+// parsePoints reads the key points listed under a "points:" line, either
+// comma-separated on the line itself or as a bullet list below it
+func parsePoints(body string) []string {
+	points := []string{}
+	isInList := false
+
+	for line := range strings.SplitSeq(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(strings.ToLower(trimmed), "points:") {
+			isInList = true
+
+			for point := range strings.SplitSeq(trimmed[len("points:"):], ",") {
+				if cleanPoint := strings.TrimSpace(point); cleanPoint != "" {
+					points = append(points, cleanPoint)
+				}
+			}
+
+			continue
+		}
+
+		if !isInList {
+			continue
+		}
+
+		bullet := bulletPattern.FindString(trimmed)
+
+		// the list ends at the first line that isn't a bullet, allowing a
+		// blank line straight after "points:"
+		if bullet == "" {
+			if trimmed == "" && len(points) == 0 {
+				continue
+			}
+
+			isInList = false
+			continue
+		}
+
+		if point := strings.TrimSpace(trimmed[len(bullet):]); point != "" {
+			points = append(points, point)
+		}
+	}
+
+	return points
+}
+
+// parsePostDate checks a date directive, returning "" when it isn't a valid
+// YYYY-MM-DD date so the post keeps today's date
+func parsePostDate(value string) string {
+	value = strings.TrimSpace(value)
+
+	if _, err := time.Parse(postDateLayout, value); err != nil {
+		return ""
+	}
+
+	return value
+}
+
+// parseBoolDirective reads a yes/no directive that overrides a repo default
+func parseBoolDirective(value string) *bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	isOn := value == "true" || value == "yes"
+
+	return &isOn
+}
@@ -0,0 +1,50 @@
+package botblog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+)
+
+func FuzzParseIssueForRequest(f *testing.F) {
+	f.Add("Blog post: Testing handlers", "How to test webhook handlers")
+	f.Add("Blog post: Tags", "tags: go, htmx\npoints: one, two\ndraft: false\ndate: 2025-03-01")
+	f.Add("Directives", "style: deep dive\nlanguage: pt-BR\nwords: 1,500 words\ntoc: yes\noutline: true\nprogressive: true")
+	f.Add("Bullets", "points:\n\n- first\n* second\n1. third\n\nafter the list")
+	f.Add("Form", "### Topic\n\nTesting handlers\n\n### Tags\n\ngo, testing\n\n### Draft\n\n- [x] yes\n\n### Length\n\nlong")
+	f.Add("", "words: -5\nlength: huge\ndate: 2025-13-40\nstyle: shouty\nlanguage: not a language")
+
+	f.Fuzz(func(t *testing.T, title, body string) {
+		request := ParseIssueForRequest(title, body)
+
+		if len(request.Tags) == 0 || request.Tags[0] != "ai-generated" {
+			t.Errorf("got tags %q, want ai-generated first", request.Tags)
+		}
+
+		for _, point := range request.Points {
+			if point == "" || point != strings.TrimSpace(point) {
+				t.Errorf("got point %q, want it trimmed and non-empty", point)
+			}
+		}
+
+		if request.Words < 0 || request.Words > maxTargetWords {
+			t.Errorf("got %d words, want 0 to %d", request.Words, maxTargetWords)
+		}
+
+		if request.Date != "" {
+			if _, err := time.Parse(postDateLayout, request.Date); err != nil {
+				t.Errorf("got date %q, which isn't YYYY-MM-DD", request.Date)
+			}
+		}
+
+		if request.Language != "" && !languagePattern.MatchString(request.Language) {
+			t.Errorf("got language %q, which isn't a language code", request.Language)
+		}
+
+		if request.Style != "" && !botAi.IsStylePreset(request.Style) {
+			t.Errorf("got style %q, which isn't a preset", request.Style)
+		}
+	})
+}
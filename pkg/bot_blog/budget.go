@@ -0,0 +1,14 @@
+package botblog
+
+import (
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+)
+
+// budgetExhaustedComment explains why a request is on hold and how to pick it up again
+func (handler *Handler) budgetExhaustedComment(err error) string {
+	return handler.replies.Render(
+		"blog_budget_exhausted",
+		botReplies.Data{"Reason": botHandler.BudgetReason(err), "RetryCommand": generateCommand},
+	)
+}
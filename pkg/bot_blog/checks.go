@@ -0,0 +1,28 @@
+package botblog
+
+import botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+
+// generationCheckName is the check run shown on the head commit of new post PRs
+const generationCheckName = "AI blog post"
+
+// newGenerationCheck prepares the check run that shows a post being written
+func (handler *Handler) newGenerationCheck() *botGithub.GenerationCheck {
+	return handler.GithubClient.NewGenerationCheck(
+		botGithub.NewGenerationCheckArgs{
+			Name:  generationCheckName,
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+		},
+	)
+}
+
+// reportGenerated marks a post written in one go as generated on its commit
+func (handler *Handler) reportGenerated(commitSHA string) {
+	if err := handler.newGenerationCheck().Finish(
+		commitSHA,
+		botGithub.CheckConclusionSuccess,
+		"✅ post generated",
+	); err != nil {
+		handler.logger.Error("Reporting check run failed", "error", err)
+	}
+}
@@ -0,0 +1,52 @@
+package botblog
+
+import (
+	"fmt"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// outcomeComment describes a closed post PR for its source issue, linking the
+// post when it was merged
+func (handler *Handler) outcomeComment(pullRequest *github.PullRequest) string {
+	prNumber := pullRequest.GetNumber()
+
+	if !pullRequest.GetMerged() {
+		return fmt.Sprintf("#%d was closed without merging, so nothing was published.", prNumber)
+	}
+
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Getting PR files failed", "error", err)
+	}
+
+	for _, file := range files {
+		if !handler.Config.Content.IsPostFile(file.GetFilename()) || file.GetStatus() == "removed" {
+			continue
+		}
+
+		link := fmt.Sprintf(
+			"[`%s`](https://github.com/%s/blob/%s/%s)",
+			file.GetFilename(),
+			handler.common().FullRepoName(),
+			pullRequest.GetBase().GetRef(),
+			file.GetFilename(),
+		)
+
+		if handler.Config.Content.IsDraftFile(file.GetFilename()) {
+			return fmt.Sprintf("📝 #%d was merged. The post is saved as a draft: %s", prNumber, link)
+		}
+
+		return fmt.Sprintf("🎉 #%d was merged and the post is published: %s", prNumber, link)
+	}
+
+	return fmt.Sprintf("🎉 #%d was merged.", prNumber)
+}
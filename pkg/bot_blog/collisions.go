@@ -0,0 +1,64 @@
+package botblog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// claimPostKey gives the post a key no post on main uses yet, as a draft or
+// published, adding "-2", "-3" and so on until one is free. Committing
+// over an existing post would either fail or replace it
+func (handler *Handler) claimPostKey(post *Post) error {
+	var lookupErr error
+
+	isTaken := func(key string) bool {
+		draftPath := handler.postFilePath(&Post{IsDraft: true, Key: key})
+		publishedPath := handler.postFilePath(&Post{IsDraft: false, Key: key})
+
+		for _, filePath := range slices.Compact([]string{draftPath, publishedPath}) {
+			exists, err := handler.postFileExists(filePath)
+			if err != nil {
+				lookupErr = err
+				return false
+			}
+
+			if exists {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	key := sharedUtils.UniqueSlug(post.Key, "-", isTaken)
+
+	if lookupErr != nil {
+		return fmt.Errorf("checking for an existing post: %w", lookupErr)
+	}
+
+	post.Key = key
+
+	return nil
+}
+
+// postFileExists reports whether a file exists on the main branch
+func (handler *Handler) postFileExists(filePath string) (bool, error) {
+	_, _, err := handler.GithubClient.GetFileContent(
+		botGithub.GetFileContentArgs{
+			Filename: filePath,
+			Owner:    handler.Owner,
+			Ref:      "main",
+			Repo:     handler.Repo,
+		},
+	)
+
+	if errors.Is(err, botGithub.ErrFileNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,70 @@
+package botblog
+
+import (
+	"strings"
+
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	botPreview "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_preview"
+)
+
+// handlePRCommand runs slash commands posted on a blog PR, reporting whether
+// the comment was handled as a command
+func (handler *Handler) handlePRCommand(prNumber int, commentBody, author string) bool {
+	command, argument := parseCommand(commentBody)
+
+	switch {
+	case command == "/publish":
+		handler.handlePublishCommand(prNumber, argument, author)
+
+	case command == botPreview.ApplyCommand:
+		handler.handleApplyCommand(prNumber, author)
+
+	case command == botHandler.UndoCommand:
+		handler.common().HandleUndoCommand(prNumber)
+
+	case command == regenerateCommand:
+		handler.handleRegenerateCommand(prNumber, argument)
+
+	case command == botHandler.ReadyCommand:
+		handler.common().HandleReadyCommand(prNumber)
+
+	case command == translateCommand && argument != "":
+		handler.handleTranslateCommand(prNumber, argument)
+
+	case command == crosspostCommand:
+		handler.handleCrosspostCommand(prNumber, argument)
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// parseCommand splits the first line of a "/command argument" comment
+func parseCommand(commentBody string) (string, string) {
+	trimmed := strings.TrimSpace(commentBody)
+
+	if !strings.HasPrefix(trimmed, "/") {
+		return "", ""
+	}
+
+	firstLine := strings.SplitN(trimmed, "\n", 2)[0]
+	parts := strings.SplitN(firstLine, " ", 2)
+
+	command := strings.ToLower(parts[0])
+	argument := ""
+
+	if len(parts) == 2 {
+		argument = strings.TrimSpace(parts[1])
+	}
+
+	return command, argument
+}
+
+// handleApplyCommand commits the author's latest previewed change
+func (handler *Handler) handleApplyCommand(prNumber int, author string) {
+	if err := handler.previewer.Apply(prNumber, author); err != nil {
+		handler.common().CommentOnPR(prNumber, handler.common().CommandFailedComment(prNumber, "apply that", err))
+	}
+}
@@ -0,0 +1,252 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	"github.com/google/go-github/v57/github"
+)
+
+const generateCommand = "/generate"
+
+// wantsConversation reports whether an issue asked to refine the request in
+// comments before anything is generated ("conversation: true")
+func wantsConversation(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		cleanLine := strings.ToLower(strings.TrimSpace(line))
+
+		if strings.HasPrefix(cleanLine, "conversation:") {
+			value := strings.TrimSpace(strings.TrimPrefix(cleanLine, "conversation:"))
+			return value == "true" || value == "yes"
+		}
+	}
+
+	return false
+}
+
+// startConversation stores the request so follow-up comments can refine it
+func (handler *Handler) startConversation(issueNumber int, request *BlogPostRequest, intro string) {
+	if err := handler.Store.SaveIssueRequest(handler.common().FullRepoName(), issueNumber, request); err != nil {
+		handler.logger.Error("Saving issue request failed", "error", err)
+		return
+	}
+
+	handler.common().CommentOnIssue(issueNumber, fmt.Sprintf(
+		"%s\n\nReply with `title: ...`, `tags: a, b`, `- a point to cover` or more context to refine it, and `%s` when it's ready.\n\n%s",
+		intro,
+		generateCommand,
+		describeRequest(request),
+	))
+}
+
+// handleIssueConversation refines a pending request from an issue comment, or
+// opens its PR on /generate
+func (handler *Handler) handleIssueConversation(issue *github.Issue, comment *github.IssueComment) {
+	issueNumber := issue.GetNumber()
+	request := &BlogPostRequest{}
+
+	isFound, err := handler.Store.LoadIssueRequest(handler.common().FullRepoName(), issueNumber, request)
+	if err != nil {
+		handler.logger.Error("Loading issue request failed", "error", err)
+		return
+	}
+
+	// nothing pending means the PR already exists or this isn't a blog request
+	if !isFound || !handler.common().IsAuthorized(comment.GetUser().GetLogin(), issueNumber) {
+		return
+	}
+
+	if !handler.common().IsWithinRateLimit(comment.GetUser().GetLogin(), issueNumber) {
+		return
+	}
+
+	// a proposed outline is waiting for approval
+	if len(request.Outline) > 0 {
+		handler.handleOutlineReply(issue, request, comment.GetBody())
+		return
+	}
+
+	command, _ := parseCommand(comment.GetBody())
+
+	if command == generateCommand && handler.wantsOutlineReview(request) {
+		handler.proposeOutline(issueNumber, request, "")
+		return
+	}
+
+	if command == generateCommand || command == approveOutlineCommand {
+		handler.generateFromConversation(issue, request)
+		return
+	}
+
+	if command != "" || !refineRequest(request, comment.GetBody()) {
+		return
+	}
+
+	if err := handler.Store.SaveIssueRequest(handler.common().FullRepoName(), issueNumber, request); err != nil {
+		handler.logger.Error("Saving issue request failed", "error", err)
+		return
+	}
+
+	handler.common().CommentOnIssue(issueNumber, fmt.Sprintf("📝 Updated the request.\n\n%s", describeRequest(request)))
+}
+
+// generateFromConversation opens the PR for a refined request; the request is
+// kept on failure so /generate can be retried
+func (handler *Handler) generateFromConversation(issue *github.Issue, request *BlogPostRequest) {
+	handler = handler.inLanguage(request.Language)
+
+	if !handler.common().EnsureWritable(issue.GetNumber()) {
+		return
+	}
+
+	if err := handler.common().CheckBudget(); err != nil {
+		handler.notifyBudgetExhausted(issue, err)
+		handler.common().CommentOnIssue(issue.GetNumber(), handler.budgetExhaustedComment(err))
+		return
+	}
+
+	if err := handler.GithubClient.ReactToIssue(
+		botGithub.ReactToIssueArgs{
+			IssueNumber: issue.GetNumber(),
+			Owner:       handler.Owner,
+			Reaction:    "rocket",
+			Repo:        handler.Repo,
+		},
+	); err != nil {
+		handler.logger.Error("Reacting to issue failed", "error", err)
+	}
+
+	if handler.isDryRun(issue) {
+		handler.previewRequest(issue, request)
+		return
+	}
+
+	if err := handler.createBlogPostPR(issue, request); err != nil {
+		handler.logger.Error("Creating blog post PR failed", "error", err)
+		reason, _ := botErrors.UserMessage(err)
+
+		handler.common().CommentOnIssue(
+			issue.GetNumber(),
+			handler.replies.Render(
+				"blog_generation_failed",
+				botReplies.Data{"Reason": reason, "RetryCommand": generateCommand},
+			)+handler.common().FailureFooter(issue.GetNumber(), err),
+		)
+		return
+	}
+
+	if err := handler.Store.DeleteIssueRequest(handler.common().FullRepoName(), issue.GetNumber()); err != nil {
+		handler.logger.Error("Deleting issue request failed", "error", err)
+	}
+}
+
+// refineRequest applies a follow-up comment to the request, reporting whether
+// anything changed. Directive lines set fields, bullets add points and any
+// other text is added to the topic
+func refineRequest(request *BlogPostRequest, comment string) bool {
+	isChanged := false
+	extraContext := []string{}
+
+	for _, line := range strings.Split(comment, "\n") {
+		cleanLine := strings.TrimSpace(line)
+		lowerLine := strings.ToLower(cleanLine)
+
+		switch {
+		case cleanLine == "":
+			continue
+
+		case strings.HasPrefix(lowerLine, "title:"):
+			request.Title = strings.TrimSpace(cleanLine[len("title:"):])
+
+		case strings.HasPrefix(lowerLine, "tags:"):
+			request.Tags = mergeTags(request.Tags, strings.Split(cleanLine[len("tags:"):], ","))
+
+		case strings.HasPrefix(lowerLine, "progressive:"):
+			value := strings.TrimSpace(lowerLine[len("progressive:"):])
+			request.Progressive = value == "true" || value == "yes"
+
+		case strings.HasPrefix(lowerLine, "draft:"):
+			value := strings.TrimSpace(lowerLine[len("draft:"):])
+			request.Draft = value != "false" && value != "no"
+
+		case strings.HasPrefix(lowerLine, "date:"):
+			request.Date = parsePostDate(cleanLine[len("date:"):])
+
+		case strings.HasPrefix(lowerLine, "language:"):
+			request.Language = parseLanguage(cleanLine[len("language:"):])
+
+		case strings.HasPrefix(lowerLine, "length:"):
+			request.Words = parseLength(lowerLine[len("length:"):])
+
+		case strings.HasPrefix(lowerLine, "words:"):
+			request.Words = parseWordCount(lowerLine[len("words:"):])
+
+		case strings.HasPrefix(lowerLine, "style:"):
+			request.Style = parseStyle(cleanLine[len("style:"):])
+
+		case strings.HasPrefix(lowerLine, "toc:"):
+			request.TOC = parseBoolDirective(lowerLine[len("toc:"):])
+
+		case strings.HasPrefix(cleanLine, "- ") || strings.HasPrefix(cleanLine, "* "):
+			request.Points = append(request.Points, strings.TrimSpace(cleanLine[2:]))
+
+		default:
+			extraContext = append(extraContext, cleanLine)
+		}
+
+		isChanged = true
+	}
+
+	if len(extraContext) > 0 {
+		request.Topic = strings.TrimSpace(request.Topic + "\n\n" + strings.Join(extraContext, "\n"))
+	}
+
+	return isChanged
+}
+
+// describeRequest renders the request as it stands, for confirmation comments
+func describeRequest(request *BlogPostRequest) string {
+	var description strings.Builder
+
+	title := request.Title
+	if title == "" {
+		title = "_(the AI will pick one)_"
+	}
+
+	description.WriteString(fmt.Sprintf("**Title:** %s\n", title))
+	description.WriteString(fmt.Sprintf("**Tags:** %s\n", strings.Join(request.Tags, ", ")))
+	description.WriteString(fmt.Sprintf("**Draft:** %t\n", request.Draft))
+
+	if request.Date != "" {
+		description.WriteString(fmt.Sprintf("**Date:** %s\n", request.Date))
+	}
+
+	if request.Language != "" {
+		description.WriteString(fmt.Sprintf("**Language:** %s\n", request.Language))
+	}
+
+	if request.Style != "" {
+		description.WriteString(fmt.Sprintf("**Style:** %s\n", request.Style))
+	}
+
+	if request.Words > 0 {
+		description.WriteString(fmt.Sprintf("**Target length:** about %d words\n", request.Words))
+	}
+
+	if request.TOC != nil {
+		description.WriteString(fmt.Sprintf("**Table of contents:** %t\n", *request.TOC))
+	}
+
+	if len(request.Points) > 0 {
+		description.WriteString("**Points:**\n")
+
+		for _, point := range request.Points {
+			description.WriteString(fmt.Sprintf("- %s\n", point))
+		}
+	}
+
+	return description.String()
+}
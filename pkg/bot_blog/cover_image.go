@@ -0,0 +1,246 @@
+package botblog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+)
+
+// CoverImageGenerator makes the cover art committed beside a new post. Set
+// Handler.CoverImages to plug in your own; by default the repo config picks
+// an image endpoint or the built-in SVG card
+type CoverImageGenerator interface {
+	Generate(post *Post) (*CoverImage, error)
+}
+
+// CoverImage is generated cover art and the file extension it's saved with
+type CoverImage struct {
+	Content   []byte
+	Extension string // e.g. ".png"
+}
+
+// coverImageTimeout bounds a call to an image endpoint
+const coverImageTimeout = 60 * time.Second
+
+// maxCoverImageBytes caps what an image endpoint may send back
+const maxCoverImageBytes = 5 << 20
+
+// coverImageExtensions maps the content types an endpoint may reply with to file extensions
+var coverImageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/svg+xml": ".svg",
+	"image/webp":    ".webp",
+}
+
+// coverImageGenerator returns the handler's generator, or nil when cover images are off
+func (handler *Handler) coverImageGenerator() CoverImageGenerator {
+	cover := handler.Config.CoverImage
+
+	switch {
+	case handler.CoverImages != nil:
+		return handler.CoverImages
+	case cover.Endpoint != "":
+		return &endpointCoverImages{
+			client:   &http.Client{Timeout: coverImageTimeout},
+			endpoint: cover.Endpoint,
+		}
+	case cover.SVGCard:
+		return svgCardImages{}
+	}
+
+	return nil
+}
+
+// addCoverImage generates cover art for a post, commits it to the post's
+// branch and points the frontmatter at it. Cover art is a nice-to-have, so
+// a failure is logged and the post goes ahead without it
+func (handler *Handler) addCoverImage(branch string, post *Post) {
+	generator := handler.coverImageGenerator()
+	if generator == nil {
+		return
+	}
+
+	cover, err := generator.Generate(post)
+	if err != nil {
+		handler.logger.Error("Generating a cover image failed", "title", post.Title, "error", err)
+		return
+	}
+
+	// it's committed where the post is published, so the frontmatter's
+	// relative path holds once the post is live
+	imageName := post.Key + cover.Extension
+	filename := path.Join(handler.Config.Content.Dir(false), imageName)
+
+	if err := handler.checkPath(filename); err != nil {
+		handler.logger.Error("Adding a cover image failed", "title", post.Title, "error", err)
+		return
+	}
+
+	commitSHA, err := handler.GithubClient.CreateFile(
+		botGithub.CreateFileArgs{
+			Branch:   branch,
+			Content:  string(cover.Content),
+			Filename: filename,
+			Message:  "Add cover image",
+			Owner:    handler.Owner,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Committing a cover image failed", "title", post.Title, "error", err)
+		return
+	}
+
+	handler.common().RecordBranchCommit(branch, commitSHA)
+	post.Image = imageName
+}
+
+// coverImageFields writes the cover image's file name to the configured frontmatter key
+func coverImageFields(key string) []frontmatterField {
+	if key == "" {
+		return nil
+	}
+
+	return []frontmatterField{
+		{
+			key:   key,
+			read:  func(post *Post) any { return omitEmpty(post.Image) },
+			write: func(post *Post, value any) { post.Image = asString(value) },
+		},
+	}
+}
+
+// endpointCoverImages POSTs a post's details to an image service, which
+// replies with the image
+type endpointCoverImages struct {
+	client   *http.Client
+	endpoint string
+}
+
+func (images *endpointCoverImages) Generate(post *Post) (*CoverImage, error) {
+	body, err := json.Marshal(map[string]any{
+		"key":     post.Key,
+		"summary": post.Summary,
+		"tags":    post.Tags,
+		"title":   post.Title,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("encoding cover image request: %w", err)
+	}
+
+	response, err := images.client.Post(images.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("calling image endpoint: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image endpoint returned %s", response.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+
+	extension, isImage := coverImageExtensions[mediaType]
+	if !isImage {
+		return nil, fmt.Errorf("image endpoint returned unsupported content type %q", mediaType)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxCoverImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading cover image: %w", err)
+	}
+
+	if len(content) > maxCoverImageBytes {
+		return nil, fmt.Errorf("cover image is larger than %d bytes", maxCoverImageBytes)
+	}
+
+	return &CoverImage{Content: content, Extension: extension}, nil
+}
+
+// svgCardImages draws a 1200x630 social card with the post's title and tags
+type svgCardImages struct{}
+
+// svgCardLineLength and svgCardMaxLines keep the title inside the card
+const (
+	svgCardLineLength = 28
+	svgCardMaxLines   = 4
+)
+
+func (svgCardImages) Generate(post *Post) (*CoverImage, error) {
+	var card strings.Builder
+
+	card.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="1200" height="630" viewBox="0 0 1200 630">
+  <defs>
+    <linearGradient id="background" x1="0" y1="0" x2="1" y2="1">
+      <stop offset="0%" stop-color="#1e293b"/>
+      <stop offset="100%" stop-color="#4338ca"/>
+    </linearGradient>
+  </defs>
+  <rect width="1200" height="630" fill="url(#background)"/>
+`)
+
+	for index, line := range wrapCardTitle(post.Title) {
+		fmt.Fprintf(
+			&card,
+			"  <text x=\"80\" y=\"%d\" font-family=\"system-ui, sans-serif\" font-size=\"64\" font-weight=\"700\" fill=\"#f8fafc\">%s</text>\n",
+			200+index*80,
+			html.EscapeString(line),
+		)
+	}
+
+	if len(post.Tags) > 0 {
+		fmt.Fprintf(
+			&card,
+			"  <text x=\"80\" y=\"560\" font-family=\"system-ui, sans-serif\" font-size=\"32\" fill=\"#c7d2fe\">%s</text>\n",
+			html.EscapeString("#"+strings.Join(post.Tags, "  #")),
+		)
+	}
+
+	card.WriteString("</svg>\n")
+
+	return &CoverImage{Content: []byte(card.String()), Extension: ".svg"}, nil
+}
+
+// wrapCardTitle breaks a title into lines that fit the card, ending the last
+// line with an ellipsis when the title is too long
+func wrapCardTitle(title string) []string {
+	lines := []string{}
+	line := ""
+
+	for word := range strings.FieldsSeq(title) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > svgCardLineLength {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+
+		line += word
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) > svgCardMaxLines {
+		lines = lines[:svgCardMaxLines]
+		lines[svgCardMaxLines-1] += "…"
+	}
+
+	return lines
+}
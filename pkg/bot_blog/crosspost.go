@@ -0,0 +1,271 @@
+package botblog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botSyndicate "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_syndicate"
+)
+
+const crosspostCommand = "/crosspost"
+
+// crosspostFields record where a post was cross-posted, e.g. "devto_url",
+// written only once it has been
+func crosspostFields() []frontmatterField {
+	fields := []frontmatterField{}
+
+	for _, platform := range botSyndicate.PlatformNames {
+		fields = append(
+			fields,
+			frontmatterField{
+				key:  platform + "_url",
+				read: func(post *Post) any { return omitEmpty(post.Crossposts[platform]) },
+				write: func(post *Post, value any) {
+					if post.Crossposts == nil {
+						post.Crossposts = map[string]string{}
+					}
+
+					post.Crossposts[platform] = asString(value)
+				},
+			},
+		)
+	}
+
+	return fields
+}
+
+// handleCrosspostCommand publishes a merged PR's post on another platform
+// and records the link in the post's frontmatter
+func (handler *Handler) handleCrosspostCommand(prNumber int, argument string) {
+	platformName := strings.ToLower(strings.TrimSpace(argument))
+
+	platform, err := handler.crosspostPlatform(platformName)
+	if err != nil {
+		handler.common().CommentOnPR(prNumber, handler.common().CommandFailedComment(prNumber, "cross-post there", err))
+		return
+	}
+
+	articleURL, recordedIn, err := handler.crosspost(prNumber, platformName, platform)
+	if err != nil {
+		handler.common().CommentOnPR(prNumber, handler.common().CommandFailedComment(prNumber, "cross-post the post", err))
+		return
+	}
+
+	reply := fmt.Sprintf("📣 Cross-posted to %s: %s", platformName, articleURL)
+
+	if !handler.Config.Crossposting.Publish {
+		reply += "\n\nIt's a draft there until you publish it."
+	}
+
+	if recordedIn != "" {
+		reply += "\n\n" + recordedIn
+	}
+
+	handler.common().CommentOnPR(prNumber, reply)
+}
+
+// crosspostPlatform creates the adapter for a platform the repo has credentials for
+func (handler *Handler) crosspostPlatform(name string) (botSyndicate.Platform, error) {
+	tokens := map[string]string{
+		botSyndicate.PlatformDevTo:  handler.Config.Crossposting.DevToAPIKey,
+		botSyndicate.PlatformMedium: handler.Config.Crossposting.MediumToken,
+	}
+
+	token, isKnown := tokens[name]
+
+	switch {
+	case !isKnown:
+		return nil, &botErrors.ValidationError{
+			Message: fmt.Sprintf("Pick one of `%s`, e.g. `%s devto`.", strings.Join(botSyndicate.PlatformNames, "`, `"), crosspostCommand),
+		}
+	case token == "":
+		return nil, &botErrors.ValidationError{Message: fmt.Sprintf("No %s credentials are configured.", name)}
+	}
+
+	return botSyndicate.NewPlatform(name, token, handler.Config.Crossposting.Publish)
+}
+
+// crosspost publishes the post a merged PR added, as it is on main, and
+// records its new URL. It returns that URL and a note on where it was recorded
+func (handler *Handler) crosspost(prNumber int, platformName string, platform botSyndicate.Platform) (string, string, error) {
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", "", fmt.Errorf("getting PR: %w", err)
+	}
+
+	// the canonical URL has to point at a live post
+	if !pullRequest.GetMerged() {
+		return "", "", &botErrors.ValidationError{Message: "The post has to be merged and published first."}
+	}
+
+	filePath, err := handler.publishedPostPath(prNumber)
+	if err != nil {
+		return "", "", err
+	}
+
+	content, _, err := handler.GithubClient.GetFileContent(
+		botGithub.GetFileContentArgs{
+			Filename: filePath,
+			Owner:    handler.Owner,
+			Ref:      "main",
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", "", fmt.Errorf("reading %s: %w", filePath, err)
+	}
+
+	post, err := handler.frontmatterFormat().Decode(content)
+	if err != nil {
+		return "", "", fmt.Errorf("reading frontmatter: %w", err)
+	}
+
+	if existing := post.Crossposts[platformName]; existing != "" {
+		return "", "", &botErrors.ValidationError{Message: fmt.Sprintf("It's already on %s: %s", platformName, existing)}
+	}
+
+	canonicalURL := handler.Config.Content.URL(post.Key)
+	if !strings.HasPrefix(canonicalURL, "http://") && !strings.HasPrefix(canonicalURL, "https://") {
+		return "", "", &botErrors.ValidationError{
+			Message: fmt.Sprintf("The canonical URL %q isn't absolute; set the post URL to include the site, e.g. `https://example.com/posts/{key}`.", canonicalURL),
+		}
+	}
+
+	articleURL, err := platform.Publish(
+		&botSyndicate.Article{
+			CanonicalURL: canonicalURL,
+			Description:  post.Summary,
+			Markdown:     post.Content,
+			Tags:         post.Tags,
+			Title:        post.Title,
+		},
+	)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	recordedIn, err := handler.recordCrosspost(filePath, content, post.Key, platformName, articleURL)
+	if err != nil {
+		handler.logger.Error("Recording cross-post link failed", "error", err)
+		return articleURL, "⚠️ I couldn't record the link in the post's frontmatter. " + botErrors.UserMessageOrLogs(err), nil
+	}
+
+	return articleURL, recordedIn, nil
+}
+
+// publishedPostPath finds the published, original-language post a PR added
+func (handler *Handler) publishedPostPath(prNumber int) (string, error) {
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("getting PR files: %w", err)
+	}
+
+	for _, file := range files {
+		isPublishedPost := handler.Config.Content.IsPostFile(file.GetFilename()) &&
+			!handler.Config.Content.IsDraftFile(file.GetFilename()) &&
+			!isTranslationFile(file.GetFilename()) &&
+			file.GetStatus() != "removed"
+
+		if isPublishedPost {
+			return file.GetFilename(), nil
+		}
+	}
+
+	return "", &botErrors.ValidationError{Message: "I couldn't find a published post in this PR."}
+}
+
+// recordCrosspost writes the cross-posted URL into the post's frontmatter on
+// main through a PR it merges, like a scheduled publish
+func (handler *Handler) recordCrosspost(filePath, content, key, platformName, articleURL string) (string, error) {
+	updatedContent, err := handler.frontmatterFormat().Update(content, func(post *Post) {
+		if post.Crossposts == nil {
+			post.Crossposts = map[string]string{}
+		}
+
+		post.Crossposts[platformName] = articleURL
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("updating frontmatter: %w", err)
+	}
+
+	if err := handler.checkPath(filePath); err != nil {
+		return "", err
+	}
+
+	branchName, err := handler.GithubClient.CreateBranch(
+		botGithub.CreateBranchArgs{
+			BranchName: fmt.Sprintf("crosspost-%s-%s", platformName, key),
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+		},
+	)
+
+	if errors.Is(err, botGithub.ErrBranchInUse) {
+		return "", &botErrors.ValidationError{Message: "An earlier cross-post PR for this post is still open."}
+	} else if err != nil {
+		return "", fmt.Errorf("creating branch: %w", err)
+	}
+
+	if _, err := handler.GithubClient.CommitFiles(
+		botGithub.CommitFilesArgs{
+			Branch: branchName,
+			Changes: []botGithub.FileChange{
+				{Content: updatedContent, Path: filePath},
+			},
+			Message: fmt.Sprintf("Record %s cross-post of %s", platformName, key),
+			Owner:   handler.Owner,
+			Repo:    handler.Repo,
+		},
+	); err != nil {
+		return "", fmt.Errorf("committing frontmatter: %w", err)
+	}
+
+	pullRequest, err := handler.GithubClient.CreatePullRequest(
+		botGithub.CreatePullRequestArgs{
+			Base:  "main",
+			Body:  fmt.Sprintf("📣 `%s` was cross-posted to %s: %s", filePath, platformName, articleURL),
+			Head:  fmt.Sprintf("%s:%s", handler.Owner, branchName),
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+			Title: fmt.Sprintf("Record %s cross-post of %s", platformName, key),
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("creating PR: %w", err)
+	}
+
+	if err := handler.GithubClient.MergePullRequest(
+		botGithub.MergePullRequestArgs{
+			Method:   botGithub.MergeMethodSquash,
+			Owner:    handler.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	); err != nil {
+		handler.logger.Error("Merging cross-post link PR failed", "pr", pullRequest.GetNumber(), "error", err)
+		return fmt.Sprintf("The link is recorded in #%d, which I couldn't merge. %s", pullRequest.GetNumber(), botErrors.UserMessageOrLogs(err)), nil
+	}
+
+	return fmt.Sprintf("The link is recorded in the post's `%s_url` frontmatter (#%d).", platformName, pullRequest.GetNumber()), nil
+}
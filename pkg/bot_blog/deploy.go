@@ -0,0 +1,109 @@
+package botblog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// deployHookTimeout bounds a call to the deploy hook
+const deployHookTimeout = 30 * time.Second
+
+// maxDeployResponseBytes caps how much of the deploy hook's reply is read
+const maxDeployResponseBytes = 64 << 10
+
+// deployResponse holds the fields hosts send back about the build they
+// started; Vercel replies with a job, Netlify with an empty body
+type deployResponse struct {
+	Job struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+	} `json:"job"`
+}
+
+// deployPublishedPosts kicks off a site build through the deploy hook when a
+// merged PR put published posts on main, and reports how it went on the PR
+func (handler *Handler) deployPublishedPosts(pullRequest *github.PullRequest) {
+	if handler.Config.DeployHook == "" || !pullRequest.GetMerged() || pullRequest.GetBase().GetRef() != "main" {
+		return
+	}
+
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Getting PR files for deploy failed", "error", err)
+		return
+	}
+
+	hasPublishedPost := false
+
+	for _, file := range files {
+		isPublished := handler.Config.Content.IsPostFile(file.GetFilename()) &&
+			!handler.Config.Content.IsDraftFile(file.GetFilename()) &&
+			file.GetStatus() != "removed"
+
+		if isPublished {
+			hasPublishedPost = true
+			break
+		}
+	}
+
+	if !hasPublishedPost {
+		return
+	}
+
+	status, err := triggerDeploy(handler.Config.DeployHook)
+	if err != nil {
+		// the hook URL is a secret, and errors from calling it include it, so they're only logged
+		handler.logger.Error("Triggering deploy failed", "error", err)
+		handler.common().CommentOnPR(pullRequest.GetNumber(), "⚠️ This PR published a post, but I couldn't trigger a site deploy; the details are in the bot logs.")
+		return
+	}
+
+	handler.common().CommentOnPR(pullRequest.GetNumber(), "🚀 Triggered a site deploy for the published post: "+status+".")
+}
+
+// triggerDeploy POSTs to a deploy hook and describes the deployment it started
+func triggerDeploy(hookURL string) (string, error) {
+	client := &http.Client{Timeout: deployHookTimeout}
+
+	response, err := client.Post(hookURL, "application/json", nil)
+	if err != nil {
+		return "", fmt.Errorf("calling deploy hook: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", fmt.Errorf("deploy hook returned %s", response.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxDeployResponseBytes))
+	if err != nil {
+		return "", fmt.Errorf("reading deploy hook response: %w", err)
+	}
+
+	deploy := deployResponse{}
+
+	// an empty or non-JSON reply still means the hook accepted the build
+	if json.Unmarshal(body, &deploy) != nil || deploy.Job.ID == "" {
+		return fmt.Sprintf("the hook accepted it (%s)", response.Status), nil
+	}
+
+	if deploy.Job.State == "" {
+		return fmt.Sprintf("job `%s`", deploy.Job.ID), nil
+	}
+
+	return fmt.Sprintf("job `%s` is %s", deploy.Job.ID, deploy.Job.State), nil
+}
@@ -0,0 +1,89 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+	"github.com/google/go-github/v57/github"
+)
+
+// isDryRun reports whether a request should only be previewed, because the
+// repo is in dry-run mode or the issue has the dry-run label
+func (handler *Handler) isDryRun(issue *github.Issue) bool {
+	return handler.Config.DryRun || botHandler.HasLabel(issue, handler.Config.DryRunLabel)
+}
+
+// previewRequest writes the post without a branch or PR and comments the
+// plan and the post on the issue. The request is kept so /generate can open
+// the PR once the dry run is turned off
+func (handler *Handler) previewRequest(issue *github.Issue, request *BlogPostRequest) {
+	comment, err := handler.dryRunComment(issue, request)
+	if err != nil {
+		handler.logger.Error("Previewing blog post failed", "error", err)
+		comment = fmt.Sprintf("🧪 **Dry run:** sorry, I ran into an error writing the preview. %s", botErrors.UserMessageOrLogs(err))
+	}
+
+	handler.startConversation(issue.GetNumber(), request, comment+"\n\n"+handler.dryRunHint())
+}
+
+// dryRunComment generates and checks the post the way createBlogPostPR
+// would, describing what would be committed instead of committing it
+func (handler *Handler) dryRunComment(issue *github.Issue, request *BlogPostRequest) (string, error) {
+	post, model := handler.generatePost(request, outlineGuidance(request.Outline))
+
+	// the template fallback isn't worth previewing
+	if model == "" {
+		return "", fmt.Errorf("the AI couldn't write the post")
+	}
+
+	handler.suggestTags(post)
+	handler.addSEOMetadata(post)
+	handler.addRelatedReading(post)
+
+	if err := handler.claimPostKey(post); err != nil {
+		return "", err
+	}
+
+	localPost := handler.renderLocalPost(post, model)
+
+	if err := handler.checkPath(localPost.Path); err != nil {
+		localPost.Problems = append(localPost.Problems, err.Error())
+	}
+
+	var comment strings.Builder
+
+	comment.WriteString("🧪 **Dry run:** nothing was committed. For real, I would:\n\n")
+	comment.WriteString(fmt.Sprintf("- create the branch `ai-assisted-post-%d`\n", issue.GetNumber()))
+	comment.WriteString(fmt.Sprintf("- commit `%s`, written by %s\n", localPost.Path, localPost.Model))
+	comment.WriteString(fmt.Sprintf("- open a PR titled \"Add blog post: %s\"\n", post.Title))
+
+	if len(localPost.Problems) > 0 {
+		comment.WriteString(fmt.Sprintf(
+			"\n⚠️ The PR would note problems I couldn't fix on my own:\n\n- %s\n",
+			strings.Join(localPost.Problems, "\n- "),
+		))
+	}
+
+	comment.WriteString(fmt.Sprintf(
+		"\n<details>\n<summary>Preview of <code>%s</code></summary>\n\n%s\n\n</details>",
+		localPost.Path,
+		sharedUtils.CodeBlock("markdown", sharedUtils.TruncateText(localPost.Markdown, dryRunPreviewLimit)),
+	))
+
+	return comment.String(), nil
+}
+
+// dryRunPreviewLimit keeps preview comments under GitHub's comment size limit
+const dryRunPreviewLimit = 50_000
+
+// dryRunHint says how to turn a dry run into the real thing
+func (handler *Handler) dryRunHint() string {
+	if handler.Config.DryRun {
+		return "Dry runs are on for this repo; once they're off, reply `/generate` to open the PR."
+	}
+
+	return fmt.Sprintf("Remove the `%s` label and reply `/generate` to open the PR.", handler.Config.DryRunLabel)
+}
@@ -0,0 +1,100 @@
+package botblog
+
+import (
+	"fmt"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// handleIssueEdited regenerates the post on an issue's open PR after the
+// issue's body was edited, rather than leaving the PR out of step with the
+// request; a PR someone else has pushed to is only regenerated on /regenerate
+func (handler *Handler) handleIssueEdited(issue *github.Issue, editor string) {
+	if !handler.isTriggered(issue) {
+		return
+	}
+
+	if !handler.common().EnsureWritable(issue.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsAuthorized(editor, issue.GetNumber()) {
+		return
+	}
+
+	pullRequest, err := handler.GithubClient.FindPullRequestForBranch(
+		botGithub.FindPullRequestForBranchArgs{
+			BranchName: fmt.Sprintf("ai-assisted-post-%d", issue.GetNumber()),
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+			State:      "open",
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Finding PR for edited issue failed", "issue", issue.GetNumber(), "error", err)
+		return
+	}
+
+	// without an open PR there's nothing to amend
+	if pullRequest == nil {
+		return
+	}
+
+	if !handler.common().IsWithinRateLimit(editor, issue.GetNumber()) {
+		return
+	}
+
+	prNumber := pullRequest.GetNumber()
+
+	if handler.hasOthersCommits(prNumber) {
+		handler.common().CommentOnPR(prNumber, fmt.Sprintf(
+			"✋ #%d was edited, but this PR has commits I didn't make, so I left the post alone. "+
+				"Comment `%s` to rewrite it from the edited issue anyway, replacing those changes.",
+			issue.GetNumber(),
+			regenerateCommand,
+		))
+		return
+	}
+
+	if err := handler.regeneratePost(prNumber, ""); err != nil {
+		handler.logger.Error("Regenerating post after issue edit failed", "error", err)
+		handler.common().CommentOnPR(
+			prNumber,
+			handler.common().CommandFailedComment(
+				prNumber,
+				fmt.Sprintf("update the post after #%d was edited", issue.GetNumber()),
+				err,
+			),
+		)
+		return
+	}
+
+	handler.common().CommentOnPR(prNumber, fmt.Sprintf("✏️ #%d was edited, so I regenerated the post to match.", issue.GetNumber()))
+}
+
+// hasOthersCommits reports whether a PR's branch has commits the bot didn't
+// push, which regenerating would throw away; a PR the bot has no record of
+// counts as having them
+func (handler *Handler) hasOthersCommits(prNumber int) bool {
+	record, exists := handler.Store.PullRequest(handler.common().FullRepoName(), prNumber)
+	if !exists {
+		return true
+	}
+
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Getting PR failed", "pr", prNumber, "error", err)
+		return true
+	}
+
+	return pullRequest.GetCommits() > record.BotCommits
+}
@@ -0,0 +1,143 @@
+package botblog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errNoFrontmatter means a post file doesn't open with a frontmatter block
+var errNoFrontmatter = errors.New("post has no frontmatter")
+
+// splitFrontmatter splits a post file into the text between its delimiter
+// lines and the body after them
+func splitFrontmatter(markdown, delimiter string) (string, string, error) {
+	normalized := strings.ReplaceAll(markdown, "\r\n", "\n")
+
+	if !strings.HasPrefix(normalized, delimiter+"\n") {
+		return "", "", errNoFrontmatter
+	}
+
+	lines := strings.SplitAfter(normalized, "\n")
+
+	for index := 1; index < len(lines); index++ {
+		if strings.TrimRight(lines[index], "\n") == delimiter {
+			return strings.Join(lines[1:index], ""), strings.Join(lines[index+1:], ""), nil
+		}
+	}
+
+	return "", "", errNoFrontmatter
+}
+
+// yamlDocument is YAML frontmatter kept as a node, so fields the bot doesn't
+// know about, their order and comments all survive an edit
+type yamlDocument struct {
+	fields *yaml.Node // a mapping node
+}
+
+func parseYAMLDocument(raw string) (*yamlDocument, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &document); err != nil {
+		return nil, fmt.Errorf("parsing frontmatter: %w", err)
+	}
+
+	fields := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	if len(document.Content) > 0 {
+		fields = document.Content[0]
+	}
+
+	if fields.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("frontmatter is not a set of fields")
+	}
+
+	return &yamlDocument{fields: fields}, nil
+}
+
+// values decodes the frontmatter into plain Go values
+func (document *yamlDocument) values() (map[string]any, error) {
+	values := map[string]any{}
+
+	if err := document.fields.Decode(&values); err != nil {
+		return nil, fmt.Errorf("decoding frontmatter: %w", err)
+	}
+
+	return values, nil
+}
+
+// set replaces a field, adding it at the end if it's missing
+func (document *yamlDocument) set(key string, value any) error {
+	valueNode, err := yamlValueNode(value)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", key, err)
+	}
+
+	fields := document.fields.Content
+
+	for index := 0; index+1 < len(fields); index += 2 {
+		if fields[index].Value == key {
+			fields[index+1] = valueNode
+			return nil
+		}
+	}
+
+	document.fields.Content = append(
+		fields,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		valueNode,
+	)
+
+	return nil
+}
+
+// remove drops a field, if it's there
+func (document *yamlDocument) remove(key string) {
+	fields := document.fields.Content
+
+	for index := 0; index+1 < len(fields); index += 2 {
+		if fields[index].Value == key {
+			document.fields.Content = append(fields[:index], fields[index+2:]...)
+			return
+		}
+	}
+}
+
+func (document *yamlDocument) render() (string, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(document.fields); err != nil {
+		return "", fmt.Errorf("encoding frontmatter: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("encoding frontmatter: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// yamlValueNode encodes a field value, writing dates as plain YAML dates the
+// way hand-written posts have them rather than as quoted strings
+func yamlValueNode(value any) (*yaml.Node, error) {
+	if date, isDate := value.(frontmatterDate); isDate {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: string(date)}, nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return &node, nil
+}
+
+// ParsePost reads a post file written in the default frontmatter format
+func ParsePost(markdown string) (*Post, error) {
+	return defaultFrontmatter.Decode(markdown)
+}
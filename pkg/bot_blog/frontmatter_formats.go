@@ -0,0 +1,495 @@
+package botblog
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterFormat describes how a site's generator expects post files to
+// look: the frontmatter language, its field names and the file extension
+type FrontmatterFormat struct {
+	Delimiter string // "---" for YAML, "+++" for TOML
+	Extension string // ".md" or ".mdx"
+	IsTOML    bool
+	Name      string
+
+	fields []frontmatterField
+}
+
+// frontmatterField maps one frontmatter key onto a Post
+type frontmatterField struct {
+	key   string
+	read  func(post *Post) any // nil leaves the field out
+	write func(post *Post, value any)
+}
+
+// frontmatterDate is a YYYY-MM-DD date written unquoted, so generators read
+// it as a date rather than a string
+type frontmatterDate string
+
+// Frontmatter format names, as set in a repo's config
+const (
+	FrontmatterDefault  = "default"
+	FrontmatterHugo     = "hugo"
+	FrontmatterHugoTOML = "hugo-toml"
+	FrontmatterJekyll   = "jekyll"
+	FrontmatterAstro    = "astro"
+	FrontmatterMDX      = "mdx"
+)
+
+// disclosureFields are written by every format, and only when set
+var disclosureFields = []frontmatterField{
+	{
+		key: "ai_assisted",
+		read: func(post *Post) any {
+			if !post.AIAssisted {
+				return nil
+			}
+
+			return true
+		},
+		write: func(post *Post, value any) { post.AIAssisted = asBool(value) },
+	},
+	{
+		key:   "license",
+		read:  func(post *Post) any { return omitEmpty(post.License) },
+		write: func(post *Post, value any) { post.License = asString(value) },
+	},
+}
+
+// defaultFrontmatter is the schema the bot has always written
+var defaultFrontmatter = FrontmatterFormat{
+	Delimiter: "---",
+	Extension: ".md",
+	Name:      FrontmatterDefault,
+	fields: []frontmatterField{
+		disclosureFields[0],
+		{key: "created_at", read: readDate, write: writeDate},
+		{key: "is_draft", read: readDraft, write: writeDraft},
+		{key: "key", read: func(post *Post) any { return post.Key }, write: writeKey},
+		{
+			key:   "language",
+			read:  func(post *Post) any { return post.Language },
+			write: func(post *Post, value any) { post.Language = asString(value) },
+		},
+		disclosureFields[1],
+		{key: "summary", read: func(post *Post) any { return post.Summary }, write: writeSummary},
+		{key: "tags", read: func(post *Post) any { return post.Tags }, write: writeTags},
+		{key: "title", read: readTitle, write: writeTitle},
+		{
+			key:   "type",
+			read:  func(post *Post) any { return post.Type },
+			write: func(post *Post, value any) { post.Type = asString(value) },
+		},
+	},
+}
+
+// hugoFields are Hugo's front matter variables
+var hugoFields = withDisclosure(
+	frontmatterField{key: "title", read: readTitle, write: writeTitle},
+	frontmatterField{key: "date", read: readDate, write: writeDate},
+	frontmatterField{key: "draft", read: readDraft, write: writeDraft},
+	frontmatterField{key: "slug", read: readKey, write: writeKey},
+	frontmatterField{key: "description", read: readSummary, write: writeSummary},
+	frontmatterField{key: "tags", read: readTags, write: writeTags},
+)
+
+// astroFields follow the schema of Astro's blog starter content collection
+var astroFields = withDisclosure(
+	frontmatterField{key: "title", read: readTitle, write: writeTitle},
+	frontmatterField{key: "description", read: readSummary, write: writeSummary},
+	frontmatterField{key: "pubDate", read: readDate, write: writeDate},
+	frontmatterField{key: "tags", read: readTags, write: writeTags},
+	frontmatterField{key: "draft", read: readDraft, write: writeDraft},
+	frontmatterField{key: "slug", read: readKey, write: writeKey},
+)
+
+// frontmatterFormats are the formats a repo can choose by name
+var frontmatterFormats = map[string]FrontmatterFormat{
+	FrontmatterDefault: defaultFrontmatter,
+	FrontmatterHugo: {
+		Delimiter: "---",
+		Extension: ".md",
+		Name:      FrontmatterHugo,
+		fields:    hugoFields,
+	},
+	FrontmatterHugoTOML: {
+		Delimiter: "+++",
+		Extension: ".md",
+		IsTOML:    true,
+		Name:      FrontmatterHugoTOML,
+		fields:    hugoFields,
+	},
+	FrontmatterJekyll: {
+		Delimiter: "---",
+		Extension: ".md",
+		Name:      FrontmatterJekyll,
+		fields: withDisclosure(
+			frontmatterField{
+				key:   "layout",
+				read:  func(post *Post) any { return "post" },
+				write: func(post *Post, value any) {},
+			},
+			frontmatterField{key: "title", read: readTitle, write: writeTitle},
+			frontmatterField{key: "date", read: readDate, write: writeDate},
+			// Jekyll hides posts with published: false
+			frontmatterField{
+				key:   "published",
+				read:  func(post *Post) any { return !post.IsDraft },
+				write: func(post *Post, value any) { post.IsDraft = !asBool(value) },
+			},
+			frontmatterField{key: "slug", read: readKey, write: writeKey},
+			frontmatterField{key: "description", read: readSummary, write: writeSummary},
+			frontmatterField{key: "tags", read: readTags, write: writeTags},
+		),
+	},
+	FrontmatterAstro: {
+		Delimiter: "---",
+		Extension: ".md",
+		Name:      FrontmatterAstro,
+		fields:    astroFields,
+	},
+	FrontmatterMDX: {
+		Delimiter: "---",
+		Extension: ".mdx",
+		Name:      FrontmatterMDX,
+		fields:    astroFields,
+	},
+}
+
+// LookupFrontmatterFormat finds a format by its config name; an empty name
+// is the default format
+func LookupFrontmatterFormat(name string) (FrontmatterFormat, bool) {
+	if name == "" {
+		return defaultFrontmatter, true
+	}
+
+	format, isKnown := frontmatterFormats[strings.ToLower(strings.TrimSpace(name))]
+
+	return format, isKnown
+}
+
+// frontmatterFormat is the format configured for the handler's repo
+func (handler *Handler) frontmatterFormat() FrontmatterFormat {
+	format, isKnown := LookupFrontmatterFormat(handler.Config.Frontmatter)
+
+	if !isKnown {
+		handler.logger.Warn(
+			"Unknown frontmatter format, using the default",
+			"format", handler.Config.Frontmatter,
+			"repo", handler.common().FullRepoName(),
+			"default", FrontmatterDefault,
+		)
+
+		format = defaultFrontmatter
+	}
+
+	format.fields = overrideFields(
+		format.fields,
+		slices.Concat(
+			readingStatsFields(handler.Config.ReadingStats),
+			seoFields(handler.Config.SEOFields),
+			coverImageFields(handler.Config.CoverImage.FrontmatterKey),
+			publishAtFields(),
+			crosspostFields(),
+		),
+	)
+
+	return format
+}
+
+// postTitle reads the title from a post file's frontmatter, or "" if it can't be parsed
+func (handler *Handler) postTitle(markdown string) string {
+	post, err := handler.frontmatterFormat().Decode(markdown)
+	if err != nil {
+		return ""
+	}
+
+	return post.Title
+}
+
+// overrideFields adds the repo's configured fields to a format's own, a
+// configured field taking the place of one with the same key (e.g. a meta
+// description written to Hugo's "description"). It returns a copy, so the
+// shared field lists of the built-in formats are never changed
+func overrideFields(fields, configured []frontmatterField) []frontmatterField {
+	merged := slices.Clone(fields)
+
+	for _, field := range configured {
+		index := slices.IndexFunc(merged, func(existing frontmatterField) bool {
+			return existing.key == field.key
+		})
+
+		if index == -1 {
+			merged = append(merged, field)
+		} else {
+			merged[index] = field
+		}
+	}
+
+	return merged
+}
+
+// postFilePath is where a post is written, with the format's extension
+func (handler *Handler) postFilePath(post *Post) string {
+	return post.GetFilePath(handler.Config.Content, handler.frontmatterFormat().Extension)
+}
+
+// isDraftPost reads whether a post file is a draft from its directory, or
+// from its frontmatter when drafts and posts share one
+func (handler *Handler) isDraftPost(filePath, content string) bool {
+	if handler.Config.Content.HasDraftsDir() {
+		return handler.Config.Content.IsDraftFile(filePath)
+	}
+
+	post, err := handler.frontmatterFormat().Decode(content)
+
+	return err == nil && post.IsDraft
+}
+
+// Render writes a post file in this format
+func (format FrontmatterFormat) Render(post *Post) string {
+	values := map[string]any{}
+	keys := []string{}
+
+	for _, field := range format.fields {
+		if value := field.read(post); value != nil {
+			values[field.key] = value
+			keys = append(keys, field.key)
+		}
+	}
+
+	frontmatter, err := format.encode(keys, values)
+
+	// the fields are plain strings, bools, dates and lists, which always encode
+	if err != nil {
+		slog.Error("Encoding frontmatter failed", "error", err)
+	}
+
+	return fmt.Sprintf("%s\n%s%s\n\n%s", format.Delimiter, frontmatter, format.Delimiter, post.Content)
+}
+
+// Decode reads a post file written in this format
+func (format FrontmatterFormat) Decode(markdown string) (*Post, error) {
+	raw, body, err := splitFrontmatter(markdown, format.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := format.decodeValues(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	post := &Post{Content: strings.TrimPrefix(body, "\n")}
+
+	for _, field := range format.fields {
+		if value, isSet := values[field.key]; isSet {
+			field.write(post, value)
+		}
+	}
+
+	return post, nil
+}
+
+// Update applies change to a post file, rewriting only the frontmatter
+// fields it changed (and dropping any it cleared) so everything else in the
+// file stays as it was
+func (format FrontmatterFormat) Update(markdown string, change func(post *Post)) (string, error) {
+	post, err := format.Decode(markdown)
+	if err != nil {
+		return "", err
+	}
+
+	raw, body, err := splitFrontmatter(markdown, format.Delimiter)
+	if err != nil {
+		return "", err
+	}
+
+	before := make([]any, len(format.fields))
+	for index, field := range format.fields {
+		before[index] = field.read(post)
+	}
+
+	change(post)
+
+	changed := map[string]any{}
+	keys := []string{}
+
+	for index, field := range format.fields {
+		value := field.read(post)
+
+		// a nil value for a field that was set removes it
+		if !reflect.DeepEqual(value, before[index]) {
+			changed[field.key] = value
+			keys = append(keys, field.key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return markdown, nil
+	}
+
+	if format.IsTOML {
+		for _, key := range keys {
+			if changed[key] == nil {
+				raw = removeTOMLField(raw, key)
+				continue
+			}
+
+			if raw, err = setTOMLField(raw, key, changed[key]); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		document, err := parseYAMLDocument(raw)
+		if err != nil {
+			return "", err
+		}
+
+		for _, key := range keys {
+			if changed[key] == nil {
+				document.remove(key)
+				continue
+			}
+
+			if err := document.set(key, changed[key]); err != nil {
+				return "", err
+			}
+		}
+
+		if raw, err = document.render(); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%s\n%s%s\n%s", format.Delimiter, raw, format.Delimiter, body), nil
+}
+
+// encode writes the given fields, in order, in the format's language
+func (format FrontmatterFormat) encode(keys []string, values map[string]any) (string, error) {
+	if format.IsTOML {
+		return encodeTOMLFields(keys, values)
+	}
+
+	document := &yamlDocument{fields: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}}
+
+	for _, key := range keys {
+		if err := document.set(key, values[key]); err != nil {
+			return "", err
+		}
+	}
+
+	return document.render()
+}
+
+func (format FrontmatterFormat) decodeValues(raw string) (map[string]any, error) {
+	if format.IsTOML {
+		return decodeTOMLFields(raw)
+	}
+
+	document, err := parseYAMLDocument(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return document.values()
+}
+
+// withDisclosure appends the AI disclosure fields to a format's own fields
+func withDisclosure(fields ...frontmatterField) []frontmatterField {
+	return append(fields, disclosureFields...)
+}
+
+func readTitle(post *Post) any   { return post.Title }
+func readKey(post *Post) any     { return omitEmpty(post.Key) }
+func readSummary(post *Post) any { return omitEmpty(post.Summary) }
+func readDraft(post *Post) any   { return post.IsDraft }
+
+func readTags(post *Post) any {
+	if len(post.Tags) == 0 {
+		return nil
+	}
+
+	return post.Tags
+}
+
+// readDate writes valid dates unquoted and anything else as it was given
+func readDate(post *Post) any {
+	if post.CreatedAt == "" {
+		return nil
+	}
+
+	if parsePostDate(post.CreatedAt) == "" {
+		return post.CreatedAt
+	}
+
+	return frontmatterDate(post.CreatedAt)
+}
+
+func writeTitle(post *Post, value any)   { post.Title = asString(value) }
+func writeKey(post *Post, value any)     { post.Key = asString(value) }
+func writeSummary(post *Post, value any) { post.Summary = asString(value) }
+func writeDraft(post *Post, value any)   { post.IsDraft = asBool(value) }
+func writeTags(post *Post, value any)    { post.Tags = asStrings(value) }
+func writeDate(post *Post, value any)    { post.CreatedAt = asString(value) }
+
+func omitEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+// asString reads a decoded frontmatter value as text; dates come back from
+// both YAML and TOML as times
+func asString(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case time.Time:
+		return typed.Format(postDateLayout)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func asBool(value any) bool {
+	switch typed := value.(type) {
+	case bool:
+		return typed
+	case string:
+		return typed == "true" || typed == "yes"
+	default:
+		return false
+	}
+}
+
+// asStrings reads a list field, accepting a single comma-separated string too
+func asStrings(value any) []string {
+	strs := []string{}
+
+	switch typed := value.(type) {
+	case []any:
+		for _, item := range typed {
+			strs = append(strs, asString(item))
+		}
+	case string:
+		for item := range strings.SplitSeq(typed, ",") {
+			if trimmed := strings.TrimSpace(item); trimmed != "" {
+				strs = append(strs, trimmed)
+			}
+		}
+	}
+
+	return strs
+}
@@ -0,0 +1,138 @@
+package botblog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlKeyPattern matches the key of a "key = value" line
+var tomlKeyPattern = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=`)
+
+// decodeTOMLFields reads TOML frontmatter into plain Go values
+func decodeTOMLFields(raw string) (map[string]any, error) {
+	values := map[string]any{}
+
+	if _, err := toml.Decode(raw, &values); err != nil {
+		return nil, fmt.Errorf("parsing frontmatter: %w", err)
+	}
+
+	return values, nil
+}
+
+// encodeTOMLFields writes one "key = value" line per field, in order
+func encodeTOMLFields(keys []string, values map[string]any) (string, error) {
+	var buf strings.Builder
+
+	for _, key := range keys {
+		encoded, err := tomlValue(values[key])
+		if err != nil {
+			return "", fmt.Errorf("encoding %s: %w", key, err)
+		}
+
+		fmt.Fprintf(&buf, "%s = %s\n", key, encoded)
+	}
+
+	return buf.String(), nil
+}
+
+// setTOMLField replaces a top-level field, adding it before the first table
+// if it's missing. It works line by line so the rest of the frontmatter,
+// comments included, stays as it was
+func setTOMLField(raw, key string, value any) (string, error) {
+	encoded, err := tomlValue(value)
+	if err != nil {
+		return "", fmt.Errorf("encoding %s: %w", key, err)
+	}
+
+	newLine := fmt.Sprintf("%s = %s\n", key, encoded)
+	lines := strings.SplitAfter(raw, "\n")
+	insertAt := len(lines)
+
+	for index, line := range lines {
+		// everything after a [table] header belongs to that table
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			insertAt = index
+			break
+		}
+
+		if match := tomlKeyPattern.FindStringSubmatch(line); match != nil && match[1] == key {
+			lines[index] = newLine
+			return strings.Join(lines, ""), nil
+		}
+	}
+
+	// SplitAfter leaves an empty last element after a trailing newline
+	if insertAt == len(lines) && insertAt > 0 && lines[insertAt-1] == "" {
+		insertAt--
+	}
+
+	lines = append(lines[:insertAt], append([]string{newLine}, lines[insertAt:]...)...)
+
+	return strings.Join(lines, ""), nil
+}
+
+// tomlValue encodes a field value as TOML
+func tomlValue(value any) (string, error) {
+	switch typed := value.(type) {
+	case frontmatterDate:
+		return string(typed), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case int:
+		return strconv.Itoa(typed), nil
+	case string:
+		return tomlString(typed)
+	case []string:
+		items := make([]string, 0, len(typed))
+
+		for _, item := range typed {
+			encoded, err := tomlString(item)
+			if err != nil {
+				return "", err
+			}
+
+			items = append(items, encoded)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("unsupported frontmatter value %T", value)
+	}
+}
+
+// tomlString quotes a TOML basic string, whose escapes are JSON's
+func tomlString(value string) (string, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// removeTOMLField drops a top-level key, if it's there
+func removeTOMLField(raw, key string) string {
+	lines := strings.SplitAfter(raw, "\n")
+
+	for index, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			break
+		}
+
+		if match := tomlKeyPattern.FindStringSubmatch(line); match != nil && match[1] == key {
+			return strings.Join(append(lines[:index], lines[index+1:]...), "")
+		}
+	}
+
+	return raw
+}
@@ -0,0 +1,82 @@
+package botblog
+
+import (
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithubTest "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github/botgithubtest"
+	"github.com/google/go-github/v57/github"
+)
+
+// update rewrites the golden files instead of comparing against them:
+// go test ./pkg/bot_blog/ -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// assertGolden compares got with testdata/golden/<name>.golden
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", "golden", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", goldenPath, err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("reading %s (run with -update to create it): %v", goldenPath, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output doesn't match %s (run with -update to accept it)\n--- got ---\n%s\n--- want ---\n%s", goldenPath, got, want)
+	}
+}
+
+// newGoldenPost is a post with a fixed date and the disclosure fields set
+func newGoldenPost() *Post {
+	post := NewPost("Testing Go HTTP handlers", "testing handlers", []string{"go", "testing"}, true)
+
+	post.Content = "{.text-lg .text-gray-600 .mb-8}\nHandlers are where requests meet your code.\n"
+	post.CreatedAt = "2025-03-01"
+	post.Summary = "How to test handlers without a server."
+	post.ApplyLicensing(botConfig.Licensing{AIAssisted: true, License: "CC-BY-4.0"})
+
+	return post
+}
+
+func TestGenerateMarkdownGolden(t *testing.T) {
+	assertGolden(t, "markdown_"+FrontmatterDefault, newGoldenPost().GenerateMarkdown())
+
+	for _, name := range slices.Sorted(maps.Keys(frontmatterFormats)) {
+		if name == FrontmatterDefault {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			assertGolden(t, "markdown_"+name, frontmatterFormats[name].Render(newGoldenPost()))
+		})
+	}
+}
+
+func TestPRBodyGolden(t *testing.T) {
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), botGithubTest.NewMockClient(nil))
+
+	issue := &github.Issue{Number: github.Int(7), Title: github.String("Blog post: Testing Go HTTP handlers")}
+	request := &BlogPostRequest{Style: "tutorial", Words: 1500}
+
+	assertGolden(t, "pr_body", handler.generatePRBody(issue, request, newGoldenPost(), "mock-model"))
+	assertGolden(t, "pr_body_template", handler.generatePRBody(issue, &BlogPostRequest{}, newGoldenPost(), ""))
+}
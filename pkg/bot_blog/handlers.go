@@ -1,36 +1,187 @@
 package botblog
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"path/filepath"
 	"strings"
+	"time"
 
 	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
 	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	botMerge "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_merge"
+	botNotify "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_notify"
+	botPreview "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_preview"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	botTriage "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_triage"
 	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
 	"github.com/google/go-github/v57/github"
 )
 
 // Handler manages webhook events and blog operations
 type Handler struct {
-	AiClient      *botAi.Client
-	GithubClient  *botGithub.Client
+	AiClient      botAi.AIClient
+	Config        *botConfig.RepoConfig
+	CoverImages   CoverImageGenerator // optional; defaults to the one the repo config describes
+	GithubClient  botGithub.GithubAPI
 	Owner         string
 	Repo          string
+	Store         *botState.Store
 	WebhookSecret string
+
+	background *botHandler.Background
+	logger     *slog.Logger
+	merger     *botMerge.Merger
+	notifier   *botNotify.Dispatcher
+	previewer  *botPreview.Previewer
+	repoConfig *botConfig.RepoFileLoader
+	replies    *botReplies.Renderer
+	triager    *botTriage.Triager
 }
 
 // NewHandler creates a new blog handler
 func NewHandler(args Handler) *Handler {
-	return &Handler{
-		AiClient:      args.AiClient,
+	handler := &Handler{
+		AiClient:      args.AiClient.ForIssue(args.Owner+"/"+args.Repo, 0),
+		Config:        args.Config,
+		CoverImages:   args.CoverImages,
 		GithubClient:  args.GithubClient,
 		Owner:         args.Owner,
 		Repo:          args.Repo,
+		Store:         args.Store,
 		WebhookSecret: args.WebhookSecret,
+		background:    &botHandler.Background{},
+		logger:        slog.Default(),
+		merger: botMerge.NewMerger(
+			botMerge.Merger{
+				GithubClient: args.GithubClient,
+				Method:       args.Config.MergeMethod,
+				Owner:        args.Owner,
+				Repo:         args.Repo,
+				Store:        args.Store,
+			},
+		),
+		notifier: botNotify.NewDispatcher(args.Config.Notifications),
+		previewer: botPreview.NewPreviewer(
+			botPreview.Previewer{
+				GithubClient: args.GithubClient,
+				Owner:        args.Owner,
+				Repo:         args.Repo,
+				Store:        args.Store,
+			},
+		),
+		repoConfig: botConfig.NewRepoFileLoader(
+			botConfig.RepoFileLoader{
+				Base:         args.Config,
+				GithubClient: args.GithubClient,
+				Owner:        args.Owner,
+				Repo:         args.Repo,
+			},
+		),
+		replies: botReplies.NewRenderer(
+			botReplies.Renderer{
+				Dir:          args.Config.RepliesDir,
+				GithubClient: args.GithubClient,
+				Language:     args.Config.ReplyLanguage,
+				Owner:        args.Owner,
+				Repo:         args.Repo,
+			},
+		),
+		triager: botTriage.NewTriager(
+			botTriage.Triager{
+				AiClient:     args.AiClient,
+				GithubClient: args.GithubClient,
+				Labels:       args.Config.Labels,
+				Owner:        args.Owner,
+				Repo:         args.Repo,
+			},
+		),
 	}
+
+	// every AI call stops once the month's budget is used up
+	checkBudget := handler.common().CheckBudget
+	handler.AiClient = handler.AiClient.WithBudget(checkBudget)
+	handler.triager.AiClient = handler.triager.AiClient.WithBudget(checkBudget)
+
+	return handler
+}
+
+// WithLogger returns a copy of the handler whose log lines, and those of the
+// clients and helpers it uses, go to logger, e.g. one carrying a webhook's
+// delivery ID so everything it led to can be traced together
+func (handler *Handler) WithLogger(logger *slog.Logger) *Handler {
+	scoped := *handler
+	scoped.AiClient = handler.AiClient.WithLogger(logger)
+	scoped.logger = logger
+	scoped.merger = handler.merger.WithLogger(logger)
+	scoped.previewer = handler.previewer.WithLogger(logger)
+	scoped.repoConfig = handler.repoConfig.WithLogger(logger)
+	scoped.replies = handler.replies.WithLogger(logger)
+
+	return &scoped
+}
+
+// forIssue returns a copy of the handler whose AI spend is counted against
+// an issue or PR
+func (handler *Handler) forIssue(number int) *Handler {
+	scoped := *handler
+	scoped.AiClient = handler.AiClient.ForIssue(handler.common().FullRepoName(), number)
+
+	return &scoped
+}
+
+// inLanguage returns a copy of the handler that replies in a request's
+// language, or the repo's when the request doesn't set one
+func (handler *Handler) inLanguage(language string) *Handler {
+	scoped := *handler
+	scoped.replies = handler.replies.In(language)
+
+	return &scoped
+}
+
+// withRepoConfig returns a copy of the handler using the settings in the
+// target repo's botConfig.RepoFile, including its AI model
+func (handler *Handler) withRepoConfig() *Handler {
+	scoped := *handler
+	scoped.Config = handler.repoConfig.Config()
+	scoped.AiClient = handler.AiClient.WithModel(scoped.Config.Model, scoped.Config.ModelFallbacks)
+
+	return &scoped
+}
+
+// common returns the helpers the blog and code handlers share, working with
+// this handler's settings, clients and logger
+func (handler *Handler) common() *botHandler.Common {
+	return botHandler.NewCommon(
+		botHandler.Common{
+			AiClient:     handler.AiClient,
+			Config:       handler.Config,
+			GithubClient: handler.GithubClient,
+			Logger:       handler.logger,
+			Merger:       handler.merger,
+			Owner:        handler.Owner,
+			Replies:      handler.replies,
+			Repo:         handler.Repo,
+			Store:        handler.Store,
+			Triager:      handler.triager,
+		},
+	)
+}
+
+// Wait blocks until the webhooks already answered have been handled, e.g.
+// before shutting down
+func (handler *Handler) Wait() {
+	handler.background.Wait()
+}
+
+// ApplyApprovedChanges commits previewed edits the requester gave a 👍
+func (handler *Handler) ApplyApprovedChanges() {
+	handler.previewer.ApplyApproved()
 }
 
 // HandleWebhook processes GitHub webhook events
@@ -40,43 +191,134 @@ func (handler *Handler) HandleWebhook(
 ) {
 	payload, err := github.ValidatePayload(request, []byte(handler.WebhookSecret))
 	if err != nil {
-		log.Printf("webhook validation failed: %v", err)
+		handler.logger.Warn("Webhook validation failed", "error", err)
 		http.Error(writer, "validation failed", http.StatusUnauthorized)
 		return
 	}
 
 	event, err := github.ParseWebHook(github.WebHookType(request), payload)
 	if err != nil {
-		log.Printf("webhook parsing failed: %v", err)
+		handler.logger.Warn("Webhook parsing failed", "error", err)
 		http.Error(writer, "parsing failed", http.StatusBadRequest)
 		return
 	}
 
+	// a push changing the settings file is handled before they're read
+	if push, isPush := event.(*github.PushEvent); isPush && botGithub.PushChangesFile(push, botConfig.RepoFile) {
+		handler.logger.Info("Repo config changed", "file", botConfig.RepoFile)
+		handler.repoConfig.Forget()
+	}
+
+	// GitHub gives up on a delivery after ten seconds, so it's answered once
+	// it's known to be genuine and handled afterwards
+	handler.background.Go(handler.logger, func() {
+		handler.forIssue(botGithub.EventNumber(event)).withRepoConfig().handleEvent(event)
+	})
+
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// handleEvent acts on a webhook event that has already been answered
+func (handler *Handler) handleEvent(event any) {
 	switch e := event.(type) {
 	case *github.IssuesEvent:
-		if *e.Action == "opened" {
+		switch e.GetAction() {
+		case "opened":
 			handler.handleNewIssue(e.Issue)
+		case "edited":
+			// a title or label change doesn't change what was asked for
+			if e.GetChanges().GetBody() != nil {
+				handler.handleIssueEdited(e.Issue, e.GetSender().GetLogin())
+			}
+		case "labeled":
+			handler.handleIssueLabeled(e.Issue, e.Label, e.Sender)
 		}
 	case *github.IssueCommentEvent:
-		if *e.Action == "created" {
+		if e.GetAction() == "created" && !handler.common().IsOwnComment(e.GetComment().GetUser()) {
 			handler.handleIssueComment(e.Issue, e.Comment)
 		}
 	case *github.PullRequestReviewCommentEvent:
-		if *e.Action == "created" {
+		if e.GetAction() == "created" && !handler.common().IsOwnComment(e.GetComment().GetUser()) {
 			handler.handlePRComment(e.PullRequest, e.Comment)
 		}
+	case *github.PullRequestEvent:
+		if e.GetAction() == "closed" {
+			handler.handlePRClosed(e.PullRequest)
+		}
+	case *github.PullRequestReviewEvent:
+		if e.GetAction() == "submitted" {
+			handler.handleReviewSubmitted(e.PullRequest, e.Review)
+		}
 	}
-
-	writer.WriteHeader(http.StatusOK)
 }
 
 // handleNewIssue processes new GitHub issues
 func (handler *Handler) handleNewIssue(issue *github.Issue) {
-	title := *issue.Title
-	body := *issue.Body
-
 	// Check if this is a blog post request
-	if !strings.Contains(strings.ToLower(title), "blog post") {
+	if !isBlogPostTitle(issue.GetTitle()) {
+		// an issue opened with the trigger label is picked up by its labeled event
+		isHandledElsewhere := botHandler.HasLabel(issue, handler.Config.TriggerLabel) ||
+			handler.Config.IsBotSender(issue.GetUser().GetLogin())
+
+		if !isHandledElsewhere {
+			handler.common().TriageOrExplainFormat(issue, "blog_format_help", `the title doesn't contain "Blog post:"`)
+		}
+
+		return
+	}
+
+	handler.startRequest(issue, issue.GetUser().GetLogin())
+}
+
+// handleIssueLabeled starts a request when the trigger label is applied, for
+// issues whose title doesn't ask for the bot
+func (handler *Handler) handleIssueLabeled(issue *github.Issue, label *github.Label, sender *github.User) {
+	isTriggerLabel := handler.Config.TriggerLabel != "" &&
+		strings.EqualFold(label.GetName(), handler.Config.TriggerLabel)
+
+	// titles that already ask for the bot were handled when the issue was opened
+	if !isTriggerLabel || isBlogPostTitle(issue.GetTitle()) || handler.Config.IsBotSender(sender.GetLogin()) {
+		return
+	}
+
+	handler.startRequest(issue, sender.GetLogin())
+}
+
+// startRequest acknowledges a blog post request and works on it, if the
+// requester may trigger the bot
+func (handler *Handler) startRequest(issue *github.Issue, requester string) {
+	title := issue.GetTitle()
+	body := issue.GetBody() // nil when the issue was opened without a description
+
+	if !handler.common().EnsureWritable(issue.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsAuthorized(requester, issue.GetNumber()) {
+		return
+	}
+
+	// Parse the request and generate blog post
+	request := ParseIssueForRequest(title, body)
+	handler = handler.inLanguage(request.Language)
+
+	if request.Style == "" {
+		request.Style = parseStyle(handler.Config.Style)
+	}
+
+	if strings.TrimSpace(request.Topic) == "" {
+		handler.common().ExplainFormat(issue, "blog_format_help", "the issue body doesn't describe what the post should cover")
+		return
+	}
+
+	if !handler.common().IsWithinRateLimit(requester, issue.GetNumber()) {
+		return
+	}
+
+	// keep the request so /generate can pick it up once there's budget again
+	if err := handler.common().CheckBudget(); err != nil {
+		handler.notifyBudgetExhausted(issue, err)
+		handler.startConversation(issue.GetNumber(), request, handler.budgetExhaustedComment(err))
 		return
 	}
 
@@ -85,97 +327,253 @@ func (handler *Handler) handleNewIssue(issue *github.Issue) {
 		botGithub.ReactToIssueArgs{
 			Owner:       handler.Owner,
 			Repo:        handler.Repo,
-			IssueNumber: *issue.Number,
+			IssueNumber: issue.GetNumber(),
 			Reaction:    "+1",
 		},
 	); err != nil {
-		log.Printf("Error reacting to issue: %v", err)
+		handler.logger.Error("Reacting to issue failed", "error", err)
+	}
+
+	handler.common().AddLabels(issue.GetNumber(), handler.Config.Labels.Content)
+
+	if wantsConversation(body) {
+		handler.startConversation(issue.GetNumber(), request, "💬 Let's shape this post before I write it.")
+		return
+	}
+
+	if handler.wantsOutlineReview(request) {
+		handler.proposeOutline(issue.GetNumber(), request, "")
+		return
+	}
+
+	if handler.isDryRun(issue) {
+		handler.previewRequest(issue, request)
+		return
 	}
 
-	// Parse the request and generate blog post
-	request := ParseIssueForRequest(title, body)
 	if err := handler.createBlogPostPR(issue, request); err != nil {
-		log.Printf("Error creating blog post PR: %v", err)
-		handler.GithubClient.CommentOnIssue(
-			botGithub.CommentOnIssueArgs{
-				Comment:     "Sorry, I ran into an error creating the blog post. Could you check the request format?",
-				IssueNumber: *issue.Number,
-				Owner:       handler.Owner,
-				Repo:        handler.Repo,
-			},
-		)
+		handler.logger.Error("Creating blog post PR failed", "error", err)
+
+		// a redelivered webhook finds its own PR already open; nothing to report
+		if errors.Is(err, botGithub.ErrBranchInUse) {
+			return
+		}
+
+		reason, _ := botErrors.UserMessage(err)
+		intro := handler.replies.Render("blog_generation_failed", botReplies.Data{"Reason": reason, "RetryCommand": ""})
+
+		if errors.Is(err, botGithub.ErrSecretDetected) {
+			intro = handler.changeFailureComment(err)
+		}
+
+		handler.notifyGenerationFailed(issue, err)
+		handler.startConversation(issue.GetNumber(), request, intro+handler.common().FailureFooter(issue.GetNumber(), err))
 	}
 }
 
 // createBlogPostPR generates a blog post and creates a PR
 func (handler *Handler) createBlogPostPR(issue *github.Issue, request *BlogPostRequest) error {
-	// Generate the blog post content using AI
-	content, err := handler.AiClient.GenerateBlogPost(
+	if request.Progressive {
+		return handler.createProgressivePostPR(issue, request)
+	}
+
+	post, model := handler.generatePost(request, outlineGuidance(request.Outline))
+
+	// the template fallback has nothing worth tagging
+	if model != "" {
+		handler.suggestTags(post)
+		handler.addSEOMetadata(post)
+		handler.addRelatedReading(post)
+	}
+
+	if err := handler.claimPostKey(post); err != nil {
+		return err
+	}
+
+	branchName := fmt.Sprintf("ai-assisted-post-%d", issue.GetNumber())
+
+	_, err := handler.openPostPR(
+		openPostPRArgs{
+			Body:        handler.generatePRBody(issue, request, post, model),
+			BranchName:  branchName,
+			ContentType: botState.ContentTypeBlog,
+			Message:     "Add AI-generated blog post",
+			Post:        post,
+			Requester:   issue.GetUser().GetLogin(),
+		},
+	)
+
+	return err
+}
+
+// generatePost writes a post for the request, falling back to the template when
+// the AI call fails, and returns it with the model that wrote it
+func (handler *Handler) generatePost(request *BlogPostRequest, guidance string) (*Post, string) {
+	// Generate the blog post content and metadata using AI
+	draft, err := handler.AiClient.GenerateBlogPost(
 		&botAi.BlogPostRequest{
-			Title:  request.Title,
-			Topic:  request.Topic,
-			Points: request.Points,
-			Tags:   request.Tags,
-			Draft:  request.Draft,
+			Title:    request.Title,
+			Topic:    request.Topic,
+			Points:   request.Points,
+			Tags:     request.Tags,
+			Draft:    request.Draft,
+			Language: request.Language,
+			Style:    request.Style,
+			Words:    request.Words,
+			Guidance: guidance,
 		},
 	)
 
 	if err != nil {
-		log.Printf("AI generation failed, using template: %v", err)
-		content = handler.generateTemplateContent(request)
+		handler.logger.Warn("AI generation failed, using template", "error", err)
+
+		draft = &botAi.BlogPostDraft{
+			Body: handler.generateTemplateContent(request),
+		}
+	}
+
+	title := request.Title
+	if title == "" {
+		title = draft.Title
 	}
 
 	// instantiate blog post struct, incomplete
 	post := NewPost(
-		request.Title,
+		title,
 		request.Topic,
-		request.Tags,
+		mergeTags(request.Tags, draft.Tags),
 		request.Draft,
 	)
 
-	// post content is assigned here
-	post.Content = content
+	post.ApplyRequestDate(request)
+	post.ApplyRequestLanguage(request)
 
-	// Create branch
-	branchName := fmt.Sprintf("ai-assisted-post-%d", *issue.Number)
+	// post content and AI-written summary are assigned here
+	post.Content = draft.Body
+
+	if draft.Model != "" {
+		handler.proofreadPost(post)
+		handler.checkSnippets(post)
+	}
 
-	if err := handler.GithubClient.CreateBranch(
+	if handler.wantsTOC(request) {
+		post.Content = insertTableOfContents(post.Content)
+	}
+
+	if draft.Summary != "" {
+		post.Summary = draft.Summary
+	} else if draft.Model != "" {
+		handler.summarizePost(post)
+	}
+
+	return post, draft.Model
+}
+
+// proofreadPost runs the repo's optional typo and grammar pass over a
+// generated post, keeping the original if it fails
+func (handler *Handler) proofreadPost(post *Post) {
+	if !handler.Config.Proofread {
+		return
+	}
+
+	proofread, err := handler.AiClient.Proofread(post.Content)
+	if err != nil {
+		handler.logger.Warn("Proofreading failed, keeping the post as written", "title", post.Title, "error", err)
+		return
+	}
+
+	post.Content = proofread
+}
+
+// suggestTags merges AI-suggested tags into the author's, keeping the
+// author's tags if the AI call fails
+func (handler *Handler) suggestTags(post *Post) {
+	suggested, err := handler.AiClient.SuggestTags(post.Title, post.Content)
+	if err != nil {
+		handler.logger.Error("Suggesting tags failed", "title", post.Title, "error", err)
+		return
+	}
+
+	post.Tags = mergeTags(post.Tags, suggested)
+}
+
+// summarizePost replaces the placeholder summary with one written from the
+// finished post, keeping the placeholder if the AI call fails
+func (handler *Handler) summarizePost(post *Post) {
+	summary, err := handler.AiClient.GenerateSummary(post.Title, post.Content)
+	if err != nil {
+		handler.logger.Error("Generating summary failed", "title", post.Title, "error", err)
+		return
+	}
+
+	post.Summary = summary
+}
+
+type openPostPRArgs struct {
+	Body        string
+	BranchName  string
+	ContentType string // recorded for lifecycle metrics
+	Message     string
+	Post        *Post
+	Requester   string // login to request a review from when none are configured
+}
+
+// openPostPR commits a post to a new branch and opens a PR for it
+func (handler *Handler) openPostPR(args openPostPRArgs) (*github.PullRequest, error) {
+	if err := handler.checkPath(handler.postFilePath(args.Post)); err != nil {
+		return nil, err
+	}
+
+	// Create branch; a leftover one with someone's commits on it gets a suffixed name
+	branchName, err := handler.GithubClient.CreateBranch(
 		botGithub.CreateBranchArgs{
-			BranchName: branchName,
+			BranchName: args.BranchName,
 			Owner:      handler.Owner,
 			Repo:       handler.Repo,
 		},
-	); err != nil {
-		return fmt.Errorf("creating branch: %w", err)
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("creating branch: %w", err)
 	}
 
+	args.BranchName = branchName
+
+	args.Post.ApplyLicensing(handler.Config.Licensing)
+	postProblems := handler.checkPostContent(args.Post)
+	handler.addCoverImage(args.BranchName, args.Post)
+
 	// Create markdown file
-	filename := post.GetFilePath()
-	markdown := post.GenerateMarkdown()
-	message := "Add AI-generated blog post"
+	filename := handler.postFilePath(args.Post)
+	markdown := handler.frontmatterFormat().Render(args.Post)
 
-	if err := handler.GithubClient.CreateFile(
+	commitSHA, err := handler.GithubClient.CreateFile(
 		botGithub.CreateFileArgs{
-			Branch:   branchName,
+			Branch:   args.BranchName,
 			Content:  markdown,
 			Filename: filename,
-			Message:  message,
+			Message:  args.Message,
 			Owner:    handler.Owner,
 			Repo:     handler.Repo,
 		},
-	); err != nil {
-		return fmt.Errorf("creating file: %w", err)
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("creating file: %w", err)
 	}
 
+	handler.common().RecordBranchCommit(args.BranchName, commitSHA)
+	handler.reportGenerated(commitSHA)
+
 	// Create PR
-	title := fmt.Sprintf("Add blog post: %s", post.Title)
-	body := handler.generatePRBody(issue, post)
-	head := fmt.Sprintf("%s:%s", handler.Owner, branchName)
+	title := fmt.Sprintf("Add blog post: %s", args.Post.Title)
+	head := fmt.Sprintf("%s:%s", handler.Owner, args.BranchName)
 
-	_, err = handler.GithubClient.CreatePullRequest(
+	pullRequest, err := handler.GithubClient.CreatePullRequest(
 		botGithub.CreatePullRequestArgs{
-			Body:  body,
+			Body:  args.Body,
 			Base:  "main",
+			Draft: handler.Config.DraftPRs,
 			Head:  head,
 			Owner: handler.Owner,
 			Repo:  handler.Repo,
@@ -184,10 +582,66 @@ func (handler *Handler) createBlogPostPR(issue *github.Issue, request *BlogPostR
 	)
 
 	if err != nil {
-		return fmt.Errorf("creating PR: %w", err)
+		return nil, fmt.Errorf("creating PR: %w", err)
 	}
 
-	return nil
+	handler.recordPullRequestOpened(pullRequest.GetNumber(), args.ContentType)
+	handler.notifyPullRequestOpened(pullRequest)
+	handler.addPreviewToPRBody(pullRequest, args.Post.Key)
+	handler.common().LabelPullRequest(pullRequest.GetNumber())
+	handler.common().RequestAttention(pullRequest.GetNumber(), args.Requester)
+	handler.reportPostProblems(pullRequest.GetNumber(), postProblems)
+	handler.common().DispatchWorkflow(pullRequest.GetNumber(), args.BranchName)
+
+	return pullRequest, nil
+}
+
+// handlePRClosed records how a bot-created PR ended and cleans up after it
+func (handler *Handler) handlePRClosed(pullRequest *github.PullRequest) {
+	// a redelivered close event has already been handled
+	if handler.Store.IsJobDone(handler.common().FullRepoName(), pullRequest.GetNumber()) {
+		return
+	}
+
+	if err := handler.Store.RecordPullRequestClosed(
+		botState.RecordPullRequestClosedArgs{
+			IsMerged:     pullRequest.GetMerged(),
+			Number:       pullRequest.GetNumber(),
+			Repo:         handler.common().FullRepoName(),
+			TotalCommits: pullRequest.GetCommits(),
+		},
+	); err != nil {
+		handler.logger.Error("Recording PR metrics failed", "error", err)
+	}
+
+	handler.common().FinishJob(pullRequest, handler.outcomeComment)
+	handler.deployPublishedPosts(pullRequest)
+	handler.handlePostPublished(pullRequest)
+}
+
+// handlePostPublished notifies and announces the post a merged PR published,
+// if any
+func (handler *Handler) handlePostPublished(pullRequest *github.PullRequest) {
+	isWanted := handler.Config.Social.Drafts || handler.notifier.Wants(botNotify.EventPublished)
+	if !isWanted || !pullRequest.GetMerged() || pullRequest.GetBase().GetRef() != "main" {
+		return
+	}
+
+	post, err := handler.newlyPublishedPost(pullRequest)
+	if err != nil {
+		handler.logger.Error("Finding the published post failed", "pr", pullRequest.GetNumber(), "error", err)
+		return
+	}
+
+	if post == nil {
+		return
+	}
+
+	handler.notifyPublished(pullRequest, post)
+
+	if handler.Config.Social.Drafts {
+		handler.announcePublishedPost(pullRequest, post)
+	}
 }
 
 // handlePRComment processes comments on pull requests
@@ -195,24 +649,52 @@ func (handler *Handler) handlePRComment(
 	pullRequest *github.PullRequest,
 	comment *github.PullRequestComment,
 ) {
+	commentBody := comment.GetBody()
+
+	if !handler.isActionable(commentBody) {
+		return
+	}
+
+	if !handler.common().EnsureWritable(pullRequest.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsAuthorized(comment.GetUser().GetLogin(), pullRequest.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsWithinRateLimit(comment.GetUser().GetLogin(), pullRequest.GetNumber()) {
+		return
+	}
+
 	// React with thumbs up to acknowledge
 	if err := handler.GithubClient.ReactToPRComment(
 		botGithub.ReactToPRCommentArgs{
-			CommentID: *comment.ID,
+			CommentID: comment.GetID(),
 			Owner:     handler.Owner,
 			Reaction:  "+1",
 			Repo:      handler.Repo,
 		},
 	); err != nil {
-		log.Printf("Error reacting to PR comment: %v", err)
+		handler.logger.Error("Reacting to PR comment failed", "error", err)
 	}
 
-	commentBody := *comment.Body
+	// Suggestions need the lines the review comment was left on, so they're
+	// handled here rather than with the other slash commands
+	if command, argument := parseCommand(commentBody); command == botHandler.SuggestCommand {
+		handler.common().HandleSuggestion(pullRequest, comment, argument)
+		return
+	}
+
+	// Slash commands take priority over keyword matching
+	if handler.handlePRCommand(pullRequest.GetNumber(), commentBody, comment.GetUser().GetLogin()) {
+		return
+	}
 
 	// Check for draft status changes
 	if handler.hasDraftStatusChange(commentBody) {
 		if err := handler.handleDraftStatusChange(pullRequest, commentBody); err != nil {
-			log.Printf("Error changing draft status: %v", err)
+			handler.logger.Error("Changing draft status failed", "error", err)
 		}
 
 		return
@@ -220,14 +702,19 @@ func (handler *Handler) handlePRComment(
 
 	// Handle content changes
 	if handler.isChangeRequest(commentBody) {
-		if err := handler.handleContentChange(pullRequest, commentBody); err != nil {
-			log.Printf("Error updating content: %v", err)
+		if handler.Config.EditMode == botConfig.EditModeSuggest {
+			handler.common().HandleSuggestion(pullRequest, comment, commentBody)
+			return
+		}
+
+		if err := handler.handleContentChange(pullRequest, comment); err != nil {
+			handler.logger.Error("Updating content failed", "error", err)
 
 			handler.GithubClient.CommentOnPR(
 				botGithub.CommentOnPRArgs{
-					Comment:  "Sorry, I had trouble making that change. Could you be more specific?",
+					Comment:  handler.changeFailureComment(err) + handler.common().FailureFooter(pullRequest.GetNumber(), err),
 					Owner:    handler.Owner,
-					PrNumber: *pullRequest.Number,
+					PrNumber: pullRequest.GetNumber(),
 					Repo:     handler.Repo,
 				},
 			)
@@ -235,7 +722,7 @@ func (handler *Handler) handlePRComment(
 			// React with rocket to show completion
 			handler.GithubClient.ReactToPRComment(
 				botGithub.ReactToPRCommentArgs{
-					CommentID: *comment.ID,
+					CommentID: comment.GetID(),
 					Owner:     handler.Owner,
 					Reaction:  "rocket",
 					Repo:      handler.Repo,
@@ -245,17 +732,20 @@ func (handler *Handler) handlePRComment(
 	}
 }
 
-// handleContentChange modifies blog post content based on feedback
+// handleContentChange modifies the post file a review comment was left on
 func (handler *Handler) handleContentChange(
 	pullRequest *github.PullRequest,
-	changeRequest string,
+	comment *github.PullRequestComment,
 ) error {
+	changeRequest := comment.GetBody()
+	commentedPath := comment.GetPath()
+
 	// Get files changed in this PR
 	files, err := handler.GithubClient.ListPullRequestFiles(
 		botGithub.ListPullRequestFilesArgs{
 			Owner:    handler.Owner,
 			Repo:     handler.Repo,
-			PrNumber: *pullRequest.Number,
+			PrNumber: pullRequest.GetNumber(),
 		},
 	)
 
@@ -265,17 +755,19 @@ func (handler *Handler) handleContentChange(
 
 	// Find the blog post file
 	for _, file := range files {
-		isMarkdownFile := strings.HasSuffix(*file.Filename, ".md")
-		isFileInPostsDir := strings.Contains(*file.Filename, "pkg/blog_markdown_content/posts")
-		isFileInDraftsDir := strings.Contains(*file.Filename, "pkg/blog_markdown_content/drafts")
+		isCommentedFile := commentedPath == "" || file.GetFilename() == commentedPath
+
+		if handler.Config.Content.IsPostFile(file.GetFilename()) && isCommentedFile {
+			if err := handler.checkPath(file.GetFilename()); err != nil {
+				return err
+			}
 
-		if isMarkdownFile && (isFileInPostsDir || isFileInDraftsDir) {
 			// Get current content
 			currentContent, sha, err := handler.GithubClient.GetFileContent(
 				botGithub.GetFileContentArgs{
-					Filename: *file.Filename,
+					Filename: file.GetFilename(),
 					Owner:    handler.Owner,
-					Ref:      *pullRequest.Head.Ref,
+					Ref:      pullRequest.GetHead().GetRef(),
 					Repo:     handler.Repo,
 				},
 			)
@@ -288,37 +780,72 @@ func (handler *Handler) handleContentChange(
 			updatedContent, err := handler.AiClient.ModifyBlogPost(
 				currentContent,
 				changeRequest,
+				comment.GetDiffHunk(),
+				handler.conversationHistory(pullRequest.GetNumber()),
 			)
 
 			if err != nil {
 				return fmt.Errorf("AI modification failed: %w", err)
 			}
 
+			updatedContent, altTextNotes := handler.fillAltText(handler.postTitle(updatedContent), updatedContent)
+
+			handler.recordConversationTurn(pullRequest.GetNumber(), file.GetFilename(), changeRequest)
+
 			// Update the file
 			message := fmt.Sprintf(
 				"Update blog post based on feedback: %s",
 				sharedUtils.TruncateText(changeRequest, 50),
 			)
 
-			if err := handler.GithubClient.UpdateFile(
+			if handler.Config.ConfirmEdits {
+				handler.reportPostProblems(pullRequest.GetNumber(), altTextNotes)
+
+				return handler.previewer.Propose(
+					botPreview.ProposeArgs{
+						Author:     comment.GetUser().GetLogin(),
+						Branch:     pullRequest.GetHead().GetRef(),
+						CommentID:  comment.GetID(),
+						Content:    updatedContent,
+						Message:    message,
+						OldContent: currentContent,
+						Path:       file.GetFilename(),
+						PrNumber:   pullRequest.GetNumber(),
+						Sha:        sha,
+					},
+				)
+			}
+
+			commitSHA, err := handler.GithubClient.UpdateFile(
 				botGithub.UpdateFileArgs{
-					Branch:   *pullRequest.Head.Ref,
+					Branch:   pullRequest.GetHead().GetRef(),
 					Content:  updatedContent,
-					Filename: *file.Filename,
+					Filename: file.GetFilename(),
 					Message:  message,
 					Owner:    handler.Owner,
 					Repo:     handler.Repo,
 					Sha:      sha,
 				},
-			); err != nil {
+			)
+
+			if err != nil {
 				return fmt.Errorf("updating file: %w", err)
 			}
 
-			break
+			handler.common().RecordBotCommit(pullRequest.GetNumber(), pullRequest.GetHead().GetRef(), commitSHA)
+			handler.reportPostProblems(pullRequest.GetNumber(), altTextNotes)
+			handler.common().DispatchWorkflow(pullRequest.GetNumber(), pullRequest.GetHead().GetRef())
+			handler.commentPreviewLink(pullRequest.GetNumber(), pullRequest.GetHead().GetRef(), file.GetFilename())
+
+			return nil
 		}
 	}
 
-	return nil
+	if commentedPath == "" {
+		return fmt.Errorf("no blog post found in PR #%d", pullRequest.GetNumber())
+	}
+
+	return fmt.Errorf("%s is not a blog post in PR #%d", commentedPath, pullRequest.GetNumber())
 }
 
 // handleDraftStatusChange moves blog posts between drafts and posts directories
@@ -336,7 +863,7 @@ func (handler *Handler) handleDraftStatusChange(
 		botGithub.ListPullRequestFilesArgs{
 			Owner:    handler.Owner,
 			Repo:     handler.Repo,
-			PrNumber: *pullRequest.Number,
+			PrNumber: pullRequest.GetNumber(),
 		},
 	)
 
@@ -345,17 +872,13 @@ func (handler *Handler) handleDraftStatusChange(
 	}
 
 	for _, file := range files {
-		isMarkdownFile := strings.HasSuffix(*file.Filename, ".md")
-		isFileInPostsDir := strings.Contains(*file.Filename, "pkg/blog_markdown_content/posts")
-		isFileInDraftsDir := strings.Contains(*file.Filename, "pkg/blog_markdown_content/drafts")
-
-		if isMarkdownFile && (isFileInPostsDir || isFileInDraftsDir) {
+		if handler.Config.Content.IsPostFile(file.GetFilename()) {
 			// Get current content
-			currentContent, sha, err := handler.GithubClient.GetFileContent(
+			currentContent, _, err := handler.GithubClient.GetFileContent(
 				botGithub.GetFileContentArgs{
-					Filename: *file.Filename,
+					Filename: file.GetFilename(),
 					Owner:    handler.Owner,
-					Ref:      *pullRequest.Head.Ref,
+					Ref:      pullRequest.GetHead().GetRef(),
 					Repo:     handler.Repo,
 				},
 			)
@@ -365,66 +888,72 @@ func (handler *Handler) handleDraftStatusChange(
 			}
 
 			// Update draft status in content
-			updatedContent := handler.updateDraftStatus(currentContent, !shouldPublish)
+			updatedContent, err := handler.updateDraftStatus(currentContent, !shouldPublish)
+			if err != nil {
+				return fmt.Errorf("updating draft status in %s: %w", file.GetFilename(), err)
+			}
 
-			// Determine new file path
-			baseName := strings.TrimSuffix(filepath.Base(*file.Filename), ".md")
+			// Determine new file path; without a drafts directory the post stays put
+			newFilename := handler.Config.Content.MovedPath(file.GetFilename(), !shouldPublish)
 
-			var newFilename string
-			if shouldPublish {
-				newFilename = filepath.Join("pkg", "blog_markdown_content", "posts", baseName+".md")
-			} else {
-				newFilename = filepath.Join("pkg", "blog_markdown_content", "drafts", baseName+".md")
+			if err := handler.checkPath(newFilename); err != nil {
+				return err
 			}
 
-			// Create new file in /posts
+			// Move the file in one commit so the post never exists twice
 			message := fmt.Sprintf(
 				"Move blog post to %s",
 				map[bool]string{true: "published", false: "draft"}[shouldPublish],
 			)
 
-			if err := handler.GithubClient.CreateFile(
-				botGithub.CreateFileArgs{
-					Branch:   *pullRequest.Head.Ref,
-					Content:  updatedContent,
-					Filename: newFilename,
-					Message:  message,
-					Owner:    handler.Owner,
-					Repo:     handler.Repo,
-				},
-			); err != nil {
-				return fmt.Errorf("creating new file: %w", err)
+			changes := []botGithub.FileChange{
+				{Content: updatedContent, MovedFrom: file.GetFilename(), Path: newFilename},
 			}
 
-			// Delete old file in /drafts assumedly
-			if err := handler.GithubClient.DeleteFile(
-				botGithub.DeleteFileArgs{
-					Owner:    handler.Owner,
-					Repo:     handler.Repo,
-					Branch:   *pullRequest.Head.Ref,
-					Filename: *file.Filename,
-					Message:  "Remove old blog post file",
-					Sha:      sha,
+			if newFilename != file.GetFilename() {
+				changes = append(changes, botGithub.FileChange{Path: file.GetFilename(), Delete: true})
+			}
+
+			commitSHA, err := handler.GithubClient.CommitFiles(
+				botGithub.CommitFilesArgs{
+					Branch:  pullRequest.GetHead().GetRef(),
+					Changes: changes,
+					Message: message,
+					Owner:   handler.Owner,
+					Repo:    handler.Repo,
 				},
-			); err != nil {
-				return fmt.Errorf("deleting old file: %w", err)
+			)
+
+			if err != nil {
+				return fmt.Errorf("moving file: %w", err)
 			}
 
+			handler.common().RecordBotCommit(pullRequest.GetNumber(), pullRequest.GetHead().GetRef(), commitSHA)
+
 			// Comment on success
 			statusMsg := map[bool]string{
 				true:  "published",
 				false: "moved to drafts",
 			}[shouldPublish]
 
+			reply := fmt.Sprintf("✅ Blog post %s!", statusMsg)
+
+			// the deploy hook fires from handlePRClosed, once the post is on main
+			if shouldPublish && handler.Config.DeployHook != "" {
+				reply += " The site will be redeployed once this PR is merged."
+			}
+
 			handler.GithubClient.CommentOnPR(
 				botGithub.CommentOnPRArgs{
-					Comment:  fmt.Sprintf("✅ Blog post %s!", statusMsg),
+					Comment:  reply,
 					Owner:    handler.Owner,
-					PrNumber: *pullRequest.Number,
+					PrNumber: pullRequest.GetNumber(),
 					Repo:     handler.Repo,
 				},
 			)
 
+			handler.common().DispatchWorkflow(pullRequest.GetNumber(), pullRequest.GetHead().GetRef())
+
 			break
 		}
 	}
@@ -434,12 +963,108 @@ func (handler *Handler) handleDraftStatusChange(
 
 // Helper methods
 
+func (handler *Handler) recordPullRequestOpened(prNumber int, contentType string) {
+	if err := handler.Store.RecordPullRequestOpened(
+		botState.RecordPullRequestOpenedArgs{
+			ContentType:   contentType,
+			Number:        prNumber,
+			PromptVersion: botAi.PromptVersion,
+			Repo:          handler.common().FullRepoName(),
+		},
+	); err != nil {
+		handler.logger.Error("Recording PR metrics failed", "error", err)
+	}
+}
+
+// checkPath refuses writes outside the paths the repo lets the bot write to
+func (handler *Handler) checkPath(filePath string) error {
+	if !handler.Config.Paths.IsAllowed(filePath) {
+		return fmt.Errorf("%s is outside the paths the bot may write to", filePath)
+	}
+
+	return nil
+}
+
+// changeFailureComment explains why a requested change wasn't made
+func (handler *Handler) changeFailureComment(err error) string {
+	reason, _ := botErrors.UserMessage(err)
+
+	if errors.Is(err, botGithub.ErrSecretDetected) {
+		return handler.replies.Render("secret_detected", botReplies.Data{"Reason": reason})
+	}
+
+	if errors.Is(err, botAi.ErrContentTooLarge) || errors.Is(err, botAi.ErrOutputTruncated) {
+		return handler.replies.Render("content_too_large", botReplies.Data{"Kind": "post", "Reason": reason})
+	}
+
+	return handler.replies.Render("change_failed", botReplies.Data{"Reason": reason})
+}
+
+// conversationHistory returns the earlier change requests on a PR for the AI
+func (handler *Handler) conversationHistory(prNumber int) []botAi.Turn {
+	history := []botAi.Turn{}
+
+	for _, turn := range handler.Store.ConversationHistory(handler.common().FullRepoName(), prNumber) {
+		history = append(history, botAi.Turn{Path: turn.Path, Request: turn.Request, Response: turn.Response})
+	}
+
+	return history
+}
+
+func (handler *Handler) recordConversationTurn(prNumber int, path, request string) {
+	if err := handler.Store.AppendConversationTurn(
+		handler.common().FullRepoName(),
+		prNumber,
+		botState.ConversationTurn{
+			CreatedAt: time.Now(),
+			Path:      path,
+			Request:   request,
+			Response:  fmt.Sprintf("I edited %s to address this.", path),
+		},
+	); err != nil {
+		handler.logger.Error("Recording conversation failed", "error", err)
+	}
+}
+
 func (handler *Handler) handleIssueComment(
 	issue *github.Issue,
 	comment *github.IssueComment,
 ) {
-	// Handle comments on the original issue if needed
-	// For now, we mainly focus on PR comments
+	// Conversation comments on PRs can carry slash commands
+	if botGithub.IsPullRequest(issue) {
+		command, _ := parseCommand(comment.GetBody())
+
+		if command == "" || !handler.common().IsAuthorized(comment.GetUser().GetLogin(), issue.GetNumber()) {
+			return
+		}
+
+		if !handler.common().IsWithinRateLimit(comment.GetUser().GetLogin(), issue.GetNumber()) {
+			return
+		}
+
+		handler.handlePRCommand(issue.GetNumber(), comment.GetBody(), comment.GetUser().GetLogin())
+		return
+	}
+
+	// Comments on the original issue refine the request until its PR is opened
+	handler.handleIssueConversation(issue, comment)
+}
+
+// isBlogPostTitle reports whether an issue title asks for a blog post
+func isBlogPostTitle(title string) bool {
+	return strings.Contains(strings.ToLower(title), "blog post")
+}
+
+// isTriggered reports whether an issue asks for the bot, by its title or the trigger label
+func (handler *Handler) isTriggered(issue *github.Issue) bool {
+	return isBlogPostTitle(issue.GetTitle()) || botHandler.HasLabel(issue, handler.Config.TriggerLabel)
+}
+
+// isActionable reports whether a PR comment would make the bot do anything
+func (handler *Handler) isActionable(comment string) bool {
+	command, _ := parseCommand(comment)
+
+	return command != "" || handler.hasDraftStatusChange(comment) || handler.isChangeRequest(comment)
 }
 
 func (handler *Handler) isChangeRequest(comment string) bool {
@@ -465,29 +1090,55 @@ func (handler *Handler) hasDraftStatusChange(comment string) bool {
 		strings.Contains(lowerComment, "make it a draft")
 }
 
-func (handler *Handler) updateDraftStatus(content string, isDraft bool) string {
-	lines := strings.Split(content, "\n")
+func (handler *Handler) updateDraftStatus(content string, isDraft bool) (string, error) {
+	return handler.frontmatterFormat().Update(content, func(post *Post) {
+		post.IsDraft = isDraft
+	})
+}
 
-	for i, line := range lines {
-		if strings.HasPrefix(line, "is_draft:") {
-			lines[i] = fmt.Sprintf("is_draft: %t", isDraft)
-			break
-		}
+func (handler *Handler) generatePRBody(
+	issue *github.Issue,
+	request *BlogPostRequest,
+	post *Post,
+	model string,
+) string {
+	generatedBy := "the fallback template"
+	if model != "" {
+		generatedBy = model + handler.costNote(issue.GetNumber())
 	}
 
-	return strings.Join(lines, "\n")
+	return handler.replies.Render(
+		"blog_pr_body",
+		botReplies.Data{
+			"File":        handler.postFilePath(post),
+			"GeneratedBy": generatedBy,
+			"Issue":       issue.GetNumber(),
+			"Style":       styleName(request),
+			"Summary":     post.Summary,
+			"Tags":        post.Tags,
+			"Title":       post.Title,
+			"Words":       describeWordCount(post, request),
+		},
+	)
 }
 
-func (handler *Handler) generatePRBody(issue *github.Issue, post *Post) string {
-	return fmt.Sprintf(`🤖 AI-generated blog post based on issue #%d
-
-**Title:** %s
-**Summary:** %s
-**Tags:** %s
+// costNote is the estimated AI spend on an issue so far, e.g. " (~$0.12)",
+// when the repo shows costs and anything has been spent
+func (handler *Handler) costNote(issueNumber int) string {
+	if !handler.Config.ShowCost {
+		return ""
+	}
 
-This blog post was automatically generated. Feel free to comment with any changes you'd like me to make!
+	cost := handler.Store.IssueCost(handler.common().FullRepoName(), issueNumber).CostUSD
 
-Closes #%d`, *issue.Number, post.Title, post.Summary, strings.Join(post.Tags, ", "), *issue.Number)
+	switch {
+	case cost <= 0:
+		return ""
+	case cost < 0.01:
+		return " (<$0.01)"
+	default:
+		return fmt.Sprintf(" (~$%.2f)", cost)
+	}
 }
 
 func (handler *Handler) generateTemplateContent(request *BlogPostRequest) string {
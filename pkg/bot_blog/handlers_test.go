@@ -0,0 +1,326 @@
+package botblog
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	botAiTest "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai/botaitest"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithubTest "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github/botgithubtest"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	"github.com/google/go-github/v57/github"
+)
+
+const testWebhookSecret = "test-secret"
+
+func newTestHandler(t *testing.T) *Handler {
+	return newMockedHandler(t, &botConfig.RepoConfig{}, botGithubTest.NewMockClient(nil))
+}
+
+// newMockedHandler builds a handler on the in-memory GitHub and AI mocks
+func newMockedHandler(t *testing.T, config *botConfig.RepoConfig, githubClient *botGithubTest.MockClient) *Handler {
+	t.Helper()
+
+	store, err := botState.NewStore("")
+	if err != nil {
+		t.Fatalf("creating store: %v", err)
+	}
+
+	return NewHandler(
+		Handler{
+			AiClient:      botAiTest.NewMockClient(nil),
+			Config:        config,
+			GithubClient:  githubClient,
+			Owner:         "owner",
+			Repo:          "blog",
+			Store:         store,
+			WebhookSecret: testWebhookSecret,
+		},
+	)
+}
+
+// deliver sends a signed webhook to the handler, waits for it to be handled
+// and returns the response status
+func deliver(t *testing.T, handler *Handler, eventType, payload string) int {
+	t.Helper()
+
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(payload))
+
+	request := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(payload))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-GitHub-Event", eventType)
+	request.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	recorder := httptest.NewRecorder()
+	handler.HandleWebhook(recorder, request)
+	handler.Wait()
+
+	return recorder.Code
+}
+
+func TestHandleWebhookWithoutOptionalFields(t *testing.T) {
+	tests := []struct {
+		eventType string
+		name      string
+		payload   string
+	}{
+		{eventType: "issues", name: "issue event without an action", payload: `{"issue":{"number":1}}`},
+		{eventType: "issues", name: "opened issue without a title or body", payload: `{"action":"opened","issue":{"number":1}}`},
+		{eventType: "issues", name: "edited event without the issue", payload: `{"action":"edited"}`},
+		{eventType: "issues", name: "labeled event without the label", payload: `{"action":"labeled","issue":{"number":1}}`},
+		{eventType: "issue_comment", name: "comment event without the comment or issue", payload: `{"action":"created"}`},
+		{eventType: "issue_comment", name: "comment without a body", payload: `{"action":"created","issue":{"number":1},"comment":{"id":2}}`},
+		{eventType: "pull_request", name: "closed PR without a head or base", payload: `{"action":"closed","pull_request":{"number":3}}`},
+		{eventType: "pull_request_review_comment", name: "review comment without a body", payload: `{"action":"created","pull_request":{"number":3},"comment":{"id":4}}`},
+		{eventType: "pull_request_review", name: "review event without the review", payload: `{"action":"submitted","pull_request":{"number":3}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if status := deliver(t, newTestHandler(t), test.eventType, test.payload); status != http.StatusAccepted {
+				t.Errorf("got status %d, want %d", status, http.StatusAccepted)
+			}
+		})
+	}
+}
+
+func TestBlogPostRequestFlow(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(nil)
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), githubClient)
+
+	opened := `{"action":"opened","issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test webhook handlers without live tokens","user":{"login":"author"}}}`
+
+	if status := deliver(t, handler, "issues", opened); status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+
+	if len(githubClient.PullRequests) != 1 {
+		t.Fatalf("got %d PRs, want 1", len(githubClient.PullRequests))
+	}
+
+	pullRequest := githubClient.PullRequests[0]
+
+	if got, want := pullRequest.GetTitle(), "Add blog post: Testing handlers"; got != want {
+		t.Errorf("got PR title %q, want %q", got, want)
+	}
+
+	if !strings.Contains(pullRequest.GetBody(), "#1") {
+		t.Errorf("PR body doesn't mention the issue:\n%s", pullRequest.GetBody())
+	}
+
+	postPath := "pkg/blog_markdown_content/drafts/testing-handlers.md"
+	post := githubClient.Branches[pullRequest.GetHead().GetRef()][postPath]
+
+	if !strings.Contains(post, "A mock post about How to test webhook handlers") {
+		t.Fatalf("%s doesn't hold the generated post:\n%s", postPath, post)
+	}
+
+	comment := fmt.Sprintf(
+		`{"action":"created","pull_request":{"number":%d,"head":{"ref":%q}},"comment":{"id":5,"path":%q,"body":"Please add a conclusion","user":{"login":"author"}}}`,
+		pullRequest.GetNumber(),
+		pullRequest.GetHead().GetRef(),
+		postPath,
+	)
+
+	if status := deliver(t, handler, "pull_request_review_comment", comment); status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+
+	edited := githubClient.Branches[pullRequest.GetHead().GetRef()][postPath]
+
+	if !strings.Contains(edited, "Edited: Please add a conclusion") {
+		t.Errorf("the review comment wasn't applied to %s:\n%s", postPath, edited)
+	}
+}
+
+func TestRepoFileSettings(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(
+		map[string]string{botConfig.RepoFile: "content:\n  drafts_dir: content/drafts\nreviewers: [editor]\n"},
+	)
+
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), githubClient)
+
+	open := func(number int, title string) string {
+		t.Helper()
+
+		opened := fmt.Sprintf(
+			`{"action":"opened","issue":{"number":%d,"title":"Blog post: %s","body":"How settings files work","user":{"login":"author"}}}`,
+			number,
+			title,
+		)
+
+		if status := deliver(t, handler, "issues", opened); status != http.StatusAccepted {
+			t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+		}
+
+		pullRequest := githubClient.PullRequests[len(githubClient.PullRequests)-1]
+
+		if got := githubClient.Reviewers[pullRequest.GetNumber()]; !slices.Contains(got, "editor") {
+			t.Errorf("got reviewers %q, want the file's", got)
+		}
+
+		return pullRequest.GetHead().GetRef()
+	}
+
+	branch := open(1, "Settings files")
+	if _, isWritten := githubClient.Branches[branch]["content/drafts/settings-files.md"]; !isWritten {
+		t.Errorf("the post wasn't written to the file's drafts directory: %v", slices.Collect(maps.Keys(githubClient.Branches[branch])))
+	}
+
+	// the file is read again only after a push changes it
+	githubClient.Branches["main"][botConfig.RepoFile] = "content:\n  drafts_dir: posts/drafts\nreviewers: [editor]\n"
+
+	push := fmt.Sprintf(
+		`{"ref":"refs/heads/main","repository":{"default_branch":"main"},"commits":[{"modified":[%q]}]}`,
+		botConfig.RepoFile,
+	)
+
+	if status := deliver(t, handler, "push", push); status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+
+	branch = open(2, "Pushed settings")
+	if _, isWritten := githubClient.Branches[branch]["posts/drafts/pushed-settings.md"]; !isWritten {
+		t.Errorf("the pushed drafts directory wasn't used: %v", slices.Collect(maps.Keys(githubClient.Branches[branch])))
+	}
+}
+
+func TestBlogPostRequestFromUnauthorizedUser(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(nil)
+	githubClient.Permissions["stranger"] = "read"
+
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), githubClient)
+
+	opened := `{"action":"opened","issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test handlers","user":{"login":"stranger"}}}`
+
+	if status := deliver(t, handler, "issues", opened); status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+
+	if len(githubClient.PullRequests) != 0 {
+		t.Errorf("got %d PRs, want none", len(githubClient.PullRequests))
+	}
+
+	if len(githubClient.CommentsOn(1)) != 1 {
+		t.Errorf("got comments %q, want one explaining who can trigger the bot", githubClient.CommentsOn(1))
+	}
+}
+
+func TestBlogPostDryRun(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(nil)
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), githubClient)
+
+	opened := `{"action":"opened","issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test handlers","labels":[{"name":"ai-dry-run"}],"user":{"login":"author"}}}`
+
+	if status := deliver(t, handler, "issues", opened); status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+
+	if len(githubClient.PullRequests) != 0 || len(githubClient.Branches) != 1 {
+		t.Errorf("a dry run opened %d PRs and %d branches besides main", len(githubClient.PullRequests), len(githubClient.Branches)-1)
+	}
+
+	comments := githubClient.CommentsOn(1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "A mock post about How to test handlers") {
+		t.Errorf("got comments %q, want a preview of the post", comments)
+	}
+}
+
+func TestProgressivePostCountsFirstCommitOnce(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(nil)
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), githubClient)
+
+	opened := `{"action":"opened","issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test webhook handlers\n\nprogressive: true","user":{"login":"author"}}}`
+
+	if status := deliver(t, handler, "issues", opened); status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+
+	if len(githubClient.PullRequests) != 1 {
+		t.Fatalf("got %d PRs, want 1", len(githubClient.PullRequests))
+	}
+
+	// the sections are written faster than the flush interval, so the post is pushed once
+	record, exists := handler.Store.PullRequest("owner/blog", githubClient.PullRequests[0].GetNumber())
+	if !exists {
+		t.Fatal("the PR wasn't recorded")
+	}
+
+	if record.BotCommits != 1 {
+		t.Errorf("got %d bot commits, want 1 for the commit the PR was opened with", record.BotCommits)
+	}
+}
+
+func TestIssueEditAsksBeforeRegeneratingOverOthersCommits(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(nil)
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), githubClient)
+
+	opened := `{"action":"opened","issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test webhook handlers","user":{"login":"author"}}}`
+
+	if status := deliver(t, handler, "issues", opened); status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+
+	pullRequest := githubClient.PullRequests[0]
+	pullRequest.Commits = github.Int(2)
+	before := maps.Clone(githubClient.Branches[pullRequest.GetHead().GetRef()])
+
+	titleEdited := `{"action":"edited","changes":{"title":{"from":"Blog post: Old"}},"issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test webhook handlers","user":{"login":"author"}},"sender":{"login":"author"}}`
+	deliver(t, handler, "issues", titleEdited)
+
+	if comments := githubClient.CommentsOn(pullRequest.GetNumber()); len(comments) != 0 {
+		t.Fatalf("got comments %q after a title edit, want none", comments)
+	}
+
+	bodyEdited := `{"action":"edited","changes":{"body":{"from":"Old"}},"issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test webhook handlers, with examples","user":{"login":"author"}},"sender":{"login":"author"}}`
+	deliver(t, handler, "issues", bodyEdited)
+
+	comments := githubClient.CommentsOn(pullRequest.GetNumber())
+	if len(comments) != 1 || !strings.Contains(comments[0], regenerateCommand) {
+		t.Fatalf("got comments %q, want one asking for %s", comments, regenerateCommand)
+	}
+
+	if !maps.Equal(githubClient.Branches[pullRequest.GetHead().GetRef()], before) {
+		t.Error("the post was regenerated over someone else's commits")
+	}
+}
+
+func TestClosingPRDeletesOnlyBotBranches(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(nil)
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_BLOG_"), githubClient)
+
+	opened := `{"action":"opened","issue":{"number":1,"title":"Blog post: Testing handlers","body":"How to test webhook handlers","user":{"login":"author"}}}`
+	deliver(t, handler, "issues", opened)
+
+	botBranch := githubClient.PullRequests[0].GetHead().GetRef()
+	githubClient.Branches["ai-lookalike"] = map[string]string{}
+
+	// a human's PR from a branch named like the bot's
+	humanClosed := `{"action":"closed","pull_request":{"number":7,"merged":true,"head":{"ref":"ai-lookalike","repo":{"full_name":"owner/blog"}}}}`
+	deliver(t, handler, "pull_request", humanClosed)
+
+	if _, exists := githubClient.Branches["ai-lookalike"]; !exists {
+		t.Error("deleted the branch of a PR the bot didn't open")
+	}
+
+	botClosed := fmt.Sprintf(
+		`{"action":"closed","pull_request":{"number":%d,"merged":true,"body":"Closes #1","head":{"ref":%q,"repo":{"full_name":"owner/blog"}}}}`,
+		githubClient.PullRequests[0].GetNumber(),
+		botBranch,
+	)
+	deliver(t, handler, "pull_request", botClosed)
+
+	if _, exists := githubClient.Branches[botBranch]; exists {
+		t.Errorf("kept the branch %s of a closed bot PR", botBranch)
+	}
+}
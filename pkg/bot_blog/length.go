@@ -0,0 +1,45 @@
+package botblog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lengthPresets are the word counts behind "length: short|medium|long"
+var lengthPresets = map[string]int{
+	"short":  600,
+	"medium": 1200,
+	"long":   2500,
+}
+
+// maxTargetWords caps "words:" at what fits in a single reply
+const maxTargetWords = 6000
+
+// parseLength reads a "length:" directive, returning 0 for an unknown preset
+func parseLength(value string) int {
+	return lengthPresets[strings.ToLower(strings.TrimSpace(value))]
+}
+
+// parseWordCount reads a "words:" directive like "1500" or "1,500 words",
+// returning 0 when it isn't a positive number
+func parseWordCount(value string) int {
+	value = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), "words")
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+
+	words, err := strconv.Atoi(value)
+	if err != nil || words <= 0 {
+		return 0
+	}
+
+	return min(words, maxTargetWords)
+}
+
+// describeWordCount reports a post's length, with the target it was written to
+func describeWordCount(post *Post, request *BlogPostRequest) string {
+	if request.Words == 0 {
+		return strconv.Itoa(post.WordCount())
+	}
+
+	return fmt.Sprintf("%d (target %d)", post.WordCount(), request.Words)
+}
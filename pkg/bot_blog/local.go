@@ -0,0 +1,57 @@
+package botblog
+
+import "errors"
+
+// LocalPost is a post written without going through GitHub, as the bot
+// would commit it
+type LocalPost struct {
+	Markdown string
+	Model    string
+	Path     string   // where in the repo the bot would commit it
+	Problems []string // what the markdown and alt text passes couldn't fix
+}
+
+// WritePost generates, checks and renders a post the way a request issue
+// would, without a branch or PR. Related reading is left out, since finding
+// it needs the repo's published posts
+func (handler *Handler) WritePost(request *BlogPostRequest) (*LocalPost, error) {
+	post, model := handler.generatePost(request, "")
+
+	// the template fallback is only worth committing as a starting point in a PR
+	if model == "" {
+		return nil, errors.New("the AI couldn't write the post; the log has the error")
+	}
+
+	handler.suggestTags(post)
+	handler.addSEOMetadata(post)
+
+	return handler.renderLocalPost(post, model), nil
+}
+
+// renderLocalPost licenses, checks and renders a post the way openPostPR
+// would, without committing it
+func (handler *Handler) renderLocalPost(post *Post, model string) *LocalPost {
+	post.ApplyLicensing(handler.Config.Licensing)
+
+	problems := handler.checkPostContent(post)
+
+	return &LocalPost{
+		Markdown: handler.frontmatterFormat().Render(post),
+		Model:    model,
+		Path:     handler.postFilePath(post),
+		Problems: problems,
+	}
+}
+
+// ModifyPost applies an edit request to a post's markdown, as a review
+// comment on its PR would, returning notes on images still missing alt text
+func (handler *Handler) ModifyPost(markdown, instruction string) (string, []string, error) {
+	modified, err := handler.AiClient.ModifyBlogPost(markdown, instruction, "", nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	modified, altTextNotes := handler.fillAltText(handler.postTitle(modified), modified)
+
+	return modified, altTextNotes, nil
+}
@@ -0,0 +1,160 @@
+package botblog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// headingPattern matches an ATX heading, capturing its hashes
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+\S`)
+
+	// inlineCodePattern matches a code span, which can't hold links
+	inlineCodePattern = regexp.MustCompile("`[^`]*`")
+
+	// referenceLinkPattern matches "[text][label]" and "[text][]" links
+	referenceLinkPattern = regexp.MustCompile(`\[([^\]]+)\]\[([^\]]*)\]`)
+
+	// referenceDefinitionPattern matches "[label]: url"
+	referenceDefinitionPattern = regexp.MustCompile(`^\s{0,3}\[([^\]]+)\]:\s*\S`)
+)
+
+// lintMarkdown fixes what it safely can in a post body (trailing whitespace,
+// skipped heading levels, an unclosed code fence) and lists what it can't
+func lintMarkdown(content string) (string, []string) {
+	lines := strings.Split(content, "\n")
+	problems := []string{}
+	definitions := map[string]bool{}
+	fence := ""
+	previousLevel := 1 // the post title is the page's h1
+
+	for index, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		lines[index] = line
+
+		if match := referenceDefinitionPattern.FindStringSubmatch(line); match != nil && fence == "" {
+			definitions[referenceLabel(match[1])] = true
+		}
+
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			marker := trimmed[:3]
+
+			switch {
+			case fence == "":
+				fence = marker
+			case fence == marker && strings.Trim(trimmed, marker[:1]) == "":
+				fence = ""
+			}
+
+			continue
+		}
+
+		if fence != "" {
+			continue
+		}
+
+		match := headingPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		level := len(match[1])
+
+		switch {
+		case level == 1:
+			problems = append(problems, fmt.Sprintf(
+				"line %d: %q is a top-level heading, but the title is already the page's h1",
+				index+1,
+				trimmed,
+			))
+
+		case level > previousLevel+1:
+			level = previousLevel + 1
+			lines[index] = strings.Repeat("#", level) + line[len(match[1]):]
+		}
+
+		previousLevel = level
+	}
+
+	if fence != "" {
+		lines = append(lines, fence)
+
+		// keep the file's trailing newline after the new fence
+		if len(lines) > 1 && lines[len(lines)-2] == "" {
+			lines[len(lines)-2], lines[len(lines)-1] = lines[len(lines)-1], ""
+		}
+	}
+
+	problems = append(problems, findBrokenReferenceLinks(lines, definitions)...)
+
+	return strings.Join(lines, "\n"), problems
+}
+
+// findBrokenReferenceLinks lists "[text][label]" links with no "[label]: url"
+func findBrokenReferenceLinks(lines []string, definitions map[string]bool) []string {
+	problems := []string{}
+	isInFence := false
+
+	for index, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			isInFence = !isInFence
+			continue
+		}
+
+		if isInFence {
+			continue
+		}
+
+		for _, match := range referenceLinkPattern.FindAllStringSubmatch(inlineCodePattern.ReplaceAllString(line, ""), -1) {
+			label := match[2]
+			if label == "" {
+				label = match[1]
+			}
+
+			if !definitions[referenceLabel(label)] {
+				problems = append(problems, fmt.Sprintf("line %d: the reference link [%s] has no definition", index+1, label))
+			}
+		}
+	}
+
+	return problems
+}
+
+// referenceLabel normalizes a link label the way markdown matches them:
+// case-insensitive, with runs of whitespace collapsed
+func referenceLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// checkPostContent runs the markdown lint and alt text passes over a post,
+// returning the problems they couldn't fix
+func (handler *Handler) checkPostContent(post *Post) []string {
+	var lintProblems, altTextNotes []string
+
+	if handler.Config.MarkdownLint {
+		post.Content, lintProblems = lintMarkdown(post.Content)
+	}
+
+	post.Content, altTextNotes = handler.fillAltText(post.Title, post.Content)
+
+	return slices.Concat(lintProblems, altTextNotes)
+}
+
+// reportPostProblems leaves a comment on the PR listing what the markdown
+// and alt text passes couldn't fix
+func (handler *Handler) reportPostProblems(prNumber int, problems []string) {
+	if len(problems) == 0 {
+		return
+	}
+
+	handler.common().CommentOnPR(prNumber, fmt.Sprintf(
+		"⚠️ I found problems in the post that I couldn't fix on my own:\n\n- %s\n\nLine numbers count from the start of the post body, below the frontmatter.",
+		strings.Join(problems, "\n- "),
+	))
+}
@@ -0,0 +1,146 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	"github.com/google/go-github/v57/github"
+)
+
+const approveOutlineCommand = "/approve-outline"
+
+// wantsOutlineReview reports whether a request's outline is proposed on the
+// issue before the post is written, the issue's outline: directive
+// overriding the repo default
+func (handler *Handler) wantsOutlineReview(request *BlogPostRequest) bool {
+	if request.OutlineReview != nil {
+		return *request.OutlineReview
+	}
+
+	return handler.Config.OutlineReview
+}
+
+// proposeOutline has the AI outline the post and posts the outline on the
+// issue, storing the request until the outline is approved. Without an
+// outline the request stays pending like a conversation
+func (handler *Handler) proposeOutline(issueNumber int, request *BlogPostRequest, guidance string) {
+	outline, err := handler.AiClient.GenerateBlogOutline(
+		&botAi.BlogPostRequest{
+			Draft:    request.Draft,
+			Guidance: guidance,
+			Language: request.Language,
+			Points:   request.Points,
+			Style:    request.Style,
+			Tags:     request.Tags,
+			Title:    request.Title,
+			Topic:    request.Topic,
+			Words:    request.Words,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Outlining post failed", "error", err)
+		handler.common().CommentOnIssue(issueNumber, fmt.Sprintf("Sorry, I couldn't outline the post. Reply `%s` to try again, or `%s` to write it without an outline.", generateCommand, approveOutlineCommand))
+
+		request.Outline = nil
+		handler.saveOutlineRequest(issueNumber, request)
+
+		return
+	}
+
+	request.Outline = outline.Sections
+
+	if request.Title == "" {
+		request.Title = outline.Title
+	}
+
+	if !handler.saveOutlineRequest(issueNumber, request) {
+		return
+	}
+
+	handler.common().CommentOnIssue(issueNumber, fmt.Sprintf(
+		"🗺️ Here's the outline I'd write from:\n\n**%s**\n\n%s\nReply `%s` to write the post, a new list of sections to use instead, or any other comment to have me redo the outline with it in mind.",
+		request.Title,
+		formatOutline(request.Outline),
+		approveOutlineCommand,
+	))
+}
+
+// handleOutlineReply approves, replaces or redoes a proposed outline
+func (handler *Handler) handleOutlineReply(issue *github.Issue, request *BlogPostRequest, commentBody string) {
+	command, _ := parseCommand(commentBody)
+
+	switch {
+	case command == approveOutlineCommand || command == generateCommand:
+		handler.generateFromConversation(issue, request)
+
+	case command != "":
+		return
+
+	case isSectionList(commentBody):
+		request.Outline = parsePoints("points:\n" + commentBody)
+
+		if handler.saveOutlineRequest(issue.GetNumber(), request) {
+			handler.common().CommentOnIssue(issue.GetNumber(), fmt.Sprintf(
+				"📝 Updated the outline.\n\n%s\nReply `%s` to write the post.",
+				formatOutline(request.Outline),
+				approveOutlineCommand,
+			))
+		}
+
+	default:
+		handler.proposeOutline(issue.GetNumber(), request, commentBody)
+	}
+}
+
+func (handler *Handler) saveOutlineRequest(issueNumber int, request *BlogPostRequest) bool {
+	if err := handler.Store.SaveIssueRequest(handler.common().FullRepoName(), issueNumber, request); err != nil {
+		handler.logger.Error("Saving issue request failed", "error", err)
+		return false
+	}
+
+	return true
+}
+
+// isSectionList reports whether a comment is nothing but a list of items,
+// which replaces the outline rather than guiding a new one
+func isSectionList(commentBody string) bool {
+	hasItems := false
+
+	for line := range strings.SplitSeq(commentBody, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			continue
+		}
+
+		if bulletPattern.FindString(trimmed) == "" {
+			return false
+		}
+
+		hasItems = true
+	}
+
+	return hasItems
+}
+
+// formatOutline renders section headings as a numbered list
+func formatOutline(sections []string) string {
+	var outline strings.Builder
+
+	for index, section := range sections {
+		fmt.Fprintf(&outline, "%d. %s\n", index+1, section)
+	}
+
+	return outline.String()
+}
+
+// outlineGuidance asks a one-shot post to follow an approved outline
+func outlineGuidance(sections []string) string {
+	if len(sections) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("Follow this approved outline, one \"## \" section per item, in order:\n%s", formatOutline(sections))
+}
@@ -0,0 +1,53 @@
+package botblog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// previewSection links the post in the PR's preview deploy from the PR
+// description, or is empty when the site has no previews
+func (handler *Handler) previewSection(prNumber int, branch, key string) string {
+	link := handler.Config.Content.PreviewLink(prNumber, branch, key)
+	if link == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\n**Preview:** %s", link)
+}
+
+// addPreviewToPRBody adds the preview link to a new PR's description, which
+// is written before the PR number is known
+func (handler *Handler) addPreviewToPRBody(pullRequest *github.PullRequest, key string) {
+	section := handler.previewSection(pullRequest.GetNumber(), pullRequest.GetHead().GetRef(), key)
+	if section == "" {
+		return
+	}
+
+	if err := handler.GithubClient.UpdatePullRequest(
+		botGithub.UpdatePullRequestArgs{
+			Body:     pullRequest.GetBody() + section,
+			Owner:    handler.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	); err != nil {
+		handler.logger.Error("Adding the preview link failed", "pr", pullRequest.GetNumber(), "error", err)
+	}
+}
+
+// commentPreviewLink links an updated post in the PR's preview deploy
+func (handler *Handler) commentPreviewLink(prNumber int, branch, filePath string) {
+	key := strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+
+	link := handler.Config.Content.PreviewLink(prNumber, branch, key)
+	if link == "" {
+		return
+	}
+
+	handler.common().CommentOnPR(prNumber, fmt.Sprintf("🔍 [Preview the updated post](%s) once the preview deploy for this change finishes.", link))
+}
@@ -0,0 +1,307 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	"github.com/google/go-github/v57/github"
+)
+
+// progressiveFlushInterval throttles how often an in-progress post is pushed
+const progressiveFlushInterval = 30 * time.Second
+
+// progressivePost pushes a long post to its branch as sections are written,
+// opening the PR after the first push so the draft can be read early
+type progressivePost struct {
+	branchName  string
+	check       *botGithub.GenerationCheck
+	handler     *Handler
+	headSHA     string
+	issue       *github.Issue
+	lastFlush   time.Time
+	post        *Post
+	progress    *botGithub.ProgressComment
+	pullRequest *github.PullRequest
+	pushed      string
+}
+
+// createProgressivePostPR outlines a post, then writes it one section at a time
+func (handler *Handler) createProgressivePostPR(issue *github.Issue, request *BlogPostRequest) error {
+	aiRequest := &botAi.BlogPostRequest{
+		Title:    request.Title,
+		Topic:    request.Topic,
+		Points:   request.Points,
+		Tags:     request.Tags,
+		Draft:    request.Draft,
+		Language: request.Language,
+		Style:    request.Style,
+		Words:    request.Words,
+	}
+
+	// an outline approved on the issue is written as it stands
+	outline := &botAi.BlogOutline{Sections: request.Outline, Title: request.Title}
+
+	if len(request.Outline) == 0 {
+		generated, err := handler.AiClient.GenerateBlogOutline(aiRequest)
+		if err != nil {
+			return fmt.Errorf("outlining post: %w", err)
+		}
+
+		outline = generated
+	}
+
+	title := request.Title
+	if title == "" {
+		title = outline.Title
+	}
+
+	post := NewPost(
+		title,
+		request.Topic,
+		mergeTags(request.Tags, outline.Tags),
+		request.Draft,
+	)
+
+	post.ApplyRequestDate(request)
+	post.ApplyRequestLanguage(request)
+
+	if outline.Summary != "" {
+		post.Summary = outline.Summary
+	}
+
+	if err := handler.claimPostKey(post); err != nil {
+		return err
+	}
+
+	if err := handler.checkPath(handler.postFilePath(post)); err != nil {
+		return err
+	}
+
+	branchName, err := handler.GithubClient.CreateBranch(
+		botGithub.CreateBranchArgs{
+			BranchName: fmt.Sprintf("ai-assisted-post-%d", issue.GetNumber()),
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("creating branch: %w", err)
+	}
+
+	delivery := &progressivePost{
+		branchName: branchName,
+		check:      handler.newGenerationCheck(),
+		handler:    handler,
+		issue:      issue,
+		lastFlush:  time.Now(),
+		post:       post,
+	}
+
+	sections := []string{}
+	model := ""
+
+	for index, heading := range outline.Sections {
+		completion, err := handler.AiClient.GenerateBlogSection(
+			aiRequest,
+			outline,
+			index,
+			strings.Join(sections, "\n\n"),
+		)
+
+		if err != nil {
+			delivery.fail(fmt.Errorf("writing %q: %w", heading, err))
+			return fmt.Errorf("writing section %d: %w", index+1, err)
+		}
+
+		model = completion.Model
+		sections = append(sections, completion.Text)
+		post.Content = strings.Join(sections, "\n\n")
+
+		if time.Since(delivery.lastFlush) < progressiveFlushInterval {
+			continue
+		}
+
+		progressLine := fmt.Sprintf("pushed %d of %d sections", index+1, len(outline.Sections))
+
+		if err := delivery.flush(progressLine); err != nil {
+			// keep writing; the final flush pushes everything still pending
+			handler.logger.Error("Pushing in-progress post failed", "error", err)
+		}
+	}
+
+	handler.proofreadPost(post)
+	handler.checkSnippets(post)
+
+	if handler.wantsTOC(request) {
+		post.Content = insertTableOfContents(post.Content)
+	}
+
+	// the outline's summary and tags were written before any of the post was,
+	// and the SEO text needs the finished post too
+	handler.summarizePost(post)
+	handler.suggestTags(post)
+	handler.addSEOMetadata(post)
+	handler.addRelatedReading(post)
+	post.ApplyLicensing(handler.Config.Licensing)
+	postProblems := handler.checkPostContent(post)
+	handler.addCoverImage(delivery.branchName, post)
+
+	if err := delivery.flush(fmt.Sprintf("pushed all %d sections", len(outline.Sections))); err != nil {
+		delivery.finish(botGithub.CheckConclusionFailure, "❌ Pushing the finished post failed. "+botErrors.UserMessageOrLogs(err))
+		return err
+	}
+
+	if err := handler.GithubClient.UpdatePullRequest(
+		botGithub.UpdatePullRequestArgs{
+			Body:     handler.generatePRBody(issue, request, post, model) + handler.previewSection(delivery.pullRequest.GetNumber(), delivery.branchName, post.Key),
+			Owner:    handler.Owner,
+			PrNumber: delivery.pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	); err != nil {
+		delivery.finish(botGithub.CheckConclusionFailure, "❌ Updating the PR description failed. "+botErrors.UserMessageOrLogs(err))
+		return err
+	}
+
+	handler.reportPostProblems(delivery.pullRequest.GetNumber(), postProblems)
+
+	delivery.handler.common().DispatchWorkflow(delivery.pullRequest.GetNumber(), delivery.branchName)
+
+	return delivery.finish(botGithub.CheckConclusionSuccess, "✅ post finished")
+}
+
+// flush commits the post if it changed since the last push
+func (delivery *progressivePost) flush(progressLine string) error {
+	delivery.lastFlush = time.Now()
+
+	markdown := delivery.handler.frontmatterFormat().Render(delivery.post)
+	if markdown == delivery.pushed {
+		return nil
+	}
+
+	handler := delivery.handler
+
+	commitSHA, err := handler.GithubClient.CommitFiles(
+		botGithub.CommitFilesArgs{
+			Branch: delivery.branchName,
+			Changes: []botGithub.FileChange{
+				{Content: markdown, Path: handler.postFilePath(delivery.post)},
+			},
+			Message: "Add AI-generated blog post",
+			Owner:   handler.Owner,
+			Repo:    handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("committing post: %w", err)
+	}
+
+	delivery.pushed = markdown
+	delivery.headSHA = commitSHA
+	isFirstPush := delivery.pullRequest == nil
+
+	if err := delivery.ensurePullRequest(); err != nil {
+		return err
+	}
+
+	// opening the PR counted its first commit
+	if isFirstPush {
+		handler.common().RecordBranchCommit(delivery.branchName, commitSHA)
+	} else {
+		handler.common().RecordBotCommit(delivery.pullRequest.GetNumber(), delivery.branchName, commitSHA)
+	}
+
+	return delivery.report(progressLine)
+}
+
+// ensurePullRequest opens the PR on the first push
+func (delivery *progressivePost) ensurePullRequest() error {
+	if delivery.pullRequest != nil {
+		return nil
+	}
+
+	handler := delivery.handler
+
+	pullRequest, err := handler.GithubClient.CreatePullRequest(
+		botGithub.CreatePullRequestArgs{
+			Base:  "main",
+			Body:  fmt.Sprintf("🚧 This post for #%d is still being written. Sections are pushed as they are finished.", *delivery.issue.Number),
+			Draft: handler.Config.DraftPRs,
+			Head:  fmt.Sprintf("%s:%s", handler.Owner, delivery.branchName),
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+			Title: fmt.Sprintf("Add blog post: %s", delivery.post.Title),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("creating PR: %w", err)
+	}
+
+	delivery.pullRequest = pullRequest
+
+	delivery.progress = handler.GithubClient.NewProgressComment(
+		botGithub.NewProgressCommentArgs{
+			Heading:     "⏳ **Writing progress**",
+			IssueNumber: pullRequest.GetNumber(),
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+
+	handler.recordPullRequestOpened(pullRequest.GetNumber(), botState.ContentTypeBlog)
+	handler.notifyPullRequestOpened(pullRequest)
+	handler.common().LabelPullRequest(pullRequest.GetNumber())
+	handler.common().RequestAttention(pullRequest.GetNumber(), delivery.issue.GetUser().GetLogin())
+
+	return nil
+}
+
+// fail keeps the sections written so far and notes the failure on the PR
+func (delivery *progressivePost) fail(cause error) {
+	if delivery.post.Content == "" {
+		return
+	}
+
+	if delivery.flush("pushed the sections written so far") != nil {
+		return
+	}
+
+	delivery.finish(
+		botGithub.CheckConclusionFailure,
+		fmt.Sprintf("❌ writing stopped early: %v — the sections above were kept", cause),
+	)
+}
+
+// report adds a line to the progress comment and the PR's check run
+func (delivery *progressivePost) report(line string) error {
+	if err := delivery.check.Report(delivery.headSHA, line); err != nil {
+		delivery.handler.logger.Error("Reporting check run failed", "error", err)
+	}
+
+	if delivery.progress == nil {
+		return nil
+	}
+
+	return delivery.progress.Report(line)
+}
+
+// finish adds the last line to the progress comment and completes the check run
+func (delivery *progressivePost) finish(conclusion, line string) error {
+	if err := delivery.check.Finish(delivery.headSHA, conclusion, line); err != nil {
+		delivery.handler.logger.Error("Completing check run failed", "error", err)
+	}
+
+	if delivery.progress == nil {
+		return nil
+	}
+
+	return delivery.progress.Report(line)
+}
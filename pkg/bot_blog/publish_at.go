@@ -0,0 +1,187 @@
+package botblog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+)
+
+// publishAtKey is the frontmatter field a draft's publish time is read from
+const publishAtKey = "publish_at"
+
+// publishAtFields adds publish_at to every format, written only when set
+func publishAtFields() []frontmatterField {
+	return []frontmatterField{
+		{
+			key:  publishAtKey,
+			read: func(post *Post) any { return omitEmpty(post.PublishAt) },
+			write: func(post *Post, value any) {
+				// a full YAML or TOML timestamp decodes as a time, which asString would cut to a date
+				if publishAt, isTime := value.(time.Time); isTime {
+					post.PublishAt = publishAt.Format(time.RFC3339)
+					return
+				}
+
+				post.PublishAt = asString(value)
+			},
+		},
+	}
+}
+
+// PublishDueDrafts publishes the drafts on main whose publish_at time has
+// passed, opening a PR that moves each one to the posts directory and merging it
+func (handler *Handler) PublishDueDrafts() {
+	if !handler.Config.PublishAt {
+		return
+	}
+
+	handler = handler.withRepoConfig()
+
+	entries, err := handler.GithubClient.ListDirectory(
+		botGithub.ListDirectoryArgs{
+			Owner: handler.Owner,
+			Path:  handler.Config.Content.Dir(true),
+			Ref:   "main",
+			Repo:  handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Listing drafts to publish failed", "error", err)
+		return
+	}
+
+	now := time.Now()
+
+	for _, entry := range entries {
+		isPost := entry.GetType() == "file" && handler.Config.Content.IsPostFile(entry.GetPath())
+		if !isPost {
+			continue
+		}
+
+		content, _, err := handler.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: entry.GetPath(),
+				Owner:    handler.Owner,
+				Ref:      "main",
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err != nil {
+			handler.logger.Error("Reading draft failed", "path", entry.GetPath(), "error", err)
+			continue
+		}
+
+		post, err := handler.frontmatterFormat().Decode(content)
+		if err != nil || post.PublishAt == "" || !handler.isDraftPost(entry.GetPath(), content) {
+			continue
+		}
+
+		publishAt, err := parsePublishTime(post.PublishAt)
+		if err != nil {
+			handler.logger.Error("Reading publish_at failed", "path", entry.GetPath(), "error", err)
+			continue
+		}
+
+		if publishAt.After(now) {
+			continue
+		}
+
+		if err := handler.publishDraft(entry.GetPath(), content, post); err != nil {
+			handler.logger.Error("Publishing draft failed", "path", entry.GetPath(), "error", err)
+		}
+	}
+}
+
+// publishDraft moves a due draft to the posts directory in a new PR and merges it
+func (handler *Handler) publishDraft(filePath, content string, post *Post) error {
+	updatedContent, err := handler.frontmatterFormat().Update(content, func(post *Post) {
+		post.IsDraft = false
+		post.PublishAt = ""
+	})
+
+	if err != nil {
+		return fmt.Errorf("updating frontmatter: %w", err)
+	}
+
+	newFilename := handler.Config.Content.MovedPath(filePath, false)
+
+	if err := handler.checkPath(newFilename); err != nil {
+		return err
+	}
+
+	branchName, err := handler.GithubClient.CreateBranch(
+		botGithub.CreateBranchArgs{
+			BranchName: fmt.Sprintf("scheduled-publish-%s", post.Key),
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+		},
+	)
+
+	// an earlier run's PR that couldn't be merged is left for a human
+	if errors.Is(err, botGithub.ErrBranchInUse) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("creating branch: %w", err)
+	}
+
+	changes := []botGithub.FileChange{
+		{Content: updatedContent, MovedFrom: filePath, Path: newFilename},
+	}
+
+	if newFilename != filePath {
+		changes = append(changes, botGithub.FileChange{Delete: true, Path: filePath})
+	}
+
+	if _, err := handler.GithubClient.CommitFiles(
+		botGithub.CommitFilesArgs{
+			Branch:  branchName,
+			Changes: changes,
+			Message: fmt.Sprintf("Publish %s", post.Title),
+			Owner:   handler.Owner,
+			Repo:    handler.Repo,
+		},
+	); err != nil {
+		return fmt.Errorf("moving post: %w", err)
+	}
+
+	pullRequest, err := handler.GithubClient.CreatePullRequest(
+		botGithub.CreatePullRequestArgs{
+			Base: "main",
+			Body: fmt.Sprintf(
+				"🗓️ Publishing `%s`, which was scheduled for %s.",
+				filePath,
+				post.PublishAt,
+			),
+			Head:  fmt.Sprintf("%s:%s", handler.Owner, branchName),
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+			Title: fmt.Sprintf("Publish blog post: %s", post.Title),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("creating PR: %w", err)
+	}
+
+	if err := handler.GithubClient.MergePullRequest(
+		botGithub.MergePullRequestArgs{
+			Method:   botGithub.MergeMethodSquash,
+			Owner:    handler.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	); err != nil {
+		handler.common().CommentOnPR(
+			pullRequest.GetNumber(),
+			handler.common().CommandFailedComment(pullRequest.GetNumber(), "merge this post, which is due to be published", err),
+		)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package botblog
+
+import (
+	"strings"
+
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+)
+
+// wordsPerMinute is the reading speed reading times are estimated with
+const wordsPerMinute = 200
+
+// WordCount counts the words a reader sees, skipping the {.class} attribute
+// lines that style the following paragraph
+func (p *Post) WordCount() int {
+	count := 0
+
+	for line := range strings.SplitSeq(p.Content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "{.") && strings.HasSuffix(trimmed, "}") {
+			continue
+		}
+
+		count += len(strings.Fields(trimmed))
+	}
+
+	return count
+}
+
+// ReadingTime estimates the minutes the post takes to read, rounded up
+func (p *Post) ReadingTime() int {
+	return max(1, (p.WordCount()+wordsPerMinute-1)/wordsPerMinute)
+}
+
+// readingStatsFields are the word count and reading time fields under the
+// repo's configured keys. They're worked out from the content each time the
+// frontmatter is written, so they're never read back
+func readingStatsFields(stats botConfig.ReadingStats) []frontmatterField {
+	fields := []frontmatterField{}
+	ignore := func(post *Post, value any) {}
+
+	if stats.WordCountKey != "" {
+		fields = append(fields, frontmatterField{
+			key:   stats.WordCountKey,
+			read:  func(post *Post) any { return post.WordCount() },
+			write: ignore,
+		})
+	}
+
+	if stats.ReadingTimeKey != "" {
+		fields = append(fields, frontmatterField{
+			key:   stats.ReadingTimeKey,
+			read:  func(post *Post) any { return post.ReadingTime() },
+			write: ignore,
+		})
+	}
+
+	return fields
+}
@@ -0,0 +1,17 @@
+package botblog
+
+import "github.com/google/go-github/v57/github"
+
+// handleReviewSubmitted takes a maintainer's approval of a bot PR as the go
+// ahead to make it ready and, with auto-merge on, merge it
+func (handler *Handler) handleReviewSubmitted(pullRequest *github.PullRequest, review *github.PullRequestReview) {
+	if handler.common().IsMaintainerApproval(pullRequest, review) {
+		handler.common().HandleApproval(pullRequest, review)
+	}
+}
+
+// MergeApprovedPRs merges approved bot PRs whose checks have passed since the
+// approval came in
+func (handler *Handler) MergeApprovedPRs() {
+	handler.common().MergeApprovedPRs()
+}
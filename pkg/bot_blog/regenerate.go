@@ -0,0 +1,146 @@
+package botblog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+)
+
+const regenerateCommand = "/regenerate"
+
+// handleRegenerateCommand throws away the post on a PR and writes it again
+// from the original issue, with optional extra guidance
+func (handler *Handler) handleRegenerateCommand(prNumber int, guidance string) {
+	if err := handler.regeneratePost(prNumber, guidance); err != nil {
+		handler.common().CommentOnPR(prNumber, handler.common().CommandFailedComment(prNumber, "regenerate the post", err))
+		return
+	}
+
+	handler.common().CommentOnPR(prNumber, "🔄 Regenerated the post from scratch.")
+}
+
+func (handler *Handler) regeneratePost(prNumber int, guidance string) error {
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("getting PR: %w", err)
+	}
+
+	issueNumber := botHandler.SourceIssueNumber(pullRequest.GetBody())
+	if issueNumber == 0 {
+		return &botErrors.ValidationError{Message: "This PR wasn't generated from a blog post issue."}
+	}
+
+	issue, err := handler.GithubClient.GetIssue(
+		botGithub.GetIssueArgs{
+			IssueNumber: issueNumber,
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("getting PR files: %w", err)
+	}
+
+	branch := pullRequest.GetHead().GetRef()
+
+	for _, file := range files {
+		// translations are left as they are; /translate again to refresh them
+		if !handler.Config.Content.IsPostFile(file.GetFilename()) || isTranslationFile(file.GetFilename()) {
+			continue
+		}
+
+		if err := handler.checkPath(file.GetFilename()); err != nil {
+			return err
+		}
+
+		currentContent, sha, err := handler.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: file.GetFilename(),
+				Owner:    handler.Owner,
+				Ref:      branch,
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err != nil {
+			return fmt.Errorf("getting file content: %w", err)
+		}
+
+		request := ParseIssueForRequest(issue.GetTitle(), issue.GetBody())
+		request.Draft = handler.isDraftPost(file.GetFilename(), currentContent)
+
+		post, model := handler.generatePost(request, guidance)
+
+		// an empty model means the template fallback was used, which would
+		// replace a real post with a skeleton
+		if model == "" {
+			return fmt.Errorf("AI generation failed")
+		}
+
+		post.ApplyLicensing(handler.Config.Licensing)
+		postProblems := handler.checkPostContent(post)
+
+		// the post keeps its file and key even if the AI picked a different title
+		post.Key = strings.TrimSuffix(path.Base(file.GetFilename()), path.Ext(file.GetFilename()))
+		commitSHA, err := handler.GithubClient.UpdateFile(
+			botGithub.UpdateFileArgs{
+				Branch:   branch,
+				Content:  handler.frontmatterFormat().Render(post),
+				Filename: file.GetFilename(),
+				Message:  "Regenerate blog post",
+				Owner:    handler.Owner,
+				Repo:     handler.Repo,
+				Sha:      sha,
+			},
+		)
+
+		if err != nil {
+			return fmt.Errorf("updating file: %w", err)
+		}
+
+		handler.common().RecordBotCommit(prNumber, branch, commitSHA)
+		handler.common().DispatchWorkflow(prNumber, branch)
+
+		if err := handler.GithubClient.UpdatePullRequest(
+			botGithub.UpdatePullRequestArgs{
+				Body:     handler.generatePRBody(issue, request, post, model) + handler.previewSection(prNumber, branch, post.Key),
+				Owner:    handler.Owner,
+				PrNumber: prNumber,
+				Repo:     handler.Repo,
+			},
+		); err != nil {
+			return err
+		}
+
+		handler.reportPostProblems(prNumber, postProblems)
+		handler.commentPreviewLink(prNumber, branch, file.GetFilename())
+
+		return nil
+	}
+
+	return &botErrors.ValidationError{Message: "I couldn't find a blog post in this PR."}
+}
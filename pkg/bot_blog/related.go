@@ -0,0 +1,131 @@
+package botblog
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+)
+
+// maxRelatedCandidates caps how many existing posts are read for their
+// titles, newest names first, so a large archive doesn't mean hundreds of
+// API calls per post
+const maxRelatedCandidates = 50
+
+// addRelatedReading links the post to related published posts in a
+// "Related reading" section at the end. It's best effort: a failure leaves
+// the post as it was
+func (handler *Handler) addRelatedReading(post *Post) {
+	if !handler.Config.RelatedPosts {
+		return
+	}
+
+	candidates, err := handler.listPublishedPosts(post.Key)
+	if err != nil {
+		handler.logger.Error("Listing posts to link to failed", "title", post.Title, "error", err)
+		return
+	}
+
+	keys, err := handler.AiClient.SuggestRelatedPosts(
+		&botAi.RelatedPostsRequest{
+			Candidates: candidates,
+			Content:    post.Content,
+			Title:      post.Title,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Picking related posts failed", "title", post.Title, "error", err)
+		return
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	var section strings.Builder
+
+	section.WriteString("## Related reading\n\n")
+
+	for _, key := range keys {
+		index := slices.IndexFunc(candidates, func(candidate botAi.ExistingPost) bool {
+			return candidate.Key == key
+		})
+
+		fmt.Fprintf(&section, "- [%s](%s)\n", candidates[index].Title, handler.Config.Content.URL(key))
+	}
+
+	post.Content = strings.TrimRight(post.Content, "\n") + "\n\n" + section.String()
+}
+
+// listPublishedPosts reads the keys and titles of the published posts on
+// main, leaving out translations and the post being written
+func (handler *Handler) listPublishedPosts(excludeKey string) ([]botAi.ExistingPost, error) {
+	entries, err := handler.GithubClient.ListDirectory(
+		botGithub.ListDirectoryArgs{
+			Owner: handler.Owner,
+			Path:  handler.Config.Content.Dir(false),
+			Ref:   "main",
+			Repo:  handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filenames := []string{}
+
+	for _, entry := range entries {
+		isOriginalPost := entry.GetType() == "file" &&
+			handler.Config.Content.IsPostFile(entry.GetPath()) &&
+			!isTranslationFile(entry.GetPath())
+
+		if isOriginalPost {
+			filenames = append(filenames, entry.GetPath())
+		}
+	}
+
+	slices.Sort(filenames)
+	slices.Reverse(filenames)
+
+	format := handler.frontmatterFormat()
+	posts := []botAi.ExistingPost{}
+
+	for _, filename := range filenames[:min(len(filenames), maxRelatedCandidates)] {
+		content, _, err := handler.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: filename,
+				Owner:    handler.Owner,
+				Ref:      "main",
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err != nil {
+			handler.logger.Error("Reading post failed", "path", filename, "error", err)
+			continue
+		}
+
+		existing, err := format.Decode(content)
+		if err != nil || existing.IsDraft {
+			continue
+		}
+
+		// formats like Hugo's make the slug optional, defaulting to the filename
+		if existing.Key == "" {
+			existing.Key = strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+		}
+
+		if existing.Key == excludeKey {
+			continue
+		}
+
+		posts = append(posts, botAi.ExistingPost{Key: existing.Key, Title: existing.Title})
+	}
+
+	return posts, nil
+}
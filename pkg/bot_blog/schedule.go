@@ -0,0 +1,187 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+)
+
+// publishTimeLayouts are the accepted formats for "/publish at ..."
+var publishTimeLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// timeZoneOffsets maps common zone abbreviations to their UTC offset in hours,
+// since time.Parse can't resolve abbreviations outside the local zone
+var timeZoneOffsets = map[string]int{
+	"UTC": 0, "GMT": 0,
+	"PST": -8, "PDT": -7,
+	"MST": -7, "MDT": -6,
+	"CST": -6, "CDT": -5,
+	"EST": -5, "EDT": -4,
+	"CET": 1, "CEST": 2,
+}
+
+// handlePublishCommand publishes a blog PR straight away, or schedules or
+// cancels a timed publish of it
+func (handler *Handler) handlePublishCommand(prNumber int, argument, author string) {
+	lowerArgument := strings.ToLower(argument)
+
+	if lowerArgument == "" {
+		handler.publishNow(prNumber)
+		return
+	}
+
+	if lowerArgument == "cancel" {
+		if err := handler.Store.CancelScheduledPublish(handler.common().FullRepoName(), prNumber); err != nil {
+			handler.logger.Error("Cancelling scheduled publish failed", "error", err)
+		}
+
+		handler.common().CommentOnPR(prNumber, "🗓️ Scheduled publish cancelled.")
+		return
+	}
+
+	if !strings.HasPrefix(lowerArgument, "at ") {
+		handler.common().CommentOnPR(prNumber, "To schedule this post, comment `/publish at 2024-08-01 09:00 PST` (or `/publish cancel`), or `/publish` to publish it now.")
+		return
+	}
+
+	publishAt, err := parsePublishTime(strings.TrimSpace(argument[3:]))
+	if err != nil {
+		handler.common().CommentOnPR(prNumber, handler.common().CommandFailedComment(prNumber, "read that time", err))
+		return
+	}
+
+	if publishAt.Before(time.Now()) {
+		handler.common().CommentOnPR(prNumber, "That time is already in the past. Comment `/publish` to publish right away.")
+		return
+	}
+
+	if err := handler.Store.SchedulePublish(
+		botState.SchedulePublishArgs{
+			PrNumber:    prNumber,
+			PublishAt:   publishAt,
+			Repo:        handler.common().FullRepoName(),
+			RequestedBy: author,
+		},
+	); err != nil {
+		handler.logger.Error("Scheduling publish failed", "error", err)
+		handler.common().CommentOnPR(prNumber, "Sorry, I couldn't save that schedule.")
+		return
+	}
+
+	handler.common().CommentOnPR(
+		prNumber,
+		fmt.Sprintf(
+			"🗓️ Scheduled to publish and merge at %s (%s). Comment `/publish cancel` to undo.",
+			publishAt.Format("2006-01-02 15:04 MST"),
+			publishAt.UTC().Format("2006-01-02 15:04 UTC"),
+		),
+	)
+}
+
+// parsePublishTime reads "2024-08-01 09:00 PST" style times; without a zone, UTC is assumed
+func parsePublishTime(text string) (time.Time, error) {
+	location := time.UTC
+	fields := strings.Fields(text)
+
+	if len(fields) > 1 {
+		zoneName := strings.ToUpper(fields[len(fields)-1])
+
+		if offset, isKnownZone := timeZoneOffsets[zoneName]; isKnownZone {
+			location = time.FixedZone(zoneName, offset*60*60)
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	dateText := strings.Join(fields, " ")
+
+	for _, layout := range publishTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, dateText, location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, dateText); err == nil {
+		return parsed, nil
+	}
+
+	return time.Time{}, &botErrors.ValidationError{Message: fmt.Sprintf("Use a time like `2024-08-01 09:00 PST` rather than %q.", text)}
+}
+
+// PublishScheduledPosts publishes and merges every PR whose scheduled time has passed
+func (handler *Handler) PublishScheduledPosts() {
+	due := handler.Store.DueScheduledPublishes(handler.common().FullRepoName(), time.Now())
+	if len(due) == 0 {
+		return
+	}
+
+	handler = handler.withRepoConfig()
+
+	for _, schedule := range due {
+		if err := handler.publishPost(schedule.PrNumber); err != nil {
+			handler.logger.Error("Publishing scheduled PR failed", "pr", schedule.PrNumber, "error", err)
+			handler.common().CommentOnPR(schedule.PrNumber, handler.common().CommandFailedComment(schedule.PrNumber, "publish this post as scheduled", err))
+		} else {
+			handler.common().CommentOnPR(schedule.PrNumber, "🚀 Published and merged as scheduled.")
+		}
+
+		if err := handler.Store.CancelScheduledPublish(handler.common().FullRepoName(), schedule.PrNumber); err != nil {
+			handler.logger.Error("Clearing scheduled publish failed", "error", err)
+		}
+	}
+}
+
+// publishNow publishes and merges a PR on a bare /publish, dropping any
+// publish scheduled for it
+func (handler *Handler) publishNow(prNumber int) {
+	if err := handler.publishPost(prNumber); err != nil {
+		handler.logger.Error("Publishing PR failed", "pr", prNumber, "error", err)
+		handler.common().CommentOnPR(prNumber, handler.common().CommandFailedComment(prNumber, "publish this post", err))
+		return
+	}
+
+	if err := handler.Store.CancelScheduledPublish(handler.common().FullRepoName(), prNumber); err != nil {
+		handler.logger.Error("Clearing scheduled publish failed", "error", err)
+	}
+
+	handler.common().CommentOnPR(prNumber, "🚀 Published and merged.")
+}
+
+// publishPost moves the post out of drafts and merges the PR
+func (handler *Handler) publishPost(prNumber int) error {
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if pullRequest.GetState() != "open" {
+		return &botErrors.ValidationError{Message: "The PR is no longer open."}
+	}
+
+	if err := handler.handleDraftStatusChange(pullRequest, "publish"); err != nil {
+		return fmt.Errorf("publishing post: %w", err)
+	}
+
+	return handler.GithubClient.MergePullRequest(
+		botGithub.MergePullRequestArgs{
+			Method:   botGithub.MergeMethodSquash,
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+}
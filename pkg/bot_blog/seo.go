@@ -0,0 +1,52 @@
+package botblog
+
+import botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+
+// addSEOMetadata fills in the post's meta description and OpenGraph text
+// when the repo has fields for them, leaving them empty if the AI call fails
+func (handler *Handler) addSEOMetadata(post *Post) {
+	if !handler.Config.SEOFields.IsEnabled() {
+		return
+	}
+
+	metadata, err := handler.AiClient.GenerateSEOMetadata(post.Title, post.Content)
+	if err != nil {
+		handler.logger.Error("Generating SEO metadata failed", "title", post.Title, "error", err)
+		return
+	}
+
+	post.MetaDescription = metadata.MetaDescription
+	post.OpenGraphDescription = metadata.OpenGraphDescription
+	post.OpenGraphTitle = metadata.OpenGraphTitle
+}
+
+// seoFields are the SEO metadata fields under the repo's configured keys
+func seoFields(keys botConfig.SEOFields) []frontmatterField {
+	fields := []frontmatterField{}
+
+	if keys.MetaDescription != "" {
+		fields = append(fields, frontmatterField{
+			key:   keys.MetaDescription,
+			read:  func(post *Post) any { return omitEmpty(post.MetaDescription) },
+			write: func(post *Post, value any) { post.MetaDescription = asString(value) },
+		})
+	}
+
+	if keys.OpenGraphTitle != "" {
+		fields = append(fields, frontmatterField{
+			key:   keys.OpenGraphTitle,
+			read:  func(post *Post) any { return omitEmpty(post.OpenGraphTitle) },
+			write: func(post *Post, value any) { post.OpenGraphTitle = asString(value) },
+		})
+	}
+
+	if keys.OpenGraphDescription != "" {
+		fields = append(fields, frontmatterField{
+			key:   keys.OpenGraphDescription,
+			read:  func(post *Post) any { return omitEmpty(post.OpenGraphDescription) },
+			write: func(post *Post, value any) { post.OpenGraphDescription = asString(value) },
+		})
+	}
+
+	return fields
+}
@@ -0,0 +1,260 @@
+package botblog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// maxSnippetFixes bounds the AI calls spent repairing one post's examples
+const maxSnippetFixes = 5
+
+// snippetVetTimeout bounds a single go vet run on an example
+const snippetVetTimeout = time.Minute
+
+// declarationStarts begin top-level Go code, as opposed to statements
+var declarationStarts = []string{"const ", "const(", "func ", "import ", "import(", "type ", "var ", "var("}
+
+// goSnippet is a fenced Go block in a post, with the line indexes of its
+// code between the fences
+type goSnippet struct {
+	Code  string
+	End   int // index of the closing fence line
+	Start int // index of the first code line
+}
+
+// checkSnippets has the AI repair Go examples in a post that don't parse,
+// or that go vet flags when the repo turns that on. An example the AI can't
+// fix is left as written
+func (handler *Handler) checkSnippets(post *Post) {
+	if !handler.Config.Snippets.Validate {
+		return
+	}
+
+	snippets := findGoSnippets(post.Content)
+	fixes := 0
+
+	// last to first, so fixing one doesn't shift the lines of those before it
+	for index := len(snippets) - 1; index >= 0 && fixes < maxSnippetFixes; index-- {
+		snippet := snippets[index]
+
+		problem := handler.snippetProblem(snippet.Code)
+		if problem == nil {
+			continue
+		}
+
+		fixes++
+
+		fixed, err := handler.AiClient.FixGoSnippet(snippet.Code, problem.Error(), handler.snippetProblem)
+		if err != nil {
+			handler.logger.Warn("Fixing a Go example failed, leaving it as written", "title", post.Title, "error", err)
+			continue
+		}
+
+		post.Content = replaceSnippet(post.Content, snippet, fixed)
+	}
+}
+
+// snippetProblem runs the configured checks on one example
+func (handler *Handler) snippetProblem(code string) error {
+	if err := checkGoSnippet(code); err != nil {
+		return err
+	}
+
+	if handler.Config.Snippets.Vet {
+		return vetGoSnippet(code, handler.logger)
+	}
+
+	return nil
+}
+
+// findGoSnippets lists the complete ```go blocks in markdown content
+func findGoSnippets(content string) []goSnippet {
+	snippets := []goSnippet{}
+	lines := strings.Split(content, "\n")
+	start := -1
+	isInFence := false
+
+	for index, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+
+		if isInFence {
+			if start >= 0 {
+				snippets = append(snippets, goSnippet{
+					Code:  strings.Join(lines[start:index], "\n"),
+					End:   index,
+					Start: start,
+				})
+			}
+
+			isInFence = false
+			start = -1
+			continue
+		}
+
+		isInFence = true
+
+		// "```go", "```golang" or "```go title=main.go"
+		info := strings.Fields(strings.TrimLeft(trimmed, "`"))
+		if len(info) > 0 && (strings.EqualFold(info[0], "go") || strings.EqualFold(info[0], "golang")) {
+			start = index + 1
+		}
+	}
+
+	return snippets
+}
+
+// replaceSnippet swaps a snippet's code for new code, keeping its fences
+func replaceSnippet(content string, snippet goSnippet, code string) string {
+	lines := strings.Split(content, "\n")
+	replaced := append([]string{}, lines[:snippet.Start]...)
+	replaced = append(replaced, strings.TrimRight(code, "\n"))
+	replaced = append(replaced, lines[snippet.End:]...)
+
+	return strings.Join(replaced, "\n")
+}
+
+// checkGoSnippet makes sure an example parses as a file, as top-level
+// declarations or as statements in a function body, since posts show all three
+func checkGoSnippet(code string) error {
+	trimmed := strings.TrimSpace(code)
+
+	if trimmed == "" {
+		return nil
+	}
+
+	if hasPackageClause(trimmed) {
+		return parseGoSource(code)
+	}
+
+	asDeclarations := parseGoSource("package snippet\n\n" + code)
+	if asDeclarations == nil {
+		return nil
+	}
+
+	asStatements := parseGoSource("package snippet\n\nfunc _() {\n" + code + "\n}\n")
+	if asStatements == nil {
+		return nil
+	}
+
+	if hasDeclarationStart(trimmed) {
+		return asDeclarations
+	}
+
+	return asStatements
+}
+
+// parseGoSource reports why source doesn't parse as a Go file
+func parseGoSource(source string) error {
+	if _, err := parser.ParseFile(token.NewFileSet(), "snippet.go", source, parser.AllErrors); err != nil {
+		return fmt.Errorf("the example doesn't parse: %w", err)
+	}
+
+	return nil
+}
+
+// hasPackageClause reports whether an example is a whole file, skipping any
+// leading comments
+func hasPackageClause(code string) bool {
+	for line := range strings.SplitSeq(code, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		return strings.HasPrefix(trimmed, "package ")
+	}
+
+	return false
+}
+
+func hasDeclarationStart(code string) bool {
+	for _, start := range declarationStarts {
+		if strings.HasPrefix(code, start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// vetGoSnippet runs go vet on a whole-file example in a throwaway module.
+// Examples that aren't whole files, or that import packages outside the
+// standard library, can't be vetted on their own and are skipped, as are
+// examples the checker itself fails on, which are logged to logger
+func vetGoSnippet(code string, logger *slog.Logger) error {
+	if !hasPackageClause(code) {
+		return nil
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "snippet.go", code, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+
+	for _, importSpec := range file.Imports {
+		importPath, _ := strconv.Unquote(importSpec.Path.Value)
+
+		if strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+			return nil
+		}
+	}
+
+	workDir, err := os.MkdirTemp("", "bot-snippet-*")
+	if err != nil {
+		logger.Error("Creating a directory to vet an example in failed", "error", err)
+		return nil
+	}
+
+	defer os.RemoveAll(workDir)
+
+	files := map[string]string{
+		"go.mod":  "module snippet\n",
+		"main.go": code,
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(content), 0o644); err != nil {
+			logger.Error("Writing an example to vet failed", "error", err)
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snippetVetTimeout)
+	defer cancel()
+
+	command := exec.CommandContext(ctx, "go", "vet", ".")
+	command.Dir = workDir
+	command.Env = sharedUtils.FilterEnvironment(sharedUtils.SandboxEnvironment)
+
+	output, err := command.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+
+	var exitError *exec.ExitError
+
+	// a missing go binary or a timeout says nothing about the example
+	if !errors.As(err, &exitError) || ctx.Err() != nil {
+		logger.Error("Vetting an example failed", "error", err)
+		return nil
+	}
+
+	return fmt.Errorf("go vet reported:\n%s", sharedUtils.TruncateText(string(output), 1500))
+}
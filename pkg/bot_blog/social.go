@@ -0,0 +1,189 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botSyndicate "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_syndicate"
+	"github.com/google/go-github/v57/github"
+)
+
+// socialNetworkNames are how the networks are written in comments
+var socialNetworkNames = map[string]string{
+	botAi.SocialNetworkBluesky:  "Bluesky",
+	botAi.SocialNetworkMastodon: "Mastodon",
+	botAi.SocialNetworkX:        "X",
+}
+
+// announcePublishedPost drafts social posts for a post a merged PR published,
+// posting the first draft to each configured account, and lists them on the PR
+func (handler *Handler) announcePublishedPost(pullRequest *github.PullRequest, post *Post) {
+	posts, err := handler.AiClient.WriteSocialPosts(
+		&botAi.SocialPostsRequest{
+			Content: post.Content,
+			Summary: post.Summary,
+			Tags:    post.Tags,
+			Title:   post.Title,
+			URL:     handler.absolutePostURL(post.Key),
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Writing social posts failed", "pr", pullRequest.GetNumber(), "error", err)
+		return
+	}
+
+	handler.common().CommentOnPR(pullRequest.GetNumber(), formatSocialPosts(post.Title, posts, handler.postAnnouncements(posts)))
+}
+
+// absolutePostURL returns where a post is served, or "" when the configured
+// post URL is only a path and so can't be shared
+func (handler *Handler) absolutePostURL(key string) string {
+	link := handler.Config.Content.URL(key)
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return ""
+	}
+
+	return link
+}
+
+// newlyPublishedPost finds the post a merged PR published: one it added to
+// or moved into the posts directory, or one whose draft flag it cleared.
+// It returns nil when the PR published nothing new
+func (handler *Handler) newlyPublishedPost(pullRequest *github.PullRequest) (*Post, error) {
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting PR files: %w", err)
+	}
+
+	for _, file := range files {
+		filePath := file.GetFilename()
+
+		isCandidate := handler.Config.Content.IsPostFile(filePath) &&
+			!handler.Config.Content.IsDraftFile(filePath) &&
+			!isTranslationFile(filePath) &&
+			file.GetStatus() != "removed"
+
+		// with drafts in their own directory, an edit to a file already in the posts directory publishes nothing
+		isEdit := file.GetStatus() == "modified"
+		if !isCandidate || (isEdit && handler.Config.Content.HasDraftsDir()) {
+			continue
+		}
+
+		content, _, err := handler.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: filePath,
+				Owner:    handler.Owner,
+				Ref:      pullRequest.GetMergeCommitSHA(),
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filePath, err)
+		}
+
+		if handler.isDraftPost(filePath, content) {
+			continue
+		}
+
+		if isEdit {
+			before, _, err := handler.GithubClient.GetFileContent(
+				botGithub.GetFileContentArgs{
+					Filename: filePath,
+					Owner:    handler.Owner,
+					Ref:      pullRequest.GetBase().GetSHA(),
+					Repo:     handler.Repo,
+				},
+			)
+
+			if err != nil || !handler.isDraftPost(filePath, before) {
+				continue
+			}
+		}
+
+		return handler.frontmatterFormat().Decode(content)
+	}
+
+	return nil, nil
+}
+
+// postAnnouncements posts the first draft for each network with an account
+// configured, returning a line on how each went
+func (handler *Handler) postAnnouncements(posts botAi.SocialPosts) map[string]string {
+	results := map[string]string{}
+
+	for _, network := range botAi.SocialNetworks {
+		announcer := handler.socialAnnouncer(network)
+		if announcer == nil || len(posts[network]) == 0 {
+			continue
+		}
+
+		postURL, err := announcer.Announce(posts[network][0])
+		if err != nil {
+			handler.logger.Error("Announcing failed", "network", network, "error", err)
+			results[network] = "⚠️ I couldn't post the first one. " + botErrors.UserMessageOrLogs(err)
+			continue
+		}
+
+		results[network] = "✅ Posted the first one: " + postURL
+	}
+
+	return results
+}
+
+// formatSocialPosts lists the drafts for each network, each in its own block
+// so it copies cleanly, with how posting them went
+func formatSocialPosts(title string, posts botAi.SocialPosts, results map[string]string) string {
+	var comment strings.Builder
+
+	comment.WriteString(fmt.Sprintf("📣 **Announcement drafts** for \"%s\":\n", title))
+
+	for _, network := range botAi.SocialNetworks {
+		if len(posts[network]) == 0 {
+			continue
+		}
+
+		comment.WriteString(fmt.Sprintf(
+			"\n**%s** (up to %d characters)\n",
+			socialNetworkNames[network],
+			botAi.SocialPostLimits[network],
+		))
+
+		for _, post := range posts[network] {
+			comment.WriteString(fmt.Sprintf("\n```text\n%s\n```\n", post))
+		}
+
+		if result := results[network]; result != "" {
+			comment.WriteString("\n" + result + "\n")
+		}
+	}
+
+	return comment.String()
+}
+
+// socialAnnouncer returns the account configured for a network, or nil
+func (handler *Handler) socialAnnouncer(network string) botSyndicate.Announcer {
+	social := handler.Config.Social
+
+	switch {
+	case network == botAi.SocialNetworkBluesky && social.BlueskyHandle != "" && social.BlueskyPassword != "":
+		return botSyndicate.NewBlueskyAnnouncer(social.BlueskyHandle, social.BlueskyPassword)
+	case network == botAi.SocialNetworkMastodon && social.MastodonServer != "" && social.MastodonToken != "":
+		return botSyndicate.NewMastodonAnnouncer(social.MastodonServer, social.MastodonToken)
+	case network == botAi.SocialNetworkX && social.XToken != "":
+		return botSyndicate.NewXAnnouncer(social.XToken)
+	default:
+		return nil
+	}
+}
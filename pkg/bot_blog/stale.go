@@ -0,0 +1,167 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// staleReminderMarker tags the bot's reminder comment, so the next run can
+// find it and start the grace period from it
+const staleReminderMarker = "<!-- bot:stale-reminder -->"
+
+// day is the unit stale PR settings are counted in
+const day = 24 * time.Hour
+
+// HandleStalePRs reminds reviewers about bot PRs with no human activity and
+// closes them, deleting their branches, if the reminder goes unanswered
+func (handler *Handler) HandleStalePRs() {
+	policy := handler.Config.StalePRs
+
+	if policy.ReminderAfterDays <= 0 {
+		return
+	}
+
+	pullRequests, err := handler.GithubClient.ListOpenPullRequests(
+		botGithub.ListOpenPullRequestsArgs{
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Listing PRs to check for staleness failed", "error", err)
+		return
+	}
+
+	for _, pullRequest := range pullRequests {
+		if !handler.Store.IsBotPullRequest(handler.common().FullRepoName(), pullRequest.GetNumber()) {
+			continue
+		}
+
+		if err := handler.checkStalePR(pullRequest); err != nil {
+			handler.logger.Error("Checking PR for staleness failed", "pr", pullRequest.GetNumber(), "error", err)
+		}
+	}
+}
+
+// checkStalePR posts the reminder or closes the PR, depending on how long
+// it's been since a human last touched it
+func (handler *Handler) checkStalePR(pullRequest *github.PullRequest) error {
+	prNumber := pullRequest.GetNumber()
+	policy := handler.Config.StalePRs
+
+	lastHumanActivity, reminderAt, err := handler.prActivity(pullRequest)
+	if err != nil {
+		return err
+	}
+
+	isReminded := reminderAt.After(lastHumanActivity)
+
+	if !isReminded {
+		if time.Since(lastHumanActivity) < time.Duration(policy.ReminderAfterDays)*day {
+			return nil
+		}
+
+		closing := "Comment here to keep it open."
+		if policy.CloseAfterDays > 0 {
+			closing = fmt.Sprintf(
+				"I'll close it and delete its branch in %d days unless someone comments on or reviews it.",
+				policy.CloseAfterDays,
+			)
+		}
+
+		handler.common().CommentOnPR(prNumber, fmt.Sprintf(
+			"%s\n👋 This PR hasn't had any activity for %d days. %s",
+			staleReminderMarker,
+			policy.ReminderAfterDays,
+			closing,
+		))
+
+		return nil
+	}
+
+	if policy.CloseAfterDays <= 0 || time.Since(reminderAt) < time.Duration(policy.CloseAfterDays)*day {
+		return nil
+	}
+
+	handler.common().CommentOnPR(prNumber, "🧹 Closing this PR after no activity since the reminder. Reopen it or open a new request to pick it back up.")
+
+	closed, err := handler.GithubClient.ClosePullRequest(
+		botGithub.ClosePullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	// the closed webhook does the same, and whichever runs second is skipped
+	handler.handlePRClosed(closed)
+
+	return nil
+}
+
+// prActivity finds when a human last commented on or reviewed a PR, and
+// when the bot last posted a stale reminder on it
+func (handler *Handler) prActivity(pullRequest *github.PullRequest) (time.Time, time.Time, error) {
+	lastHumanActivity := pullRequest.GetCreatedAt().Time
+	reminderAt := time.Time{}
+
+	comments, err := handler.GithubClient.ListIssueComments(
+		botGithub.ListIssueCommentsArgs{
+			IssueNumber: pullRequest.GetNumber(),
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	for _, comment := range comments {
+		createdAt := comment.GetCreatedAt().Time
+
+		switch {
+		case strings.Contains(comment.GetBody(), staleReminderMarker):
+			reminderAt = latest(reminderAt, createdAt)
+		case !handler.Config.IsBotSender(comment.GetUser().GetLogin()):
+			lastHumanActivity = latest(lastHumanActivity, createdAt)
+		}
+	}
+
+	reviews, err := handler.GithubClient.ListPullRequestReviews(
+		botGithub.ListPullRequestReviewsArgs{
+			Owner:    handler.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	for _, review := range reviews {
+		if !handler.Config.IsBotSender(review.GetUser().GetLogin()) {
+			lastHumanActivity = latest(lastHumanActivity, review.GetSubmittedAt().Time)
+		}
+	}
+
+	return lastHumanActivity, reminderAt, nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+
+	return a
+}
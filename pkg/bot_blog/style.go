@@ -0,0 +1,29 @@
+package botblog
+
+import (
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+)
+
+// parseStyle reads a style directive, accepting "deep dive" or "deep_dive" for
+// "deep-dive", and returns "" when it isn't a known preset
+func parseStyle(value string) string {
+	style := strings.ToLower(strings.TrimSpace(value))
+	style = strings.NewReplacer(" ", "-", "_", "-").Replace(style)
+
+	if !botAi.IsStylePreset(style) {
+		return ""
+	}
+
+	return style
+}
+
+// styleName is the preset a request is written in, casual when it didn't pick one
+func styleName(request *BlogPostRequest) string {
+	if request.Style == "" {
+		return botAi.StyleCasual
+	}
+
+	return request.Style
+}
@@ -0,0 +1,148 @@
+package botblog
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Markers around a generated table of contents, so it's replaced rather than
+// repeated when the post is processed again
+const (
+	tocStartMarker = "<!-- toc -->"
+	tocEndMarker   = "<!-- /toc -->"
+)
+
+// minTOCHeadings is the fewest headings worth a table of contents
+const minTOCHeadings = 3
+
+// tocHeading is a "## " or "### " heading and the anchor a site links it by
+type tocHeading struct {
+	anchor string
+	level  int
+	text   string
+}
+
+// wantsTOC reports whether a request's post gets a table of contents, the
+// issue's toc: directive overriding the repo default
+func (handler *Handler) wantsTOC(request *BlogPostRequest) bool {
+	if request.TOC != nil {
+		return *request.TOC
+	}
+
+	return handler.Config.TableOfContents
+}
+
+// insertTableOfContents adds an anchor-linked list of the post's headings
+// after its intro, i.e. just before the first "## " heading that follows
+// some text. Posts with only a couple of headings are left alone
+func insertTableOfContents(content string) string {
+	content = removeTableOfContents(content)
+	headings := findTOCHeadings(content)
+
+	if len(headings) < minTOCHeadings {
+		return content
+	}
+
+	var toc strings.Builder
+
+	toc.WriteString(tocStartMarker + "\n**Contents**\n\n")
+
+	for _, heading := range headings {
+		indent := strings.Repeat("  ", heading.level-2)
+		fmt.Fprintf(&toc, "%s- [%s](#%s)\n", indent, heading.text, heading.anchor)
+	}
+
+	toc.WriteString(tocEndMarker + "\n\n")
+
+	lines := strings.SplitAfter(content, "\n")
+	isInFence := false
+	hasIntro := false
+
+	for index, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			isInFence = !isInFence
+		}
+
+		// a post that opens with a heading has its first section as the intro
+		if !isInFence && strings.HasPrefix(line, "## ") && hasIntro {
+			return strings.Join(lines[:index], "") + toc.String() + strings.Join(lines[index:], "")
+		}
+
+		hasIntro = hasIntro || strings.TrimSpace(line) != ""
+	}
+
+	return content
+}
+
+// removeTableOfContents drops a previously generated table of contents
+func removeTableOfContents(content string) string {
+	start := strings.Index(content, tocStartMarker)
+	end := strings.Index(content, tocEndMarker)
+
+	if start == -1 || end < start {
+		return content
+	}
+
+	return content[:start] + strings.TrimLeft(content[end+len(tocEndMarker):], "\n")
+}
+
+// findTOCHeadings lists the second and third level headings outside code
+// blocks, with anchors numbered the way GitHub and Hugo number repeats
+func findTOCHeadings(content string) []tocHeading {
+	headings := []tocHeading{}
+	anchorCounts := map[string]int{}
+	isInFence := false
+
+	for line := range strings.SplitSeq(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			isInFence = !isInFence
+			continue
+		}
+
+		level := 0
+
+		switch {
+		case isInFence:
+		case strings.HasPrefix(line, "## "):
+			level = 2
+		case strings.HasPrefix(line, "### "):
+			level = 3
+		}
+
+		if level == 0 {
+			continue
+		}
+
+		text := strings.TrimSpace(strings.TrimLeft(line, "#"))
+		anchor := headingAnchor(text)
+
+		if count := anchorCounts[anchor]; count > 0 {
+			anchorCounts[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, count)
+		} else {
+			anchorCounts[anchor] = 1
+		}
+
+		headings = append(headings, tocHeading{anchor: anchor, level: level, text: text})
+	}
+
+	return headings
+}
+
+// headingAnchor builds the id GitHub-flavored markdown gives a heading:
+// lowercased, punctuation dropped and spaces turned into dashes
+func headingAnchor(text string) string {
+	var anchor strings.Builder
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			anchor.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r):
+			anchor.WriteRune(r)
+		}
+	}
+
+	return anchor.String()
+}
@@ -0,0 +1,175 @@
+package botblog
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+)
+
+const translateCommand = "/translate"
+
+// languagePattern matches a language code like "es", "pt-BR" or "zh-Hant"
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// translationSuffixPattern matches the ".es" of a translated post's "my-post.es.md"
+var translationSuffixPattern = regexp.MustCompile(`\.[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// parseLanguage checks a language directive, returning "" when it isn't a
+// language code so the post stays in English
+func parseLanguage(value string) string {
+	language := strings.ToLower(strings.TrimSpace(value))
+
+	if !languagePattern.MatchString(language) {
+		return ""
+	}
+
+	return language
+}
+
+// translationPath is the sibling file a translation is written to, e.g.
+// "posts/my-post.md" becomes "posts/my-post.es.md"
+func translationPath(filePath, language string) string {
+	extension := path.Ext(filePath)
+
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(filePath, extension), language, extension)
+}
+
+// isTranslationFile reports whether a post file is itself a translation
+func isTranslationFile(filePath string) bool {
+	base := strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+
+	return translationSuffixPattern.MatchString(base)
+}
+
+// handleTranslateCommand adds a translated copy of each post on the PR
+func (handler *Handler) handleTranslateCommand(prNumber int, argument string) {
+	language := parseLanguage(argument)
+	if language == "" {
+		handler.common().CommentOnPR(prNumber, fmt.Sprintf("Sorry, `%s` isn't a language code I know. Try something like `%s es` or `%s pt-br`.", argument, translateCommand, translateCommand))
+		return
+	}
+
+	paths, err := handler.translatePosts(prNumber, language)
+	if err != nil {
+		handler.common().CommentOnPR(prNumber, handler.common().CommandFailedComment(prNumber, "translate the post", err))
+		return
+	}
+
+	handler.common().CommentOnPR(prNumber, fmt.Sprintf("🌐 Added the `%s` translation: `%s`", language, strings.Join(paths, "`, `")))
+}
+
+// translatePosts writes a translation next to every original post on the PR
+// in one commit, returning the paths it wrote
+func (handler *Handler) translatePosts(prNumber int, language string) ([]string, error) {
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting PR: %w", err)
+	}
+
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting PR files: %w", err)
+	}
+
+	branch := pullRequest.GetHead().GetRef()
+	format := handler.frontmatterFormat()
+	changes := []botGithub.FileChange{}
+	paths := []string{}
+
+	for _, file := range files {
+		filename := file.GetFilename()
+
+		isOriginal := handler.Config.Content.IsPostFile(filename) && !isTranslationFile(filename)
+		if !isOriginal || file.GetStatus() == "removed" {
+			continue
+		}
+
+		targetPath := translationPath(filename, language)
+
+		if err := handler.checkPath(targetPath); err != nil {
+			return nil, err
+		}
+
+		content, _, err := handler.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: filename,
+				Owner:    handler.Owner,
+				Ref:      branch,
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("getting file content: %w", err)
+		}
+
+		post, err := format.Decode(content)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filename, err)
+		}
+
+		translation, err := handler.AiClient.Translate(
+			&botAi.TranslateRequest{
+				Content:  post.Content,
+				Language: language,
+				Summary:  post.Summary,
+				Title:    post.Title,
+			},
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("translating %s: %w", filename, err)
+		}
+
+		// the translation keeps the original's key, so sites can pair them up
+		post.Content = translation.Body
+		post.Language = language
+		post.Summary = translation.Summary
+		post.Title = translation.Title
+
+		changes = append(changes, botGithub.FileChange{Content: format.Render(post), Path: targetPath})
+		paths = append(paths, targetPath)
+	}
+
+	if len(changes) == 0 {
+		return nil, &botErrors.ValidationError{Message: "I couldn't find a blog post in this PR."}
+	}
+
+	commitSHA, err := handler.GithubClient.CommitFiles(
+		botGithub.CommitFilesArgs{
+			Branch:  branch,
+			Changes: changes,
+			Message: fmt.Sprintf("Add %s translation of blog post", language),
+			Owner:   handler.Owner,
+			Repo:    handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("committing translation: %w", err)
+	}
+
+	handler.common().RecordBotCommit(prNumber, branch, commitSHA)
+	handler.common().DispatchWorkflow(prNumber, branch)
+
+	return paths, nil
+}
@@ -0,0 +1,72 @@
+package botblog
+
+import (
+	"fmt"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	"github.com/google/go-github/v57/github"
+)
+
+// CreateWriteUpPRArgs describes a drafted write-up of a merged code PR
+type CreateWriteUpPRArgs struct {
+	Content        string
+	Model          string
+	SourcePRNumber int
+	SourceRepo     string // owner/repo of the code PR
+	SourceURL      string
+	Title          string
+}
+
+// CreateWriteUpPR opens a blog post PR on the website repo for a merged code PR
+func (handler *Handler) CreateWriteUpPR(
+	args CreateWriteUpPRArgs,
+) (*github.PullRequest, error) {
+	handler = handler.withRepoConfig()
+
+	if err := handler.GithubClient.CheckRepositoryWritable(
+		botGithub.GetRepositoryArgs{
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf("Write-up: %s", args.Title)
+	tags := []string{"ai-generated", "write-up"}
+
+	post := NewPost(title, args.Title, tags, true)
+	post.Content = args.Content
+	handler.summarizePost(post)
+
+	if err := handler.claimPostKey(post); err != nil {
+		return nil, err
+	}
+
+	branchName := fmt.Sprintf("ai-write-up-%d", args.SourcePRNumber)
+	body := fmt.Sprintf(`🤖 AI-generated write-up of %s#%d
+
+**Title:** %s
+**File:** `+"`%s`"+`
+**Source:** %s
+
+This blog post was drafted by %s from the merged pull request's diff and discussion. Feel free to comment with any changes you'd like me to make!`,
+		args.SourceRepo,
+		args.SourcePRNumber,
+		post.Title,
+		handler.postFilePath(post),
+		args.SourceURL,
+		args.Model,
+	)
+
+	return handler.openPostPR(
+		openPostPRArgs{
+			Body:        body,
+			BranchName:  branchName,
+			ContentType: botState.ContentTypeWriteUp,
+			Message:     "Add AI-generated write-up",
+			Post:        post,
+		},
+	)
+}
@@ -0,0 +1,12 @@
+package botcode
+
+import (
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+)
+
+// budgetExhaustedComment explains why a request was turned down; code
+// requests aren't kept, so it has to be made again
+func (handler *Handler) budgetExhaustedComment(err error) string {
+	return handler.replies.Render("code_budget_exhausted", botReplies.Data{"Reason": botHandler.BudgetReason(err)})
+}
@@ -0,0 +1,329 @@
+package botcode
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+	"github.com/google/go-github/v57/github"
+)
+
+// changelogPath is the file release notes are added to
+const changelogPath = "CHANGELOG.md"
+
+// unreleasedHeading starts the section of changes since the last tag
+const unreleasedHeading = "## Unreleased"
+
+// changelogTitlePrefix marks the bot's changelog PRs, which are left out of later notes
+const changelogTitlePrefix = "Changelog:"
+
+var (
+	// versionTagPattern matches release tags like "v1.2.3" or "1.2.3"
+	versionTagPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)$`)
+
+	// mergedPRPatterns find the PR number in squash ("Title (#12)") and merge
+	// ("Merge pull request #12 from ...") commit subjects
+	mergedPRPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\(#(\d+)\)$`),
+		regexp.MustCompile(`^Merge pull request #(\d+)`),
+	}
+)
+
+// OpenChangelogPR asks the AI for release notes covering the PRs merged since
+// the last tag and opens a PR adding them to CHANGELOG.md, returning its number
+func (handler *Handler) OpenChangelogPR() (int, error) {
+	tag, err := handler.latestTag()
+	if err != nil {
+		return 0, err
+	}
+
+	pullRequests, err := handler.mergedSince(tag)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(pullRequests) == 0 {
+		return 0, fmt.Errorf("no pull requests merged since %s", tag)
+	}
+
+	notes, err := handler.AiClient.WriteReleaseNotes(
+		&botAi.ReleaseNotesRequest{
+			PullRequests: pullRequests,
+			Since:        tag,
+		},
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("writing release notes: %w", err)
+	}
+
+	changelog, _, err := handler.GithubClient.GetFileContent(
+		botGithub.GetFileContentArgs{
+			Filename: changelogPath,
+			Owner:    handler.Owner,
+			Ref:      "main",
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil && !errors.Is(err, botGithub.ErrFileNotFound) {
+		return 0, err
+	}
+
+	branchName, err := handler.GithubClient.CreateBranch(
+		botGithub.CreateBranchArgs{
+			BranchName: "ai-changelog-" + sharedUtils.Slugify(tag, "-"),
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := handler.GithubClient.CommitFiles(
+		botGithub.CommitFilesArgs{
+			Branch: branchName,
+			Changes: []botGithub.FileChange{
+				{
+					Content: addUnreleasedNotes(changelog, tag, notes),
+					Path:    changelogPath,
+				},
+			},
+			Message: fmt.Sprintf("Add release notes for changes since %s", tag),
+			Owner:   handler.Owner,
+			Repo:    handler.Repo,
+		},
+	); err != nil {
+		return 0, fmt.Errorf("committing changelog: %w", err)
+	}
+
+	pullRequest, err := handler.GithubClient.CreatePullRequest(
+		botGithub.CreatePullRequestArgs{
+			Base:  "main",
+			Body:  changelogPRBody(tag, pullRequests, notes),
+			Draft: handler.Config.DraftPRs,
+			Head:  fmt.Sprintf("%s:%s", handler.Owner, branchName),
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+			Title: fmt.Sprintf("%s release notes since %s", changelogTitlePrefix, tag),
+		},
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("creating PR: %w", err)
+	}
+
+	if err := handler.Store.RecordPullRequestOpened(
+		botState.RecordPullRequestOpenedArgs{
+			ContentType:   botState.ContentTypeCode,
+			Number:        pullRequest.GetNumber(),
+			PromptVersion: botAi.PromptVersion,
+			Repo:          handler.common().FullRepoName(),
+		},
+	); err != nil {
+		handler.logger.Error("Recording PR metrics failed", "error", err)
+	}
+
+	handler.notifyPullRequestOpened(pullRequest)
+	handler.common().LabelPullRequest(pullRequest.GetNumber())
+
+	return pullRequest.GetNumber(), nil
+}
+
+// latestTag picks the highest version tag, falling back to the most recent
+// tag when none of them look like versions
+func (handler *Handler) latestTag() (string, error) {
+	tags, err := handler.GithubClient.ListTags(
+		botGithub.ListTagsArgs{
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(tags) == 0 {
+		return "", fmt.Errorf("the repo has no tags to start the release notes from")
+	}
+
+	versions := []*github.RepositoryTag{}
+
+	for _, tag := range tags {
+		if versionTagPattern.MatchString(tag.GetName()) {
+			versions = append(versions, tag)
+		}
+	}
+
+	if len(versions) == 0 {
+		return tags[0].GetName(), nil
+	}
+
+	latest := slices.MaxFunc(versions, func(a, b *github.RepositoryTag) int {
+		return slices.Compare(versionParts(a.GetName()), versionParts(b.GetName()))
+	})
+
+	return latest.GetName(), nil
+}
+
+// versionParts splits a version tag into its major, minor and patch numbers
+func versionParts(tag string) []int {
+	parts := []int{}
+
+	for _, part := range versionTagPattern.FindStringSubmatch(tag)[1:] {
+		number, _ := strconv.Atoi(part)
+		parts = append(parts, number)
+	}
+
+	return parts
+}
+
+// mergedSince finds the PRs merged into main after tag, from the subjects of
+// the commits between them
+func (handler *Handler) mergedSince(tag string) ([]botAi.MergedPullRequest, error) {
+	commits, err := handler.GithubClient.CompareCommits(
+		botGithub.CompareCommitsArgs{
+			Base:  tag,
+			Head:  "main",
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequests := []botAi.MergedPullRequest{}
+	seen := map[int]bool{}
+
+	for _, commit := range commits {
+		number := mergedPRNumber(commit.GetCommit().GetMessage())
+
+		if number == 0 || seen[number] {
+			continue
+		}
+
+		seen[number] = true
+
+		issue, err := handler.GithubClient.GetIssue(
+			botGithub.GetIssueArgs{
+				IssueNumber: number,
+				Owner:       handler.Owner,
+				Repo:        handler.Repo,
+			},
+		)
+
+		if err != nil {
+			handler.logger.Error("Getting PR for release notes failed", "pr", number, "error", err)
+			continue
+		}
+
+		if strings.HasPrefix(issue.GetTitle(), changelogTitlePrefix) {
+			continue
+		}
+
+		labels := []string{}
+		for _, label := range issue.Labels {
+			labels = append(labels, label.GetName())
+		}
+
+		pullRequests = append(
+			pullRequests,
+			botAi.MergedPullRequest{
+				Body:   issue.GetBody(),
+				Labels: labels,
+				Number: number,
+				Title:  issue.GetTitle(),
+			},
+		)
+	}
+
+	return pullRequests, nil
+}
+
+// mergedPRNumber reads the PR number from a merge or squash commit message,
+// returning 0 for commits pushed straight to main
+func mergedPRNumber(message string) int {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+
+	for _, pattern := range mergedPRPatterns {
+		if match := pattern.FindStringSubmatch(subject); match != nil {
+			number, _ := strconv.Atoi(match[1])
+			return number
+		}
+	}
+
+	return 0
+}
+
+// addUnreleasedNotes puts notes under an "Unreleased" heading at the top of
+// the changelog, replacing the notes from an earlier run that weren't released
+func addUnreleasedNotes(changelog, tag, notes string) string {
+	section := fmt.Sprintf("%s\n\nChanges since %s.\n\n%s\n", unreleasedHeading, tag, strings.TrimSpace(notes))
+
+	if strings.TrimSpace(changelog) == "" {
+		return "# Changelog\n\n" + section
+	}
+
+	lines := strings.Split(changelog, "\n")
+	start, end := -1, len(lines)
+
+	for index, line := range lines {
+		if start < 0 {
+			if strings.HasPrefix(line, unreleasedHeading) {
+				start = index
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(line, "## ") {
+			end = index
+			break
+		}
+	}
+
+	// without an Unreleased section, the notes go above the newest release
+	if start < 0 {
+		start = slices.IndexFunc(lines, func(line string) bool {
+			return strings.HasPrefix(line, "## ")
+		})
+
+		if start < 0 {
+			return strings.TrimRight(changelog, "\n") + "\n\n" + section
+		}
+
+		end = start
+	}
+
+	kept := slices.Concat(lines[:start], strings.Split(section, "\n"), lines[end:])
+
+	return strings.Join(kept, "\n")
+}
+
+// changelogPRBody lists the PRs the notes were written from
+func changelogPRBody(tag string, pullRequests []botAi.MergedPullRequest, notes string) string {
+	var body strings.Builder
+
+	body.WriteString(fmt.Sprintf("Release notes for the %d pull requests merged since `%s`:\n\n", len(pullRequests), tag))
+
+	for _, pullRequest := range pullRequests {
+		body.WriteString(fmt.Sprintf("- #%d %s\n", pullRequest.Number, pullRequest.Title))
+	}
+
+	body.WriteString("\n---\n\n" + strings.TrimSpace(notes) + "\n\n")
+	body.WriteString("_Edit the notes on this branch before merging; the entries are AI-written from PR titles and descriptions._\n")
+
+	return body.String()
+}
@@ -0,0 +1,400 @@
+package botcode
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+	"github.com/google/go-github/v57/github"
+)
+
+const (
+	// ciStartGrace is how long a commit with no checks at all waits for CI
+	// to pick it up before the repo is taken to have no CI
+	ciStartGrace = 3 * time.Minute
+
+	// ciTimeout is how long the bot waits for CI to finish before giving up
+	ciTimeout = 45 * time.Minute
+
+	// maxCIFailureDetails keeps a failure report under GitHub's comment size limit
+	maxCIFailureDetails = 4000
+)
+
+// failedConclusions are the check run conclusions that mean CI is red
+var failedConclusions = []string{"action_required", "cancelled", "failure", "startup_failure", "timed_out"}
+
+// ciFailure is one failed check run or commit status
+type ciFailure struct {
+	Details string // error output and annotations, when the check left any
+	Name    string
+	Paths   []string // files the check's annotations point at
+	URL     string
+}
+
+// ciResult is the state of CI on one commit
+type ciResult struct {
+	failures  []ciFailure
+	hasChecks bool
+	isPending bool
+}
+
+// watchCI waits for CI on a commit the bot pushed, so success is only
+// announced once the build passes
+func (handler *Handler) watchCI(prNumber int, branch, sha string, commentID int64) {
+	if !handler.Config.WaitForCI || sha == "" {
+		return
+	}
+
+	if err := handler.Store.WatchCI(
+		botState.CIWatch{
+			Branch:    branch,
+			CommentID: commentID,
+			PrNumber:  prNumber,
+			Repo:      handler.common().FullRepoName(),
+			SHA:       sha,
+			StartedAt: time.Now(),
+		},
+	); err != nil {
+		handler.logger.Error("Watching CI failed", "error", err)
+	}
+}
+
+// CheckPendingCI reports on bot commits whose CI has finished: a 🚀 on the
+// comment that asked for a passing change, or the failing checks (and, when
+// enabled, one attempt at a fix) for a failing one
+func (handler *Handler) CheckPendingCI() {
+	for _, watch := range handler.Store.CIWatches(handler.common().FullRepoName()) {
+		result, err := handler.ciResult(watch.SHA)
+		if err != nil {
+			handler.logger.Error("Checking CI failed", "pr", watch.PrNumber, "error", err)
+			continue
+		}
+
+		waited := time.Since(watch.StartedAt)
+		isWaitingForStart := !result.hasChecks && waited < ciStartGrace
+
+		switch {
+		case (result.isPending || isWaitingForStart) && waited < ciTimeout:
+			continue
+
+		case result.isPending:
+			handler.common().CommentOnPR(watch.PrNumber, fmt.Sprintf(
+				"⏱️ CI on %s is still running after %d minutes, so I've stopped waiting for it.",
+				watch.SHA,
+				int(ciTimeout.Minutes()),
+			))
+
+		case len(result.failures) == 0:
+			handler.reportCIPassed(watch)
+
+		default:
+			handler.reportCIFailed(watch, result.failures)
+			continue
+		}
+
+		handler.stopWatchingCI(watch)
+	}
+}
+
+// ciResult combines the check runs and commit statuses on a commit, leaving
+// out the bot's own generation check
+func (handler *Handler) ciResult(sha string) (ciResult, error) {
+	result := ciResult{}
+
+	checkRuns, err := handler.GithubClient.ListCheckRuns(
+		botGithub.ListCheckRunsArgs{
+			Owner: handler.Owner,
+			Ref:   sha,
+			Repo:  handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return result, err
+	}
+
+	for _, checkRun := range checkRuns {
+		if checkRun.GetName() == generationCheckName {
+			continue
+		}
+
+		result.hasChecks = true
+
+		switch {
+		case checkRun.GetStatus() != "completed":
+			result.isPending = true
+		case slices.Contains(failedConclusions, checkRun.GetConclusion()):
+			result.failures = append(result.failures, handler.checkRunFailure(checkRun))
+		}
+	}
+
+	combinedStatus, err := handler.GithubClient.GetCombinedStatus(
+		botGithub.GetCombinedStatusArgs{
+			Owner: handler.Owner,
+			Ref:   sha,
+			Repo:  handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return result, err
+	}
+
+	for _, status := range combinedStatus.Statuses {
+		result.hasChecks = true
+
+		switch status.GetState() {
+		case "pending":
+			result.isPending = true
+		case "error", "failure":
+			result.failures = append(
+				result.failures,
+				ciFailure{
+					Details: status.GetDescription(),
+					Name:    status.GetContext(),
+					URL:     status.GetTargetURL(),
+				},
+			)
+		}
+	}
+
+	return result, nil
+}
+
+// checkRunFailure collects what a failed check run said about the failure
+func (handler *Handler) checkRunFailure(checkRun *github.CheckRun) ciFailure {
+	failure := ciFailure{
+		Name: checkRun.GetName(),
+		URL:  checkRun.GetHTMLURL(),
+	}
+
+	details := []string{}
+
+	for _, text := range []string{checkRun.GetOutput().GetTitle(), checkRun.GetOutput().GetSummary(), checkRun.GetOutput().GetText()} {
+		if strings.TrimSpace(text) != "" {
+			details = append(details, strings.TrimSpace(text))
+		}
+	}
+
+	annotations, err := handler.GithubClient.ListCheckRunAnnotations(
+		botGithub.ListCheckRunAnnotationsArgs{
+			CheckRunID: checkRun.GetID(),
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Getting check run annotations failed", "check", checkRun.GetName(), "error", err)
+	}
+
+	for _, annotation := range annotations {
+		if annotation.GetAnnotationLevel() != "failure" {
+			continue
+		}
+
+		details = append(details, fmt.Sprintf("%s:%d: %s", annotation.GetPath(), annotation.GetStartLine(), annotation.GetMessage()))
+
+		if path := annotation.GetPath(); path != "" && !slices.Contains(failure.Paths, path) {
+			failure.Paths = append(failure.Paths, path)
+		}
+	}
+
+	failure.Details = strings.Join(details, "\n")
+
+	return failure
+}
+
+// reportCIPassed celebrates a passing change on the comment that asked for it
+func (handler *Handler) reportCIPassed(watch botState.CIWatch) {
+	if watch.IsFixAttempt {
+		handler.common().CommentOnPR(watch.PrNumber, fmt.Sprintf("✅ CI passes with the fix in %s.", watch.SHA))
+	}
+
+	if watch.CommentID == 0 {
+		return
+	}
+
+	if err := handler.GithubClient.ReactToPRComment(
+		botGithub.ReactToPRCommentArgs{
+			CommentID: watch.CommentID,
+			Owner:     handler.Owner,
+			Reaction:  "rocket",
+			Repo:      handler.Repo,
+		},
+	); err != nil {
+		handler.logger.Error("Reacting to PR comment failed", "error", err)
+	}
+}
+
+// reportCIFailed lists the failing checks on the PR, pushing one AI fix
+// attempt when that's enabled and the failure hasn't been through one yet
+func (handler *Handler) reportCIFailed(watch botState.CIWatch, failures []ciFailure) {
+	var comment strings.Builder
+
+	comment.WriteString(fmt.Sprintf("❌ CI failed on %s:\n", watch.SHA))
+	comment.WriteString(formatCIFailures(failures))
+
+	if !handler.Config.FixCI || watch.IsFixAttempt {
+		handler.common().CommentOnPR(watch.PrNumber, comment.String())
+		handler.stopWatchingCI(watch)
+		return
+	}
+
+	fixSHA, err := handler.forIssue(watch.PrNumber).fixCIFailure(watch, failures)
+	if err != nil {
+		handler.logger.Error("Fixing CI failure failed", "pr", watch.PrNumber, "error", err)
+		comment.WriteString(fmt.Sprintf("\nI couldn't push a fix. %s\n", botErrors.UserMessageOrLogs(err)))
+		handler.common().CommentOnPR(watch.PrNumber, comment.String())
+		handler.stopWatchingCI(watch)
+		return
+	}
+
+	comment.WriteString(fmt.Sprintf("\n🔧 I've pushed an attempted fix in %s and will report back once CI finishes.\n", fixSHA))
+	handler.common().CommentOnPR(watch.PrNumber, comment.String())
+
+	watch.IsFixAttempt = true
+	watch.SHA = fixSHA
+	watch.StartedAt = time.Now()
+
+	if err := handler.Store.WatchCI(watch); err != nil {
+		handler.logger.Error("Watching CI failed", "error", err)
+	}
+}
+
+// formatCIFailures renders failures as a markdown list with their output
+func formatCIFailures(failures []ciFailure) string {
+	var list strings.Builder
+
+	for _, failure := range failures {
+		if failure.URL != "" {
+			list.WriteString(fmt.Sprintf("\n- [%s](%s)\n", failure.Name, failure.URL))
+		} else {
+			list.WriteString(fmt.Sprintf("\n- %s\n", failure.Name))
+		}
+
+		if failure.Details != "" {
+			list.WriteString(fmt.Sprintf(
+				"\n  ```\n%s\n  ```\n",
+				sharedUtils.TruncateText(failure.Details, maxCIFailureDetails),
+			))
+		}
+	}
+
+	return list.String()
+}
+
+// fixCIFailure asks the AI to fix the Go files the failing checks point at
+// and commits the result, returning the new commit's SHA
+func (handler *Handler) fixCIFailure(watch botState.CIWatch, failures []ciFailure) (string, error) {
+	paths, err := handler.ciFixPaths(watch.PrNumber, failures)
+	if err != nil {
+		return "", err
+	}
+
+	changeRequest := "CI failed on this code. Fix the problems it reported:\n\n" + formatCIFailures(failures)
+	codeFiles := []*CodeFile{}
+
+	for _, path := range paths {
+		currentContent, _, err := handler.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: path,
+				Owner:    handler.Owner,
+				Ref:      watch.Branch,
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err != nil {
+			return "", fmt.Errorf("getting %s: %w", path, err)
+		}
+
+		fixedContent, err := handler.AiClient.ModifyCode(currentContent, changeRequest, "", nil)
+		if err != nil {
+			return "", fmt.Errorf("fixing %s: %w", path, err)
+		}
+
+		codeFiles = append(
+			codeFiles,
+			NewCodeFile(
+				CodeFile{
+					Content: fixedContent,
+					Message: "Fix CI failure",
+					Path:    path,
+				},
+			),
+		)
+	}
+
+	commitSHA, err := handler.commitCodeFiles(watch.Branch, codeFiles)
+	if err != nil {
+		return "", err
+	}
+
+	handler.common().RecordBotCommit(watch.PrNumber, watch.Branch, commitSHA)
+	handler.common().DispatchWorkflow(watch.PrNumber, watch.Branch)
+
+	return commitSHA, nil
+}
+
+// ciFixPaths picks the Go files changed on the PR that the failures point
+// at; with no annotations to go on, a PR changing a single Go file is fixed
+// in that file
+func (handler *Handler) ciFixPaths(prNumber int, failures []ciFailure) ([]string, error) {
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting PR files: %w", err)
+	}
+
+	changed := []string{}
+
+	for _, file := range files {
+		path := file.GetFilename()
+
+		isEditable := strings.HasSuffix(path, ".go") &&
+			file.GetStatus() != "removed" &&
+			!handler.Config.SelfUpdate.IsProtected(path) &&
+			handler.Config.Paths.IsAllowed(path)
+
+		if isEditable {
+			changed = append(changed, path)
+		}
+	}
+
+	named := []string{}
+
+	for _, failure := range failures {
+		for _, path := range failure.Paths {
+			if slices.Contains(changed, path) && !slices.Contains(named, path) {
+				named = append(named, path)
+			}
+		}
+	}
+
+	switch {
+	case len(named) > 0:
+		return named, nil
+	case len(changed) == 1:
+		return changed, nil
+	default:
+		return nil, fmt.Errorf("the failing checks don't point at a Go file this PR changed")
+	}
+}
+
+func (handler *Handler) stopWatchingCI(watch botState.CIWatch) {
+	if err := handler.Store.StopWatchingCI(watch.Repo, watch.PrNumber); err != nil {
+		handler.logger.Error("Clearing CI watch failed", "error", err)
+	}
+}
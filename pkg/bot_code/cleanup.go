@@ -0,0 +1,21 @@
+package botcode
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// outcomeComment describes a closed code PR for its source issue
+func outcomeComment(pullRequest *github.PullRequest) string {
+	if !pullRequest.GetMerged() {
+		return fmt.Sprintf("#%d was closed without merging, so the change wasn't applied.", pullRequest.GetNumber())
+	}
+
+	return fmt.Sprintf(
+		"✅ #%d was merged into `%s` in %s.",
+		pullRequest.GetNumber(),
+		pullRequest.GetBase().GetRef(),
+		pullRequest.GetMergeCommitSHA(),
+	)
+}
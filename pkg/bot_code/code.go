@@ -12,6 +12,7 @@ import (
 type ChangeRequest struct {
 	Description string
 	FileType    string // "go", "md", etc.
+	SkipTests   bool   // "tests: false" opts out of generated test files
 	Tags        []string
 	TargetPath  string // where the file should go
 	Title       string
@@ -22,6 +23,10 @@ func ParseIssueForCodeRequest(title, body string) *ChangeRequest {
 	cleanTitle := strings.TrimSpace(strings.TrimPrefix(title, "Code:"))
 	cleanTitle = strings.TrimSpace(strings.TrimPrefix(cleanTitle, "code:"))
 
+	if form, isForm := sharedUtils.ParseIssueForm(body); isForm {
+		return requestFromForm(cleanTitle, form)
+	}
+
 	request := &ChangeRequest{
 		Title:       cleanTitle,
 		Description: body,
@@ -52,6 +57,37 @@ func ParseIssueForCodeRequest(title, body string) *ChangeRequest {
 		}
 	}
 
+	for line := range strings.SplitSeq(body, "\n") {
+		lowerLine := strings.ToLower(strings.TrimSpace(line))
+
+		if strings.HasPrefix(lowerLine, "tests:") {
+			value := strings.TrimSpace(strings.TrimPrefix(lowerLine, "tests:"))
+			request.SkipTests = value == "false" || value == "no"
+		}
+	}
+
+	return request
+}
+
+// requestFromForm builds a request from an issue created with an issue form,
+// where each field has its own "### " section
+func requestFromForm(title string, form sharedUtils.IssueForm) *ChangeRequest {
+	request := &ChangeRequest{
+		Description: form.Value("description", "change", "what should change"),
+		FileType:    "go",
+		Tags:        append([]string{"ai-generated"}, form.List("tags")...),
+		TargetPath:  form.Value("target path", "path", "file"),
+		Title:       title,
+	}
+
+	if formTitle := form.Value("title"); formTitle != "" {
+		request.Title = formTitle
+	}
+
+	if wantsTests, isSet := form.Bool("tests", "generate tests"); isSet {
+		request.SkipTests = !wantsTests
+	}
+
 	return request
 }
 
@@ -89,8 +125,9 @@ func (codeFile *CodeFile) GetFilePath() string {
 // DetermineTargetPath figures out where a code file should go based on the request
 // todo - oh this needs help
 func DetermineTargetPath(request *ChangeRequest) string {
-	if request.TargetPath != "" {
-		return request.TargetPath
+	// a path like "." cleans to nothing and falls through to the default
+	if targetPath := sharedUtils.CleanRepoPath(request.TargetPath); targetPath != "" {
+		return targetPath
 	}
 
 	// Default paths based on request type
@@ -118,21 +155,11 @@ func DetermineTargetPath(request *ChangeRequest) string {
 
 // generateFilename creates a filename from a title
 func generateFilename(title string) string {
-	filename := strings.ToLower(title)
-	filename = strings.ReplaceAll(filename, " ", "_")
-
-	// Remove special characters
-	var result strings.Builder
-
-	for _, rune := range filename {
-		if sharedUtils.IsRuneAlphabetical(rune) ||
-			sharedUtils.IsRuneNumerical(rune) ||
-			sharedUtils.IsRuneDashCharacter(rune) {
-			result.WriteRune(rune)
-		}
+	if name := sharedUtils.Slugify(title, "_"); name != "" {
+		return name + ".go"
 	}
 
-	return result.String() + ".go"
+	return "change.go"
 }
 
 // GenerateCommitMessage creates a descriptive commit message
@@ -0,0 +1,33 @@
+package botcode
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func FuzzParseIssueForCodeRequest(f *testing.F) {
+	f.Add("Code: Add a helper", "Add a helper package\nPath: pkg/helpers/helpers.go")
+	f.Add("code: Tests off", "file: ../../etc/passwd\ntests: false")
+	f.Add("Windows path", "Path: pkg\\helpers\\helpers.go\nTests: no")
+	f.Add("Form", "### Description\n\nAdd a helper\n\n### Target path\n\n/pkg/helpers.go\n\n### Tests\n\n- [ ] generate tests")
+	f.Add("", "")
+
+	f.Fuzz(func(t *testing.T, title, body string) {
+		request := ParseIssueForCodeRequest(title, body)
+
+		if len(request.Tags) == 0 || request.Tags[0] != "ai-generated" {
+			t.Errorf("got tags %q, want ai-generated first", request.Tags)
+		}
+
+		if request.FileType != "go" {
+			t.Errorf("got file type %q, want go", request.FileType)
+		}
+
+		targetPath := DetermineTargetPath(request)
+
+		if targetPath == "" || path.IsAbs(targetPath) || targetPath != path.Clean(targetPath) || targetPath == ".." || strings.HasPrefix(targetPath, "../") {
+			t.Errorf("got target path %q, want a clean repo-relative path", targetPath)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package botcode
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	"github.com/google/go-github/v57/github"
+)
+
+// progressiveFlushInterval throttles how often in-progress files are pushed
+const progressiveFlushInterval = 30 * time.Second
+
+// generationCheckName is the check run shown on the head commit of code PRs
+const generationCheckName = "AI code change"
+
+// progressiveDelivery pushes files to the branch as the agent writes them,
+// opening the PR after the first push so reviewers can start reading early
+// and a late failure doesn't lose the work already done
+type progressiveDelivery struct {
+	branchName  string
+	check       *botGithub.GenerationCheck
+	committed   map[string]string
+	handler     *Handler
+	headSHA     string
+	issue       *github.Issue
+	lastFlush   time.Time
+	progress    *botGithub.ProgressComment
+	pullRequest *github.PullRequest
+	request     *ChangeRequest
+	workspace   *repoWorkspace
+}
+
+func newProgressiveDelivery(
+	handler *Handler,
+	issue *github.Issue,
+	request *ChangeRequest,
+	branchName string,
+) *progressiveDelivery {
+	return &progressiveDelivery{
+		branchName: branchName,
+		check: handler.GithubClient.NewGenerationCheck(
+			botGithub.NewGenerationCheckArgs{
+				Name:  generationCheckName,
+				Owner: handler.Owner,
+				Repo:  handler.Repo,
+			},
+		),
+		committed: map[string]string{},
+		handler:   handler,
+		issue:     issue,
+		lastFlush: time.Now(),
+		request:   request,
+	}
+}
+
+// onWrite flushes staged files once the throttle interval has passed
+func (delivery *progressiveDelivery) onWrite() {
+	if time.Since(delivery.lastFlush) < progressiveFlushInterval {
+		return
+	}
+
+	if err := delivery.flush(false); err != nil {
+		// keep going; the final flush will retry everything still pending
+		delivery.handler.logger.Error("Pushing in-progress files failed", "error", err)
+	}
+}
+
+// flush commits every staged file that changed since the last push; isFinal
+// marks the last push, where files that don't compile are an error
+func (delivery *progressiveDelivery) flush(isFinal bool) error {
+	delivery.lastFlush = time.Now()
+
+	pending := []*CodeFile{}
+	message := GenerateCommitMessage(delivery.request, "Add")
+
+	for _, codeFile := range delivery.workspace.StagedFiles(message) {
+		if delivery.committed[codeFile.Path] != codeFile.Content {
+			pending = append(pending, codeFile)
+		}
+	}
+
+	pending, err := delivery.checkFiles(pending, isFinal)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	commitSHA, err := delivery.handler.commitCodeFiles(delivery.branchName, pending)
+	if err != nil {
+		return err
+	}
+
+	for _, codeFile := range pending {
+		delivery.committed[codeFile.Path] = codeFile.Content
+	}
+
+	delivery.headSHA = commitSHA
+	isFirstPush := delivery.pullRequest == nil
+
+	if err := delivery.ensurePullRequest(); err != nil {
+		return err
+	}
+
+	// opening the PR counted its first commit
+	if isFirstPush {
+		delivery.handler.common().RecordBranchCommit(delivery.branchName, commitSHA)
+	} else {
+		delivery.handler.common().RecordBotCommit(delivery.pullRequest.GetNumber(), delivery.branchName, commitSHA)
+	}
+
+	return delivery.report(fmt.Sprintf("pushed %d file(s): %s", len(pending), joinPaths(pending)))
+}
+
+// ensurePullRequest opens the PR on the first push
+func (delivery *progressiveDelivery) ensurePullRequest() error {
+	if delivery.pullRequest != nil {
+		return nil
+	}
+
+	handler := delivery.handler
+
+	pullRequest, err := handler.GithubClient.CreatePullRequest(
+		botGithub.CreatePullRequestArgs{
+			Base:  "main",
+			Body:  fmt.Sprintf("🚧 AI code generation for #%d is still in progress. Files are pushed as they are written.", *delivery.issue.Number),
+			Draft: handler.Config.DraftPRs,
+			Head:  fmt.Sprintf("%s:%s", handler.Owner, delivery.branchName),
+			Owner: handler.Owner,
+			Repo:  handler.Repo,
+			Title: fmt.Sprintf("Add code: %s", delivery.request.Title),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("creating PR: %w", err)
+	}
+
+	delivery.pullRequest = pullRequest
+
+	delivery.progress = handler.GithubClient.NewProgressComment(
+		botGithub.NewProgressCommentArgs{
+			Heading:     "⏳ **Generation progress**",
+			IssueNumber: pullRequest.GetNumber(),
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+
+	if err := handler.Store.RecordPullRequestOpened(
+		botState.RecordPullRequestOpenedArgs{
+			ContentType:   botState.ContentTypeCode,
+			Number:        pullRequest.GetNumber(),
+			PromptVersion: botAi.PromptVersion,
+			Repo:          handler.common().FullRepoName(),
+		},
+	); err != nil {
+		delivery.handler.logger.Error("Recording PR metrics failed", "error", err)
+	}
+
+	handler.notifyPullRequestOpened(pullRequest)
+	handler.common().LabelPullRequest(pullRequest.GetNumber())
+	handler.common().RequestAttention(pullRequest.GetNumber(), delivery.issue.GetUser().GetLogin())
+
+	return nil
+}
+
+// finish pushes whatever is left and replaces the placeholder PR body
+func (delivery *progressiveDelivery) finish(completion *botAi.Completion) error {
+	if err := delivery.flush(true); err != nil {
+		delivery.finishCheck(botGithub.CheckConclusionFailure, "❌ "+botErrors.UserMessageOrLogs(err))
+		return err
+	}
+
+	if delivery.pullRequest == nil {
+		return fmt.Errorf("AI code generation produced no files")
+	}
+
+	message := GenerateCommitMessage(delivery.request, "Add")
+	body := delivery.handler.generatePRBody(
+		delivery.issue,
+		delivery.workspace.StagedFiles(message),
+		completion,
+	)
+
+	if delivery.handler.isSelfUpdate() {
+		body = delivery.handler.selfUpdateBanner() + "\n" + body
+	}
+
+	if err := delivery.handler.GithubClient.UpdatePullRequest(
+		botGithub.UpdatePullRequestArgs{
+			Body:     body,
+			Owner:    delivery.handler.Owner,
+			PrNumber: delivery.pullRequest.GetNumber(),
+			Repo:     delivery.handler.Repo,
+		},
+	); err != nil {
+		delivery.finishCheck(botGithub.CheckConclusionFailure, "❌ Updating the PR description failed. "+botErrors.UserMessageOrLogs(err))
+		return err
+	}
+
+	delivery.handler.watchCI(delivery.pullRequest.GetNumber(), delivery.branchName, delivery.headSHA, 0)
+	delivery.handler.common().DispatchWorkflow(delivery.pullRequest.GetNumber(), delivery.branchName)
+	delivery.handler.startSelfUpdateChecks(delivery.pullRequest.GetNumber(), delivery.branchName)
+
+	return delivery.finishCheck(botGithub.CheckConclusionSuccess, "✅ generation finished")
+}
+
+// fail records a failure in the progress comment when a PR already exists
+func (delivery *progressiveDelivery) fail(cause error) {
+	if delivery.flush(false) != nil || delivery.pullRequest == nil {
+		return
+	}
+
+	delivery.finishCheck(
+		botGithub.CheckConclusionFailure,
+		fmt.Sprintf("❌ generation stopped early: %v — the files above were kept", cause),
+	)
+}
+
+// report adds a line to the progress comment and the PR's check run
+func (delivery *progressiveDelivery) report(line string) error {
+	if err := delivery.check.Report(delivery.headSHA, line); err != nil {
+		delivery.handler.logger.Error("Reporting check run failed", "error", err)
+	}
+
+	if delivery.progress == nil {
+		return nil
+	}
+
+	return delivery.progress.Report(line)
+}
+
+// finishCheck adds the last line to the progress comment and completes the check run
+func (delivery *progressiveDelivery) finishCheck(conclusion, line string) error {
+	if err := delivery.check.Finish(delivery.headSHA, conclusion, line); err != nil {
+		delivery.handler.logger.Error("Completing check run failed", "error", err)
+	}
+
+	if delivery.progress == nil {
+		return nil
+	}
+
+	return delivery.progress.Report(line)
+}
+
+func joinPaths(codeFiles []*CodeFile) string {
+	paths := []string{}
+
+	for _, codeFile := range codeFiles {
+		paths = append(paths, "`"+codeFile.Path+"`")
+	}
+
+	return strings.Join(paths, ", ")
+}
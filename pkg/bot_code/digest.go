@@ -0,0 +1,92 @@
+package botcode
+
+import (
+	"fmt"
+	"time"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+)
+
+// PostWeeklyDigest keeps an issue summarizing this week's bot activity up to
+// date. Last week's issue gets its final numbers and is closed once the week
+// is over.
+func (handler *Handler) PostWeeklyDigest() {
+	if !handler.Config.WeeklyDigest {
+		return
+	}
+
+	weekStart := startOfWeek(time.Now())
+	lastWeekStart := weekStart.AddDate(0, 0, -7)
+
+	if issue, exists := handler.Store.DigestIssue(handler.common().FullRepoName(), lastWeekStart); exists && !issue.IsFinal {
+		if err := handler.writeDigest(lastWeekStart, true); err != nil {
+			handler.logger.Error("Finishing last week's digest failed", "error", err)
+		}
+	}
+
+	if err := handler.writeDigest(weekStart, false); err != nil {
+		handler.logger.Error("Updating the weekly digest failed", "error", err)
+	}
+}
+
+// startOfWeek returns midnight UTC on the Monday of the week containing at
+func startOfWeek(at time.Time) time.Time {
+	day := at.UTC().Truncate(24 * time.Hour)
+	daysSinceMonday := (int(day.Weekday()) + 6) % 7
+
+	return day.AddDate(0, 0, -daysSinceMonday)
+}
+
+// writeDigest opens the digest issue for the week starting at weekStart, or
+// rewrites its body if it's already open, closing it when isFinal
+func (handler *Handler) writeDigest(weekStart time.Time, isFinal bool) error {
+	digest := handler.Store.Digest(weekStart, weekStart.AddDate(0, 0, 7))
+	body := botState.FormatDigest(digest)
+
+	issue, exists := handler.Store.DigestIssue(handler.common().FullRepoName(), weekStart)
+
+	if !exists {
+		created, err := handler.GithubClient.CreateIssue(
+			botGithub.CreateIssueArgs{
+				Body:   body,
+				Labels: botHandler.NonEmpty([]string{handler.Config.Labels.Digest}),
+				Owner:  handler.Owner,
+				Repo:   handler.Repo,
+				Title:  fmt.Sprintf("Bot digest: week of %s", weekStart.Format("Jan 2, 2006")),
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+
+		return handler.Store.SaveDigestIssue(
+			handler.common().FullRepoName(),
+			weekStart,
+			botState.DigestIssue{Number: created.GetNumber()},
+		)
+	}
+
+	state := ""
+	if isFinal {
+		state = "closed"
+	}
+
+	if err := handler.GithubClient.EditIssue(
+		botGithub.EditIssueArgs{
+			Body:        body,
+			IssueNumber: issue.Number,
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+			State:       state,
+		},
+	); err != nil {
+		return err
+	}
+
+	issue.IsFinal = isFinal
+
+	return handler.Store.SaveDigestIssue(handler.common().FullRepoName(), weekStart, issue)
+}
@@ -0,0 +1,121 @@
+package botcode
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+	"github.com/google/go-github/v57/github"
+)
+
+// dryRunPreviewLimit keeps preview comments under GitHub's comment size
+// limit; it's shared between the files written
+const dryRunPreviewLimit = 50_000
+
+// isDryRun reports whether a request should only be previewed, because the
+// repo is in dry-run mode or the issue has the dry-run label
+func (handler *Handler) isDryRun(issue *github.Issue) bool {
+	return handler.Config.DryRun || botHandler.HasLabel(issue, handler.Config.DryRunLabel)
+}
+
+// previewChange comments the plan and the files a request would commit on
+// its issue, without a branch or PR
+func (handler *Handler) previewChange(issue *github.Issue, request *ChangeRequest) {
+	comment, err := handler.writePreview(issue, request)
+	if err != nil {
+		handler.logger.Error("Previewing code change failed", "error", err)
+		comment = fmt.Sprintf("🧪 **Dry run:** sorry, I ran into an error writing the preview. %s", botErrors.UserMessageOrLogs(err))
+	}
+
+	handler.GithubClient.CommentOnIssue(
+		botGithub.CommentOnIssueArgs{
+			Comment:     comment,
+			IssueNumber: issue.GetNumber(),
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+}
+
+// writePreview runs the code agent against the default branch, keeping its
+// writes in memory, and describes what it wrote
+func (handler *Handler) writePreview(issue *github.Issue, request *ChangeRequest) (string, error) {
+	workspace := newRepoWorkspace(
+		newRepoWorkspaceArgs{
+			GithubClient: handler.GithubClient,
+			Owner:        handler.Owner,
+			Paths:        handler.Config.Paths,
+			Policy:       handler.Config.SelfUpdate,
+			Repo:         handler.Repo,
+		},
+	)
+
+	completion, err := handler.AiClient.RunCodeAgent(
+		&botAi.CodeRequest{
+			Title:       request.Title,
+			Description: request.Description,
+			FileType:    request.FileType,
+			TargetPath:  DetermineTargetPath(request),
+			Tags:        request.Tags,
+		},
+		workspace,
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("AI code generation failed: %w", err)
+	}
+
+	codeFiles := workspace.StagedFiles(GenerateCommitMessage(request, "Add"))
+	if len(codeFiles) == 0 {
+		return "", fmt.Errorf("AI code generation produced no files")
+	}
+
+	return handler.dryRunComment(issue, request, codeFiles, completion), nil
+}
+
+// dryRunComment describes the branch, commit and PR a request would have
+// made, with the files it would have committed
+func (handler *Handler) dryRunComment(
+	issue *github.Issue,
+	request *ChangeRequest,
+	codeFiles []*CodeFile,
+	completion *botAi.Completion,
+) string {
+	var comment strings.Builder
+
+	comment.WriteString("🧪 **Dry run:** nothing was committed. For real, I would:\n\n")
+	comment.WriteString(fmt.Sprintf("- create the branch `ai-code-change-%d`\n", issue.GetNumber()))
+	comment.WriteString(fmt.Sprintf("- commit %s, written by %s\n", joinPaths(codeFiles), completion.Model))
+
+	if !request.SkipTests {
+		comment.WriteString("- add tests for them\n")
+	}
+
+	comment.WriteString(fmt.Sprintf("- open a PR titled \"Add code: %s\"\n", request.Title))
+	comment.WriteString(fmt.Sprintf("\n**Summary:**\n%s\n", completion.Text))
+
+	limit := dryRunPreviewLimit / len(codeFiles)
+
+	for _, codeFile := range codeFiles {
+		content := formatCodeFile(codeFile.Path, codeFile.Content, handler.logger)
+
+		comment.WriteString(fmt.Sprintf(
+			"\n<details>\n<summary><code>%s</code></summary>\n\n%s\n\n</details>\n",
+			codeFile.Path,
+			sharedUtils.CodeBlock(strings.TrimPrefix(path.Ext(codeFile.Path), "."), sharedUtils.TruncateText(content, limit)),
+		))
+	}
+
+	if handler.Config.DryRun {
+		comment.WriteString("\nDry runs are on for this repo; once they're off, open the request again to get the PR.")
+	} else {
+		comment.WriteString(fmt.Sprintf("\nRemove the `%s` label and open the request again to get the PR.", handler.Config.DryRunLabel))
+	}
+
+	return comment.String()
+}
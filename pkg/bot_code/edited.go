@@ -0,0 +1,173 @@
+package botcode
+
+import (
+	"fmt"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	"github.com/google/go-github/v57/github"
+)
+
+const regenerateCommand = "/regenerate"
+
+// HandleIssueEdited reworks the open PR for an issue after its request was
+// edited, so the change follows the issue instead of leaving a stale PR; a PR
+// someone else has pushed to is only reworked on /regenerate
+func (handler *Handler) HandleIssueEdited(issue *github.Issue, editor string) {
+	if !handler.isTriggered(issue) {
+		return
+	}
+
+	if !handler.common().EnsureWritable(issue.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsAuthorized(editor, issue.GetNumber()) {
+		return
+	}
+
+	branchName := fmt.Sprintf("ai-code-change-%d", issue.GetNumber())
+
+	found, err := handler.GithubClient.FindPullRequestForBranch(
+		botGithub.FindPullRequestForBranchArgs{
+			BranchName: branchName,
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+			State:      "open",
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Finding PR for edited issue failed", "issue", issue.GetNumber(), "error", err)
+		return
+	}
+
+	// without an open PR there's nothing to amend
+	if found == nil {
+		return
+	}
+
+	if !handler.common().IsWithinRateLimit(editor, issue.GetNumber()) {
+		return
+	}
+
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: found.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Getting PR failed", "pr", found.GetNumber(), "error", err)
+		return
+	}
+
+	if handler.hasOthersCommits(pullRequest) {
+		handler.common().CommentOnPR(pullRequest.GetNumber(), fmt.Sprintf(
+			"✋ #%d was edited, but this PR has commits I didn't make, so I left it alone. "+
+				"Comment `%s` to rework it from the edited issue anyway.",
+			issue.GetNumber(),
+			regenerateCommand,
+		))
+		return
+	}
+
+	handler.amendForIssue(issue, pullRequest)
+}
+
+// handleRegenerateCommand reworks a PR from its source issue as it reads now,
+// whoever else has pushed to the branch
+func (handler *Handler) handleRegenerateCommand(prNumber int) {
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Getting PR failed", "pr", prNumber, "error", err)
+		return
+	}
+
+	issueNumber := botHandler.SourceIssueNumber(pullRequest.GetBody())
+	if issueNumber == 0 {
+		handler.common().CommentOnPR(prNumber, "This PR wasn't generated from a code change issue, so there's nothing to rework it from.")
+		return
+	}
+
+	issue, err := handler.GithubClient.GetIssue(
+		botGithub.GetIssueArgs{
+			IssueNumber: issueNumber,
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Getting source issue failed", "issue", issueNumber, "error", err)
+		return
+	}
+
+	handler.amendForIssue(issue, pullRequest)
+}
+
+// amendForIssue reworks a PR from its edited issue, saying on the PR if that
+// failed
+func (handler *Handler) amendForIssue(issue *github.Issue, pullRequest *github.PullRequest) {
+	request := ParseIssueForCodeRequest(issue.GetTitle(), issue.GetBody())
+
+	if err := handler.amendCodeChangePR(issue, request, pullRequest); err != nil {
+		handler.logger.Error("Updating PR after issue edit failed", "pr", pullRequest.GetNumber(), "error", err)
+
+		handler.GithubClient.CommentOnPR(
+			botGithub.CommentOnPRArgs{
+				Comment: handler.common().CommandFailedComment(
+					pullRequest.GetNumber(),
+					fmt.Sprintf("update this PR after #%d was edited", issue.GetNumber()),
+					err,
+				),
+				Owner:    handler.Owner,
+				PrNumber: pullRequest.GetNumber(),
+				Repo:     handler.Repo,
+			},
+		)
+	}
+}
+
+// hasOthersCommits reports whether a PR's branch has commits the bot didn't
+// push, which a rerun of the agent would build on without asking; a PR the
+// bot has no record of counts as having them
+func (handler *Handler) hasOthersCommits(pullRequest *github.PullRequest) bool {
+	record, exists := handler.Store.PullRequest(handler.common().FullRepoName(), pullRequest.GetNumber())
+	if !exists {
+		return true
+	}
+
+	return pullRequest.GetCommits() > record.BotCommits
+}
+
+// amendCodeChangePR runs the code agent again on an existing PR's branch,
+// which still holds the files from the previous run
+func (handler *Handler) amendCodeChangePR(
+	issue *github.Issue,
+	request *ChangeRequest,
+	pullRequest *github.PullRequest,
+) error {
+	delivery := newProgressiveDelivery(handler, issue, request, pullRequest.GetHead().GetRef())
+	delivery.pullRequest = pullRequest
+
+	delivery.progress = handler.GithubClient.NewProgressComment(
+		botGithub.NewProgressCommentArgs{
+			Heading:     fmt.Sprintf("✏️ **Updating for the edited #%d**", issue.GetNumber()),
+			IssueNumber: pullRequest.GetNumber(),
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+
+	return handler.deliverCodeChange(delivery)
+}
@@ -0,0 +1,253 @@
+package botcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+const explainCommand = "/explain"
+
+// maxExplainDiff keeps a whole-PR explanation prompt to a reasonable size
+const maxExplainDiff = 60000
+
+// errNoChanges answers "/explain" on a PR that doesn't change anything yet
+var errNoChanges = &botErrors.ValidationError{Message: "This PR doesn't change any files yet, so there's nothing to explain."}
+
+// handleExplainCommand answers "/explain [path]" in the PR conversation,
+// explaining one file from the PR, or the whole diff when no path is given
+func (handler *Handler) handleExplainCommand(prNumber int, argument string) {
+	explanation, err := handler.explainPullRequest(prNumber, argument)
+	if err != nil {
+		handler.logger.Error("Explaining PR failed", "error", err)
+		explanation = handler.common().CommandFailedComment(prNumber, "explain that", err)
+	}
+
+	handler.GithubClient.CommentOnPR(
+		botGithub.CommentOnPRArgs{
+			Comment:  explanation,
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+}
+
+func (handler *Handler) explainPullRequest(prNumber int, filePath string) (string, error) {
+	diff, err := handler.pullRequestDiff(prNumber, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	request := &botAi.ExplainRequest{Code: truncateDiff(diff), Path: "the whole pull request"}
+
+	if filePath != "" {
+		pullRequest, err := handler.GithubClient.GetPullRequest(
+			botGithub.GetPullRequestArgs{
+				Owner:    handler.Owner,
+				PrNumber: prNumber,
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err != nil {
+			return "", fmt.Errorf("getting PR: %w", err)
+		}
+
+		content, _, err := handler.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: filePath,
+				Owner:    handler.Owner,
+				Ref:      pullRequest.GetHead().GetRef(),
+				Repo:     handler.Repo,
+			},
+		)
+
+		// a deleted file has no content left, so its patch is explained instead
+		if err == nil {
+			request.Code = content
+		}
+
+		request.Path = filePath
+	}
+
+	completion, err := handler.AiClient.Explain(request)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("💡 **Explanation of %s**\n\n%s", request.Path, completion.Text), nil
+}
+
+// pullRequestDiff is the PR's diff, or one file's patch from it. The whole
+// diff comes from GitHub as git prints it, with renames and mode changes; past
+// the size GitHub renders, the per-file patches are put together instead
+func (handler *Handler) pullRequestDiff(prNumber int, filePath string) (string, error) {
+	if filePath == "" {
+		diff, err := handler.GithubClient.GetPullRequestDiff(
+			botGithub.GetPullRequestDiffArgs{
+				Owner:    handler.Owner,
+				PrNumber: prNumber,
+				Repo:     handler.Repo,
+			},
+		)
+
+		if err == nil && strings.TrimSpace(diff) == "" {
+			return "", errNoChanges
+		}
+
+		if err == nil {
+			return diff, nil
+		}
+
+		if !errors.Is(err, botGithub.ErrDiffTooLarge) {
+			return "", fmt.Errorf("getting PR diff: %w", err)
+		}
+	}
+
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("getting PR files: %w", err)
+	}
+
+	patches := []string{}
+
+	for _, file := range files {
+		if filePath != "" && file.GetFilename() != filePath {
+			continue
+		}
+
+		patches = append(patches, fmt.Sprintf("--- %s\n%s", file.GetFilename(), file.GetPatch()))
+	}
+
+	if len(patches) == 0 && filePath == "" {
+		return "", errNoChanges
+	}
+
+	if len(patches) == 0 {
+		return "", &botErrors.ValidationError{Message: fmt.Sprintf("`%s` isn't changed in this PR.", filePath)}
+	}
+
+	return strings.Join(patches, "\n\n"), nil
+}
+
+// pullRequestPatches is each changed file's patch, split from the PR's
+// unified diff; past the size GitHub renders, the per-file patches are
+// listed instead
+func (handler *Handler) pullRequestPatches(prNumber int) ([]botGithub.FilePatch, error) {
+	diff, err := handler.GithubClient.GetPullRequestDiff(
+		botGithub.GetPullRequestDiffArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err == nil {
+		return botGithub.SplitDiff(diff), nil
+	}
+
+	if !errors.Is(err, botGithub.ErrDiffTooLarge) {
+		return nil, fmt.Errorf("getting PR diff: %w", err)
+	}
+
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting PR files: %w", err)
+	}
+
+	patches := []botGithub.FilePatch{}
+
+	for _, file := range files {
+		patches = append(patches, botGithub.FilePatch{Patch: file.GetPatch(), Path: file.GetFilename()})
+	}
+
+	return patches, nil
+}
+
+// handleExplainReview answers "/explain [question]" on a review comment in
+// its thread, focusing on the lines the comment was left on
+func (handler *Handler) handleExplainReview(
+	pullRequest *github.PullRequest,
+	comment *github.PullRequestComment,
+	question string,
+) {
+	reply, err := handler.explainReviewComment(pullRequest, comment, question)
+	if err != nil {
+		handler.logger.Error("Explaining code failed", "error", err)
+		reply = handler.common().CommandFailedComment(pullRequest.GetNumber(), "explain this", err)
+	}
+
+	if err := handler.GithubClient.ReplyToReviewComment(
+		botGithub.ReplyToReviewCommentArgs{
+			Comment:   reply,
+			CommentID: comment.GetID(),
+			Owner:     handler.Owner,
+			PrNumber:  pullRequest.GetNumber(),
+			Repo:      handler.Repo,
+		},
+	); err != nil {
+		handler.logger.Error("Replying to review comment failed", "error", err)
+	}
+}
+
+func (handler *Handler) explainReviewComment(
+	pullRequest *github.PullRequest,
+	comment *github.PullRequestComment,
+	question string,
+) (string, error) {
+	content, _, err := handler.GithubClient.GetFileContent(
+		botGithub.GetFileContentArgs{
+			Filename: comment.GetPath(),
+			Owner:    handler.Owner,
+			Ref:      pullRequest.GetHead().GetRef(),
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("getting file content: %w", err)
+	}
+
+	completion, err := handler.AiClient.Explain(
+		&botAi.ExplainRequest{
+			Code:     content,
+			Focus:    comment.GetDiffHunk(),
+			Path:     comment.GetPath(),
+			Question: question,
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return completion.Text, nil
+}
+
+func truncateDiff(diff string) string {
+	if len(diff) <= maxExplainDiff {
+		return diff
+	}
+
+	return diff[:maxExplainDiff] + "\n... (diff truncated)"
+}
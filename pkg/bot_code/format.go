@@ -0,0 +1,168 @@
+package botcode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// standardImports maps the standard library packages generated code most
+// often forgets to import to their paths
+var standardImports = map[string]string{
+	"bufio":    "bufio",
+	"bytes":    "bytes",
+	"context":  "context",
+	"errors":   "errors",
+	"filepath": "path/filepath",
+	"fmt":      "fmt",
+	"http":     "net/http",
+	"io":       "io",
+	"json":     "encoding/json",
+	"log":      "log",
+	"math":     "math",
+	"os":       "os",
+	"regexp":   "regexp",
+	"sort":     "sort",
+	"strconv":  "strconv",
+	"strings":  "strings",
+	"sync":     "sync",
+	"time":     "time",
+	"url":      "net/url",
+}
+
+// formatCodeFile gofmts a Go file and fixes its imports, leaving other files
+// alone. A file that can't be formatted is returned unchanged
+func formatCodeFile(filePath, content string, logger *slog.Logger) string {
+	if !strings.HasSuffix(filePath, ".go") {
+		return content
+	}
+
+	formatted, err := formatGoSource(content)
+	if err != nil {
+		logger.Warn("Formatting failed, committing the file as is", "path", filePath, "error", err)
+		return content
+	}
+
+	return formatted
+}
+
+// formatGoSource removes unused standard library imports, adds missing ones
+// and formats the result the way gofmt would
+func formatGoSource(content string) (string, error) {
+	fixed, err := fixImports(content)
+	if err != nil {
+		return "", err
+	}
+
+	formatted, err := format.Source([]byte(fixed))
+	if err != nil {
+		return "", fmt.Errorf("formatting: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+// fixImports is a small goimports: it only touches standard library imports,
+// since other packages' names can't be known without loading them
+func fixImports(content string) (string, error) {
+	fileSet := token.NewFileSet()
+
+	file, err := parser.ParseFile(fileSet, "", content, 0)
+	if err != nil {
+		return "", fmt.Errorf("parsing: %w", err)
+	}
+
+	used := usedPackageNames(file)
+	imported := map[string]bool{}
+	removedLines := map[int]bool{}
+
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		isStandard := !strings.Contains(strings.Split(importPath, "/")[0], ".")
+
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		imported[name] = true
+
+		isKnownName := spec.Name != nil || isStandard
+		if !isKnownName || name == "_" || name == "." || used[name] {
+			continue
+		}
+
+		removedLines[fileSet.Position(spec.Pos()).Line] = true
+	}
+
+	missing := []string{}
+
+	for name := range used {
+		if importPath, isKnown := standardImports[name]; isKnown && !imported[name] {
+			missing = append(missing, fmt.Sprintf("\t%q", importPath))
+		}
+	}
+
+	sort.Strings(missing)
+
+	// new imports go into the first import block, or a new one after the package clause
+	anchorLine := fileSet.Position(file.Name.End()).Line
+	if len(missing) > 0 {
+		missing = append([]string{"", "import ("}, append(missing, ")")...)
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, isGenDecl := decl.(*ast.GenDecl)
+
+		if isGenDecl && genDecl.Tok == token.IMPORT && genDecl.Lparen.IsValid() && len(missing) > 0 {
+			anchorLine = fileSet.Position(genDecl.Lparen).Line
+			missing = missing[2 : len(missing)-1]
+			break
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	fixed := []string{}
+
+	for index, line := range lines {
+		if removedLines[index+1] {
+			continue
+		}
+
+		fixed = append(fixed, line)
+
+		if index+1 == anchorLine {
+			fixed = append(fixed, missing...)
+		}
+	}
+
+	return strings.Join(fixed, "\n"), nil
+}
+
+// usedPackageNames collects the unresolved identifiers used as a selector's
+// receiver, which is how a file refers to imported packages
+func usedPackageNames(file *ast.File) map[string]bool {
+	used := map[string]bool{}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, isSelector := node.(*ast.SelectorExpr)
+		if !isSelector {
+			return true
+		}
+
+		if ident, isIdent := selector.X.(*ast.Ident); isIdent && ident.Obj == nil {
+			used[ident.Name] = true
+		}
+
+		return true
+	})
+
+	return used
+}
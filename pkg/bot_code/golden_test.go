@@ -0,0 +1,73 @@
+package botcode
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botAiTest "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai/botaitest"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithubTest "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github/botgithubtest"
+	"github.com/google/go-github/v57/github"
+)
+
+// update rewrites the golden files instead of comparing against them:
+// go test ./pkg/bot_code/ -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// assertGolden compares got with testdata/golden/<name>.golden
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", "golden", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", goldenPath, err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("reading %s (run with -update to create it): %v", goldenPath, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output doesn't match %s (run with -update to accept it)\n--- got ---\n%s\n--- want ---\n%s", goldenPath, got, want)
+	}
+}
+
+func TestPRBodyGolden(t *testing.T) {
+	handler := newMockedHandler(t, botConfig.LoadFromEnv("TEST_CODE_"), botGithubTest.NewMockClient(nil))
+
+	issue := &github.Issue{Number: github.Int(7), Title: github.String("Code: Add a doubling helper")}
+	codeFiles := []*CodeFile{
+		{Path: "pkg/helpers/helpers.go"},
+		{Path: "pkg/helpers/helpers_test.go"},
+	}
+
+	assertGolden(t, "pr_body", handler.generatePRBody(
+		issue,
+		codeFiles,
+		&botAi.Completion{Model: botAiTest.MockModel, Text: "Added Double with a table test."},
+	))
+}
+
+func TestChangelogPRBodyGolden(t *testing.T) {
+	pullRequests := []botAi.MergedPullRequest{
+		{Number: 12, Title: "Add a readiness check"},
+		{Number: 13, Title: "Fix webhook retries"},
+	}
+
+	notes := "### Features\n\n- Added /readyz (#12)\n\n### Fixes\n\n- Retried failed webhooks (#13)\n"
+
+	assertGolden(t, "changelog_pr_body", changelogPRBody("v1.2.0", pullRequests, notes))
+}
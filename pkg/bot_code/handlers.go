@@ -1,35 +1,184 @@
 package botcode
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botBlog "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_blog"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
 	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botHandler "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_handler"
+	botMerge "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_merge"
+	botNotify "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_notify"
+	botPreview "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_preview"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	botTriage "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_triage"
 	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
 	"github.com/google/go-github/v57/github"
 )
 
 // Handler manages webhook events and code operations
 type Handler struct {
-	AiClient      *botAi.Client
-	GithubClient  *botGithub.Client
+	AiClient      botAi.AIClient
+	Config        *botConfig.RepoConfig
+	BlogHandler   *botBlog.Handler // receives write-ups of merged PRs
+	GithubClient  botGithub.GithubAPI
 	Owner         string
 	Repo          string
+	Store         *botState.Store
 	WebhookSecret string
+
+	background *botHandler.Background
+	logger     *slog.Logger
+	merger     *botMerge.Merger
+	notifier   *botNotify.Dispatcher
+	previewer  *botPreview.Previewer
+	repoConfig *botConfig.RepoFileLoader
+	replies    *botReplies.Renderer
+	triager    *botTriage.Triager
 }
 
 // NewHandler creates a new code handler
 func NewHandler(handlerArgs Handler) *Handler {
-	return &Handler{
-		AiClient:      handlerArgs.AiClient,
+	handler := &Handler{
+		AiClient:      handlerArgs.AiClient.ForIssue(handlerArgs.Owner+"/"+handlerArgs.Repo, 0),
+		Config:        handlerArgs.Config,
+		BlogHandler:   handlerArgs.BlogHandler,
 		GithubClient:  handlerArgs.GithubClient,
 		Owner:         handlerArgs.Owner,
 		Repo:          handlerArgs.Repo,
+		Store:         handlerArgs.Store,
 		WebhookSecret: handlerArgs.WebhookSecret,
+		background:    &botHandler.Background{},
+		logger:        slog.Default(),
+		merger: botMerge.NewMerger(
+			botMerge.Merger{
+				GithubClient:     handlerArgs.GithubClient,
+				Method:           handlerArgs.Config.MergeMethod,
+				Owner:            handlerArgs.Owner,
+				Repo:             handlerArgs.Repo,
+				RequiredWorkflow: requiredWorkflow(handlerArgs.Config),
+				Store:            handlerArgs.Store,
+			},
+		),
+		notifier: botNotify.NewDispatcher(handlerArgs.Config.Notifications),
+		previewer: botPreview.NewPreviewer(
+			botPreview.Previewer{
+				GithubClient: handlerArgs.GithubClient,
+				Owner:        handlerArgs.Owner,
+				Repo:         handlerArgs.Repo,
+				Store:        handlerArgs.Store,
+			},
+		),
+		repoConfig: botConfig.NewRepoFileLoader(
+			botConfig.RepoFileLoader{
+				Base:         handlerArgs.Config,
+				GithubClient: handlerArgs.GithubClient,
+				Owner:        handlerArgs.Owner,
+				Repo:         handlerArgs.Repo,
+			},
+		),
+		replies: botReplies.NewRenderer(
+			botReplies.Renderer{
+				Dir:          handlerArgs.Config.RepliesDir,
+				GithubClient: handlerArgs.GithubClient,
+				Owner:        handlerArgs.Owner,
+				Repo:         handlerArgs.Repo,
+			},
+		),
+		triager: botTriage.NewTriager(
+			botTriage.Triager{
+				AiClient:     handlerArgs.AiClient,
+				GithubClient: handlerArgs.GithubClient,
+				Labels:       handlerArgs.Config.Labels,
+				Owner:        handlerArgs.Owner,
+				Repo:         handlerArgs.Repo,
+			},
+		),
+	}
+
+	// every AI call stops once the month's budget is used up
+	checkBudget := handler.common().CheckBudget
+	handler.AiClient = handler.AiClient.WithBudget(checkBudget)
+	handler.triager.AiClient = handler.triager.AiClient.WithBudget(checkBudget)
+
+	return handler
+}
+
+// WithLogger returns a copy of the handler whose log lines, and those of the
+// clients and helpers it uses, go to logger, e.g. one carrying a webhook's
+// delivery ID so everything it led to can be traced together
+func (handler *Handler) WithLogger(logger *slog.Logger) *Handler {
+	scoped := *handler
+	scoped.AiClient = handler.AiClient.WithLogger(logger)
+	scoped.logger = logger
+	scoped.merger = handler.merger.WithLogger(logger)
+	scoped.previewer = handler.previewer.WithLogger(logger)
+	scoped.repoConfig = handler.repoConfig.WithLogger(logger)
+	scoped.replies = handler.replies.WithLogger(logger)
+
+	if handler.BlogHandler != nil {
+		scoped.BlogHandler = handler.BlogHandler.WithLogger(logger)
 	}
+
+	return &scoped
+}
+
+// forIssue returns a copy of the handler whose AI spend is counted against
+// an issue or PR
+func (handler *Handler) forIssue(number int) *Handler {
+	scoped := *handler
+	scoped.AiClient = handler.AiClient.ForIssue(handler.common().FullRepoName(), number)
+
+	return &scoped
+}
+
+// withRepoConfig returns a copy of the handler using the settings in the
+// target repo's botConfig.RepoFile, including its AI model
+func (handler *Handler) withRepoConfig() *Handler {
+	scoped := *handler
+	scoped.Config = handler.repoConfig.Config()
+	scoped.AiClient = handler.AiClient.WithModel(scoped.Config.Model, scoped.Config.ModelFallbacks)
+	scoped.merger = handler.merger.WithRequiredWorkflow(requiredWorkflow(scoped.Config))
+
+	return &scoped
+}
+
+// common returns the helpers the blog and code handlers share, working with
+// this handler's settings, clients and logger
+func (handler *Handler) common() *botHandler.Common {
+	return botHandler.NewCommon(
+		botHandler.Common{
+			AiClient:     handler.AiClient,
+			Config:       handler.Config,
+			GithubClient: handler.GithubClient,
+			Logger:       handler.logger,
+			Merger:       handler.merger,
+			Owner:        handler.Owner,
+			Replies:      handler.replies,
+			Repo:         handler.Repo,
+			Store:        handler.Store,
+			Triager:      handler.triager,
+		},
+	)
+}
+
+// Wait blocks until the webhooks already answered have been handled, e.g.
+// before shutting down
+func (handler *Handler) Wait() {
+	handler.background.Wait()
+}
+
+// ApplyApprovedChanges commits previewed edits the requester gave a 👍
+func (handler *Handler) ApplyApprovedChanges() {
+	handler.previewer.ApplyApproved()
 }
 
 // HandleWebhook processes GitHub webhook events for code changes
@@ -39,41 +188,147 @@ func (handler *Handler) HandleWebhook(
 ) {
 	payload, err := github.ValidatePayload(request, []byte(handler.WebhookSecret))
 	if err != nil {
-		log.Printf("webhook validation failed: %v", err)
+		handler.logger.Warn("Webhook validation failed", "error", err)
 		http.Error(writer, "validation failed", http.StatusUnauthorized)
 		return
 	}
 
-	log.Printf("Received payload of length: %d", len(payload))
+	handler.logger.Debug("Received payload", "bytes", len(payload))
 
 	event, err := github.ParseWebHook(github.WebHookType(request), payload)
 	if err != nil {
-		log.Printf("webhook parsing failed: %v", err)
+		handler.logger.Warn("Webhook parsing failed", "error", err)
 		http.Error(writer, "parsing failed", http.StatusBadRequest)
 		return
 	}
 
+	// a push changing the settings file is handled before they're read
+	if push, isPush := event.(*github.PushEvent); isPush && botGithub.PushChangesFile(push, botConfig.RepoFile) {
+		handler.logger.Info("Repo config changed", "file", botConfig.RepoFile)
+		handler.repoConfig.Forget()
+	}
+
+	// GitHub gives up on a delivery after ten seconds, so it's answered once
+	// it's known to be genuine and handled afterwards
+	handler.background.Go(handler.logger, func() {
+		handler.forIssue(botGithub.EventNumber(event)).withRepoConfig().handleEvent(event)
+	})
+
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// handleEvent acts on a webhook event that has already been answered
+func (handler *Handler) handleEvent(event any) {
 	switch e := event.(type) {
 	case *github.IssuesEvent:
-		if *e.Action == "opened" {
+		switch e.GetAction() {
+		case "opened":
 			handler.HandleNewIssue(e.Issue)
+
+		case "edited":
+			// a title or label change doesn't change what was asked for
+			if e.GetChanges().GetBody() != nil {
+				handler.HandleIssueEdited(e.Issue, e.GetSender().GetLogin())
+			}
+
+		case "labeled":
+			handler.HandleIssueLabeled(e.Issue, e.Label, e.Sender)
+		}
+
+	case *github.IssueCommentEvent:
+		if e.GetAction() == "created" && !handler.common().IsOwnComment(e.GetComment().GetUser()) {
+			handler.HandleIssueComment(e.Issue, e.Comment)
 		}
 
 	case *github.PullRequestReviewCommentEvent:
-		if *e.Action == "created" {
+		if e.GetAction() == "created" && !handler.common().IsOwnComment(e.GetComment().GetUser()) {
 			handler.HandlePRComment(e.PullRequest, e.Comment)
 		}
-	}
 
-	writer.WriteHeader(http.StatusOK)
+	case *github.PullRequestEvent:
+		switch e.GetAction() {
+		case "closed":
+			handler.HandlePRClosed(e.PullRequest)
+
+		case "opened", "synchronize", "ready_for_review":
+			handler.handlePullRequestReview(e)
+		}
+
+	case *github.PullRequestReviewEvent:
+		if e.GetAction() == "submitted" {
+			handler.handleReviewSubmitted(e.PullRequest, e.Review)
+		}
+	}
 }
 
 // HandleNewIssue processes new GitHub issues for code changes
 func (handler *Handler) HandleNewIssue(issue *github.Issue) {
-	title := *issue.Title
-	body := *issue.Body
+	if !handler.isCodeRequest(issue.GetTitle()) {
+		// an issue opened with the trigger label is picked up by its labeled event
+		isHandledElsewhere := botHandler.HasLabel(issue, handler.Config.TriggerLabel) ||
+			handler.Config.IsBotSender(issue.GetUser().GetLogin())
+
+		if !isHandledElsewhere {
+			handler.common().TriageOrExplainFormat(issue, "code_format_help", `the title doesn't start with "Code:" or mention a feature, refactor or implementation`)
+		}
+
+		return
+	}
+
+	handler.startRequest(issue, issue.GetUser().GetLogin())
+}
+
+// HandleIssueLabeled starts a request when the trigger label is applied, for
+// issues whose title doesn't ask for the bot
+func (handler *Handler) HandleIssueLabeled(issue *github.Issue, label *github.Label, sender *github.User) {
+	isTriggerLabel := handler.Config.TriggerLabel != "" &&
+		strings.EqualFold(label.GetName(), handler.Config.TriggerLabel)
+
+	// titles that already ask for the bot were handled when the issue was opened
+	if !isTriggerLabel || handler.isCodeRequest(issue.GetTitle()) || handler.Config.IsBotSender(sender.GetLogin()) {
+		return
+	}
+
+	handler.startRequest(issue, sender.GetLogin())
+}
+
+// startRequest acknowledges a code change request and works on it, if the
+// requester may trigger the bot
+func (handler *Handler) startRequest(issue *github.Issue, requester string) {
+	title := issue.GetTitle()
+	body := issue.GetBody() // nil when the issue was opened without a description
+
+	if !handler.common().EnsureWritable(issue.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsAuthorized(requester, issue.GetNumber()) {
+		return
+	}
+
+	request := ParseIssueForCodeRequest(title, body)
+
+	if strings.TrimSpace(request.Description) == "" {
+		handler.common().ExplainFormat(issue, "code_format_help", "the issue body doesn't describe the change")
+		return
+	}
+
+	if !handler.common().IsWithinRateLimit(requester, issue.GetNumber()) {
+		return
+	}
+
+	if err := handler.common().CheckBudget(); err != nil {
+		handler.notifyBudgetExhausted(issue, err)
+
+		handler.GithubClient.CommentOnIssue(
+			botGithub.CommentOnIssueArgs{
+				Comment:     handler.budgetExhaustedComment(err),
+				IssueNumber: issue.GetNumber(),
+				Owner:       handler.Owner,
+				Repo:        handler.Repo,
+			},
+		)
 
-	if !handler.isCodeRequest(title) {
 		return
 	}
 
@@ -81,22 +336,49 @@ func (handler *Handler) HandleNewIssue(issue *github.Issue) {
 		botGithub.ReactToIssueArgs{
 			Owner:       handler.Owner,
 			Repo:        handler.Repo,
-			IssueNumber: *issue.Number,
+			IssueNumber: issue.GetNumber(),
 			Reaction:    "+1",
 		},
 	); err != nil {
-		log.Printf("Error reacting to issue: %v", err)
+		handler.logger.Error("Reacting to issue failed", "error", err)
 	}
 
-	request := ParseIssueForCodeRequest(title, body)
+	handler.common().AddLabels(issue.GetNumber(), handler.Config.Labels.Content)
+
+	if handler.isDryRun(issue) {
+		handler.previewChange(issue, request)
+		return
+	}
 
 	if err := handler.createCodeChangePR(issue, request); err != nil {
-		log.Printf("Error creating code change PR: %v", err)
+		handler.logger.Error("Creating code change PR failed", "error", err)
+
+		// a redelivered webhook finds its own PR already open; nothing to report
+		if errors.Is(err, botGithub.ErrBranchInUse) {
+			return
+		}
+
+		reason, _ := botErrors.UserMessage(err)
+		comment := handler.replies.Render("code_generation_failed", botReplies.Data{"Reason": reason})
+
+		if errors.Is(err, botGithub.ErrSecretDetected) {
+			comment = handler.changeFailureComment(err)
+		}
+
+		var compileErr *goCompileError
+		if errors.As(err, &compileErr) {
+			comment = handler.replies.Render(
+				"compile_failed",
+				botReplies.Data{"Path": compileErr.Path, "Problems": compileErr.UserMessage()},
+			)
+		}
+
+		handler.notifyGenerationFailed(issue, err)
 
 		handler.GithubClient.CommentOnIssue(
 			botGithub.CommentOnIssueArgs{
-				Comment:     "Sorry, I ran into an error creating the code change. Could you check the request format?",
-				IssueNumber: *issue.Number,
+				Comment:     comment + handler.common().FailureFooter(issue.GetNumber(), err),
+				IssueNumber: issue.GetNumber(),
 				Owner:       handler.Owner,
 				Repo:        handler.Repo,
 			},
@@ -104,81 +386,187 @@ func (handler *Handler) HandleNewIssue(issue *github.Issue) {
 	}
 }
 
-// createCodeChangePR generates code and creates a PR
+// createCodeChangePR lets the code agent write a change on a new branch and opens a PR
 func (handler *Handler) createCodeChangePR(
 	issue *github.Issue,
 	request *ChangeRequest,
 ) error {
+	branchName, err := handler.GithubClient.CreateBranch(
+		botGithub.CreateBranchArgs{
+			BranchName: fmt.Sprintf("ai-code-change-%d", issue.GetNumber()),
+			Owner:      handler.Owner,
+			Repo:       handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("creating branch: %w", err)
+	}
+
+	delivery := newProgressiveDelivery(handler, issue, request, branchName)
+
+	return handler.deliverCodeChange(delivery)
+}
+
+// deliverCodeChange runs the code agent on the delivery's branch, pushing
+// files as they are written and finishing with the PR body
+func (handler *Handler) deliverCodeChange(delivery *progressiveDelivery) error {
+	request := delivery.request
+
 	codeRequest := &botAi.CodeRequest{
 		Title:       request.Title,
 		Description: request.Description,
 		FileType:    request.FileType,
-		TargetPath:  request.TargetPath,
+		TargetPath:  DetermineTargetPath(request),
 		Tags:        request.Tags,
 	}
 
-	content, err := handler.AiClient.GenerateCode(codeRequest)
+	delivery.workspace = newRepoWorkspace(
+		newRepoWorkspaceArgs{
+			GithubClient: handler.GithubClient,
+			OnWrite:      delivery.onWrite,
+			Owner:        handler.Owner,
+			Paths:        handler.Config.Paths,
+			Policy:       handler.Config.SelfUpdate,
+			Ref:          delivery.branchName,
+			Repo:         handler.Repo,
+		},
+	)
+
+	completion, err := handler.AiClient.RunCodeAgent(codeRequest, delivery.workspace)
 	if err != nil {
+		delivery.fail(err)
 		return fmt.Errorf("AI code generation failed: %w", err)
 	}
 
-	targetPath := DetermineTargetPath(request)
+	if !request.SkipTests {
+		delivery.addTests()
+	}
+
+	return delivery.finish(completion)
+}
+
+// commitCodeFiles writes all files to the branch in a single commit and returns its SHA
+func (handler *Handler) commitCodeFiles(branchName string, codeFiles []*CodeFile) (string, error) {
+	changes := []botGithub.FileChange{}
+
+	for _, codeFile := range codeFiles {
+		changes = append(
+			changes,
+			botGithub.FileChange{
+				Content: formatCodeFile(codeFile.Path, codeFile.Content, handler.logger),
+				Path:    codeFile.Path,
+			},
+		)
+	}
 
-	codeFile := NewCodeFile(
-		CodeFile{
-			Content: content,
-			Message: GenerateCommitMessage(request, "Add"),
-			Path:    targetPath,
+	commitSHA, err := handler.GithubClient.CommitFiles(
+		botGithub.CommitFilesArgs{
+			Branch:  branchName,
+			Changes: changes,
+			Message: codeFiles[0].Message,
+			Owner:   handler.Owner,
+			Repo:    handler.Repo,
 		},
 	)
 
-	branchName := fmt.Sprintf("ai-code-change-%d", *issue.Number)
+	if err != nil {
+		return "", fmt.Errorf("committing files: %w", err)
+	}
 
-	if err := handler.GithubClient.CreateBranch(
-		botGithub.CreateBranchArgs{
-			BranchName: branchName,
-			Owner:      handler.Owner,
-			Repo:       handler.Repo,
-		},
-	); err != nil {
-		return fmt.Errorf("creating branch: %w", err)
+	return commitSHA, nil
+}
+
+// HandleIssueComment processes slash commands in PR conversation comments
+func (handler *Handler) HandleIssueComment(
+	issue *github.Issue,
+	comment *github.IssueComment,
+) {
+	commentBody := strings.TrimSpace(comment.GetBody())
+
+	isApplyCommand := strings.HasPrefix(commentBody, botPreview.ApplyCommand)
+	isExplainCommand := strings.HasPrefix(commentBody, explainCommand)
+	isReadyCommand := strings.HasPrefix(commentBody, botHandler.ReadyCommand)
+	isRegenerateCommand := strings.HasPrefix(commentBody, regenerateCommand)
+	isUndoCommand := strings.HasPrefix(commentBody, botHandler.UndoCommand)
+	isWriteUpCommand := strings.HasPrefix(commentBody, writeUpCommand)
+
+	isCommand := isApplyCommand || isExplainCommand || isReadyCommand || isRegenerateCommand || isUndoCommand || isWriteUpCommand
+
+	if !isCommand || !botGithub.IsPullRequest(issue) {
+		return
+	}
+
+	if !handler.common().IsAuthorized(comment.GetUser().GetLogin(), issue.GetNumber()) {
+		return
 	}
 
-	message := codeFile.Message
+	if !handler.common().IsWithinRateLimit(comment.GetUser().GetLogin(), issue.GetNumber()) {
+		return
+	}
 
-	if err := handler.GithubClient.CreateFile(
-		botGithub.CreateFileArgs{
-			Branch:   branchName,
-			Content:  codeFile.Content,
-			Filename: codeFile.Path,
-			Message:  message,
-			Owner:    handler.Owner,
-			Repo:     handler.Repo,
+	if err := handler.GithubClient.ReactToIssueComment(
+		botGithub.ReactToIssueCommentArgs{
+			CommentID: comment.GetID(),
+			Owner:     handler.Owner,
+			Reaction:  "+1",
+			Repo:      handler.Repo,
 		},
 	); err != nil {
-		return fmt.Errorf("creating file: %w", err)
+		handler.logger.Error("Reacting to issue comment failed", "error", err)
 	}
 
-	title := fmt.Sprintf("Add code: %s", request.Title)
-	body := handler.generatePRBody(issue, codeFile)
-	head := fmt.Sprintf("%s:%s", handler.Owner, branchName)
+	if isApplyCommand {
+		if err := handler.previewer.Apply(issue.GetNumber(), comment.GetUser().GetLogin()); err != nil {
+			handler.GithubClient.CommentOnPR(
+				botGithub.CommentOnPRArgs{
+					Comment:  handler.common().CommandFailedComment(issue.GetNumber(), "apply that", err),
+					Owner:    handler.Owner,
+					PrNumber: issue.GetNumber(),
+					Repo:     handler.Repo,
+				},
+			)
+		}
 
-	_, err = handler.GithubClient.CreatePullRequest(
-		botGithub.CreatePullRequestArgs{
-			Base:  "main",
-			Body:  body,
-			Head:  head,
-			Owner: handler.Owner,
-			Repo:  handler.Repo,
-			Title: title,
-		},
-	)
+		return
+	}
 
-	if err != nil {
-		return fmt.Errorf("creating PR: %w", err)
+	if isUndoCommand {
+		handler.common().HandleUndoCommand(issue.GetNumber())
+		return
+	}
+
+	if isReadyCommand {
+		handler.common().HandleReadyCommand(issue.GetNumber())
+		return
+	}
+
+	if isRegenerateCommand {
+		if handler.common().EnsureWritable(issue.GetNumber()) {
+			handler.handleRegenerateCommand(issue.GetNumber())
+		}
+
+		return
+	}
+
+	if isExplainCommand {
+		filePath := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(commentBody, explainCommand)), "\n", 2)[0]
+		handler.handleExplainCommand(issue.GetNumber(), strings.TrimSpace(filePath))
+		return
 	}
 
-	return nil
+	if err := handler.handleWriteUp(issue.GetNumber()); err != nil {
+		handler.logger.Error("Creating write-up failed", "error", err)
+
+		handler.GithubClient.CommentOnPR(
+			botGithub.CommentOnPRArgs{
+				Comment:  handler.common().CommandFailedComment(issue.GetNumber(), "draft a write-up for this PR", err),
+				Owner:    handler.Owner,
+				PrNumber: issue.GetNumber(),
+				Repo:     handler.Repo,
+			},
+		)
+	}
 }
 
 // HandlePRComment processes comments on pull requests
@@ -186,31 +574,64 @@ func (handler *Handler) HandlePRComment(
 	pullRequest *github.PullRequest,
 	comment *github.PullRequestComment,
 ) {
-	commentBody := *comment.Body
+	commentBody := comment.GetBody()
+	trimmedBody := strings.TrimSpace(commentBody)
+
+	isExplainCommand := strings.HasPrefix(trimmedBody, explainCommand)
+	isSuggestCommand := strings.HasPrefix(trimmedBody, botHandler.SuggestCommand)
+
+	if !isExplainCommand && !isSuggestCommand && !handler.isChangeRequest(commentBody) {
+		return
+	}
+
+	if !handler.common().EnsureWritable(pullRequest.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsAuthorized(comment.GetUser().GetLogin(), pullRequest.GetNumber()) {
+		return
+	}
+
+	if !handler.common().IsWithinRateLimit(comment.GetUser().GetLogin(), pullRequest.GetNumber()) {
+		return
+	}
 
 	if err := handler.GithubClient.ReactToPRComment(
 		botGithub.ReactToPRCommentArgs{
 			Owner:     handler.Owner,
 			Repo:      handler.Repo,
-			CommentID: *comment.ID,
+			CommentID: comment.GetID(),
 			Reaction:  "+1",
 		},
 	); err != nil {
-		log.Printf("Error reacting to PR comment: %v", err)
+		handler.logger.Error("Reacting to PR comment failed", "error", err)
 	}
 
-	if !handler.isChangeRequest(commentBody) {
+	if isExplainCommand {
+		question := strings.TrimSpace(strings.TrimPrefix(trimmedBody, explainCommand))
+		handler.handleExplainReview(pullRequest, comment, question)
 		return
 	}
 
-	if err := handler.handleCodeModification(pullRequest, commentBody); err != nil {
-		log.Printf("Error updating code: %v", err)
+	if isSuggestCommand {
+		changeRequest := strings.TrimSpace(strings.TrimPrefix(trimmedBody, botHandler.SuggestCommand))
+		handler.common().HandleSuggestion(pullRequest, comment, changeRequest)
+		return
+	}
+
+	if handler.Config.EditMode == botConfig.EditModeSuggest {
+		handler.common().HandleSuggestion(pullRequest, comment, commentBody)
+		return
+	}
+
+	if err := handler.handleCodeModification(pullRequest, comment); err != nil {
+		handler.logger.Error("Updating code failed", "error", err)
 
 		handler.GithubClient.CommentOnPR(
 			botGithub.CommentOnPRArgs{
-				Comment:  "Sorry, I had trouble making that change. Could you be more specific?",
+				Comment:  handler.changeFailureComment(err) + handler.common().FailureFooter(pullRequest.GetNumber(), err),
 				Owner:    handler.Owner,
-				PrNumber: *pullRequest.Number,
+				PrNumber: pullRequest.GetNumber(),
 				Repo:     handler.Repo,
 			},
 		)
@@ -218,43 +639,56 @@ func (handler *Handler) HandlePRComment(
 		return
 	}
 
+	// a committed edit gets its 🚀 once CI passes
+	if handler.Config.WaitForCI && !handler.Config.ConfirmEdits {
+		return
+	}
+
 	handler.GithubClient.ReactToPRComment(
 		botGithub.ReactToPRCommentArgs{
 			Owner:     handler.Owner,
 			Repo:      handler.Repo,
-			CommentID: *comment.ID,
+			CommentID: comment.GetID(),
 			Reaction:  "rocket",
 		},
 	)
 }
 
-// handleCodeModification modifies code based on feedback
+// handleCodeModification modifies the file a review comment was left on
 func (handler *Handler) handleCodeModification(
 	pullRequest *github.PullRequest,
-	changeRequest string,
+	comment *github.PullRequestComment,
 ) error {
-	files, err := handler.GithubClient.ListPullRequestFiles(
-		botGithub.ListPullRequestFilesArgs{
-			Owner:    handler.Owner,
-			Repo:     handler.Repo,
-			PrNumber: *pullRequest.Number,
-		},
-	)
+	changeRequest := comment.GetBody()
+	commentedPath := comment.GetPath()
 
+	patches, err := handler.pullRequestPatches(pullRequest.GetNumber())
 	if err != nil {
-		return fmt.Errorf("getting PR files: %w", err)
+		return err
 	}
 
-	for _, file := range files {
-		if !strings.HasSuffix(*file.Filename, ".go") {
+	for _, file := range patches {
+		isCommentedFile := commentedPath == "" || file.Path == commentedPath
+
+		if !strings.HasSuffix(file.Path, ".go") || !isCommentedFile {
+			continue
+		}
+
+		if handler.Config.SelfUpdate.IsProtected(file.Path) {
+			handler.logger.Info("Skipping protected file", "path", file.Path)
+			continue
+		}
+
+		if !handler.Config.Paths.IsAllowed(file.Path) {
+			handler.logger.Info("Skipping file outside the paths the bot may write to", "path", file.Path)
 			continue
 		}
 
 		currentContent, sha, err := handler.GithubClient.GetFileContent(
 			botGithub.GetFileContentArgs{
-				Filename: *file.Filename,
+				Filename: file.Path,
 				Owner:    handler.Owner,
-				Ref:      *pullRequest.Head.Ref,
+				Ref:      pullRequest.GetHead().GetRef(),
 				Repo:     handler.Repo,
 			},
 		)
@@ -263,42 +697,149 @@ func (handler *Handler) handleCodeModification(
 			return fmt.Errorf("getting file content: %w", err)
 		}
 
+		// a comment without a hunk of its own gets what the PR changed in the file
+		diffHunk := comment.GetDiffHunk()
+		if diffHunk == "" {
+			diffHunk = file.Patch
+		}
+
 		updatedContent, err := handler.AiClient.ModifyCode(
 			currentContent,
 			changeRequest,
+			diffHunk,
+			handler.conversationHistory(pullRequest.GetNumber()),
 		)
 
 		if err != nil {
 			return fmt.Errorf("AI modification failed: %w", err)
 		}
 
+		handler.recordConversationTurn(pullRequest.GetNumber(), file.Path, changeRequest)
+
+		updatedContent = formatCodeFile(file.Path, updatedContent, handler.logger)
+
 		message := fmt.Sprintf(
 			"Update code based on feedback: %s",
 			sharedUtils.TruncateText(changeRequest, 50),
 		)
 
-		if err := handler.GithubClient.UpdateFile(
+		if handler.Config.ConfirmEdits {
+			return handler.previewer.Propose(
+				botPreview.ProposeArgs{
+					Author:     comment.GetUser().GetLogin(),
+					Branch:     pullRequest.GetHead().GetRef(),
+					CommentID:  comment.GetID(),
+					Content:    updatedContent,
+					Message:    message,
+					OldContent: currentContent,
+					Path:       file.Path,
+					PrNumber:   pullRequest.GetNumber(),
+					Sha:        sha,
+				},
+			)
+		}
+
+		commitSHA, err := handler.GithubClient.UpdateFile(
 			botGithub.UpdateFileArgs{
-				Branch:   *pullRequest.Head.Ref,
+				Branch:   pullRequest.GetHead().GetRef(),
 				Content:  updatedContent,
-				Filename: *file.Filename,
+				Filename: file.Path,
 				Message:  message,
 				Owner:    handler.Owner,
 				Repo:     handler.Repo,
 				Sha:      sha,
 			},
-		); err != nil {
+		)
+
+		if err != nil {
 			return fmt.Errorf("updating file: %w", err)
 		}
 
-		break
+		handler.common().RecordBotCommit(pullRequest.GetNumber(), pullRequest.GetHead().GetRef(), commitSHA)
+		handler.watchCI(pullRequest.GetNumber(), pullRequest.GetHead().GetRef(), commitSHA, comment.GetID())
+		handler.common().DispatchWorkflow(pullRequest.GetNumber(), pullRequest.GetHead().GetRef())
+
+		handler.startSelfUpdateChecks(pullRequest.GetNumber(), pullRequest.GetHead().GetRef())
+
+		return nil
+	}
+
+	if commentedPath == "" {
+		return fmt.Errorf("no Go file found in PR #%d", pullRequest.GetNumber())
 	}
 
-	return nil
+	return fmt.Errorf("%s is not a Go file the bot can change in PR #%d", commentedPath, pullRequest.GetNumber())
+}
+
+// HandlePRClosed records how a bot-created PR ended and cleans up after it
+func (handler *Handler) HandlePRClosed(pullRequest *github.PullRequest) {
+	// a redelivered close event has already been handled
+	if handler.Store.IsJobDone(handler.common().FullRepoName(), pullRequest.GetNumber()) {
+		return
+	}
+
+	if err := handler.Store.RecordPullRequestClosed(
+		botState.RecordPullRequestClosedArgs{
+			IsMerged:     pullRequest.GetMerged(),
+			Number:       pullRequest.GetNumber(),
+			Repo:         handler.common().FullRepoName(),
+			TotalCommits: pullRequest.GetCommits(),
+		},
+	); err != nil {
+		handler.logger.Error("Recording PR metrics failed", "error", err)
+	}
+
+	handler.common().FinishJob(pullRequest, outcomeComment)
 }
 
 // Helper methods
 
+// changeFailureComment explains why a requested change wasn't made
+func (handler *Handler) changeFailureComment(err error) string {
+	reason, _ := botErrors.UserMessage(err)
+
+	if errors.Is(err, botGithub.ErrSecretDetected) {
+		return handler.replies.Render("secret_detected", botReplies.Data{"Reason": reason})
+	}
+
+	if errors.Is(err, botAi.ErrContentTooLarge) || errors.Is(err, botAi.ErrOutputTruncated) {
+		return handler.replies.Render("content_too_large", botReplies.Data{"Kind": "file", "Reason": reason})
+	}
+
+	return handler.replies.Render("change_failed", botReplies.Data{"Reason": reason})
+}
+
+// conversationHistory returns the earlier change requests on a PR for the AI
+func (handler *Handler) conversationHistory(prNumber int) []botAi.Turn {
+	history := []botAi.Turn{}
+
+	for _, turn := range handler.Store.ConversationHistory(handler.common().FullRepoName(), prNumber) {
+		history = append(history, botAi.Turn{Path: turn.Path, Request: turn.Request, Response: turn.Response})
+	}
+
+	return history
+}
+
+func (handler *Handler) recordConversationTurn(prNumber int, path, request string) {
+	if err := handler.Store.AppendConversationTurn(
+		handler.common().FullRepoName(),
+		prNumber,
+		botState.ConversationTurn{
+			CreatedAt: time.Now(),
+			Path:      path,
+			Request:   request,
+			Response:  fmt.Sprintf("I edited %s to address this.", path),
+		},
+	); err != nil {
+		handler.logger.Error("Recording conversation failed", "error", err)
+	}
+}
+
+// isTriggered reports whether an issue asks for the bot, by its title or the trigger label
+func (handler *Handler) isTriggered(issue *github.Issue) bool {
+	return handler.isCodeRequest(issue.GetTitle()) || botHandler.HasLabel(issue, handler.Config.TriggerLabel)
+}
+
 func (handler *Handler) isCodeRequest(title string) bool {
 	lowerTitle := strings.ToLower(title)
 
@@ -325,13 +866,44 @@ func (handler *Handler) isChangeRequest(comment string) bool {
 	return false
 }
 
-func (handler *Handler) generatePRBody(issue *github.Issue, codeFile *CodeFile) string {
-	return fmt.Sprintf(`🤖 AI-generated code change based on issue #%d
+func (handler *Handler) generatePRBody(
+	issue *github.Issue,
+	codeFiles []*CodeFile,
+	completion *botAi.Completion,
+) string {
+	filePaths := []string{}
 
-**File:** %s
-**Description:** %s
+	for _, codeFile := range codeFiles {
+		filePaths = append(filePaths, codeFile.Path)
+	}
 
-This code was automatically generated. Feel free to comment with any changes you'd like me to make!
+	return handler.replies.Render(
+		"code_pr_body",
+		botReplies.Data{
+			"Description": issue.GetTitle(),
+			"Files":       filePaths,
+			"GeneratedBy": completion.Model + handler.costNote(issue.GetNumber()),
+			"Issue":       issue.GetNumber(),
+			"Summary":     completion.Text,
+		},
+	)
+}
 
-Closes #%d`, *issue.Number, codeFile.Path, *issue.Title, *issue.Number)
+// costNote is the estimated AI spend on an issue so far, e.g. " (~$0.12)",
+// when the repo shows costs and anything has been spent
+func (handler *Handler) costNote(issueNumber int) string {
+	if !handler.Config.ShowCost {
+		return ""
+	}
+
+	cost := handler.Store.IssueCost(handler.common().FullRepoName(), issueNumber).CostUSD
+
+	switch {
+	case cost <= 0:
+		return ""
+	case cost < 0.01:
+		return " (<$0.01)"
+	default:
+		return fmt.Sprintf(" (~$%.2f)", cost)
+	}
 }
@@ -0,0 +1,12 @@
+package botcode
+
+// ModifyFile applies an edit request to a file's content, as a review
+// comment on its PR would, formatting Go files the same way
+func (handler *Handler) ModifyFile(filePath, content, instruction string) (string, error) {
+	modified, err := handler.AiClient.ModifyCode(content, instruction, "", nil)
+	if err != nil {
+		return "", err
+	}
+
+	return formatCodeFile(filePath, modified, handler.logger), nil
+}
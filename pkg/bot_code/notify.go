@@ -0,0 +1,53 @@
+package botcode
+
+import (
+	"fmt"
+
+	botNotify "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_notify"
+	"github.com/google/go-github/v57/github"
+)
+
+// notify sends an event to the repo's chat channels; a broken webhook is
+// logged rather than getting in the way of the bot's work
+func (handler *Handler) notify(event botNotify.Event) {
+	if err := handler.notifier.Send(event); err != nil {
+		handler.logger.Error("Sending notification failed", "event", event.Type, "error", err)
+	}
+}
+
+// notifyPullRequestOpened tells the repo's chat channels about a new bot PR
+func (handler *Handler) notifyPullRequestOpened(pullRequest *github.PullRequest) {
+	handler.notify(
+		botNotify.Event{
+			Repo: handler.common().FullRepoName(),
+			Text: fmt.Sprintf("Opened PR #%d: %s", pullRequest.GetNumber(), pullRequest.GetTitle()),
+			Type: botNotify.EventPRCreated,
+			URL:  pullRequest.GetHTMLURL(),
+		},
+	)
+}
+
+// notifyGenerationFailed tells the repo's chat channels a request couldn't be written
+func (handler *Handler) notifyGenerationFailed(issue *github.Issue, err error) {
+	handler.notify(
+		botNotify.Event{
+			Repo: handler.common().FullRepoName(),
+			Text: fmt.Sprintf("Couldn't write the change for issue #%d: %v", issue.GetNumber(), err),
+			Type: botNotify.EventGenerationFailed,
+			URL:  issue.GetHTMLURL(),
+		},
+	)
+}
+
+// notifyBudgetExhausted tells the repo's chat channels a request was turned
+// down because the AI budget is used up
+func (handler *Handler) notifyBudgetExhausted(issue *github.Issue, err error) {
+	handler.notify(
+		botNotify.Event{
+			Repo: handler.common().FullRepoName(),
+			Text: fmt.Sprintf("Turned down issue #%d: %v", issue.GetNumber(), err),
+			Type: botNotify.EventBudgetExhausted,
+			URL:  issue.GetHTMLURL(),
+		},
+	)
+}
@@ -0,0 +1,27 @@
+package botcode
+
+import "github.com/google/go-github/v57/github"
+
+// handleReviewSubmitted takes a maintainer's approval of a bot PR as the go
+// ahead to make it ready and, with auto-merge on, merge it
+func (handler *Handler) handleReviewSubmitted(pullRequest *github.PullRequest, review *github.PullRequestReview) {
+	if !handler.common().IsMaintainerApproval(pullRequest, review) {
+		return
+	}
+
+	// the branch's code runs only after a person with write access vouches for this version of it
+	if handler.isSelfUpdate() && handler.Config.SelfUpdate.RequireApproval &&
+		review.GetCommitID() == pullRequest.GetHead().GetSHA() {
+		handler.runSelfUpdateChecks(pullRequest.GetNumber(), pullRequest.GetHead().GetRef())
+	}
+
+	// a self-update is only auto-merged once that run passes, which
+	// MergeApprovedPRs picks up when it hasn't yet
+	handler.common().HandleApproval(pullRequest, review)
+}
+
+// MergeApprovedPRs merges approved bot PRs whose checks have passed since the
+// approval came in
+func (handler *Handler) MergeApprovedPRs() {
+	handler.withRepoConfig().common().MergeApprovedPRs()
+}
@@ -0,0 +1,92 @@
+package botcode
+
+import (
+	"fmt"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// handlePullRequestReview posts an AI review when a PR is opened or pushed
+// to, if reviews are turned on for the repo
+func (handler *Handler) handlePullRequestReview(event *github.PullRequestEvent) {
+	pullRequest := event.GetPullRequest()
+
+	if !handler.Config.ReviewPRs || pullRequest.GetDraft() {
+		return
+	}
+
+	// the bot's own pushes would otherwise get a fresh review after every edit
+	if event.GetAction() == "synchronize" && handler.Config.IsBotSender(event.GetSender().GetLogin()) {
+		return
+	}
+
+	if err := handler.reviewPullRequest(pullRequest); err != nil {
+		handler.logger.Error("Reviewing PR failed", "pr", pullRequest.GetNumber(), "error", err)
+	}
+}
+
+func (handler *Handler) reviewPullRequest(pullRequest *github.PullRequest) error {
+	patches, err := handler.pullRequestPatches(pullRequest.GetNumber())
+	if err != nil {
+		return err
+	}
+
+	request := &botAi.ReviewRequest{
+		Description: pullRequest.GetBody(),
+		Title:       pullRequest.GetTitle(),
+	}
+
+	commentableLines := map[string]map[int]bool{}
+
+	for _, file := range patches {
+		// binary files, bare renames and very large files come without a patch
+		if file.Patch == "" {
+			continue
+		}
+
+		request.Files = append(request.Files, botAi.ReviewFile{Patch: file.Patch, Path: file.Path})
+		commentableLines[file.Path] = botGithub.CommentableLines(file.Patch)
+	}
+
+	if len(request.Files) == 0 {
+		return nil
+	}
+
+	review, err := handler.AiClient.ReviewPullRequest(request)
+	if err != nil {
+		return fmt.Errorf("AI review failed: %w", err)
+	}
+
+	inline := []botGithub.ReviewComment{}
+	outOfDiff := []string{}
+
+	// GitHub rejects the whole review if any comment is on a line outside the diff
+	for _, note := range review.Comments {
+		if commentableLines[note.Path][note.Line] {
+			inline = append(inline, botGithub.ReviewComment{Body: note.Body, Line: note.Line, Path: note.Path})
+			continue
+		}
+
+		outOfDiff = append(outOfDiff, fmt.Sprintf("- `%s:%d` %s", note.Path, note.Line, note.Body))
+	}
+
+	body := fmt.Sprintf("🤖 **AI review** (%s)\n\n%s", review.Model, review.Summary)
+	if len(outOfDiff) > 0 {
+		body += "\n\n" + strings.Join(outOfDiff, "\n")
+	}
+
+	return handler.GithubClient.CreatePullRequestReview(
+		botGithub.CreatePullRequestReviewArgs{
+			Body:     body,
+			Comments: inline,
+			CommitID: pullRequest.GetHead().GetSHA(),
+			Event:    "COMMENT",
+			Owner:    handler.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     handler.Repo,
+		},
+	)
+}
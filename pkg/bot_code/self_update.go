@@ -0,0 +1,91 @@
+package botcode
+
+import (
+	"fmt"
+	"strings"
+
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+)
+
+// isSelfUpdate reports whether this handler's PRs change the bot's own source
+func (handler *Handler) isSelfUpdate() bool {
+	return handler.Config.SelfUpdate.Enabled
+}
+
+// requiredWorkflow is the workflow a PR's head commit must pass before it's
+// auto-merged: the sandbox build and test for self-updates, and none otherwise
+func requiredWorkflow(config *botConfig.RepoConfig) string {
+	if !config.SelfUpdate.Enabled {
+		return ""
+	}
+
+	return config.SelfUpdate.ChecksWorkflow
+}
+
+// selfUpdateBanner renders the warning shown at the top of every self-update
+// PR, saying where and when the branch is built and tested
+func (handler *Handler) selfUpdateBanner() string {
+	policy := handler.Config.SelfUpdate
+
+	var banner strings.Builder
+
+	banner.WriteString("> [!WARNING]\n")
+	banner.WriteString("> **Self-update:** this PR changes the bot's own source code. ")
+	banner.WriteString("Once merged and deployed, it changes how the bot itself behaves.\n>\n")
+
+	if len(policy.ProtectedPaths) > 0 {
+		banner.WriteString(fmt.Sprintf("> Protected paths the bot may not change: `%s`\n", strings.Join(policy.ProtectedPaths, "`, `")))
+	}
+
+	switch {
+	case policy.RequireApproval:
+		banner.WriteString(fmt.Sprintf(
+			">\n> The `%s` workflow builds and tests this branch once someone with write access approves it, "+
+				"and again after each approval of a new push.\n",
+			policy.ChecksWorkflow,
+		))
+
+	default:
+		banner.WriteString(fmt.Sprintf(">\n> The `%s` workflow builds and tests this branch after each push.\n", policy.ChecksWorkflow))
+	}
+
+	return banner.String()
+}
+
+// startSelfUpdateChecks runs the sandbox workflow on a self-update branch the
+// bot just pushed, unless the policy waits for a human approval first. The
+// branch's code only ever runs in Actions, never next to the bot's tokens
+func (handler *Handler) startSelfUpdateChecks(prNumber int, branch string) {
+	if !handler.isSelfUpdate() || handler.Config.SelfUpdate.RequireApproval {
+		return
+	}
+
+	handler.runSelfUpdateChecks(prNumber, branch)
+}
+
+// runSelfUpdateChecks dispatches the sandbox workflow on a branch and links
+// the run on the PR
+func (handler *Handler) runSelfUpdateChecks(prNumber int, branch string) {
+	workflow := handler.Config.SelfUpdate.ChecksWorkflow
+	if workflow == "" {
+		return
+	}
+
+	runURL, err := handler.GithubClient.DispatchWorkflow(
+		botGithub.DispatchWorkflowArgs{
+			Owner:    handler.Owner,
+			Ref:      branch,
+			Repo:     handler.Repo,
+			Workflow: workflow,
+		},
+	)
+
+	if err != nil {
+		handler.logger.Error("Dispatching self-update checks failed", "workflow", workflow, "error", err)
+		handler.common().CommentOnPR(prNumber, fmt.Sprintf("⚠️ I couldn't start the `%s` sandbox workflow; the details are in the bot logs.", workflow))
+		return
+	}
+
+	handler.common().CommentOnPR(prNumber, fmt.Sprintf("🧪 Building and testing `%s` in the [`%s` workflow](%s).", branch, workflow, runURL))
+}
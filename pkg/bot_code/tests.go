@@ -0,0 +1,43 @@
+package botcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// testPathFor returns the _test.go companion path for a Go file
+func testPathFor(filePath string) string {
+	return strings.TrimSuffix(filePath, ".go") + "_test.go"
+}
+
+// addTests asks the AI for a test file for each Go file the agent wrote,
+// skipping files that already have tests on the branch or in the change
+func (delivery *progressiveDelivery) addTests() {
+	workspace := delivery.workspace
+
+	for _, codeFile := range workspace.StagedFiles("") {
+		isTestable := strings.HasSuffix(codeFile.Path, ".go") && !strings.HasSuffix(codeFile.Path, "_test.go")
+		if !isTestable {
+			continue
+		}
+
+		testPath := testPathFor(codeFile.Path)
+
+		// an existing test file is never replaced
+		if _, err := workspace.ReadFile(testPath); err == nil {
+			continue
+		}
+
+		delivery.report(fmt.Sprintf("🧪 writing tests for `%s`", codeFile.Path))
+
+		completion, err := delivery.handler.AiClient.GenerateTests(codeFile.Path, codeFile.Content)
+		if err != nil {
+			delivery.handler.logger.Error("Generating tests failed", "path", codeFile.Path, "error", err)
+			continue
+		}
+
+		if err := workspace.WriteFile(testPath, completion.Text); err != nil {
+			delivery.handler.logger.Error("Staging tests failed", "path", testPath, "error", err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package botcode
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// goCompileError is a generated Go file that still doesn't compile after the
+// AI had a chance to correct it
+type goCompileError struct {
+	Err  error
+	Path string
+}
+
+func (compileError *goCompileError) Error() string {
+	return fmt.Sprintf("%s doesn't compile: %v", compileError.Path, compileError.Err)
+}
+
+func (compileError *goCompileError) Unwrap() error {
+	return compileError.Err
+}
+
+func (compileError *goCompileError) Category() string {
+	return "compile"
+}
+
+// UserMessage lists the syntax errors by line, without the parser's file
+// paths or anything else from the error chain
+func (compileError *goCompileError) UserMessage() string {
+	var errorList scanner.ErrorList
+	if !errors.As(compileError.Err, &errorList) {
+		return "it has syntax errors"
+	}
+
+	lines := []string{}
+
+	for _, syntaxError := range errorList {
+		lines = append(lines, fmt.Sprintf("line %d: %s", syntaxError.Pos.Line, syntaxError.Msg))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkGoSource parses a Go file, catching the syntax errors that would stop
+// it compiling; other files pass
+func checkGoSource(filePath, content string) error {
+	if !strings.HasSuffix(filePath, ".go") {
+		return nil
+	}
+
+	_, err := parser.ParseFile(token.NewFileSet(), filePath, content, parser.AllErrors)
+
+	return err
+}
+
+// checkFiles returns the files that are safe to commit. Mid-generation, broken
+// Go files are held back since the agent may still rewrite them; on the final
+// push each gets one AI correction round and any still broken fail the push
+func (delivery *progressiveDelivery) checkFiles(codeFiles []*CodeFile, isFinal bool) ([]*CodeFile, error) {
+	checked := []*CodeFile{}
+
+	for _, codeFile := range codeFiles {
+		err := checkGoSource(codeFile.Path, codeFile.Content)
+
+		if err != nil && !isFinal {
+			delivery.handler.logger.Warn("Holding back file until it compiles", "path", codeFile.Path, "error", err)
+			continue
+		}
+
+		if err != nil {
+			if err := delivery.correct(codeFile, err); err != nil {
+				return nil, err
+			}
+		}
+
+		checked = append(checked, codeFile)
+	}
+
+	return checked, nil
+}
+
+// correct feeds a compile error back to the AI for one corrective round
+func (delivery *progressiveDelivery) correct(codeFile *CodeFile, compileErr error) error {
+	delivery.report(fmt.Sprintf("🔧 `%s` doesn't compile, asking for a fix", codeFile.Path))
+
+	fixed, err := delivery.handler.AiClient.FixCode(codeFile.Path, codeFile.Content, compileErr.Error())
+	if err != nil {
+		return fmt.Errorf("fixing %s: %w", codeFile.Path, err)
+	}
+
+	if err := checkGoSource(codeFile.Path, fixed); err != nil {
+		return &goCompileError{Err: err, Path: codeFile.Path}
+	}
+
+	codeFile.Content = fixed
+	delivery.workspace.restage(codeFile.Path, fixed)
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package botcode
+
+import (
+	"fmt"
+	"path"
+
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// repoWorkspace lets the code agent read a branch of the target repo while
+// staging its writes in memory until they are committed together
+type repoWorkspace struct {
+	githubClient botGithub.GithubAPI
+	onWrite      func()
+	order        []string
+	owner        string
+	paths        botConfig.PathPolicy
+	policy       botConfig.SelfUpdatePolicy
+	ref          string
+	repo         string
+	staged       map[string]string
+}
+
+type newRepoWorkspaceArgs struct {
+	GithubClient botGithub.GithubAPI
+	OnWrite      func() // called after every staged write
+	Owner        string
+	Paths        botConfig.PathPolicy       // rejects writes outside the allowed paths
+	Policy       botConfig.SelfUpdatePolicy // rejects writes to protected paths
+	Ref          string
+	Repo         string
+}
+
+func newRepoWorkspace(args newRepoWorkspaceArgs) *repoWorkspace {
+	return &repoWorkspace{
+		githubClient: args.GithubClient,
+		onWrite:      args.OnWrite,
+		owner:        args.Owner,
+		paths:        args.Paths,
+		policy:       args.Policy,
+		ref:          args.Ref,
+		repo:         args.Repo,
+		staged:       map[string]string{},
+	}
+}
+
+// ListDirectory lists a directory on the branch, including staged new files
+func (workspace *repoWorkspace) ListDirectory(dirPath string) ([]string, error) {
+	dirPath = cleanWorkspacePath(dirPath)
+
+	contents, err := workspace.githubClient.ListDirectory(
+		botGithub.ListDirectoryArgs{
+			Owner: workspace.owner,
+			Path:  dirPath,
+			Ref:   workspace.ref,
+			Repo:  workspace.repo,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []string{}
+	seen := map[string]bool{}
+
+	for _, content := range contents {
+		entry := content.GetPath()
+
+		if content.GetType() == "dir" {
+			entry += "/"
+		}
+
+		seen[content.GetPath()] = true
+		entries = append(entries, entry)
+	}
+
+	for _, stagedPath := range workspace.order {
+		isInDirectory := path.Dir(stagedPath) == dirPath ||
+			(dirPath == "" && path.Dir(stagedPath) == ".")
+
+		if isInDirectory && !seen[stagedPath] {
+			entries = append(entries, stagedPath)
+		}
+	}
+
+	return entries, nil
+}
+
+// ReadFile returns staged content if the agent already wrote the file
+func (workspace *repoWorkspace) ReadFile(filePath string) (string, error) {
+	filePath = cleanWorkspacePath(filePath)
+
+	if content, isStaged := workspace.staged[filePath]; isStaged {
+		return content, nil
+	}
+
+	content, _, err := workspace.githubClient.GetFileContent(
+		botGithub.GetFileContentArgs{
+			Filename: filePath,
+			Owner:    workspace.owner,
+			Ref:      workspace.ref,
+			Repo:     workspace.repo,
+		},
+	)
+
+	return content, err
+}
+
+// WriteFile stages a file to be committed once the agent finishes
+func (workspace *repoWorkspace) WriteFile(filePath, content string) error {
+	filePath = cleanWorkspacePath(filePath)
+
+	if filePath == "" {
+		return fmt.Errorf("a file path is required")
+	}
+
+	if workspace.policy.IsProtected(filePath) {
+		return fmt.Errorf("%s is protected by the self-update policy and can't be changed by the bot", filePath)
+	}
+
+	if !workspace.paths.IsAllowed(filePath) {
+		return fmt.Errorf("%s is outside the paths the bot may write to", filePath)
+	}
+
+	if _, isStaged := workspace.staged[filePath]; !isStaged {
+		workspace.order = append(workspace.order, filePath)
+	}
+
+	workspace.staged[filePath] = content
+
+	if workspace.onWrite != nil {
+		workspace.onWrite()
+	}
+
+	return nil
+}
+
+// restage replaces a staged file's content without notifying onWrite, for
+// fixes made outside the agent
+func (workspace *repoWorkspace) restage(filePath, content string) {
+	workspace.staged[cleanWorkspacePath(filePath)] = content
+}
+
+// StagedFiles returns every file the agent wrote, in the order first written
+func (workspace *repoWorkspace) StagedFiles(message string) []*CodeFile {
+	codeFiles := []*CodeFile{}
+
+	for _, filePath := range workspace.order {
+		codeFiles = append(
+			codeFiles,
+			NewCodeFile(
+				CodeFile{
+					Content: workspace.staged[filePath],
+					Message: message,
+					Path:    filePath,
+				},
+			),
+		)
+	}
+
+	return codeFiles
+}
+
+// cleanWorkspacePath normalizes agent-supplied paths to repo-relative form
+func cleanWorkspacePath(filePath string) string {
+	return sharedUtils.CleanRepoPath(filePath)
+}
@@ -0,0 +1,136 @@
+package botcode
+
+import (
+	"fmt"
+	"strings"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botBlog "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_blog"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+	"github.com/google/go-github/v57/github"
+)
+
+const writeUpCommand = "/write-up"
+
+// maxPatchLength keeps a single huge file from crowding out the rest of the diff
+const maxPatchLength = 4000
+
+// handleWriteUp drafts a blog post about a merged PR and opens it on the website repo
+func (handler *Handler) handleWriteUp(prNumber int) error {
+	if handler.BlogHandler == nil {
+		return &botErrors.ValidationError{Message: "No blog repository is configured."}
+	}
+
+	pullRequest, err := handler.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("getting PR: %w", err)
+	}
+
+	if !pullRequest.GetMerged() {
+		return &botErrors.ValidationError{Message: "Write-ups can only be drafted for merged PRs."}
+	}
+
+	files, err := handler.GithubClient.ListPullRequestFiles(
+		botGithub.ListPullRequestFilesArgs{
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("getting PR files: %w", err)
+	}
+
+	comments, err := handler.GithubClient.ListIssueComments(
+		botGithub.ListIssueCommentsArgs{
+			IssueNumber: prNumber,
+			Owner:       handler.Owner,
+			Repo:        handler.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("getting PR comments: %w", err)
+	}
+
+	completion, err := handler.AiClient.GenerateWriteUp(
+		&botAi.WriteUpRequest{
+			Description: pullRequest.GetBody(),
+			Diff:        buildDiffSummary(files),
+			Discussion:  collectDiscussion(comments),
+			SourceURL:   pullRequest.GetHTMLURL(),
+			Title:       pullRequest.GetTitle(),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("AI write-up failed: %w", err)
+	}
+
+	blogPR, err := handler.BlogHandler.CreateWriteUpPR(
+		botBlog.CreateWriteUpPRArgs{
+			Content:        completion.Text,
+			Model:          completion.Model,
+			SourcePRNumber: prNumber,
+			SourceRepo:     fmt.Sprintf("%s/%s", handler.Owner, handler.Repo),
+			SourceURL:      pullRequest.GetHTMLURL(),
+			Title:          pullRequest.GetTitle(),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("creating blog PR: %w", err)
+	}
+
+	return handler.GithubClient.CommentOnPR(
+		botGithub.CommentOnPRArgs{
+			Comment:  fmt.Sprintf("📝 Drafted a write-up of this change: %s", blogPR.GetHTMLURL()),
+			Owner:    handler.Owner,
+			PrNumber: prNumber,
+			Repo:     handler.Repo,
+		},
+	)
+}
+
+// buildDiffSummary joins the per-file patches of a PR into a single diff
+func buildDiffSummary(files []*github.CommitFile) string {
+	var diff strings.Builder
+
+	for _, file := range files {
+		diff.WriteString(fmt.Sprintf("--- %s (%s)\n", file.GetFilename(), file.GetStatus()))
+		diff.WriteString(sharedUtils.TruncateText(file.GetPatch(), maxPatchLength))
+		diff.WriteString("\n\n")
+	}
+
+	return diff.String()
+}
+
+// collectDiscussion returns the human discussion on a PR, skipping bot commands
+func collectDiscussion(comments []*github.IssueComment) []string {
+	var discussion []string
+
+	for _, comment := range comments {
+		body := strings.TrimSpace(comment.GetBody())
+
+		if body == "" || strings.HasPrefix(body, "/") {
+			continue
+		}
+
+		discussion = append(
+			discussion,
+			fmt.Sprintf("%s: %s", comment.GetUser().GetLogin(), body),
+		)
+	}
+
+	return discussion
+}
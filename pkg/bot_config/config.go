@@ -0,0 +1,453 @@
+package botconfig
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RepoConfig holds the bot settings for a single repository
+type RepoConfig struct {
+	AllowedUsers     []string         `yaml:"allowed_users"` // may trigger the bot without write access
+	AltText          bool             `yaml:"alt_text"`      // describe images that have no alt text, flagging any the AI can't
+	AutoMerge        bool             `yaml:"auto_merge"`    // merge bot PRs once a maintainer approves and checks pass
+	BotLogin         string           `yaml:"bot_login"`     // the account the bot comments as
+	Budget           Budget           `yaml:"budget"`
+	ConfirmEdits     bool             `yaml:"confirm_edits"` // preview AI edits and wait for a 👍 or /apply
+	Content          ContentLayout    `yaml:"content"`
+	CoverImage       CoverImage       `yaml:"cover_image"`
+	Crossposting     Crossposting     `yaml:"crossposting"`
+	DeployHook       string           `yaml:"deploy_hook"`       // URL POSTed to rebuild the site once a merge publishes posts
+	DispatchWorkflow string           `yaml:"dispatch_workflow"` // Actions workflow file run on the bot's branch after it pushes, e.g. a preview build
+	DraftPRs         bool             `yaml:"draft_prs"`         // open PRs as drafts until /ready or an approval
+	DryRun           bool             `yaml:"dry_run"`           // comment the plan and a preview on requests instead of opening PRs
+	DryRunLabel      string           `yaml:"dry_run_label"`     // makes a single request a dry run
+	EditMode         string           `yaml:"edit_mode"`         // EditModeCommit or EditModeSuggest
+	FixCI            bool             `yaml:"fix_ci"`            // push one AI fix attempt when CI fails on a bot change
+	FormatHelp       bool             `yaml:"format_help"`       // reply with the expected format to issues the bot can't use
+	Frontmatter      string           `yaml:"frontmatter"`       // post frontmatter format: default, hugo, hugo-toml, jekyll, astro or mdx
+	Labels           Labels           `yaml:"labels"`
+	Licensing        Licensing        `yaml:"licensing"`
+	MarkdownLint     bool             `yaml:"markdown_lint"`   // fix simple markdown problems in posts and comment on the rest
+	MergeMethod      string           `yaml:"merge_method"`    // how AutoMerge merges: squash, rebase or merge
+	Model            string           `yaml:"model"`           // AI model for this repo's work; empty for the bot's
+	ModelFallbacks   []string         `yaml:"model_fallbacks"` // tried in order when Model is overloaded
+	Notifications    Notifications    `yaml:"notifications"`
+	OutlineReview    bool             `yaml:"outline_review"` // propose an outline on the issue and wait for /approve-outline
+	Paths            PathPolicy       `yaml:"paths"`
+	Proofread        bool             `yaml:"proofread"`  // run a typo and grammar pass over generated posts
+	PublishAt        bool             `yaml:"publish_at"` // publish drafts on main once their publish_at time passes
+	RateLimit        int              `yaml:"rate_limit"` // generations and modifications one user may trigger per hour; 0 is no limit
+	ReadingStats     ReadingStats     `yaml:"reading_stats"`
+	RelatedPosts     bool             `yaml:"related_posts"`  // link new posts to related existing ones
+	ReviewPRs        bool             `yaml:"review_prs"`     // post an AI review when PRs are opened or pushed to
+	RepliesDir       string           `yaml:"replies_dir"`    // <name>.tmpl overrides for the bot's replies, on the bot's host
+	ReplyLanguage    string           `yaml:"reply_language"` // language code to reply in, e.g. "es"; empty for English
+	Reviewers        []string         `yaml:"reviewers"`      // requested on bot PRs; defaults to the issue author
+	SelfUpdate       SelfUpdatePolicy `yaml:"self_update"`
+	SEOFields        SEOFields        `yaml:"seo_fields"`
+	ShowCost         bool             `yaml:"show_cost"` // note the estimated AI cost, e.g. "(~$0.12)", in bot PR bodies
+	Snippets         SnippetChecks    `yaml:"snippets"`
+	Social           Social           `yaml:"social"`
+	StalePRs         StalePRPolicy    `yaml:"stale_prs"`
+	Style            string           `yaml:"style"`             // style preset for posts whose request doesn't name one; empty for casual
+	TableOfContents  bool             `yaml:"table_of_contents"` // add one to new posts unless the issue says "toc: false"
+	Triage           bool             `yaml:"triage"`            // label and acknowledge issues that aren't requests, instead of format help
+	TriggerLabel     string           `yaml:"trigger_label"`     // starts a request on any issue it's applied to
+	WaitForCI        bool             `yaml:"wait_for_ci"`       // report code changes as done only once CI passes on them
+	WeeklyDigest     bool             `yaml:"weekly_digest"`     // keep a digest issue of the week's bot activity in this repo
+}
+
+// Budget caps AI spend per calendar month (UTC); a zero limit is no limit.
+// The total limits cap the bot's spend across every repo, and are the same
+// for each of them
+type Budget struct {
+	MonthlyTokens      int     `yaml:"monthly_tokens"`
+	MonthlyUSD         float64 `yaml:"monthly_usd"`
+	TotalMonthlyTokens int     `yaml:"total_monthly_tokens"`
+	TotalMonthlyUSD    float64 `yaml:"total_monthly_usd"`
+}
+
+// Exhausted describes the limit this month's spend has reached, e.g. "this
+// repo's $50.00 monthly budget", or returns "" while there's budget left
+func (budget Budget) Exhausted(repoUSD float64, repoTokens int64, totalUSD float64, totalTokens int64) string {
+	switch {
+	case budget.MonthlyUSD > 0 && repoUSD >= budget.MonthlyUSD:
+		return fmt.Sprintf("this repo's $%.2f monthly budget", budget.MonthlyUSD)
+	case budget.MonthlyTokens > 0 && repoTokens >= int64(budget.MonthlyTokens):
+		return fmt.Sprintf("this repo's %d-token monthly budget", budget.MonthlyTokens)
+	case budget.TotalMonthlyUSD > 0 && totalUSD >= budget.TotalMonthlyUSD:
+		return fmt.Sprintf("the bot's $%.2f monthly budget", budget.TotalMonthlyUSD)
+	case budget.TotalMonthlyTokens > 0 && totalTokens >= int64(budget.TotalMonthlyTokens):
+		return fmt.Sprintf("the bot's %d-token monthly budget", budget.TotalMonthlyTokens)
+	default:
+		return ""
+	}
+}
+
+// CoverImage controls the cover art committed beside new posts; with no
+// endpoint and no SVG card, posts get none
+type CoverImage struct {
+	Endpoint       string `yaml:"endpoint"`        // POSTed the post's title, summary, tags and key; replies with the image
+	FrontmatterKey string `yaml:"frontmatter_key"` // e.g. "image"; empty leaves the image out of the frontmatter
+	SVGCard        bool   `yaml:"svg_card"`        // draw a social card from the title and tags when there's no endpoint
+}
+
+// Crossposting holds the credentials /crosspost publishes with; a platform
+// without one can't be cross-posted to
+type Crossposting struct {
+	DevToAPIKey string `yaml:"-"`
+	MediumToken string `yaml:"-"`
+	Publish     bool   `yaml:"publish"` // publish right away instead of creating drafts on the platform
+}
+
+// Social controls the announcement drafts written when a post is published;
+// the first draft for a network is posted to any account configured for it
+type Social struct {
+	BlueskyHandle   string `yaml:"bluesky_handle"`
+	BlueskyPassword string `yaml:"-"` // an app password
+	Drafts          bool   `yaml:"drafts"`
+	MastodonServer  string `yaml:"mastodon_server"` // e.g. "https://mastodon.social"
+	MastodonToken   string `yaml:"-"`
+	XToken          string `yaml:"-"` // an OAuth 2.0 user access token
+}
+
+// Notifications sends chat messages about the bot's work to Slack or
+// Discord; with no webhook, none are sent
+type Notifications struct {
+	DiscordWebhook string   `yaml:"-"`
+	Events         []string `yaml:"events"` // pr_created, generation_failed and published; defaults to all of them
+	SlackWebhook   string   `yaml:"-"`
+}
+
+// Licensing controls the license and AI disclosure added to generated posts
+type Licensing struct {
+	AIAssisted  bool   `yaml:"ai_assisted"` // adds `ai_assisted: true` to frontmatter
+	Attribution string `yaml:"attribution"` // appended to the rendered post when set
+	License     string `yaml:"license"`     // e.g. "CC-BY-4.0"
+}
+
+// ReadingStats names the frontmatter fields a post's word count and reading
+// time are written to; an empty key leaves that field out
+type ReadingStats struct {
+	ReadingTimeKey string `yaml:"reading_time_key"` // whole minutes, e.g. "reading_time"
+	WordCountKey   string `yaml:"word_count_key"`
+}
+
+// SEOFields names the frontmatter fields SEO metadata is written to, e.g.
+// "description" or "og_title"; with none set, posts get no SEO pass
+type SEOFields struct {
+	MetaDescription      string `yaml:"meta_description"`
+	OpenGraphDescription string `yaml:"og_description"`
+	OpenGraphTitle       string `yaml:"og_title"`
+}
+
+// IsEnabled reports whether any SEO field is configured
+func (fields SEOFields) IsEnabled() bool {
+	return fields.MetaDescription != "" || fields.OpenGraphDescription != "" || fields.OpenGraphTitle != ""
+}
+
+// SnippetChecks controls the checks run on Go examples in generated posts
+// before the PR opens; examples that fail are sent back to the AI to fix
+type SnippetChecks struct {
+	Validate bool `yaml:"validate"` // examples must parse
+	Vet      bool `yaml:"vet"`      // whole-file, standard-library-only examples must also pass go vet
+}
+
+// Labels names the labels the bot applies; an empty name turns that label off
+type Labels struct {
+	AIGenerated string `yaml:"ai_generated"` // every bot PR
+	Bug         string `yaml:"bug"`          // issues triaged as bugs
+	Content     string `yaml:"content"`      // the kind of content, on requests and their PRs
+	Digest      string `yaml:"digest"`       // weekly digest issues
+	Feature     string `yaml:"feature"`      // issues triaged as feature requests
+	NeedsReview string `yaml:"needs_review"` // bot PRs until a maintainer approves them
+	Question    string `yaml:"question"`     // issues triaged as questions
+}
+
+// Edit modes for how review-comment changes are delivered
+const (
+	EditModeCommit  = "commit"  // commit the AI edit to the PR branch
+	EditModeSuggest = "suggest" // reply with a suggestion block to apply by hand
+)
+
+// PathPolicy limits where in a repo the bot may write files
+type PathPolicy struct {
+	Allowed []string `yaml:"allowed"` // when set, the only paths the bot may write
+	Denied  []string `yaml:"denied"`  // paths the bot may never write, even if allowed
+}
+
+// defaultDeniedPaths keeps the bot away from CI workflows, which run with the
+// repo's secrets; they're denied on top of any configured paths, never instead
+var defaultDeniedPaths = []string{".github/"}
+
+// StalePRPolicy controls the reminders and closing of bot PRs nobody has
+// touched; zero days turns that step off
+type StalePRPolicy struct {
+	CloseAfterDays    int `yaml:"close_after_days"`    // grace period after the reminder before the PR is closed
+	ReminderAfterDays int `yaml:"reminder_after_days"` // days without human activity before the reminder
+}
+
+// SelfUpdatePolicy adds stricter rules for PRs against the bot's own source
+type SelfUpdatePolicy struct {
+	ChecksWorkflow  string   `yaml:"checks_workflow"`  // Actions workflow that builds and tests the branch, away from the bot's tokens
+	Enabled         bool     `yaml:"enabled"`          // the repo is the bot's own source
+	ProtectedPaths  []string `yaml:"protected_paths"`  // path prefixes the bot may never change
+	RequireApproval bool     `yaml:"require_approval"` // the checks workflow waits for a human approval of the branch's head
+}
+
+// defaultRateLimit is generous for a person, but stops a comment storm
+const defaultRateLimit = 20
+
+// defaultNotifyEvents sends every kind of notification
+var defaultNotifyEvents = []string{"budget_exhausted", "generation_failed", "pr_created", "published"}
+
+// defaultProtectedPaths covers the webhook server and the policy itself
+var defaultProtectedPaths = []string{
+	".github/",
+	"cmd/",
+	"pkg/bot_code/self_update.go",
+	"pkg/bot_config/",
+}
+
+// LoadFromEnv reads a repository's settings from environment variables
+// sharing a prefix, e.g. BLOG_LICENSE for the prefix "BLOG_"
+func LoadFromEnv(prefix string) *RepoConfig {
+	return &RepoConfig{
+		AllowedUsers: envList(prefix+"ALLOWED_USERS", envList("ALLOWED_USERS", nil)),
+		AltText:      envBool(prefix+"ALT_TEXT", true),
+		AutoMerge:    envBool(prefix+"AUTO_MERGE", false),
+		BotLogin:     envString(prefix+"BOT_LOGIN", os.Getenv("BOT_LOGIN")),
+		Budget: Budget{
+			MonthlyTokens:      envInt(prefix+"MONTHLY_BUDGET_TOKENS", 0),
+			MonthlyUSD:         envFloat(prefix+"MONTHLY_BUDGET_USD", 0),
+			TotalMonthlyTokens: envInt("MONTHLY_BUDGET_TOKENS", 0),
+			TotalMonthlyUSD:    envFloat("MONTHLY_BUDGET_USD", 0),
+		},
+		ConfirmEdits: envBool(prefix+"CONFIRM_EDITS", false),
+		Content: ContentLayout{
+			DraftsDir:  envString(prefix+"DRAFTS_DIR", defaultDraftsDir),
+			PostURL:    envString(prefix+"POST_URL", defaultPostURL),
+			PostsDir:   envString(prefix+"POSTS_DIR", defaultPostsDir),
+			PreviewURL: os.Getenv(prefix + "PREVIEW_URL"),
+		},
+		CoverImage: CoverImage{
+			Endpoint:       os.Getenv(prefix + "COVER_IMAGE_ENDPOINT"),
+			FrontmatterKey: envString(prefix+"COVER_IMAGE_KEY", "image"),
+			SVGCard:        envBool(prefix+"SVG_CARD", false),
+		},
+		Crossposting: Crossposting{
+			DevToAPIKey: os.Getenv(prefix + "DEVTO_API_KEY"),
+			MediumToken: os.Getenv(prefix + "MEDIUM_TOKEN"),
+			Publish:     envBool(prefix+"CROSSPOST_PUBLISH", false),
+		},
+		DeployHook:       os.Getenv(prefix + "DEPLOY_HOOK_URL"),
+		DispatchWorkflow: os.Getenv(prefix + "DISPATCH_WORKFLOW"),
+		DraftPRs:         envBool(prefix+"DRAFT_PRS", false),
+		DryRun:           envBool(prefix+"DRY_RUN", envBool("DRY_RUN", false)),
+		DryRunLabel:      envString(prefix+"DRY_RUN_LABEL", "ai-dry-run"),
+		EditMode:         envString(prefix+"EDIT_MODE", EditModeCommit),
+		FixCI:            envBool(prefix+"FIX_CI", false),
+		FormatHelp:       envBool(prefix+"FORMAT_HELP", true),
+		Frontmatter:      os.Getenv(prefix + "FRONTMATTER"),
+		Labels: Labels{
+			AIGenerated: envString(prefix+"LABEL_AI_GENERATED", "ai-generated"),
+			Bug:         envString(prefix+"LABEL_BUG", "bug"),
+			Content:     os.Getenv(prefix + "LABEL_CONTENT"),
+			Digest:      envString(prefix+"LABEL_DIGEST", "bot-digest"),
+			Feature:     envString(prefix+"LABEL_FEATURE", "enhancement"),
+			NeedsReview: envString(prefix+"LABEL_NEEDS_REVIEW", "needs-review"),
+			Question:    envString(prefix+"LABEL_QUESTION", "question"),
+		},
+		Licensing: Licensing{
+			AIAssisted:  envBool(prefix+"AI_ASSISTED", true),
+			Attribution: os.Getenv(prefix + "ATTRIBUTION"),
+			License:     os.Getenv(prefix + "LICENSE"),
+		},
+		MarkdownLint:   envBool(prefix+"MARKDOWN_LINT", true),
+		MergeMethod:    envString(prefix+"MERGE_METHOD", "squash"),
+		Model:          os.Getenv(prefix + "AI_MODEL"),
+		ModelFallbacks: envList(prefix+"AI_MODEL_FALLBACKS", nil),
+		Notifications: Notifications{
+			DiscordWebhook: os.Getenv(prefix + "DISCORD_WEBHOOK_URL"),
+			Events:         envList(prefix+"NOTIFY_EVENTS", defaultNotifyEvents),
+			SlackWebhook:   os.Getenv(prefix + "SLACK_WEBHOOK_URL"),
+		},
+		OutlineReview: envBool(prefix+"OUTLINE_REVIEW", false),
+		Paths: PathPolicy{
+			Allowed: envList(prefix+"ALLOWED_PATHS", nil),
+			Denied:  withDefaultDeniedPaths(envList(prefix+"DENIED_PATHS", nil)),
+		},
+		Proofread: envBool(prefix+"PROOFREAD", false),
+		PublishAt: envBool(prefix+"PUBLISH_AT", true),
+		RateLimit: envInt(prefix+"RATE_LIMIT", defaultRateLimit),
+		ReadingStats: ReadingStats{
+			ReadingTimeKey: os.Getenv(prefix + "READING_TIME_KEY"),
+			WordCountKey:   os.Getenv(prefix + "WORD_COUNT_KEY"),
+		},
+		RelatedPosts:  envBool(prefix+"RELATED_POSTS", false),
+		ReviewPRs:     envBool(prefix+"REVIEW_PRS", false),
+		RepliesDir:    os.Getenv(prefix + "REPLIES_DIR"),
+		ReplyLanguage: os.Getenv(prefix + "REPLY_LANGUAGE"),
+		Reviewers:     envList(prefix+"REVIEWERS", nil),
+		SelfUpdate: SelfUpdatePolicy{
+			ChecksWorkflow:  os.Getenv(prefix + "SELF_UPDATE_WORKFLOW"),
+			Enabled:         envBool(prefix+"SELF_UPDATE", false),
+			ProtectedPaths:  envList(prefix+"PROTECTED_PATHS", defaultProtectedPaths),
+			RequireApproval: envBool(prefix+"REQUIRE_APPROVAL", true),
+		},
+		SEOFields: SEOFields{
+			MetaDescription:      os.Getenv(prefix + "META_DESCRIPTION_KEY"),
+			OpenGraphDescription: os.Getenv(prefix + "OG_DESCRIPTION_KEY"),
+			OpenGraphTitle:       os.Getenv(prefix + "OG_TITLE_KEY"),
+		},
+		ShowCost: envBool(prefix+"SHOW_COST", false),
+		Snippets: SnippetChecks{
+			Validate: envBool(prefix+"VALIDATE_SNIPPETS", false),
+			Vet:      envBool(prefix+"VET_SNIPPETS", false),
+		},
+		Social: Social{
+			BlueskyHandle:   os.Getenv(prefix + "BLUESKY_HANDLE"),
+			BlueskyPassword: os.Getenv(prefix + "BLUESKY_APP_PASSWORD"),
+			Drafts:          envBool(prefix+"SOCIAL_DRAFTS", false),
+			MastodonServer:  os.Getenv(prefix + "MASTODON_SERVER"),
+			MastodonToken:   os.Getenv(prefix + "MASTODON_TOKEN"),
+			XToken:          os.Getenv(prefix + "X_TOKEN"),
+		},
+		StalePRs: StalePRPolicy{
+			CloseAfterDays:    envInt(prefix+"STALE_CLOSE_DAYS", 7),
+			ReminderAfterDays: envInt(prefix+"STALE_REMINDER_DAYS", 0),
+		},
+		Style:           os.Getenv(prefix + "STYLE"),
+		TableOfContents: envBool(prefix+"TOC", false),
+		Triage:          envBool(prefix+"TRIAGE", false),
+		TriggerLabel:    os.Getenv(prefix + "TRIGGER_LABEL"),
+		WaitForCI:       envBool(prefix+"WAIT_FOR_CI", true),
+		WeeklyDigest:    envBool(prefix+"WEEKLY_DIGEST", false),
+	}
+}
+
+// IsProtected reports whether the policy forbids the bot from changing a path
+func (policy SelfUpdatePolicy) IsProtected(filePath string) bool {
+	if !policy.Enabled {
+		return false
+	}
+
+	return matchesAnyPath(filePath, policy.ProtectedPaths)
+}
+
+// IsAllowed reports whether the bot may write a path; paths that try to
+// climb out of the repo are never allowed
+func (policy PathPolicy) IsAllowed(filePath string) bool {
+	isTraversal := strings.HasPrefix(filePath, "/") || slices.Contains(strings.Split(filePath, "/"), "..")
+	if isTraversal || matchesAnyPath(filePath, policy.Denied) {
+		return false
+	}
+
+	return len(policy.Allowed) == 0 || matchesAnyPath(filePath, policy.Allowed)
+}
+
+// matchesAnyPath reports whether filePath is one of paths, or inside one of
+// them when the entry ends in "/"
+func matchesAnyPath(filePath string, paths []string) bool {
+	for _, candidate := range paths {
+		isDirectoryMatch := strings.HasSuffix(candidate, "/") &&
+			strings.HasPrefix(filePath, candidate)
+
+		if isDirectoryMatch || filePath == candidate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBotSender reports whether a comment author is this bot or any GitHub App
+// bot account, so the bot never reacts to its own comments
+func (config *RepoConfig) IsBotSender(login string) bool {
+	isAppBot := strings.HasSuffix(login, "[bot]")
+	isConfiguredBot := config.BotLogin != "" && strings.EqualFold(login, config.BotLogin)
+
+	return isAppBot || isConfiguredBot
+}
+
+// IsAllowedUser reports whether a login is on the allowlist
+func (config *RepoConfig) IsAllowedUser(login string) bool {
+	for _, allowedUser := range config.AllowedUsers {
+		if strings.EqualFold(login, allowedUser) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// envString reads an environment variable, using fallback when unset
+func envString(name, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// envList parses a comma-separated environment variable, using fallback when unset
+// withDefaultDeniedPaths adds the defaultDeniedPaths a configured list lacks
+func withDefaultDeniedPaths(denied []string) []string {
+	for _, path := range defaultDeniedPaths {
+		if !slices.Contains(denied, path) {
+			denied = append(denied, path)
+		}
+	}
+
+	return denied
+}
+
+func envList(name string, fallback []string) []string {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback
+	}
+
+	items := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+
+	return items
+}
+
+// envBool parses a boolean environment variable, using fallback when unset or invalid
+func envBool(name string, fallback bool) bool {
+	value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(name)))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// envFloat parses a decimal environment variable, using fallback when unset or invalid
+func envFloat(name string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(name)), 64)
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// envInt parses a whole-number environment variable, using fallback when unset or invalid
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name)))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
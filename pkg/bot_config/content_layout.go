@@ -0,0 +1,107 @@
+package botconfig
+
+import (
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ContentLayout says where in a repo a static site keeps its posts
+type ContentLayout struct {
+	DraftsDir  string `yaml:"drafts_dir"` // the same as PostsDir when drafts are marked only in frontmatter
+	PostURL    string `yaml:"post_url"`   // where the site serves a post, with "{key}" for its key
+	PostsDir   string `yaml:"posts_dir"`
+	PreviewURL string `yaml:"preview_url"` // a PR's preview deploy of a post, with "{pr}", "{branch}" and "{key}"; empty without previews
+}
+
+// postExtensions are the file types the bot treats as posts
+var postExtensions = []string{".md", ".mdx"}
+
+// Default content directories of the bot's original website repo
+const (
+	defaultDraftsDir = "pkg/blog_markdown_content/drafts"
+	defaultPostURL   = "/posts/{key}"
+	defaultPostsDir  = "pkg/blog_markdown_content/posts"
+)
+
+// Dir returns the directory drafts or published posts are written to
+func (layout ContentLayout) Dir(isDraft bool) string {
+	if isDraft && layout.HasDraftsDir() {
+		return cleanDir(layout.DraftsDir)
+	}
+
+	return cleanDir(layout.PostsDir)
+}
+
+// HasDraftsDir reports whether drafts live in their own directory, so
+// publishing a post moves its file
+func (layout ContentLayout) HasDraftsDir() bool {
+	draftsDir := cleanDir(layout.DraftsDir)
+
+	return draftsDir != "" && draftsDir != cleanDir(layout.PostsDir)
+}
+
+// FilePath returns where a post file with the given name is written
+func (layout ContentLayout) FilePath(filename string, isDraft bool) string {
+	return path.Join(layout.Dir(isDraft), filename)
+}
+
+// URL returns the site link to a published post
+func (layout ContentLayout) URL(key string) string {
+	return strings.ReplaceAll(layout.PostURL, "{key}", key)
+}
+
+// PreviewLink returns the link to a post in a PR's preview deploy, or "" when
+// the site has no previews
+func (layout ContentLayout) PreviewLink(prNumber int, branch, key string) string {
+	if layout.PreviewURL == "" {
+		return ""
+	}
+
+	replacer := strings.NewReplacer(
+		"{branch}", branch,
+		"{key}", key,
+		"{pr}", strconv.Itoa(prNumber),
+	)
+
+	return replacer.Replace(layout.PreviewURL)
+}
+
+// IsPostFile reports whether a repo path is a post in the posts or drafts directory
+func (layout ContentLayout) IsPostFile(filePath string) bool {
+	if !slices.Contains(postExtensions, path.Ext(filePath)) {
+		return false
+	}
+
+	return isInDir(filePath, layout.Dir(false)) || isInDir(filePath, layout.Dir(true))
+}
+
+// IsDraftFile reports whether a repo path is in the drafts directory; with no
+// separate drafts directory only the frontmatter can tell
+func (layout ContentLayout) IsDraftFile(filePath string) bool {
+	return layout.HasDraftsDir() && isInDir(filePath, layout.Dir(true))
+}
+
+// MovedPath returns where a post file belongs once it's a draft or published,
+// keeping any subdirectories (e.g. Hugo page bundles) below the content directory
+func (layout ContentLayout) MovedPath(filePath string, isDraft bool) string {
+	if !layout.HasDraftsDir() {
+		return filePath
+	}
+
+	currentDir := layout.Dir(!isDraft)
+	if !isInDir(filePath, currentDir) {
+		return filePath
+	}
+
+	return path.Join(layout.Dir(isDraft), strings.TrimPrefix(filePath, currentDir+"/"))
+}
+
+func cleanDir(dir string) string {
+	return strings.Trim(dir, "/")
+}
+
+func isInDir(filePath, dir string) bool {
+	return dir != "" && strings.HasPrefix(filePath, dir+"/")
+}
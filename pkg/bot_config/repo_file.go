@@ -0,0 +1,169 @@
+package botconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoFile is the settings file a target repo keeps on its default branch
+const RepoFile = ".anthropic-bot.yml"
+
+// repoFileMaxAge is how long a read RepoFile is used when no push says it
+// changed, in case push events aren't delivered
+const repoFileMaxAge = time.Hour
+
+// repoSettings is what a RepoFile may set. The rest, like budgets, write
+// paths and credentials, stays with whoever runs the bot, since a request
+// could otherwise have the bot loosen its own limits
+type repoSettings struct {
+	Content        ContentLayout `yaml:"content"`
+	Model          string        `yaml:"model"`
+	ModelFallbacks []string      `yaml:"model_fallbacks"`
+	Reviewers      []string      `yaml:"reviewers"`
+	Style          string        `yaml:"style"`
+	TriggerLabel   string        `yaml:"trigger_label"`
+}
+
+// WithRepoFile returns a copy of the config with the settings in a RepoFile's
+// content; settings the file leaves out keep their value, and a setting it
+// doesn't allow is an error
+func (config *RepoConfig) WithRepoFile(content string) (*RepoConfig, error) {
+	settings := repoSettings{
+		Content:        config.Content,
+		Model:          config.Model,
+		ModelFallbacks: config.ModelFallbacks,
+		Reviewers:      config.Reviewers,
+		Style:          config.Style,
+		TriggerLabel:   config.TriggerLabel,
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", RepoFile, err)
+	}
+
+	withFile := *config
+	withFile.Content = settings.Content
+	withFile.Model = settings.Model
+	withFile.ModelFallbacks = settings.ModelFallbacks
+	withFile.Reviewers = settings.Reviewers
+	withFile.Style = settings.Style
+	withFile.TriggerLabel = settings.TriggerLabel
+
+	return &withFile, nil
+}
+
+// RepoFileLoader applies a target repo's RepoFile over the config the bot was
+// started with, reading it again after a push changes it
+type RepoFileLoader struct {
+	Base         *RepoConfig // the config from the environment
+	GithubClient botGithub.GithubAPI
+	Logger       *slog.Logger // defaults to slog.Default()
+	Owner        string
+	Repo         string
+
+	cache *repoFileCache
+}
+
+// repoFileCache holds the config read last; it's shared by the copies
+// WithLogger makes
+type repoFileCache struct {
+	config   *RepoConfig
+	loadedAt time.Time
+	mutex    sync.Mutex
+}
+
+// NewRepoFileLoader creates a loader for one repository; nothing is read
+// until Config is first called
+func NewRepoFileLoader(args RepoFileLoader) *RepoFileLoader {
+	logger := args.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &RepoFileLoader{
+		Base:         args.Base,
+		GithubClient: args.GithubClient,
+		Logger:       logger,
+		Owner:        args.Owner,
+		Repo:         args.Repo,
+		cache:        &repoFileCache{},
+	}
+}
+
+// WithLogger returns a copy of the loader that logs to logger
+func (loader *RepoFileLoader) WithLogger(logger *slog.Logger) *RepoFileLoader {
+	scoped := *loader
+	scoped.Logger = logger
+
+	return &scoped
+}
+
+// Config returns the repo's config, reading RepoFile when it hasn't been read
+// since it last changed. A repo without the file gets Base. A file that can't
+// be used is logged and the config before it kept until the file changes; one
+// that can't be fetched is tried again on the next call
+func (loader *RepoFileLoader) Config() *RepoConfig {
+	cache := loader.cache
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.config != nil && time.Since(cache.loadedAt) < repoFileMaxAge {
+		return cache.config
+	}
+
+	if cache.config == nil {
+		cache.config = loader.Base
+	}
+
+	content, _, err := loader.GithubClient.GetFileContent(
+		botGithub.GetFileContentArgs{
+			Filename: RepoFile,
+			Owner:    loader.Owner,
+			Repo:     loader.Repo,
+		},
+	)
+
+	switch {
+	case errors.Is(err, botGithub.ErrFileNotFound):
+		cache.config = loader.Base
+	case err != nil:
+		loader.Logger.Warn("Reading repo config failed", "file", RepoFile, "error", err)
+		return cache.config
+	default:
+		config, err := loader.Base.WithRepoFile(content)
+		if err == nil {
+			err = config.Validate()
+		}
+
+		if err != nil {
+			loader.Logger.Warn("Ignoring repo config that can't be used", "file", RepoFile, "error", err)
+		} else {
+			cache.config = config
+		}
+	}
+
+	cache.loadedAt = time.Now()
+
+	return cache.config
+}
+
+// Forget marks the config read last as out of date, e.g. after a push changes
+// RepoFile, so the next Config reads it again
+func (loader *RepoFileLoader) Forget() {
+	loader.cache.mutex.Lock()
+	defer loader.cache.mutex.Unlock()
+
+	loader.cache.loadedAt = time.Time{}
+}
@@ -0,0 +1,45 @@
+package botconfig
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// mergeMethods are the ways GitHub can merge a PR
+var mergeMethods = []string{"merge", "rebase", "squash"}
+
+// Validate reports the settings that can't work, e.g. a misspelled merge
+// method, so they're caught before a webhook runs into them
+func (config *RepoConfig) Validate() error {
+	var problems []error
+
+	if !slices.Contains([]string{EditModeCommit, EditModeSuggest}, config.EditMode) {
+		problems = append(problems, fmt.Errorf("edit mode %q isn't %s or %s", config.EditMode, EditModeCommit, EditModeSuggest))
+	}
+
+	if !slices.Contains(mergeMethods, config.MergeMethod) {
+		problems = append(problems, fmt.Errorf("merge method %q isn't one of %v", config.MergeMethod, mergeMethods))
+	}
+
+	for _, event := range config.Notifications.Events {
+		if !slices.Contains(defaultNotifyEvents, event) {
+			problems = append(problems, fmt.Errorf("notification event %q isn't one of %v", event, defaultNotifyEvents))
+		}
+	}
+
+	if config.Budget.MonthlyUSD < 0 || config.Budget.TotalMonthlyUSD < 0 ||
+		config.Budget.MonthlyTokens < 0 || config.Budget.TotalMonthlyTokens < 0 {
+		problems = append(problems, errors.New("budgets can't be negative"))
+	}
+
+	if config.SelfUpdate.Enabled && config.SelfUpdate.ChecksWorkflow == "" {
+		problems = append(problems, errors.New("self-update needs a checks workflow to build and test the bot's PRs"))
+	}
+
+	if config.RateLimit < 0 {
+		problems = append(problems, errors.New("the rate limit can't be negative"))
+	}
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,153 @@
+package boterrors
+
+import "errors"
+
+// UserFacing is an error with a message written for the person who asked the
+// bot for something, kept apart from Error(), which is written for whoever
+// reads the logs and may name internals
+type UserFacing interface {
+	error
+	UserMessage() string
+}
+
+// ValidationError means a request can't be carried out as asked, e.g. it
+// writes outside the allowed paths or what it would commit looks like a secret
+type ValidationError struct {
+	Err     error  // the detail for the logs
+	Message string // what to tell the user
+}
+
+func (validationError *ValidationError) Error() string {
+	return operatorMessage(validationError.Err, validationError.Message)
+}
+
+func (validationError *ValidationError) Unwrap() error {
+	return validationError.Err
+}
+
+func (validationError *ValidationError) UserMessage() string {
+	return validationError.Message
+}
+
+func (validationError *ValidationError) Category() string {
+	return "validation"
+}
+
+// GitHubError means a GitHub API call failed
+type GitHubError struct {
+	Err        error
+	Message    string
+	StatusCode int // 0 when GitHub never answered
+}
+
+func (githubError *GitHubError) Error() string {
+	return operatorMessage(githubError.Err, githubError.Message)
+}
+
+func (githubError *GitHubError) Unwrap() error {
+	return githubError.Err
+}
+
+func (githubError *GitHubError) UserMessage() string {
+	return githubError.Message
+}
+
+func (githubError *GitHubError) Category() string {
+	return "github"
+}
+
+// AIError means an Anthropic API call failed or its reply couldn't be used
+type AIError struct {
+	Err        error
+	Message    string
+	StatusCode int // 0 when the API never answered or the reply was the problem
+}
+
+func (aiError *AIError) Error() string {
+	return operatorMessage(aiError.Err, aiError.Message)
+}
+
+func (aiError *AIError) Unwrap() error {
+	return aiError.Err
+}
+
+func (aiError *AIError) UserMessage() string {
+	return aiError.Message
+}
+
+func (aiError *AIError) Category() string {
+	return "ai"
+}
+
+// BudgetError means a spending limit stopped an AI call
+type BudgetError struct {
+	Err     error
+	Message string
+}
+
+func (budgetError *BudgetError) Error() string {
+	return operatorMessage(budgetError.Err, budgetError.Message)
+}
+
+func (budgetError *BudgetError) Unwrap() error {
+	return budgetError.Err
+}
+
+func (budgetError *BudgetError) UserMessage() string {
+	return budgetError.Message
+}
+
+func (budgetError *BudgetError) Category() string {
+	return "budget"
+}
+
+// Categorized is an error that names the kind of failure it is, shown in
+// failure comments so a report says where to start looking
+type Categorized interface {
+	error
+	Category() string
+}
+
+// Category names the kind of failure err is, or "internal" when nothing in
+// its chain says
+func Category(err error) string {
+	var categorized Categorized
+
+	if !errors.As(err, &categorized) {
+		return "internal"
+	}
+
+	return categorized.Category()
+}
+
+// UserMessage finds the user-facing message of the first error in err's
+// chain that has one
+func UserMessage(err error) (string, bool) {
+	var userFacing UserFacing
+
+	if !errors.As(err, &userFacing) || userFacing.UserMessage() == "" {
+		return "", false
+	}
+
+	return userFacing.UserMessage(), true
+}
+
+// UserMessageOrLogs is what to tell the user about err: its user-facing
+// message, or that the details went to the logs when it has none
+func UserMessageOrLogs(err error) string {
+	if message, hasMessage := UserMessage(err); hasMessage {
+		return message
+	}
+
+	return "The details are in the bot logs."
+}
+
+// operatorMessage falls back to the user message for errors built without
+// an underlying error
+func operatorMessage(err error, message string) string {
+	if err == nil {
+		return message
+	}
+
+	return err.Error()
+}
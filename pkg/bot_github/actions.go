@@ -0,0 +1,122 @@
+package botgithub
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const (
+	// workflowRunPolls and workflowRunPollInterval bound the wait for a
+	// dispatched run to show up; the dispatch API doesn't return it
+	workflowRunPolls        = 5
+	workflowRunPollInterval = 2 * time.Second
+
+	// workflowClockSkew allows for GitHub's clock being behind the bot's
+	workflowClockSkew = 30 * time.Second
+)
+
+type DispatchWorkflowArgs struct {
+	Inputs   map[string]any // must match the inputs the workflow declares
+	Owner    string
+	Ref      string // the branch to run on
+	Repo     string
+	Workflow string // the workflow's file name, e.g. "preview.yml"
+}
+
+// DispatchWorkflow starts a workflow_dispatch run of a GitHub Actions workflow
+// and returns a link to it. When the run doesn't show up in time, the link is
+// to the workflow's runs on the branch instead
+func (client *Client) DispatchWorkflow(args DispatchWorkflowArgs) (string, error) {
+	dispatchedAt := time.Now().Add(-workflowClockSkew)
+
+	if _, err := client.github.Actions.CreateWorkflowDispatchEventByFileName(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.Workflow,
+		github.CreateWorkflowDispatchEventRequest{
+			Inputs: args.Inputs,
+			Ref:    args.Ref,
+		},
+	); err != nil {
+		return "", apiError(err, "dispatching workflow %s", args.Workflow)
+	}
+
+	options := &github.ListWorkflowRunsOptions{
+		Branch:      args.Ref,
+		Created:     ">=" + dispatchedAt.UTC().Format(time.RFC3339),
+		Event:       "workflow_dispatch",
+		ListOptions: github.ListOptions{PerPage: 1},
+	}
+
+	for poll := 0; poll < workflowRunPolls; poll++ {
+		time.Sleep(workflowRunPollInterval)
+
+		runs, _, err := client.github.Actions.ListWorkflowRunsByFileName(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.Workflow,
+			options,
+		)
+
+		if err != nil {
+			break
+		}
+
+		if len(runs.WorkflowRuns) > 0 {
+			return runs.WorkflowRuns[0].GetHTMLURL(), nil
+		}
+	}
+
+	return fmt.Sprintf(
+		"https://github.com/%s/%s/actions/workflows/%s?query=%s",
+		args.Owner,
+		args.Repo,
+		url.PathEscape(args.Workflow),
+		url.QueryEscape("branch:"+args.Ref),
+	), nil
+}
+
+type ListWorkflowRunsArgs struct {
+	HeadSHA  string
+	Owner    string
+	Repo     string
+	Workflow string // the workflow's file name, e.g. "checks.yml"
+}
+
+// ListWorkflowRuns returns a GitHub Actions workflow's runs on a commit,
+// newest first, following pagination
+func (client *Client) ListWorkflowRuns(args ListWorkflowRunsArgs) ([]*github.WorkflowRun, error) {
+	options := &github.ListWorkflowRunsOptions{
+		HeadSHA:     args.HeadSHA,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var workflowRuns []*github.WorkflowRun
+
+	for {
+		result, response, err := client.github.Actions.ListWorkflowRunsByFileName(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.Workflow,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing runs of workflow %s", args.Workflow)
+		}
+
+		workflowRuns = append(workflowRuns, result.WorkflowRuns...)
+
+		if response.NextPage == 0 {
+			return workflowRuns, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
@@ -0,0 +1,67 @@
+package botgithub
+
+import (
+	"io"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// GithubAPI is the part of the GitHub API the handlers use. Client is the
+// real implementation; botgithubtest.MockClient keeps everything in memory
+// for tests
+type GithubAPI interface {
+	AddAssignees(args AddAssigneesArgs) error
+	AddLabels(args AddLabelsArgs) error
+	CheckRepositoryWritable(args GetRepositoryArgs) error
+	ClosePullRequest(args ClosePullRequestArgs) (*github.PullRequest, error)
+	CommentOnIssue(args CommentOnIssueArgs) error
+	CommentOnPR(args CommentOnPRArgs) error
+	CommitFiles(args CommitFilesArgs) (string, error)
+	CompareCommits(args CompareCommitsArgs) ([]*github.RepositoryCommit, error)
+	CreateBranch(args CreateBranchArgs) (string, error)
+	CreateCheckRun(args CreateCheckRunArgs) (int64, error)
+	CreateComment(args CreateCommentArgs) (*github.IssueComment, error)
+	CreateFile(args CreateFileArgs) (string, error)
+	CreateIssue(args CreateIssueArgs) (*github.Issue, error)
+	CreatePullRequest(args CreatePullRequestArgs) (*github.PullRequest, error)
+	CreatePullRequestReview(args CreatePullRequestReviewArgs) error
+	DeleteBranch(args DeleteBranchArgs) error
+	DispatchWorkflow(args DispatchWorkflowArgs) (string, error)
+	DownloadArchive(args DownloadArchiveArgs) (io.ReadCloser, error)
+	EditComment(args EditCommentArgs) error
+	EditIssue(args EditIssueArgs) error
+	FindPullRequestForBranch(args FindPullRequestForBranchArgs) (*github.Issue, error)
+	GetCombinedStatus(args GetCombinedStatusArgs) (*github.CombinedStatus, error)
+	GetCompareDiff(args CompareCommitsArgs) (string, error)
+	GetFileContent(args GetFileContentArgs) (string, string, error)
+	GetIssue(args GetIssueArgs) (*github.Issue, error)
+	GetPullRequest(args GetPullRequestArgs) (*github.PullRequest, error)
+	GetPullRequestDiff(args GetPullRequestDiffArgs) (string, error)
+	GetUserPermission(args GetUserPermissionArgs) (string, error)
+	ListCheckRunAnnotations(args ListCheckRunAnnotationsArgs) ([]*github.CheckRunAnnotation, error)
+	ListCheckRuns(args ListCheckRunsArgs) ([]*github.CheckRun, error)
+	ListDirectory(args ListDirectoryArgs) ([]*github.RepositoryContent, error)
+	ListIssueCommentReactions(args ListIssueCommentReactionsArgs) ([]*github.Reaction, error)
+	ListIssueComments(args ListIssueCommentsArgs) ([]*github.IssueComment, error)
+	ListOpenPullRequests(args ListOpenPullRequestsArgs) ([]*github.PullRequest, error)
+	ListPullRequestFiles(args ListPullRequestFilesArgs) ([]*github.CommitFile, error)
+	ListPullRequestReviews(args ListPullRequestReviewsArgs) ([]*github.PullRequestReview, error)
+	ListTags(args ListTagsArgs) ([]*github.RepositoryTag, error)
+	ListWorkflowRuns(args ListWorkflowRunsArgs) ([]*github.WorkflowRun, error)
+	MarkReadyForReview(args MarkReadyForReviewArgs) error
+	MergePullRequest(args MergePullRequestArgs) error
+	NewGenerationCheck(args NewGenerationCheckArgs) *GenerationCheck
+	NewProgressComment(args NewProgressCommentArgs) *ProgressComment
+	ReactToIssue(args ReactToIssueArgs) error
+	ReactToIssueComment(args ReactToIssueCommentArgs) error
+	ReactToPRComment(args ReactToPRCommentArgs) error
+	RemoveLabels(args RemoveLabelsArgs) error
+	ReplyToReviewComment(args ReplyToReviewCommentArgs) error
+	RequestReviewers(args RequestReviewersArgs) error
+	RevertCommit(args RevertCommitArgs) (string, error)
+	UpdateCheckRun(args UpdateCheckRunArgs) error
+	UpdateFile(args UpdateFileArgs) (string, error)
+	UpdatePullRequest(args UpdatePullRequestArgs) error
+}
+
+var _ GithubAPI = (*Client)(nil)
@@ -0,0 +1,45 @@
+package botgithub
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/go-github/v57/github"
+)
+
+type DownloadArchiveArgs struct {
+	Owner string
+	Ref   string
+	Repo  string
+}
+
+// DownloadArchive streams a gzipped tarball of the repository at a ref.
+// The caller must close the returned reader
+func (client *Client) DownloadArchive(args DownloadArchiveArgs) (io.ReadCloser, error) {
+	archiveURL, _, err := client.github.Repositories.GetArchiveLink(
+		client.context,
+		args.Owner,
+		args.Repo,
+		github.Tarball,
+		&github.RepositoryContentGetOptions{Ref: args.Ref},
+		3,
+	)
+
+	if err != nil {
+		return nil, apiError(err, "getting archive link")
+	}
+
+	// the link is pre-signed, so it doesn't need the API token
+	response, err := client.httpClient.Get(archiveURL.String())
+	if err != nil {
+		return nil, apiError(err, "downloading archive")
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("downloading archive: unexpected status %s", response.Status)
+	}
+
+	return response.Body, nil
+}
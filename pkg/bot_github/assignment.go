@@ -0,0 +1,53 @@
+package botgithub
+
+import (
+	"github.com/google/go-github/v57/github"
+)
+
+type RequestReviewersArgs struct {
+	Owner     string
+	PrNumber  int
+	Repo      string
+	Reviewers []string // logins
+}
+
+// RequestReviewers asks users to review a pull request
+func (client *Client) RequestReviewers(args RequestReviewersArgs) error {
+	_, _, err := client.github.PullRequests.RequestReviewers(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+		github.ReviewersRequest{Reviewers: args.Reviewers},
+	)
+
+	if err != nil {
+		return apiError(err, "requesting reviewers")
+	}
+
+	return nil
+}
+
+type AddAssigneesArgs struct {
+	Assignees   []string // logins
+	IssueNumber int      // an issue or pull request number
+	Owner       string
+	Repo        string
+}
+
+// AddAssignees assigns users to an issue or pull request
+func (client *Client) AddAssignees(args AddAssigneesArgs) error {
+	_, _, err := client.github.Issues.AddAssignees(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.IssueNumber,
+		args.Assignees,
+	)
+
+	if err != nil {
+		return apiError(err, "adding assignees")
+	}
+
+	return nil
+}
@@ -0,0 +1,825 @@
+package botgithubtest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// mockDefaultBranch is the branch new branches start from and PRs merge into
+const mockDefaultBranch = "main"
+
+// maxBranchSuffix bounds the suffixed branch names tried, like the real client
+const maxBranchSuffix = 10
+
+// MockComment is a comment left on an issue or PR
+type MockComment struct {
+	Body        string
+	ID          int64
+	IssueNumber int
+}
+
+// MockReaction is an emoji reaction on an issue or comment; CommentID is 0
+// for reactions on the issue itself
+type MockReaction struct {
+	CommentID   int64
+	IssueNumber int
+	Reaction    string
+}
+
+// MockClient is an in-memory botGithub.GithubAPI for tests. It holds a single repo,
+// ignoring the owner and repo in every call: branches are copies of the
+// default branch's files, and comments, labels and PRs are recorded for
+// tests to check. Refs that aren't branches, such as commit SHAs, read the
+// default branch
+type MockClient struct {
+	Branches     map[string]map[string]string  // files by path on each branch
+	CheckRuns    map[string][]*github.CheckRun // by commit SHA
+	Comments     []MockComment
+	Issues       map[int]*github.Issue                 // opened with CreateIssue
+	Labels       map[int][]string                      // by issue or PR number
+	NotWritable  *botGithub.RepositoryNotWritableError // returned by CheckRepositoryWritable when set
+	Permissions  map[string]string                     // by login; anyone else has "write"
+	PullRequests []*github.PullRequest
+	Reactions    []MockReaction
+	Reviewers    map[int][]string                    // requested or assigned, by PR number
+	Reviews      map[int][]*github.PullRequestReview // submitted, by PR number
+	WorkflowRuns map[string][]*github.WorkflowRun    // by workflow file name
+
+	mutex      sync.Mutex
+	nextID     int64
+	nextNumber int
+}
+
+// NewMockClient returns a mock whose default branch holds files
+func NewMockClient(files map[string]string) *MockClient {
+	defaultBranch := map[string]string{}
+	maps.Copy(defaultBranch, files)
+
+	return &MockClient{
+		Branches:     map[string]map[string]string{mockDefaultBranch: defaultBranch},
+		CheckRuns:    map[string][]*github.CheckRun{},
+		Issues:       map[int]*github.Issue{},
+		Labels:       map[int][]string{},
+		Permissions:  map[string]string{},
+		Reviewers:    map[int][]string{},
+		Reviews:      map[int][]*github.PullRequestReview{},
+		WorkflowRuns: map[string][]*github.WorkflowRun{},
+		nextID:       1000,
+		nextNumber:   100,
+	}
+}
+
+var _ botGithub.GithubAPI = (*MockClient)(nil)
+
+// CommentsOn returns the bodies of the comments on an issue or PR, oldest first
+func (mock *MockClient) CommentsOn(number int) []string {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	bodies := []string{}
+
+	for _, comment := range mock.Comments {
+		if comment.IssueNumber == number {
+			bodies = append(bodies, comment.Body)
+		}
+	}
+
+	return bodies
+}
+
+// newID returns a fresh comment, check run or commit ID; callers hold the mutex
+func (mock *MockClient) newID() int64 {
+	mock.nextID++
+	return mock.nextID
+}
+
+// newSHA returns a fresh commit SHA; callers hold the mutex
+func (mock *MockClient) newSHA() string {
+	return fmt.Sprintf("%040x", mock.newID())
+}
+
+// branch returns a branch's files, reading the default branch for refs
+// that aren't branches; callers hold the mutex
+func (mock *MockClient) branch(ref string) map[string]string {
+	if files, exists := mock.Branches[ref]; exists {
+		return files
+	}
+
+	return mock.Branches[mockDefaultBranch]
+}
+
+// mockDiff is a unified diff from base to head with one hunk per changed
+// file, which is enough for handlers that pass the diff on
+func mockDiff(base, head map[string]string) string {
+	both := map[string]string{}
+	maps.Copy(both, base)
+	maps.Copy(both, head)
+
+	var diff strings.Builder
+
+	for _, filePath := range slices.Sorted(maps.Keys(both)) {
+		baseContent, isInBase := base[filePath]
+		headContent, isInHead := head[filePath]
+
+		if isInBase && isInHead && baseContent == headContent {
+			continue
+		}
+
+		from, to := "a/"+filePath, "b/"+filePath
+		if !isInBase {
+			from = "/dev/null"
+		}
+
+		if !isInHead {
+			to = "/dev/null"
+		}
+
+		removed, added := diffLines(baseContent), diffLines(headContent)
+
+		fmt.Fprintf(&diff, "diff --git a/%s b/%s\n--- %s\n+++ %s\n", filePath, filePath, from, to)
+		fmt.Fprintf(&diff, "@@ -%s +%s @@\n", hunkRange(len(removed)), hunkRange(len(added)))
+
+		for _, line := range removed {
+			diff.WriteString("-" + line + "\n")
+		}
+
+		for _, line := range added {
+			diff.WriteString("+" + line + "\n")
+		}
+	}
+
+	return diff.String()
+}
+
+func diffLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// hunkRange is a hunk header's start,count for a whole file
+func hunkRange(count int) string {
+	if count == 0 {
+		return "0,0"
+	}
+
+	return fmt.Sprintf("1,%d", count)
+}
+
+// pullRequest finds an open or closed PR by number; callers hold the mutex
+func (mock *MockClient) pullRequest(number int) (*github.PullRequest, error) {
+	for _, pullRequest := range mock.PullRequests {
+		if pullRequest.GetNumber() == number {
+			return pullRequest, nil
+		}
+	}
+
+	return nil, fmt.Errorf("getting PR #%d: not found", number)
+}
+
+// writeFiles applies changes to a branch as one commit; callers hold the mutex
+func (mock *MockClient) writeFiles(branch string, changes []botGithub.FileChange) (string, error) {
+	files, exists := mock.Branches[branch]
+	if !exists {
+		return "", fmt.Errorf("getting branch %s: not found", branch)
+	}
+
+	for _, change := range changes {
+		if change.Delete {
+			continue
+		}
+
+		if err := botGithub.CheckForSecrets(change.Path, files[change.Path], change.Content); err != nil {
+			return "", err
+		}
+	}
+
+	for _, change := range changes {
+		if change.Delete {
+			delete(files, change.Path)
+		} else {
+			files[change.Path] = change.Content
+		}
+	}
+
+	return mock.newSHA(), nil
+}
+
+func (mock *MockClient) comment(issueNumber int, body string) *github.IssueComment {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	comment := MockComment{Body: body, ID: mock.newID(), IssueNumber: issueNumber}
+	mock.Comments = append(mock.Comments, comment)
+
+	return &github.IssueComment{Body: github.String(body), ID: github.Int64(comment.ID)}
+}
+
+func (mock *MockClient) react(reaction MockReaction) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	mock.Reactions = append(mock.Reactions, reaction)
+
+	return nil
+}
+
+func (mock *MockClient) AddAssignees(args botGithub.AddAssigneesArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	mock.Reviewers[args.IssueNumber] = append(mock.Reviewers[args.IssueNumber], args.Assignees...)
+
+	return nil
+}
+
+func (mock *MockClient) AddLabels(args botGithub.AddLabelsArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	for _, label := range args.Labels {
+		if !slices.Contains(mock.Labels[args.IssueNumber], label) {
+			mock.Labels[args.IssueNumber] = append(mock.Labels[args.IssueNumber], label)
+		}
+	}
+
+	return nil
+}
+
+func (mock *MockClient) CheckRepositoryWritable(args botGithub.GetRepositoryArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	if mock.NotWritable != nil {
+		return mock.NotWritable
+	}
+
+	return nil
+}
+
+func (mock *MockClient) ClosePullRequest(args botGithub.ClosePullRequestArgs) (*github.PullRequest, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	pullRequest, err := mock.pullRequest(args.PrNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequest.State = github.String("closed")
+
+	return pullRequest, nil
+}
+
+func (mock *MockClient) CommentOnIssue(args botGithub.CommentOnIssueArgs) error {
+	mock.comment(args.IssueNumber, args.Comment)
+	return nil
+}
+
+func (mock *MockClient) CommentOnPR(args botGithub.CommentOnPRArgs) error {
+	mock.comment(args.PrNumber, args.Comment)
+	return nil
+}
+
+func (mock *MockClient) CommitFiles(args botGithub.CommitFilesArgs) (string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mock.writeFiles(args.Branch, args.Changes)
+}
+
+func (mock *MockClient) CompareCommits(args botGithub.CompareCommitsArgs) ([]*github.RepositoryCommit, error) {
+	return nil, nil
+}
+
+// CreateBranch copies the default branch. A leftover branch is reset when
+// it matches the default branch, refused when an open PR uses it and kept
+// otherwise, with the new branch suffixed like the real client's
+func (mock *MockClient) CreateBranch(args botGithub.CreateBranchArgs) (string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	main := mock.Branches[mockDefaultBranch]
+
+	for suffix := 1; suffix <= maxBranchSuffix; suffix++ {
+		branchName := args.BranchName
+		if suffix > 1 {
+			branchName = fmt.Sprintf("%s-%d", args.BranchName, suffix)
+		}
+
+		for _, pullRequest := range mock.PullRequests {
+			if pullRequest.GetHead().GetRef() == branchName && pullRequest.GetState() == "open" {
+				return "", fmt.Errorf("%w: %s (PR #%d)", botGithub.ErrBranchInUse, branchName, pullRequest.GetNumber())
+			}
+		}
+
+		// the mock has no history, so a branch whose files differ stands in for one with commits
+		if files, exists := mock.Branches[branchName]; !exists || maps.Equal(files, main) {
+			mock.Branches[branchName] = maps.Clone(main)
+			return branchName, nil
+		}
+	}
+
+	return "", fmt.Errorf("creating branch: %s and its suffixed names are all taken", args.BranchName)
+}
+
+func (mock *MockClient) CreateCheckRun(args botGithub.CreateCheckRunArgs) (int64, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mock.newID(), nil
+}
+
+func (mock *MockClient) CreateComment(args botGithub.CreateCommentArgs) (*github.IssueComment, error) {
+	return mock.comment(args.IssueNumber, args.Comment), nil
+}
+
+func (mock *MockClient) CreateFile(args botGithub.CreateFileArgs) (string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mock.writeFiles(args.Branch, []botGithub.FileChange{{Content: args.Content, Path: args.Filename}})
+}
+
+func (mock *MockClient) CreateIssue(args botGithub.CreateIssueArgs) (*github.Issue, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	mock.nextNumber++
+
+	issue := &github.Issue{
+		Body:   github.String(args.Body),
+		Number: github.Int(mock.nextNumber),
+		State:  github.String("open"),
+		Title:  github.String(args.Title),
+	}
+
+	mock.Issues[mock.nextNumber] = issue
+	mock.Labels[mock.nextNumber] = slices.Clone(args.Labels)
+
+	return issue, nil
+}
+
+// CreatePullRequest opens a PR from the branch in args.Head ("owner:branch")
+func (mock *MockClient) CreatePullRequest(args botGithub.CreatePullRequestArgs) (*github.PullRequest, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	_, branch, _ := strings.Cut(args.Head, ":")
+
+	if _, exists := mock.Branches[branch]; !exists {
+		return nil, fmt.Errorf("creating PR: branch %s not found", branch)
+	}
+
+	mock.nextNumber++
+
+	pullRequest := &github.PullRequest{
+		Base:    &github.PullRequestBranch{Ref: github.String(args.Base)},
+		Body:    github.String(args.Body),
+		Draft:   github.Bool(args.Draft),
+		Head:    &github.PullRequestBranch{Ref: github.String(branch)},
+		HTMLURL: github.String(fmt.Sprintf("https://github.com/%s/%s/pull/%d", args.Owner, args.Repo, mock.nextNumber)),
+		Number:  github.Int(mock.nextNumber),
+		State:   github.String("open"),
+		Title:   github.String(args.Title),
+	}
+
+	mock.PullRequests = append(mock.PullRequests, pullRequest)
+
+	return pullRequest, nil
+}
+
+func (mock *MockClient) CreatePullRequestReview(args botGithub.CreatePullRequestReviewArgs) error {
+	mock.comment(args.PrNumber, args.Body)
+	return nil
+}
+
+func (mock *MockClient) DeleteBranch(args botGithub.DeleteBranchArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	delete(mock.Branches, args.BranchName)
+
+	return nil
+}
+
+func (mock *MockClient) DispatchWorkflow(args botGithub.DispatchWorkflowArgs) (string, error) {
+	return "", nil
+}
+
+func (mock *MockClient) DownloadArchive(args botGithub.DownloadArchiveArgs) (io.ReadCloser, error) {
+	return nil, errors.New("the mock client has no archives")
+}
+
+func (mock *MockClient) EditComment(args botGithub.EditCommentArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	for index, comment := range mock.Comments {
+		if comment.ID == args.CommentID {
+			mock.Comments[index].Body = args.Comment
+			return nil
+		}
+	}
+
+	return fmt.Errorf("editing comment %d: not found", args.CommentID)
+}
+
+func (mock *MockClient) EditIssue(args botGithub.EditIssueArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	issue, exists := mock.Issues[args.IssueNumber]
+	if !exists {
+		return fmt.Errorf("editing issue #%d: not found", args.IssueNumber)
+	}
+
+	if args.Body != "" {
+		issue.Body = github.String(args.Body)
+	}
+
+	if args.State != "" {
+		issue.State = github.String(args.State)
+	}
+
+	return nil
+}
+
+func (mock *MockClient) FindPullRequestForBranch(args botGithub.FindPullRequestForBranchArgs) (*github.Issue, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	for _, pullRequest := range mock.PullRequests {
+		isMatch := pullRequest.GetHead().GetRef() == args.BranchName &&
+			(args.State == "" || args.State == pullRequest.GetState())
+
+		if isMatch {
+			return &github.Issue{
+				Body:             pullRequest.Body,
+				Number:           pullRequest.Number,
+				PullRequestLinks: &github.PullRequestLinks{HTMLURL: pullRequest.HTMLURL},
+				State:            pullRequest.State,
+				Title:            pullRequest.Title,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (mock *MockClient) GetCombinedStatus(args botGithub.GetCombinedStatusArgs) (*github.CombinedStatus, error) {
+	return &github.CombinedStatus{State: github.String("success")}, nil
+}
+
+// GetCompareDiff diffs two branches, replacing each changed file whole
+func (mock *MockClient) GetCompareDiff(args botGithub.CompareCommitsArgs) (string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mockDiff(mock.branch(args.Base), mock.branch(args.Head)), nil
+}
+
+// GetFileContent returns a file and a SHA of its content
+func (mock *MockClient) GetFileContent(args botGithub.GetFileContentArgs) (string, string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	content, exists := mock.branch(args.Ref)[args.Filename]
+	if !exists {
+		return "", "", fmt.Errorf("getting %s: %w", args.Filename, botGithub.ErrFileNotFound)
+	}
+
+	hash := sha1.Sum([]byte(content))
+
+	return content, hex.EncodeToString(hash[:]), nil
+}
+
+func (mock *MockClient) GetIssue(args botGithub.GetIssueArgs) (*github.Issue, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	issue, exists := mock.Issues[args.IssueNumber]
+	if !exists {
+		return nil, fmt.Errorf("getting issue #%d: not found", args.IssueNumber)
+	}
+
+	return issue, nil
+}
+
+func (mock *MockClient) GetPullRequest(args botGithub.GetPullRequestArgs) (*github.PullRequest, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mock.pullRequest(args.PrNumber)
+}
+
+// GetPullRequestDiff diffs the PR's branch with the default branch,
+// replacing each changed file whole
+func (mock *MockClient) GetPullRequestDiff(args botGithub.GetPullRequestDiffArgs) (string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	pullRequest, err := mock.pullRequest(args.PrNumber)
+	if err != nil {
+		return "", err
+	}
+
+	return mockDiff(mock.Branches[mockDefaultBranch], mock.branch(pullRequest.GetHead().GetRef())), nil
+}
+
+func (mock *MockClient) GetUserPermission(args botGithub.GetUserPermissionArgs) (string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	if permission, exists := mock.Permissions[args.Username]; exists {
+		return permission, nil
+	}
+
+	return "write", nil
+}
+
+func (mock *MockClient) ListCheckRunAnnotations(args botGithub.ListCheckRunAnnotationsArgs) ([]*github.CheckRunAnnotation, error) {
+	return nil, nil
+}
+
+func (mock *MockClient) ListCheckRuns(args botGithub.ListCheckRunsArgs) ([]*github.CheckRun, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mock.CheckRuns[args.Ref], nil
+}
+
+// ListDirectory lists the files and directories directly inside a path
+func (mock *MockClient) ListDirectory(args botGithub.ListDirectoryArgs) ([]*github.RepositoryContent, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	prefix := strings.Trim(args.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	contents := []*github.RepositoryContent{}
+	seen := map[string]bool{}
+
+	for _, filePath := range slices.Sorted(maps.Keys(mock.branch(args.Ref))) {
+		rest, isInside := strings.CutPrefix(filePath, prefix)
+		if !isInside {
+			continue
+		}
+
+		name, _, isDir := strings.Cut(rest, "/")
+		if seen[name] {
+			continue
+		}
+
+		seen[name] = true
+
+		entryType := "file"
+		if isDir {
+			entryType = "dir"
+		}
+
+		contents = append(
+			contents,
+			&github.RepositoryContent{
+				Name: github.String(name),
+				Path: github.String(path.Join(prefix, name)),
+				Type: github.String(entryType),
+			},
+		)
+	}
+
+	if len(contents) == 0 && prefix != "" {
+		return nil, fmt.Errorf("listing %s: %w", args.Path, botGithub.ErrFileNotFound)
+	}
+
+	return contents, nil
+}
+
+func (mock *MockClient) ListIssueCommentReactions(args botGithub.ListIssueCommentReactionsArgs) ([]*github.Reaction, error) {
+	return nil, nil
+}
+
+func (mock *MockClient) ListIssueComments(args botGithub.ListIssueCommentsArgs) ([]*github.IssueComment, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	comments := []*github.IssueComment{}
+
+	for _, comment := range mock.Comments {
+		if comment.IssueNumber == args.IssueNumber {
+			comments = append(comments, &github.IssueComment{Body: github.String(comment.Body), ID: github.Int64(comment.ID)})
+		}
+	}
+
+	return comments, nil
+}
+
+func (mock *MockClient) ListOpenPullRequests(args botGithub.ListOpenPullRequestsArgs) ([]*github.PullRequest, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	open := []*github.PullRequest{}
+
+	for _, pullRequest := range mock.PullRequests {
+		if pullRequest.GetState() == "open" {
+			open = append(open, pullRequest)
+		}
+	}
+
+	if args.MaxResults > 0 && len(open) > args.MaxResults {
+		return open[:args.MaxResults], nil
+	}
+
+	return open, nil
+}
+
+// ListPullRequestFiles compares the PR's branch with the default branch
+func (mock *MockClient) ListPullRequestFiles(args botGithub.ListPullRequestFilesArgs) ([]*github.CommitFile, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	pullRequest, err := mock.pullRequest(args.PrNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	base := mock.Branches[mockDefaultBranch]
+	head := mock.branch(pullRequest.GetHead().GetRef())
+	changed := []*github.CommitFile{}
+
+	for _, filePath := range slices.Sorted(maps.Keys(head)) {
+		baseContent, isInBase := base[filePath]
+
+		switch {
+		case !isInBase:
+			changed = append(changed, &github.CommitFile{Filename: github.String(filePath), Status: github.String("added")})
+		case baseContent != head[filePath]:
+			changed = append(changed, &github.CommitFile{Filename: github.String(filePath), Status: github.String("modified")})
+		}
+	}
+
+	for _, filePath := range slices.Sorted(maps.Keys(base)) {
+		if _, isInHead := head[filePath]; !isInHead {
+			changed = append(changed, &github.CommitFile{Filename: github.String(filePath), Status: github.String("removed")})
+		}
+	}
+
+	if args.MaxResults > 0 && len(changed) > args.MaxResults {
+		return changed[:args.MaxResults], nil
+	}
+
+	return changed, nil
+}
+
+func (mock *MockClient) ListPullRequestReviews(args botGithub.ListPullRequestReviewsArgs) ([]*github.PullRequestReview, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mock.Reviews[args.PrNumber], nil
+}
+
+func (mock *MockClient) ListTags(args botGithub.ListTagsArgs) ([]*github.RepositoryTag, error) {
+	return nil, nil
+}
+
+// ListWorkflowRuns returns the workflow's runs on the commit
+func (mock *MockClient) ListWorkflowRuns(args botGithub.ListWorkflowRunsArgs) ([]*github.WorkflowRun, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	var workflowRuns []*github.WorkflowRun
+
+	for _, workflowRun := range mock.WorkflowRuns[args.Workflow] {
+		if workflowRun.GetHeadSHA() == args.HeadSHA {
+			workflowRuns = append(workflowRuns, workflowRun)
+		}
+	}
+
+	return workflowRuns, nil
+}
+
+func (mock *MockClient) MarkReadyForReview(args botGithub.MarkReadyForReviewArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	pullRequest, err := mock.pullRequest(args.PrNumber)
+	if err != nil {
+		return err
+	}
+
+	pullRequest.Draft = github.Bool(false)
+
+	return nil
+}
+
+// MergePullRequest copies the PR's branch onto the default branch
+func (mock *MockClient) MergePullRequest(args botGithub.MergePullRequestArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	pullRequest, err := mock.pullRequest(args.PrNumber)
+	if err != nil {
+		return err
+	}
+
+	mock.Branches[mockDefaultBranch] = maps.Clone(mock.branch(pullRequest.GetHead().GetRef()))
+	pullRequest.Merged = github.Bool(true)
+	pullRequest.State = github.String("closed")
+
+	return nil
+}
+
+func (mock *MockClient) NewGenerationCheck(args botGithub.NewGenerationCheckArgs) *botGithub.GenerationCheck {
+	return botGithub.GenerationCheckFor(mock, args)
+}
+
+func (mock *MockClient) NewProgressComment(args botGithub.NewProgressCommentArgs) *botGithub.ProgressComment {
+	return botGithub.ProgressCommentFor(mock, args)
+}
+
+func (mock *MockClient) ReactToIssue(args botGithub.ReactToIssueArgs) error {
+	return mock.react(MockReaction{IssueNumber: args.IssueNumber, Reaction: args.Reaction})
+}
+
+func (mock *MockClient) ReactToIssueComment(args botGithub.ReactToIssueCommentArgs) error {
+	return mock.react(MockReaction{CommentID: args.CommentID, Reaction: args.Reaction})
+}
+
+func (mock *MockClient) ReactToPRComment(args botGithub.ReactToPRCommentArgs) error {
+	return mock.react(MockReaction{CommentID: args.CommentID, Reaction: args.Reaction})
+}
+
+func (mock *MockClient) RemoveLabels(args botGithub.RemoveLabelsArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	mock.Labels[args.IssueNumber] = slices.DeleteFunc(mock.Labels[args.IssueNumber], func(label string) bool {
+		return slices.Contains(args.Labels, label)
+	})
+
+	return nil
+}
+
+func (mock *MockClient) ReplyToReviewComment(args botGithub.ReplyToReviewCommentArgs) error {
+	mock.comment(args.PrNumber, args.Comment)
+	return nil
+}
+
+func (mock *MockClient) RequestReviewers(args botGithub.RequestReviewersArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	mock.Reviewers[args.PrNumber] = append(mock.Reviewers[args.PrNumber], args.Reviewers...)
+
+	return nil
+}
+
+func (mock *MockClient) RevertCommit(args botGithub.RevertCommitArgs) (string, error) {
+	return "", errors.New("the mock client can't revert commits")
+}
+
+func (mock *MockClient) UpdateCheckRun(args botGithub.UpdateCheckRunArgs) error {
+	return nil
+}
+
+func (mock *MockClient) UpdateFile(args botGithub.UpdateFileArgs) (string, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	return mock.writeFiles(args.Branch, []botGithub.FileChange{{Content: args.Content, Path: args.Filename}})
+}
+
+func (mock *MockClient) UpdatePullRequest(args botGithub.UpdatePullRequestArgs) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+
+	pullRequest, err := mock.pullRequest(args.PrNumber)
+	if err != nil {
+		return err
+	}
+
+	if args.Body != "" {
+		pullRequest.Body = github.String(args.Body)
+	}
+
+	if args.Title != "" {
+		pullRequest.Title = github.String(args.Title)
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package botgithub
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Check run conclusions the bot reports
+const (
+	CheckConclusionFailure = "failure"
+	CheckConclusionNeutral = "neutral"
+	CheckConclusionSuccess = "success"
+)
+
+type CreateCheckRunArgs struct {
+	Conclusion string // empty creates the run in progress
+	HeadSHA    string
+	Name       string
+	Owner      string
+	Repo       string
+	Summary    string
+	Title      string
+}
+
+// CreateCheckRun adds a check run to a commit and returns its ID. Check runs
+// can only be created when authenticated as a GitHub App
+func (client *Client) CreateCheckRun(args CreateCheckRunArgs) (int64, error) {
+	options := github.CreateCheckRunOptions{
+		HeadSHA: args.HeadSHA,
+		Name:    args.Name,
+		Output: &github.CheckRunOutput{
+			Summary: github.String(args.Summary),
+			Title:   github.String(args.Title),
+		},
+		Status: github.String("in_progress"),
+	}
+
+	if args.Conclusion != "" {
+		options.Conclusion = github.String(args.Conclusion)
+		options.CompletedAt = &github.Timestamp{Time: time.Now()}
+		options.Status = github.String("completed")
+	}
+
+	checkRun, _, err := client.github.Checks.CreateCheckRun(
+		client.context,
+		args.Owner,
+		args.Repo,
+		options,
+	)
+
+	if err != nil {
+		return 0, apiError(err, "creating check run")
+	}
+
+	return checkRun.GetID(), nil
+}
+
+type UpdateCheckRunArgs struct {
+	CheckRunID int64
+	Conclusion string // empty leaves the run in progress
+	Name       string
+	Owner      string
+	Repo       string
+	Summary    string
+	Title      string
+}
+
+// UpdateCheckRun changes a check run's output, completing it when a conclusion is given
+func (client *Client) UpdateCheckRun(args UpdateCheckRunArgs) error {
+	options := github.UpdateCheckRunOptions{
+		Name: args.Name,
+		Output: &github.CheckRunOutput{
+			Summary: github.String(args.Summary),
+			Title:   github.String(args.Title),
+		},
+	}
+
+	if args.Conclusion != "" {
+		options.Conclusion = github.String(args.Conclusion)
+		options.CompletedAt = &github.Timestamp{Time: time.Now()}
+		options.Status = github.String("completed")
+	}
+
+	_, _, err := client.github.Checks.UpdateCheckRun(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.CheckRunID,
+		options,
+	)
+
+	if err != nil {
+		return apiError(err, "updating check run")
+	}
+
+	return nil
+}
+
+type ListCheckRunsArgs struct {
+	Owner string
+	Ref   string // a SHA, branch or tag
+	Repo  string
+}
+
+// ListCheckRuns returns the latest check runs on a commit, following pagination
+func (client *Client) ListCheckRuns(args ListCheckRunsArgs) ([]*github.CheckRun, error) {
+	options := &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var checkRuns []*github.CheckRun
+
+	for {
+		result, response, err := client.github.Checks.ListCheckRunsForRef(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.Ref,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing check runs")
+		}
+
+		checkRuns = append(checkRuns, result.CheckRuns...)
+
+		if response.NextPage == 0 {
+			return checkRuns, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+type ListCheckRunAnnotationsArgs struct {
+	CheckRunID int64
+	Owner      string
+	Repo       string
+}
+
+// ListCheckRunAnnotations returns the annotations a check run left on the
+// code, e.g. compiler errors and failing test locations
+func (client *Client) ListCheckRunAnnotations(args ListCheckRunAnnotationsArgs) ([]*github.CheckRunAnnotation, error) {
+	annotations, _, err := client.github.Checks.ListCheckRunAnnotations(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.CheckRunID,
+		&github.ListOptions{PerPage: 100},
+	)
+
+	if err != nil {
+		return nil, apiError(err, "listing check run annotations")
+	}
+
+	return annotations, nil
+}
+
+type GetCombinedStatusArgs struct {
+	Owner string
+	Ref   string // a SHA, branch or tag
+	Repo  string
+}
+
+// GetCombinedStatus returns the commit statuses (as opposed to check runs)
+// reported on a commit
+func (client *Client) GetCombinedStatus(args GetCombinedStatusArgs) (*github.CombinedStatus, error) {
+	status, _, err := client.github.Repositories.GetCombinedStatus(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.Ref,
+		&github.ListOptions{PerPage: 100},
+	)
+
+	if err != nil {
+		return nil, apiError(err, "getting commit status")
+	}
+
+	return status, nil
+}
+
+// GenerationCheck shows the state of long-running bot work as a check run on
+// the branch's head commit, moving to each new commit as it's pushed. After
+// the first API error (e.g. when authenticated with a token rather than as a
+// GitHub App) it stops reporting
+type GenerationCheck struct {
+	checkRunID int64
+	client     GithubAPI
+	headSHA    string
+	isDisabled bool
+	lines      []string
+	name       string
+	owner      string
+	repo       string
+}
+
+type NewGenerationCheckArgs struct {
+	Name  string
+	Owner string
+	Repo  string
+}
+
+// NewGenerationCheck prepares a check; nothing is created until the first Report or Finish
+func (client *Client) NewGenerationCheck(args NewGenerationCheckArgs) *GenerationCheck {
+	return GenerationCheckFor(client, args)
+}
+
+// GenerationCheckFor prepares a check reported through any GithubAPI, so other
+// implementations, such as the test mock, share this one
+func GenerationCheckFor(client GithubAPI, args NewGenerationCheckArgs) *GenerationCheck {
+	return &GenerationCheck{
+		client: client,
+		name:   args.Name,
+		owner:  args.Owner,
+		repo:   args.Repo,
+	}
+}
+
+// Report adds a progress line to the in-progress check on headSHA
+func (check *GenerationCheck) Report(headSHA, line string) error {
+	return check.update(headSHA, "", "AI generation in progress", line)
+}
+
+// Finish completes the check on headSHA with a conclusion; with no commit to
+// attach it to there's nothing to report
+func (check *GenerationCheck) Finish(headSHA, conclusion, line string) error {
+	title := "AI generation succeeded"
+	if conclusion == CheckConclusionFailure {
+		title = "AI generation failed"
+	}
+
+	return check.update(headSHA, conclusion, title, line)
+}
+
+func (check *GenerationCheck) update(headSHA, conclusion, title, line string) error {
+	check.lines = append(check.lines, "- "+line)
+
+	if check.isDisabled || headSHA == "" {
+		return nil
+	}
+
+	summary := strings.Join(check.lines, "\n")
+
+	var err error
+
+	if headSHA == check.headSHA {
+		err = check.client.UpdateCheckRun(
+			UpdateCheckRunArgs{
+				CheckRunID: check.checkRunID,
+				Conclusion: conclusion,
+				Name:       check.name,
+				Owner:      check.owner,
+				Repo:       check.repo,
+				Summary:    summary,
+				Title:      title,
+			},
+		)
+	} else {
+		err = check.moveTo(headSHA, conclusion, title, summary)
+	}
+
+	if err != nil {
+		check.isDisabled = true
+	}
+
+	return err
+}
+
+// moveTo closes the run on the previous commit and starts one on headSHA
+func (check *GenerationCheck) moveTo(headSHA, conclusion, title, summary string) error {
+	if check.checkRunID != 0 {
+		if err := check.client.UpdateCheckRun(
+			UpdateCheckRunArgs{
+				CheckRunID: check.checkRunID,
+				Conclusion: CheckConclusionNeutral,
+				Name:       check.name,
+				Owner:      check.owner,
+				Repo:       check.repo,
+				Summary:    summary,
+				Title:      "Continued on a newer commit",
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	checkRunID, err := check.client.CreateCheckRun(
+		CreateCheckRunArgs{
+			Conclusion: conclusion,
+			HeadSHA:    headSHA,
+			Name:       check.name,
+			Owner:      check.owner,
+			Repo:       check.repo,
+			Summary:    summary,
+			Title:      title,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	check.checkRunID = checkRunID
+	check.headSHA = headSHA
+
+	return nil
+}
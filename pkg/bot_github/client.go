@@ -2,7 +2,12 @@ package botgithub
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/oauth2"
@@ -10,34 +15,88 @@ import (
 
 // Client wraps the GitHub API client with convenience methods
 type Client struct {
-	context context.Context
-	github  *github.Client
+	context    context.Context
+	github     *github.Client
+	httpClient *http.Client // sends requests without the token, e.g. archive downloads
 }
 
 // NewClient creates a new GitHub client with the provided token
-func NewClient(token string) *Client {
-	context := context.Background()
+func NewClient(token string, options ...Option) *Client {
+	settings := clientOptions{logger: slog.Default()}
+	for _, option := range options {
+		option(&settings)
+	}
+
+	httpClient := &http.Client{}
+	if settings.httpClient != nil {
+		copied := *settings.httpClient
+		httpClient = &copied
+	}
+
+	if httpClient.Transport == nil {
+		httpClient.Transport = http.DefaultTransport
+	}
+
+	if settings.etagEntries > 0 {
+		httpClient.Transport = newETagTransport(httpClient.Transport, settings.etagEntries)
+	}
+
+	if settings.retry.Attempts > 1 {
+		httpClient.Transport = &retryTransport{
+			base:   httpClient.Transport,
+			logger: settings.logger,
+			policy: settings.retry,
+		}
+	}
+
+	// oauth2 sends its authorized requests through the client in the context
+	context := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
 
 	tokenSource := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 
-	clientToken := oauth2.NewClient(context, tokenSource)
+	gh := github.NewClient(oauth2.NewClient(context, tokenSource))
+
+	if settings.baseURL != "" {
+		baseURL, err := url.Parse(strings.TrimSuffix(settings.baseURL, "/") + "/")
+		if err != nil || baseURL.Host == "" {
+			settings.logger.Error("Ignoring GitHub base URL that isn't a URL", "url", settings.baseURL)
+		} else {
+			gh.BaseURL = baseURL
+		}
+	}
+
+	if settings.userAgent != "" {
+		gh.UserAgent = settings.userAgent
+	}
 
 	return &Client{
-		context: context,
-		github:  github.NewClient(clientToken),
+		context:    context,
+		github:     gh,
+		httpClient: httpClient,
 	}
 }
 
+// ErrBranchInUse means the branch already backs an open pull request, which
+// usually means the same webhook was delivered twice
+var ErrBranchInUse = errors.New("branch already has an open pull request")
+
 type CreateBranchArgs struct {
 	BranchName string
 	Owner      string
 	Repo       string
 }
 
-// CreateBranch creates a new branch from the main branch
-func (client *Client) CreateBranch(args CreateBranchArgs) error {
+// maxBranchSuffix bounds the "-2", "-3", ... names tried when leftover
+// branches with commits of their own hold the name
+const maxBranchSuffix = 10
+
+// CreateBranch creates a new branch from the main branch and returns its
+// name. A leftover branch with the same name is reset to main and reused when
+// it has no commits main lacks; one that has some, e.g. a person's work on a
+// closed PR, is kept and the branch is created as "name-2", "name-3" and so on
+func (client *Client) CreateBranch(args CreateBranchArgs) (string, error) {
 	// Get the main branch reference
 	mainRef, _, err := client.github.Git.GetRef(
 		client.context,
@@ -47,26 +106,128 @@ func (client *Client) CreateBranch(args CreateBranchArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("getting main branch: %w", err)
+		return "", apiError(err, "getting main branch")
+	}
+
+	for suffix := 1; suffix <= maxBranchSuffix; suffix++ {
+		branchName := args.BranchName
+		if suffix > 1 {
+			branchName = fmt.Sprintf("%s-%d", args.BranchName, suffix)
+		}
+
+		// Create new branch reference
+		newRef := &github.Reference{
+			Object: &github.GitObject{
+				SHA: mainRef.Object.SHA,
+			},
+			Ref: github.String("refs/heads/" + branchName),
+		}
+
+		_, response, err := client.github.Git.CreateRef(
+			client.context,
+			args.Owner,
+			args.Repo,
+			newRef,
+		)
+
+		branchExists := response != nil && response.StatusCode == http.StatusUnprocessableEntity
+
+		if err != nil && !branchExists {
+			return "", apiError(err, "creating branch")
+		}
+
+		if err == nil {
+			return branchName, nil
+		}
+
+		isReused, err := client.reuseBranch(args.Owner, args.Repo, branchName, *mainRef.Object.SHA)
+		if err != nil || isReused {
+			return branchName, err
+		}
 	}
 
-	// Create new branch reference
-	newRef := &github.Reference{
-		Object: &github.GitObject{
-			SHA: mainRef.Object.SHA,
+	return "", fmt.Errorf("creating branch: %s and %d suffixed names are all taken by branches with commits", args.BranchName, maxBranchSuffix-1)
+}
+
+// reuseBranch resets a leftover branch to main so a new run can start on it,
+// reporting false without touching it when it has commits main lacks, and
+// refusing when an open PR still depends on the branch
+func (client *Client) reuseBranch(owner, repo, branchName, mainSHA string) (bool, error) {
+	openPRs, _, err := client.github.PullRequests.List(
+		client.context,
+		owner,
+		repo,
+		&github.PullRequestListOptions{
+			Head:  owner + ":" + branchName,
+			State: "open",
 		},
-		Ref: github.String("refs/heads/" + args.BranchName),
+	)
+
+	if err != nil {
+		return false, apiError(err, "checking pull requests for branch %s", branchName)
+	}
+
+	if len(openPRs) > 0 {
+		return false, fmt.Errorf("%w: %s (PR #%d)", ErrBranchInUse, branchName, openPRs[0].GetNumber())
 	}
 
-	_, _, err = client.github.Git.CreateRef(
+	comparison, _, err := client.github.Repositories.CompareCommits(
+		client.context,
+		owner,
+		repo,
+		mainSHA,
+		branchName,
+		nil,
+	)
+
+	if err != nil {
+		return false, apiError(err, "comparing branch %s with main", branchName)
+	}
+
+	if comparison.GetAheadBy() > 0 {
+		return false, nil
+	}
+
+	// not forced, so a commit pushed since the comparison fails the update instead of being lost
+	_, _, err = client.github.Git.UpdateRef(
+		client.context,
+		owner,
+		repo,
+		&github.Reference{
+			Object: &github.GitObject{SHA: github.String(mainSHA)},
+			Ref:    github.String("refs/heads/" + branchName),
+		},
+		false,
+	)
+
+	if err != nil {
+		return false, apiError(err, "resetting existing branch %s", branchName)
+	}
+
+	return true, nil
+}
+
+type DeleteBranchArgs struct {
+	BranchName string
+	Owner      string
+	Repo       string
+}
+
+// DeleteBranch removes a branch, treating an already-deleted branch as success
+func (client *Client) DeleteBranch(args DeleteBranchArgs) error {
+	response, err := client.github.Git.DeleteRef(
 		client.context,
 		args.Owner,
 		args.Repo,
-		newRef,
+		"refs/heads/"+args.BranchName,
 	)
 
-	if err != nil {
-		return fmt.Errorf("creating branch: %w", err)
+	branchMissing := response != nil &&
+		(response.StatusCode == http.StatusNotFound ||
+			response.StatusCode == http.StatusUnprocessableEntity)
+
+	if err != nil && !branchMissing {
+		return apiError(err, "deleting branch %s", args.BranchName)
 	}
 
 	return nil
@@ -81,15 +242,19 @@ type CreateFileArgs struct {
 	Repo     string
 }
 
-// CreateFile creates a new file in the repository
-func (client *Client) CreateFile(args CreateFileArgs) error {
+// CreateFile creates a new file in the repository and returns the commit SHA
+func (client *Client) CreateFile(args CreateFileArgs) (string, error) {
+	if err := CheckForSecrets(args.Filename, "", args.Content); err != nil {
+		return "", err
+	}
+
 	options := &github.RepositoryContentFileOptions{
 		Message: github.String(args.Message),
 		Content: []byte(args.Content),
 		Branch:  github.String(args.Branch),
 	}
 
-	_, _, err := client.github.Repositories.CreateFile(
+	response, _, err := client.github.Repositories.CreateFile(
 		client.context,
 		args.Owner,
 		args.Repo,
@@ -98,10 +263,10 @@ func (client *Client) CreateFile(args CreateFileArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("creating file: %w", err)
+		return "", apiError(err, "creating file")
 	}
 
-	return nil
+	return response.GetSHA(), nil
 }
 
 type UpdateFileArgs struct {
@@ -114,8 +279,14 @@ type UpdateFileArgs struct {
 	Sha      string
 }
 
-// UpdateFile updates an existing file in the repository
-func (client *Client) UpdateFile(args UpdateFileArgs) error {
+// UpdateFile updates an existing file in the repository and returns the commit SHA
+func (client *Client) UpdateFile(args UpdateFileArgs) (string, error) {
+	previous := client.currentContent(args.Owner, args.Repo, args.Branch, args.Filename)
+
+	if err := CheckForSecrets(args.Filename, previous, args.Content); err != nil {
+		return "", err
+	}
+
 	options := &github.RepositoryContentFileOptions{
 		Branch:  github.String(args.Branch),
 		Content: []byte(args.Content),
@@ -123,7 +294,7 @@ func (client *Client) UpdateFile(args UpdateFileArgs) error {
 		SHA:     github.String(args.Sha),
 	}
 
-	_, _, err := client.github.Repositories.UpdateFile(
+	response, _, err := client.github.Repositories.UpdateFile(
 		client.context,
 		args.Owner,
 		args.Repo,
@@ -132,10 +303,10 @@ func (client *Client) UpdateFile(args UpdateFileArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("updating file: %w", err)
+		return "", apiError(err, "updating file")
 	}
 
-	return nil
+	return response.GetSHA(), nil
 }
 
 type DeleteFileArgs struct {
@@ -164,7 +335,7 @@ func (client *Client) DeleteFile(args DeleteFileArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("deleting file: %w", err)
+		return apiError(err, "deleting file")
 	}
 
 	return nil
@@ -173,6 +344,7 @@ func (client *Client) DeleteFile(args DeleteFileArgs) error {
 type CreatePullRequestArgs struct {
 	Base  string
 	Body  string
+	Draft bool
 	Head  string
 	Owner string
 	Repo  string
@@ -188,6 +360,7 @@ func (client *Client) CreatePullRequest(
 		Head:  github.String(args.Head),
 		Base:  github.String(args.Base),
 		Body:  github.String(args.Body),
+		Draft: github.Bool(args.Draft),
 	}
 
 	pullRequest, _, err := client.github.PullRequests.Create(
@@ -198,12 +371,15 @@ func (client *Client) CreatePullRequest(
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("creating PR: %w", err)
+		return nil, apiError(err, "creating PR")
 	}
 
 	return pullRequest, nil
 }
 
+// ErrFileNotFound means the file doesn't exist at the requested ref
+var ErrFileNotFound = errors.New("file not found")
+
 type GetFileContentArgs struct {
 	Filename string
 	Owner    string
@@ -219,7 +395,7 @@ func (client *Client) GetFileContent(
 		Ref: args.Ref,
 	}
 
-	fileContent, _, _, err := client.github.Repositories.GetContents(
+	fileContent, _, response, err := client.github.Repositories.GetContents(
 		client.context,
 		args.Owner,
 		args.Repo,
@@ -227,8 +403,12 @@ func (client *Client) GetFileContent(
 		options,
 	)
 
+	if response != nil && response.StatusCode == http.StatusNotFound {
+		return "", "", fmt.Errorf("getting %s: %w", args.Filename, ErrFileNotFound)
+	}
+
 	if err != nil {
-		return "", "", fmt.Errorf("getting file content: %w", err)
+		return "", "", apiError(err, "getting file content")
 	}
 
 	content, err := fileContent.GetContent()
@@ -240,28 +420,48 @@ func (client *Client) GetFileContent(
 }
 
 type ListPullRequestFilesArgs struct {
-	Owner    string
-	PrNumber int
-	Repo     string
+	MaxResults int // 0 fetches every page; GitHub lists at most 3000 files
+	Owner      string
+	PrNumber   int
+	Repo       string
 }
 
-// ListPullRequestFiles returns the files changed in a pull request
+// ListPullRequestFiles returns the files changed in a pull request, following
+// pagination
 func (client *Client) ListPullRequestFiles(
 	args ListPullRequestFilesArgs,
 ) ([]*github.CommitFile, error) {
-	files, _, err := client.github.PullRequests.ListFiles(
-		client.context,
-		args.Owner,
-		args.Repo,
-		args.PrNumber,
-		nil,
-	)
+	options := &github.ListOptions{PerPage: 100}
 
-	if err != nil {
-		return nil, fmt.Errorf("listing PR files: %w", err)
-	}
+	var allFiles []*github.CommitFile
+
+	for {
+		files, response, err := client.github.PullRequests.ListFiles(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.PrNumber,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing PR files")
+		}
 
-	return files, nil
+		allFiles = append(allFiles, files...)
+
+		hasReachedLimit := args.MaxResults > 0 && len(allFiles) >= args.MaxResults
+
+		if hasReachedLimit {
+			return allFiles[:args.MaxResults], nil
+		}
+
+		if response.NextPage == 0 {
+			return allFiles, nil
+		}
+
+		options.Page = response.NextPage
+	}
 }
 
 type ReactToIssueArgs struct {
@@ -282,7 +482,7 @@ func (client *Client) ReactToIssue(args ReactToIssueArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("reacting to issue: %w", err)
+		return apiError(err, "reacting to issue")
 	}
 
 	return nil
@@ -306,7 +506,7 @@ func (client *Client) ReactToPRComment(args ReactToPRCommentArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("reacting to PR comment: %w", err)
+		return apiError(err, "reacting to PR comment")
 	}
 
 	return nil
@@ -332,7 +532,7 @@ func (client *Client) CommentOnIssue(args CommentOnIssueArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("commenting on issue: %w", err)
+		return apiError(err, "commenting on issue")
 	}
 
 	return nil
@@ -358,7 +558,310 @@ func (client *Client) CommentOnPR(args CommentOnPRArgs) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("commenting on PR: %w", err)
+		return apiError(err, "commenting on PR")
+	}
+
+	return nil
+}
+
+type GetPullRequestArgs struct {
+	Owner    string
+	PrNumber int
+	Repo     string
+}
+
+// GetPullRequest retrieves a single pull request
+func (client *Client) GetPullRequest(
+	args GetPullRequestArgs,
+) (*github.PullRequest, error) {
+	pullRequest, _, err := client.github.PullRequests.Get(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+	)
+
+	if err != nil {
+		return nil, apiError(err, "getting PR")
+	}
+
+	return pullRequest, nil
+}
+
+type GetIssueArgs struct {
+	IssueNumber int
+	Owner       string
+	Repo        string
+}
+
+// GetIssue retrieves a single issue
+func (client *Client) GetIssue(args GetIssueArgs) (*github.Issue, error) {
+	issue, _, err := client.github.Issues.Get(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.IssueNumber,
+	)
+
+	if err != nil {
+		return nil, apiError(err, "getting issue")
+	}
+
+	return issue, nil
+}
+
+type ListIssueCommentsArgs struct {
+	IssueNumber int
+	Owner       string
+	Repo        string
+}
+
+// ListIssueComments returns the conversation comments on an issue or pull
+// request, following pagination
+func (client *Client) ListIssueComments(
+	args ListIssueCommentsArgs,
+) ([]*github.IssueComment, error) {
+	options := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var allComments []*github.IssueComment
+
+	for {
+		comments, response, err := client.github.Issues.ListComments(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.IssueNumber,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing issue comments")
+		}
+
+		allComments = append(allComments, comments...)
+
+		if response.NextPage == 0 {
+			return allComments, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+type ReactToIssueCommentArgs struct {
+	CommentID int64
+	Owner     string
+	Reaction  string
+	Repo      string
+}
+
+// ReactToIssueComment adds a reaction to an issue or PR conversation comment
+func (client *Client) ReactToIssueComment(args ReactToIssueCommentArgs) error {
+	_, _, err := client.github.Reactions.CreateIssueCommentReaction(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.CommentID,
+		args.Reaction,
+	)
+
+	if err != nil {
+		return apiError(err, "reacting to issue comment")
+	}
+
+	return nil
+}
+
+type ListIssueCommentReactionsArgs struct {
+	CommentID int64
+	Owner     string
+	Repo      string
+}
+
+// ListIssueCommentReactions lists the reactions on an issue or PR conversation comment
+func (client *Client) ListIssueCommentReactions(args ListIssueCommentReactionsArgs) ([]*github.Reaction, error) {
+	options := &github.ListOptions{PerPage: 100}
+
+	var allReactions []*github.Reaction
+
+	for {
+		reactions, response, err := client.github.Reactions.ListIssueCommentReactions(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.CommentID,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing comment reactions")
+		}
+
+		allReactions = append(allReactions, reactions...)
+
+		if response.NextPage == 0 {
+			return allReactions, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+type ListDirectoryArgs struct {
+	Owner string
+	Path  string
+	Ref   string
+	Repo  string
+}
+
+// ListDirectory returns the entries of a directory in the repository
+func (client *Client) ListDirectory(
+	args ListDirectoryArgs,
+) ([]*github.RepositoryContent, error) {
+	options := &github.RepositoryContentGetOptions{
+		Ref: args.Ref,
+	}
+
+	_, directoryContent, _, err := client.github.Repositories.GetContents(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.Path,
+		options,
+	)
+
+	if err != nil {
+		return nil, apiError(err, "listing directory")
+	}
+
+	return directoryContent, nil
+}
+
+// Merge methods accepted by MergePullRequest
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodRebase = "rebase"
+	MergeMethodSquash = "squash"
+)
+
+type MergePullRequestArgs struct {
+	CommitMessage string
+	Method        string // one of the MergeMethod constants
+	Owner         string
+	PrNumber      int
+	Repo          string
+}
+
+// MergePullRequest merges a pull request with the given merge method
+func (client *Client) MergePullRequest(args MergePullRequestArgs) error {
+	options := &github.PullRequestOptions{
+		MergeMethod: args.Method,
+	}
+
+	result, _, err := client.github.PullRequests.Merge(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+		args.CommitMessage,
+		options,
+	)
+
+	if err != nil {
+		return apiError(err, "merging PR")
+	}
+
+	if !result.GetMerged() {
+		return fmt.Errorf("merging PR: %s", result.GetMessage())
+	}
+
+	return nil
+}
+
+type CreateCommentArgs struct {
+	Comment     string
+	IssueNumber int
+	Owner       string
+	Repo        string
+}
+
+// CreateComment adds a comment to an issue or PR and returns it, so it can be edited later
+func (client *Client) CreateComment(args CreateCommentArgs) (*github.IssueComment, error) {
+	comment, _, err := client.github.Issues.CreateComment(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.IssueNumber,
+		&github.IssueComment{
+			Body: github.String(args.Comment),
+		},
+	)
+
+	if err != nil {
+		return nil, apiError(err, "creating comment")
+	}
+
+	return comment, nil
+}
+
+type EditCommentArgs struct {
+	Comment   string
+	CommentID int64
+	Owner     string
+	Repo      string
+}
+
+// EditComment replaces the body of an existing issue or PR comment
+func (client *Client) EditComment(args EditCommentArgs) error {
+	_, _, err := client.github.Issues.EditComment(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.CommentID,
+		&github.IssueComment{
+			Body: github.String(args.Comment),
+		},
+	)
+
+	if err != nil {
+		return apiError(err, "editing comment")
+	}
+
+	return nil
+}
+
+type UpdatePullRequestArgs struct {
+	Body     string
+	Owner    string
+	PrNumber int
+	Repo     string
+	Title    string
+}
+
+// UpdatePullRequest replaces the title and/or body of a pull request; empty fields are left unchanged
+func (client *Client) UpdatePullRequest(args UpdatePullRequestArgs) error {
+	update := &github.PullRequest{}
+
+	if args.Body != "" {
+		update.Body = github.String(args.Body)
+	}
+
+	if args.Title != "" {
+		update.Title = github.String(args.Title)
+	}
+
+	_, _, err := client.github.PullRequests.Edit(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+		update,
+	)
+
+	if err != nil {
+		return apiError(err, "updating PR")
 	}
 
 	return nil
@@ -0,0 +1,162 @@
+package botgithub
+
+import (
+	"github.com/google/go-github/v57/github"
+)
+
+// defaultFileMode is a regular file that isn't executable, for new files
+const defaultFileMode = "100644"
+
+// FileChange is one file addition, update, or deletion within a commit
+type FileChange struct {
+	Content   string
+	Delete    bool
+	MovedFrom string // the old path of a file being moved, whose mode it keeps
+	Path      string
+}
+
+type CommitFilesArgs struct {
+	Branch  string
+	Changes []FileChange
+	Message string
+	Owner   string
+	Repo    string
+}
+
+// CommitFiles applies several file changes to a branch as a single commit,
+// using the Git tree/commit API, and returns the new commit SHA
+func (client *Client) CommitFiles(args CommitFilesArgs) (string, error) {
+	branchRef := "refs/heads/" + args.Branch
+
+	// Get the current head of the branch
+	headRef, _, err := client.github.Git.GetRef(
+		client.context,
+		args.Owner,
+		args.Repo,
+		branchRef,
+	)
+
+	if err != nil {
+		return "", apiError(err, "getting branch %s", args.Branch)
+	}
+
+	for _, change := range args.Changes {
+		if change.Delete {
+			continue
+		}
+
+		previous := client.currentContent(args.Owner, args.Repo, headRef.Object.GetSHA(), change.Path)
+
+		if err := CheckForSecrets(change.Path, previous, change.Content); err != nil {
+			return "", err
+		}
+	}
+
+	parentCommit, _, err := client.github.Git.GetCommit(
+		client.context,
+		args.Owner,
+		args.Repo,
+		headRef.Object.GetSHA(),
+	)
+
+	if err != nil {
+		return "", apiError(err, "getting head commit")
+	}
+
+	modes, err := client.fileModes(args.Owner, args.Repo, parentCommit.Tree.GetSHA())
+	if err != nil {
+		return "", err
+	}
+
+	// Build a tree on top of the parent's tree; a nil SHA and content deletes the path
+	entries := []*github.TreeEntry{}
+
+	for _, change := range args.Changes {
+		mode, exists := modes[change.Path]
+		if !exists {
+			mode, exists = modes[change.MovedFrom]
+		}
+
+		if !exists {
+			mode = defaultFileMode
+		}
+
+		entry := &github.TreeEntry{
+			Mode: github.String(mode),
+			Path: github.String(change.Path),
+			Type: github.String("blob"),
+		}
+
+		if !change.Delete {
+			entry.Content = github.String(change.Content)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	tree, _, err := client.github.Git.CreateTree(
+		client.context,
+		args.Owner,
+		args.Repo,
+		parentCommit.Tree.GetSHA(),
+		entries,
+	)
+
+	if err != nil {
+		return "", apiError(err, "creating tree")
+	}
+
+	commit, _, err := client.github.Git.CreateCommit(
+		client.context,
+		args.Owner,
+		args.Repo,
+		&github.Commit{
+			Message: github.String(args.Message),
+			Parents: []*github.Commit{{SHA: parentCommit.SHA}},
+			Tree:    tree,
+		},
+		nil,
+	)
+
+	if err != nil {
+		return "", apiError(err, "creating commit")
+	}
+
+	// Move the branch to the new commit; not forced, so a concurrent push fails loudly
+	_, _, err = client.github.Git.UpdateRef(
+		client.context,
+		args.Owner,
+		args.Repo,
+		&github.Reference{
+			Object: &github.GitObject{SHA: commit.SHA},
+			Ref:    github.String(branchRef),
+		},
+		false,
+	)
+
+	if err != nil {
+		return "", apiError(err, "updating branch %s", args.Branch)
+	}
+
+	return commit.GetSHA(), nil
+}
+
+// fileModes maps each file in a tree to its mode, so a file that's rewritten
+// keeps e.g. its executable bit ("100755"). Files past the end of a tree too
+// large for GitHub to list in full are left out
+func (client *Client) fileModes(owner, repo, treeSHA string) (map[string]string, error) {
+	tree, _, err := client.github.Git.GetTree(client.context, owner, repo, treeSHA, true)
+	if err != nil {
+		return nil, apiError(err, "getting tree")
+	}
+
+	modes := map[string]string{}
+
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			modes[entry.GetPath()] = entry.GetMode()
+		}
+	}
+
+	return modes, nil
+}
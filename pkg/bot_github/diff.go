@@ -0,0 +1,115 @@
+package botgithub
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// ErrDiffTooLarge means GitHub won't render the diff, which happens past
+// 300 files or 20,000 lines; ListPullRequestFiles still has each file's patch
+var ErrDiffTooLarge = errors.New("diff too large for GitHub to render")
+
+type GetPullRequestDiffArgs struct {
+	Owner    string
+	PrNumber int
+	Repo     string
+}
+
+// GetPullRequestDiff returns a pull request's changes as one unified diff,
+// the way git prints them
+func (client *Client) GetPullRequestDiff(args GetPullRequestDiffArgs) (string, error) {
+	diff, response, err := client.github.PullRequests.GetRaw(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+		github.RawOptions{Type: github.Diff},
+	)
+
+	if response != nil && response.StatusCode == http.StatusNotAcceptable {
+		return "", fmt.Errorf("getting PR #%d diff: %w", args.PrNumber, ErrDiffTooLarge)
+	}
+
+	if err != nil {
+		return "", apiError(err, "getting PR diff")
+	}
+
+	return diff, nil
+}
+
+// GetCompareDiff returns the changes from base to head as one unified diff,
+// e.g. what a push added to a PR's branch
+func (client *Client) GetCompareDiff(args CompareCommitsArgs) (string, error) {
+	diff, response, err := client.github.Repositories.CompareCommitsRaw(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.Base,
+		args.Head,
+		github.RawOptions{Type: github.Diff},
+	)
+
+	if response != nil && response.StatusCode == http.StatusNotAcceptable {
+		return "", fmt.Errorf("comparing %s...%s: %w", args.Base, args.Head, ErrDiffTooLarge)
+	}
+
+	if err != nil {
+		return "", apiError(err, "comparing %s...%s", args.Base, args.Head)
+	}
+
+	return diff, nil
+}
+
+// FilePatch is one file's hunks from a unified diff, the same as the patch
+// ListPullRequestFiles gives for the file
+type FilePatch struct {
+	Patch string // empty for files without hunks, e.g. binary files and bare renames
+	Path  string // the file's path after the change, or before it for a deletion
+}
+
+// SplitDiff breaks a unified diff, e.g. from GetPullRequestDiff, into each
+// file's hunks, in the order the diff lists the files
+func SplitDiff(diff string) []FilePatch {
+	patches := []FilePatch{}
+
+	var hunks []string
+
+	finish := func() {
+		if len(patches) > 0 {
+			patches[len(patches)-1].Patch = strings.Join(hunks, "\n")
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		// hunk lines all start with " ", "+", "-", "\" or "@@", so this is
+		// always the next file's header
+		case strings.HasPrefix(line, "diff --git "):
+			finish()
+
+			hunks = nil
+
+			// "diff --git a/<path> b/<path>"; a path containing " b/" is put
+			// right by the "+++" line, which deletions don't have
+			_, path, _ := strings.Cut(line, " b/")
+			patches = append(patches, FilePatch{Path: path})
+
+		case len(patches) == 0:
+			continue
+
+		case strings.HasPrefix(line, "@@") || len(hunks) > 0:
+			hunks = append(hunks, line)
+
+		case strings.HasPrefix(line, "+++ b/"):
+			patches[len(patches)-1].Path = strings.TrimPrefix(line, "+++ b/")
+		}
+	}
+
+	finish()
+
+	return patches
+}
@@ -0,0 +1,66 @@
+package botgithub
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	"github.com/google/go-github/v57/github"
+)
+
+// apiError wraps a failed API call as a GitHubError, describing the call in
+// the operator message and how GitHub answered in the user message
+func apiError(err error, format string, args ...any) error {
+	statusCode := responseStatus(err)
+
+	return &botErrors.GitHubError{
+		Err:        fmt.Errorf(format+": %w", append(args, err)...),
+		Message:    userMessage(err, statusCode),
+		StatusCode: statusCode,
+	}
+}
+
+// responseStatus is the status code GitHub answered with, or 0 when the
+// request never got an answer
+func responseStatus(err error) int {
+	var responseErr *github.ErrorResponse
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+
+	switch {
+	case errors.As(err, &responseErr) && responseErr.Response != nil:
+		return responseErr.Response.StatusCode
+	case errors.As(err, &rateLimitErr) && rateLimitErr.Response != nil:
+		return rateLimitErr.Response.StatusCode
+	case errors.As(err, &abuseErr) && abuseErr.Response != nil:
+		return abuseErr.Response.StatusCode
+	}
+
+	return 0
+}
+
+// userMessage explains a failed call without the request details
+func userMessage(err error, statusCode int) string {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+
+	switch {
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseErr), statusCode == http.StatusTooManyRequests:
+		return "GitHub is rate limiting me right now. Please try again in a few minutes."
+	case statusCode == http.StatusUnauthorized:
+		return "GitHub didn't accept my token, so a maintainer needs to check the bot's configuration."
+	case statusCode == http.StatusForbidden:
+		return "GitHub didn't let me do that; the bot may be missing a permission on this repo."
+	case statusCode == http.StatusNotFound:
+		return "GitHub couldn't find something I needed, like a branch or file. It may have been deleted or renamed."
+	case statusCode == http.StatusConflict:
+		return "The branch changed while I was working on it. Please try again."
+	case statusCode == http.StatusUnprocessableEntity:
+		return "GitHub rejected the change as invalid."
+	case statusCode >= http.StatusInternalServerError:
+		return "GitHub is having trouble right now. Please try again in a few minutes."
+	}
+
+	return "I couldn't reach GitHub to finish this. Please try again in a few minutes."
+}
@@ -0,0 +1,149 @@
+package botgithub
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// etagTransport remembers the files and directories GitHub sent with their
+// ETags and asks for them again with If-None-Match. An unchanged one comes
+// back as a 304, which doesn't count against the rate limit, and is answered
+// from the cache; so reading the same post on every turn of an edit
+// conversation costs one request, and a changed one is still seen at once
+type etagTransport struct {
+	base       http.RoundTripper
+	entries    map[string]*list.Element
+	maxEntries int
+	mutex      sync.Mutex
+	recent     *list.List // most recently used first
+}
+
+// etagEntry is a cached answer, keyed by URL, which holds the path and ref
+type etagEntry struct {
+	body   []byte
+	etag   string
+	header http.Header
+	url    string
+}
+
+func newETagTransport(base http.RoundTripper, maxEntries int) *etagTransport {
+	return &etagTransport{
+		base:       base,
+		entries:    map[string]*list.Element{},
+		maxEntries: maxEntries,
+		recent:     list.New(),
+	}
+}
+
+func (transport *etagTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !isCacheable(request) {
+		return transport.base.RoundTrip(request)
+	}
+
+	url := request.URL.String()
+	cached := transport.get(url)
+
+	if cached != nil {
+		request = request.Clone(request.Context())
+		request.Header.Set("If-None-Match", cached.etag)
+	}
+
+	response, err := transport.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && response.StatusCode == http.StatusNotModified {
+		response.Body.Close()
+		return cached.response(request, response), nil
+	}
+
+	etag := response.Header.Get("ETag")
+	if response.StatusCode != http.StatusOK || etag == "" {
+		return response, nil
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	transport.put(&etagEntry{body: body, etag: etag, header: response.Header.Clone(), url: url})
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	return response, nil
+}
+
+// isCacheable reports whether a request reads a file or directory, the
+// reads the bot repeats; the caller's own conditional requests are left alone
+func isCacheable(request *http.Request) bool {
+	return request.Method == http.MethodGet &&
+		strings.Contains(request.URL.Path, "/contents/") &&
+		request.Header.Get("If-None-Match") == "" &&
+		request.Header.Get("Range") == ""
+}
+
+func (transport *etagTransport) get(url string) *etagEntry {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	element, isCached := transport.entries[url]
+	if !isCached {
+		return nil
+	}
+
+	transport.recent.MoveToFront(element)
+
+	return element.Value.(*etagEntry)
+}
+
+// put caches an answer, dropping the least recently used past maxEntries
+func (transport *etagTransport) put(entry *etagEntry) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if element, isCached := transport.entries[entry.url]; isCached {
+		element.Value = entry
+		transport.recent.MoveToFront(element)
+
+		return
+	}
+
+	transport.entries[entry.url] = transport.recent.PushFront(entry)
+
+	for transport.recent.Len() > transport.maxEntries {
+		oldest := transport.recent.Back()
+		transport.recent.Remove(oldest)
+		delete(transport.entries, oldest.Value.(*etagEntry).url)
+	}
+}
+
+// response answers a request from the cache, keeping the 304's rate limit
+// headers so the client's view of the limit stays current
+func (entry *etagEntry) response(request *http.Request, notModified *http.Response) *http.Response {
+	header := entry.header.Clone()
+	for _, key := range []string{"X-Ratelimit-Limit", "X-Ratelimit-Remaining", "X-Ratelimit-Reset", "X-Ratelimit-Used"} {
+		if value := notModified.Header.Get(key); value != "" {
+			header.Set(key, value)
+		}
+	}
+
+	return &http.Response{
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Header:        header,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Request:       request,
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+	}
+}
@@ -0,0 +1,61 @@
+package botgithub
+
+import (
+	"slices"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// EventNumber returns the issue or PR a webhook event is about, or 0 for
+// events that aren't about one
+func EventNumber(event any) int {
+	switch e := event.(type) {
+	case *github.IssuesEvent:
+		return e.GetIssue().GetNumber()
+	case *github.IssueCommentEvent:
+		return e.GetIssue().GetNumber()
+	case *github.PullRequestEvent:
+		return e.GetPullRequest().GetNumber()
+	case *github.PullRequestReviewCommentEvent:
+		return e.GetPullRequest().GetNumber()
+	case *github.PullRequestReviewEvent:
+		return e.GetPullRequest().GetNumber()
+	default:
+		return 0
+	}
+}
+
+// IsPullRequest reports whether an issue from an event is really a PR, and
+// is false for an event that didn't include the issue
+func IsPullRequest(issue *github.Issue) bool {
+	return issue.GetPullRequestLinks() != nil
+}
+
+// IssueLabels returns an issue's labels, or none for an event that didn't
+// include the issue
+func IssueLabels(issue *github.Issue) []*github.Label {
+	if issue == nil {
+		return nil
+	}
+
+	return issue.Labels
+}
+
+// PushChangesFile reports whether a push to a repo's default branch added,
+// changed or removed a file. Pushes of more than 20 commits list only the
+// first 20, so a change further down is missed
+func PushChangesFile(event *github.PushEvent, filePath string) bool {
+	if event.GetRef() != "refs/heads/"+event.GetRepo().GetDefaultBranch() {
+		return false
+	}
+
+	for _, commit := range event.Commits {
+		if slices.Contains(commit.Added, filePath) ||
+			slices.Contains(commit.Modified, filePath) ||
+			slices.Contains(commit.Removed, filePath) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package botgithub
+
+import (
+	"context"
+	"time"
+)
+
+// healthCheckTimeout bounds a connectivity check, so a hung API doesn't hang the caller
+const healthCheckTimeout = 5 * time.Second
+
+// RateLimit is how much of the token's core API rate limit is left
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// CheckRateLimit verifies the token against the rate limit endpoint, which
+// doesn't count against the limit itself
+func (client *Client) CheckRateLimit() (RateLimit, error) {
+	context, cancel := context.WithTimeout(client.context, healthCheckTimeout)
+	defer cancel()
+
+	limits, _, err := client.github.RateLimit.Get(context)
+	if err != nil {
+		return RateLimit{}, apiError(err, "getting rate limit")
+	}
+
+	core := limits.GetCore()
+
+	return RateLimit{
+		Limit:     core.Limit,
+		Remaining: core.Remaining,
+		Reset:     core.Reset.Time,
+	}, nil
+}
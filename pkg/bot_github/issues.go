@@ -0,0 +1,68 @@
+package botgithub
+
+import (
+	"github.com/google/go-github/v57/github"
+)
+
+type CreateIssueArgs struct {
+	Body   string
+	Labels []string
+	Owner  string
+	Repo   string
+	Title  string
+}
+
+// CreateIssue opens a new issue
+func (client *Client) CreateIssue(args CreateIssueArgs) (*github.Issue, error) {
+	issue, _, err := client.github.Issues.Create(
+		client.context,
+		args.Owner,
+		args.Repo,
+		&github.IssueRequest{
+			Body:   github.String(args.Body),
+			Labels: &args.Labels,
+			Title:  github.String(args.Title),
+		},
+	)
+
+	if err != nil {
+		return nil, apiError(err, "creating issue")
+	}
+
+	return issue, nil
+}
+
+type EditIssueArgs struct {
+	Body        string // empty leaves the body as it is
+	IssueNumber int
+	Owner       string
+	Repo        string
+	State       string // "open" or "closed"; empty leaves the state as it is
+}
+
+// EditIssue updates an issue's body and state
+func (client *Client) EditIssue(args EditIssueArgs) error {
+	request := &github.IssueRequest{}
+
+	if args.Body != "" {
+		request.Body = github.String(args.Body)
+	}
+
+	if args.State != "" {
+		request.State = github.String(args.State)
+	}
+
+	_, _, err := client.github.Issues.Edit(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.IssueNumber,
+		request,
+	)
+
+	if err != nil {
+		return apiError(err, "editing issue")
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package botgithub
+
+import (
+	"net/http"
+)
+
+type AddLabelsArgs struct {
+	IssueNumber int // an issue or pull request number
+	Labels      []string
+	Owner       string
+	Repo        string
+}
+
+// AddLabels adds labels to an issue or pull request, creating any that don't exist yet
+func (client *Client) AddLabels(args AddLabelsArgs) error {
+	_, _, err := client.github.Issues.AddLabelsToIssue(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.IssueNumber,
+		args.Labels,
+	)
+
+	if err != nil {
+		return apiError(err, "adding labels")
+	}
+
+	return nil
+}
+
+type RemoveLabelsArgs struct {
+	IssueNumber int // an issue or pull request number
+	Labels      []string
+	Owner       string
+	Repo        string
+}
+
+// RemoveLabels removes labels from an issue or pull request, ignoring labels
+// it doesn't have
+func (client *Client) RemoveLabels(args RemoveLabelsArgs) error {
+	for _, label := range args.Labels {
+		response, err := client.github.Issues.RemoveLabelForIssue(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.IssueNumber,
+			label,
+		)
+
+		isMissing := response != nil && response.StatusCode == http.StatusNotFound
+
+		if err != nil && !isMissing {
+			return apiError(err, "removing label %s", label)
+		}
+	}
+
+	return nil
+}
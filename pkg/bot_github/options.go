@@ -0,0 +1,83 @@
+package botgithub
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Option customizes a Client built by NewClient
+type Option func(options *clientOptions)
+
+type clientOptions struct {
+	baseURL     string
+	etagEntries int
+	httpClient  *http.Client
+	logger      *slog.Logger
+	retry       RetryPolicy
+	userAgent   string
+}
+
+// RetryPolicy retries requests GitHub couldn't answer, or answered with a
+// 5xx or a rate limit that resets soon. Only requests that are safe to send
+// twice are retried after a 5xx or a network error, so a POST that may have
+// gone through, like opening a PR, isn't repeated
+type RetryPolicy struct {
+	Attempts  int           // tries in all, including the first; 1 or less never retries
+	BaseDelay time.Duration // wait before the first retry, doubled after each one
+	MaxDelay  time.Duration // the longest wait; a rate limit resetting later isn't retried
+}
+
+// DefaultRetryPolicy is what the bot uses: a blip is retried for about a
+// minute before the request fails
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  4,
+	BaseDelay: 2 * time.Second,
+	MaxDelay:  30 * time.Second,
+}
+
+// WithHTTPClient sends requests with httpClient, e.g. one whose transport is
+// a Recorder or goes through a proxy; the token is added to its requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(options *clientOptions) {
+		options.httpClient = httpClient
+	}
+}
+
+// WithBaseURL sends API requests to a GitHub Enterprise Server, e.g.
+// "https://github.example.com/api/v3/", instead of api.github.com
+func WithBaseURL(baseURL string) Option {
+	return func(options *clientOptions) {
+		options.baseURL = baseURL
+	}
+}
+
+// WithETagCache keeps up to maxEntries files and directories the client has
+// read and reads them again conditionally, so one that hasn't changed doesn't
+// use up the rate limit; without it every read is a full request
+func WithETagCache(maxEntries int) Option {
+	return func(options *clientOptions) {
+		options.etagEntries = maxEntries
+	}
+}
+
+// WithUserAgent names the bot in the User-Agent header GitHub sees
+func WithUserAgent(userAgent string) Option {
+	return func(options *clientOptions) {
+		options.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy retries failed requests; without it none are
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(options *clientOptions) {
+		options.retry = policy
+	}
+}
+
+// WithLogger logs the client's retries to logger instead of slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(options *clientOptions) {
+		options.logger = logger
+	}
+}
@@ -0,0 +1,31 @@
+package botgithub
+
+import ()
+
+type GetUserPermissionArgs struct {
+	Owner    string
+	Repo     string
+	Username string
+}
+
+// GetUserPermission returns a user's permission on a repository:
+// "admin", "write", "read" or "none"
+func (client *Client) GetUserPermission(args GetUserPermissionArgs) (string, error) {
+	permissionLevel, _, err := client.github.Repositories.GetPermissionLevel(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.Username,
+	)
+
+	if err != nil {
+		return "", apiError(err, "getting permission for %s", args.Username)
+	}
+
+	return permissionLevel.GetPermission(), nil
+}
+
+// HasWriteAccess reports whether a permission from GetUserPermission allows pushing
+func HasWriteAccess(permission string) bool {
+	return permission == "admin" || permission == "write"
+}
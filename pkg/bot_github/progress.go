@@ -0,0 +1,74 @@
+package botgithub
+
+import (
+	"strings"
+)
+
+// ProgressComment is a single issue or PR comment that is edited in place as
+// long-running work progresses, instead of posting a new comment per step
+type ProgressComment struct {
+	client      GithubAPI
+	commentID   int64
+	heading     string
+	issueNumber int
+	lines       []string
+	owner       string
+	repo        string
+}
+
+type NewProgressCommentArgs struct {
+	Heading     string
+	IssueNumber int
+	Owner       string
+	Repo        string
+}
+
+// NewProgressComment prepares a progress comment; nothing is posted until the first Report
+func (client *Client) NewProgressComment(args NewProgressCommentArgs) *ProgressComment {
+	return ProgressCommentFor(client, args)
+}
+
+// ProgressCommentFor prepares a progress comment posted through any GithubAPI,
+// so other implementations, such as the test mock, share this one
+func ProgressCommentFor(client GithubAPI, args NewProgressCommentArgs) *ProgressComment {
+	return &ProgressComment{
+		client:      client,
+		heading:     args.Heading,
+		issueNumber: args.IssueNumber,
+		owner:       args.Owner,
+		repo:        args.Repo,
+	}
+}
+
+// Report appends a progress line, creating the comment on first use
+func (progress *ProgressComment) Report(line string) error {
+	progress.lines = append(progress.lines, "- "+line)
+	body := progress.heading + "\n\n" + strings.Join(progress.lines, "\n")
+
+	if progress.commentID == 0 {
+		comment, err := progress.client.CreateComment(
+			CreateCommentArgs{
+				Comment:     body,
+				IssueNumber: progress.issueNumber,
+				Owner:       progress.owner,
+				Repo:        progress.repo,
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+
+		progress.commentID = comment.GetID()
+		return nil
+	}
+
+	return progress.client.EditComment(
+		EditCommentArgs{
+			Comment:   body,
+			CommentID: progress.commentID,
+			Owner:     progress.owner,
+			Repo:      progress.repo,
+		},
+	)
+}
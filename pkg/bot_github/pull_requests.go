@@ -0,0 +1,72 @@
+package botgithub
+
+import (
+	"github.com/google/go-github/v57/github"
+)
+
+type ListOpenPullRequestsArgs struct {
+	MaxResults int // 0 fetches every page
+	Owner      string
+	Repo       string
+}
+
+// ListOpenPullRequests returns the open pull requests in the repository,
+// following pagination
+func (client *Client) ListOpenPullRequests(args ListOpenPullRequestsArgs) ([]*github.PullRequest, error) {
+	options := &github.PullRequestListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+		State:       "open",
+	}
+
+	var allPullRequests []*github.PullRequest
+
+	for {
+		pullRequests, response, err := client.github.PullRequests.List(
+			client.context,
+			args.Owner,
+			args.Repo,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing open PRs")
+		}
+
+		allPullRequests = append(allPullRequests, pullRequests...)
+
+		hasReachedLimit := args.MaxResults > 0 && len(allPullRequests) >= args.MaxResults
+
+		if hasReachedLimit {
+			return allPullRequests[:args.MaxResults], nil
+		}
+
+		if response.NextPage == 0 {
+			return allPullRequests, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+type ClosePullRequestArgs struct {
+	Owner    string
+	PrNumber int
+	Repo     string
+}
+
+// ClosePullRequest closes a pull request without merging it, returning it as closed
+func (client *Client) ClosePullRequest(args ClosePullRequestArgs) (*github.PullRequest, error) {
+	pullRequest, _, err := client.github.PullRequests.Edit(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+		&github.PullRequest{State: github.String("closed")},
+	)
+
+	if err != nil {
+		return nil, apiError(err, "closing PR")
+	}
+
+	return pullRequest, nil
+}
@@ -0,0 +1,74 @@
+package botgithub
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// markReadyMutation is the GraphQL mutation for taking a PR out of draft;
+// the REST API has no equivalent
+const markReadyMutation = `mutation($id: ID!) {
+  markPullRequestReadyForReview(input: {pullRequestId: $id}) {
+    pullRequest { isDraft }
+  }
+}`
+
+type MarkReadyForReviewArgs struct {
+	Owner    string
+	PrNumber int
+	Repo     string
+}
+
+// MarkReadyForReview takes a draft PR out of draft
+func (client *Client) MarkReadyForReview(args MarkReadyForReviewArgs) error {
+	pullRequest, _, err := client.github.PullRequests.Get(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+	)
+
+	if err != nil {
+		return apiError(err, "getting PR")
+	}
+
+	if !pullRequest.GetDraft() {
+		return nil
+	}
+
+	request, err := client.github.NewRequest(
+		http.MethodPost,
+		"graphql",
+		map[string]any{
+			"query":     markReadyMutation,
+			"variables": map[string]any{"id": pullRequest.GetNodeID()},
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("building ready-for-review request: %w", err)
+	}
+
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if _, err := client.github.Do(client.context, request, &result); err != nil {
+		return apiError(err, "marking PR #%d ready for review", args.PrNumber)
+	}
+
+	if len(result.Errors) > 0 {
+		messages := []string{}
+
+		for _, graphqlError := range result.Errors {
+			messages = append(messages, graphqlError.Message)
+		}
+
+		return fmt.Errorf("marking PR #%d ready for review: %s", args.PrNumber, strings.Join(messages, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package botgithub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+// Interaction is one recorded GitHub API call
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request replays are matched on. Headers,
+// including Authorization, are never recorded
+type RecordedRequest struct {
+	Body   string `json:"body,omitempty"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// RecordedResponse is what GitHub answered
+type RecordedResponse struct {
+	Body        string `json:"body,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Link        string `json:"link,omitempty"` // pagination
+	StatusCode  int    `json:"status_code"`
+}
+
+// Recorder is an http.RoundTripper that either calls GitHub and records each
+// call to a cassette file, or replays a cassette without touching the
+// network. Replays match on method and URL, in recorded order, and check
+// JSON request bodies so a changed request fails the test instead of
+// quietly getting an old response
+type Recorder struct {
+	cassette     string
+	interactions []Interaction
+	isRecording  bool
+	mutex        sync.Mutex
+	replayed     []bool
+	transport    http.RoundTripper
+}
+
+type NewRecorderArgs struct {
+	Cassette    string            // path to the cassette's JSON file
+	IsRecording bool              // call GitHub and overwrite the cassette
+	Transport   http.RoundTripper // used while recording; nil for http.DefaultTransport
+}
+
+// NewRecorder loads a cassette to replay, or starts an empty one to record
+func NewRecorder(args NewRecorderArgs) (*Recorder, error) {
+	recorder := &Recorder{
+		cassette:    args.Cassette,
+		isRecording: args.IsRecording,
+		transport:   args.Transport,
+	}
+
+	if recorder.transport == nil {
+		recorder.transport = http.DefaultTransport
+	}
+
+	if args.IsRecording {
+		return recorder, nil
+	}
+
+	data, err := os.ReadFile(args.Cassette)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &recorder.interactions); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", args.Cassette, err)
+	}
+
+	recorder.replayed = make([]bool, len(recorder.interactions))
+
+	return recorder, nil
+}
+
+// RoundTrip answers a request from the cassette, or records GitHub's answer
+func (recorder *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := RecordedRequest{Body: body, Method: request.Method, URL: request.URL.String()}
+
+	if recorder.isRecording {
+		return recorder.record(request, recorded)
+	}
+
+	return recorder.replay(request, recorded)
+}
+
+// record sends the request on and keeps a copy of the answer
+func (recorder *Recorder) record(request *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	response, err := recorder.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			Body:        string(body),
+			ContentType: response.Header.Get("Content-Type"),
+			Link:        response.Header.Get("Link"),
+			StatusCode:  response.StatusCode,
+		},
+	}
+
+	recorder.mutex.Lock()
+	recorder.interactions = append(recorder.interactions, interaction)
+	recorder.mutex.Unlock()
+
+	return interaction.Response.toHTTP(request), nil
+}
+
+// replay finds the first unused interaction for the request
+func (recorder *Recorder) replay(request *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	for index, interaction := range recorder.interactions {
+		if recorder.replayed[index] ||
+			interaction.Request.Method != recorded.Method ||
+			interaction.Request.URL != recorded.URL {
+			continue
+		}
+
+		if !sameBody(interaction.Request.Body, recorded.Body) {
+			return nil, fmt.Errorf(
+				"%s %s sent %s, but the cassette recorded %s",
+				recorded.Method,
+				recorded.URL,
+				recorded.Body,
+				interaction.Request.Body,
+			)
+		}
+
+		recorder.replayed[index] = true
+
+		return interaction.Response.toHTTP(request), nil
+	}
+
+	return nil, fmt.Errorf("%s %s isn't in cassette %s", recorded.Method, recorded.URL, recorder.cassette)
+}
+
+// Finish writes the cassette after recording. After a replay it fails if any
+// recorded call wasn't made, since the code under test then skipped a step
+func (recorder *Recorder) Finish() error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if recorder.isRecording {
+		var data bytes.Buffer
+
+		// unescaped, so query strings and bodies stay readable in review
+		encoder := json.NewEncoder(&data)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(recorder.interactions); err != nil {
+			return fmt.Errorf("encoding cassette: %w", err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(recorder.cassette), 0o755); err != nil {
+			return fmt.Errorf("creating cassette directory: %w", err)
+		}
+
+		return os.WriteFile(recorder.cassette, data.Bytes(), 0o644)
+	}
+
+	for index, interaction := range recorder.interactions {
+		if !recorder.replayed[index] {
+			return fmt.Errorf("%s %s was recorded but never made", interaction.Request.Method, interaction.Request.URL)
+		}
+	}
+
+	return nil
+}
+
+// toHTTP builds the response a replay or recording hands back to the client
+func (recorded RecordedResponse) toHTTP(request *http.Request) *http.Response {
+	header := http.Header{}
+
+	if recorded.ContentType != "" {
+		header.Set("Content-Type", recorded.ContentType)
+	}
+
+	if recorded.Link != "" {
+		header.Set("Link", recorded.Link)
+	}
+
+	return &http.Response{
+		Body:          io.NopCloser(bytes.NewBufferString(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Header:        header,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Request:       request,
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+	}
+}
+
+// readRequestBody reads the body and puts it back for the real transport
+func readRequestBody(request *http.Request) (string, error) {
+	if request.Body == nil {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading request: %w", err)
+	}
+
+	request.Body.Close()
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return string(body), nil
+}
+
+// sameBody compares request bodies as JSON when they are, ignoring key order
+// and whitespace
+func sameBody(recorded, sent string) bool {
+	if recorded == sent {
+		return true
+	}
+
+	var recordedValue, sentValue any
+
+	if json.Unmarshal([]byte(recorded), &recordedValue) != nil ||
+		json.Unmarshal([]byte(sent), &sentValue) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(recordedValue, sentValue)
+}
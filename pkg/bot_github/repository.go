@@ -0,0 +1,77 @@
+package botgithub
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// RepositoryNotWritableError explains why the bot can't write to a repository
+type RepositoryNotWritableError struct {
+	IsLocked bool // archived or disabled, so even comments are refused
+	Reason   string
+	Repo     string
+}
+
+func (err *RepositoryNotWritableError) Error() string {
+	return fmt.Sprintf("repository %s is not writable: %s", err.Repo, err.Reason)
+}
+
+type GetRepositoryArgs struct {
+	Owner string
+	Repo  string
+}
+
+// GetRepository retrieves repository metadata, including the token's permissions
+func (client *Client) GetRepository(args GetRepositoryArgs) (*github.Repository, error) {
+	repository, _, err := client.github.Repositories.Get(
+		client.context,
+		args.Owner,
+		args.Repo,
+	)
+
+	if err != nil {
+		return nil, apiError(err, "getting repository")
+	}
+
+	return repository, nil
+}
+
+// CheckRepositoryWritable returns a *RepositoryNotWritableError when the repo is
+// archived, disabled, or the token lacks push access
+func (client *Client) CheckRepositoryWritable(args GetRepositoryArgs) error {
+	repository, err := client.GetRepository(args)
+	if err != nil {
+		return err
+	}
+
+	fullName := repository.GetFullName()
+
+	if repository.GetArchived() {
+		return &RepositoryNotWritableError{
+			IsLocked: true,
+			Reason:   "it has been archived and is read-only",
+			Repo:     fullName,
+		}
+	}
+
+	if repository.GetDisabled() {
+		return &RepositoryNotWritableError{
+			IsLocked: true,
+			Reason:   "it has been disabled",
+			Repo:     fullName,
+		}
+	}
+
+	permissions := repository.GetPermissions()
+	canPush := permissions["push"] || permissions["admin"] || permissions["maintain"]
+
+	if permissions != nil && !canPush {
+		return &RepositoryNotWritableError{
+			Reason: "my access token doesn't have write permission",
+			Repo:   fullName,
+		}
+	}
+
+	return nil
+}
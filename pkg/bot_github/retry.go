@@ -0,0 +1,132 @@
+package botgithub
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryTransport sends requests through base, retrying the ones its policy
+// allows
+type retryTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+	policy RetryPolicy
+}
+
+func (transport *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	delay := transport.policy.BaseDelay
+
+	for attempt := 1; ; attempt++ {
+		response, err := transport.base.RoundTrip(request)
+
+		wait, shouldRetry := transport.retryAfter(request, response, err, delay)
+		if !shouldRetry || attempt >= transport.policy.Attempts {
+			return response, err
+		}
+
+		retried, rewindErr := rewind(request)
+		if rewindErr != nil {
+			return response, err
+		}
+
+		status := 0
+		if response != nil {
+			status = response.StatusCode
+			response.Body.Close()
+		}
+
+		transport.logger.Warn(
+			"Retrying GitHub request",
+			"method", request.Method,
+			"path", request.URL.Path,
+			"status", status,
+			"error", err,
+			"attempt", attempt,
+			"wait", wait,
+		)
+
+		select {
+		case <-request.Context().Done():
+			return nil, request.Context().Err()
+		case <-time.After(wait):
+		}
+
+		request = retried
+		delay = min(delay*2, transport.policy.MaxDelay)
+	}
+}
+
+// retryAfter decides whether a try is retried and how long to wait first: a
+// rate limit waits until it resets, as long as that's within MaxDelay, and
+// anything else retryable waits delay
+func (transport *retryTransport) retryAfter(request *http.Request, response *http.Response, err error, delay time.Duration) (time.Duration, bool) {
+	if err != nil {
+		return delay, isIdempotent(request.Method) && request.Context().Err() == nil
+	}
+
+	if wait, isRateLimited := rateLimitWait(response); isRateLimited {
+		return wait, wait <= transport.policy.MaxDelay
+	}
+
+	return delay, response.StatusCode >= http.StatusInternalServerError && isIdempotent(request.Method)
+}
+
+// rateLimitWait is how long a rate-limited response says to wait before
+// trying again; GitHub sends Retry-After for secondary rate limits and the
+// reset time when the hourly limit is used up
+func rateLimitWait(response *http.Response) (time.Duration, bool) {
+	if response.StatusCode != http.StatusForbidden && response.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if response.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+
+	reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Until(time.Unix(reset, 0)), 0), true
+}
+
+// isIdempotent reports whether sending a request twice does no more than
+// sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodDelete, http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut:
+		return true
+	default:
+		return false
+	}
+}
+
+// rewind returns a copy of a request that can be sent again, with a fresh
+// body for those that have one
+func rewind(request *http.Request) (*http.Request, error) {
+	retried := request.Clone(request.Context())
+
+	if request.Body == nil || request.Body == http.NoBody {
+		return retried, nil
+	}
+
+	if request.GetBody == nil {
+		return nil, http.ErrBodyNotAllowed
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retried.Body = body
+
+	return retried, nil
+}
@@ -0,0 +1,104 @@
+package botgithub
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RevertCommitArgs struct {
+	Branch string
+	Owner  string
+	Repo   string
+	Sha    string
+}
+
+// RevertCommit undoes a commit on a branch with a new commit, returning its
+// SHA. It refuses when a file the commit touched has changed since, rather
+// than overwriting later work
+func (client *Client) RevertCommit(args RevertCommitArgs) (string, error) {
+	commit, _, err := client.github.Repositories.GetCommit(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.Sha,
+		nil,
+	)
+
+	if err != nil {
+		return "", apiError(err, "getting commit %s", args.Sha)
+	}
+
+	if len(commit.Parents) != 1 {
+		return "", fmt.Errorf("commit %s has %d parents; only single-parent commits can be reverted", args.Sha, len(commit.Parents))
+	}
+
+	parentSHA := commit.Parents[0].GetSHA()
+	changes := []FileChange{}
+
+	for _, file := range commit.Files {
+		filePath := file.GetFilename()
+		movedFrom := ""
+
+		if file.GetStatus() != "removed" {
+			_, currentSHA, err := client.GetFileContent(
+				GetFileContentArgs{
+					Filename: filePath,
+					Owner:    args.Owner,
+					Ref:      args.Branch,
+					Repo:     args.Repo,
+				},
+			)
+
+			if err != nil || currentSHA != file.GetSHA() {
+				return "", fmt.Errorf("%s has changed since commit %s", filePath, shortSHA(args.Sha))
+			}
+		}
+
+		switch file.GetStatus() {
+		case "added":
+			changes = append(changes, FileChange{Delete: true, Path: filePath})
+
+		case "renamed":
+			changes = append(changes, FileChange{Delete: true, Path: filePath})
+			movedFrom, filePath = filePath, file.GetPreviousFilename()
+			fallthrough
+
+		default:
+			previousContent, _, err := client.GetFileContent(
+				GetFileContentArgs{
+					Filename: filePath,
+					Owner:    args.Owner,
+					Ref:      parentSHA,
+					Repo:     args.Repo,
+				},
+			)
+
+			if err != nil {
+				return "", err
+			}
+
+			changes = append(changes, FileChange{Content: previousContent, MovedFrom: movedFrom, Path: filePath})
+		}
+	}
+
+	subject := strings.SplitN(commit.GetCommit().GetMessage(), "\n", 2)[0]
+
+	return client.CommitFiles(
+		CommitFilesArgs{
+			Branch:  args.Branch,
+			Changes: changes,
+			Message: fmt.Sprintf("Revert %q\n\nThis reverts commit %s.", subject, args.Sha),
+			Owner:   args.Owner,
+			Repo:    args.Repo,
+		},
+	)
+}
+
+// shortSHA abbreviates a commit SHA for messages
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
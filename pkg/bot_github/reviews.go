@@ -0,0 +1,126 @@
+package botgithub
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// ReviewComment is an inline comment on one line of a PR's new version
+type ReviewComment struct {
+	Body string
+	Line int
+	Path string
+}
+
+type CreatePullRequestReviewArgs struct {
+	Body     string
+	Comments []ReviewComment
+	CommitID string // the head commit the comments' lines refer to
+	Event    string // "COMMENT", "APPROVE" or "REQUEST_CHANGES"
+	Owner    string
+	PrNumber int
+	Repo     string
+}
+
+// CreatePullRequestReview posts a review with inline comments on a PR
+func (client *Client) CreatePullRequestReview(args CreatePullRequestReviewArgs) error {
+	comments := []*github.DraftReviewComment{}
+
+	for _, comment := range args.Comments {
+		comments = append(
+			comments,
+			&github.DraftReviewComment{
+				Body: github.String(comment.Body),
+				Line: github.Int(comment.Line),
+				Path: github.String(comment.Path),
+				Side: github.String("RIGHT"),
+			},
+		)
+	}
+
+	_, _, err := client.github.PullRequests.CreateReview(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+		&github.PullRequestReviewRequest{
+			Body:     github.String(args.Body),
+			Comments: comments,
+			CommitID: github.String(args.CommitID),
+			Event:    github.String(args.Event),
+		},
+	)
+
+	if err != nil {
+		return apiError(err, "creating PR review")
+	}
+
+	return nil
+}
+
+// CommentableLines returns the new-side line numbers a patch shows, which are
+// the only lines a review comment can be attached to
+func CommentableLines(patch string) map[int]bool {
+	lines := map[int]bool{}
+	newLine := 0
+
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			// "@@ -a,b +c,d @@" starts counting new lines at c
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				continue
+			}
+
+			start := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0]
+			newLine, _ = strconv.Atoi(start)
+
+		case strings.HasPrefix(line, "-"):
+			continue
+
+		case strings.HasPrefix(line, "+") || strings.HasPrefix(line, " "):
+			lines[newLine] = true
+			newLine++
+		}
+	}
+
+	return lines
+}
+
+type ListPullRequestReviewsArgs struct {
+	Owner    string
+	PrNumber int
+	Repo     string
+}
+
+// ListPullRequestReviews returns the reviews submitted on a pull request
+func (client *Client) ListPullRequestReviews(args ListPullRequestReviewsArgs) ([]*github.PullRequestReview, error) {
+	options := &github.ListOptions{PerPage: 100}
+
+	var allReviews []*github.PullRequestReview
+
+	for {
+		reviews, response, err := client.github.PullRequests.ListReviews(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.PrNumber,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing PR reviews")
+		}
+
+		allReviews = append(allReviews, reviews...)
+
+		if response.NextPage == 0 {
+			return allReviews, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
@@ -0,0 +1,194 @@
+package botgithub
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// searchPageSize is the largest page the search API allows
+const searchPageSize = 100
+
+type IssueQueryArgs struct {
+	Author        string
+	Head          string // PR head branch
+	IsPullRequest bool
+	Labels        []string
+	Owner         string
+	Repo          string
+	State         string // "open", "closed" or empty for both
+	Text          string
+	UpdatedBefore time.Time
+}
+
+// BuildIssueQuery turns structured filters into a GitHub issue search query
+func BuildIssueQuery(args IssueQueryArgs) string {
+	qualifiers := []string{}
+
+	if args.Text != "" {
+		qualifiers = append(qualifiers, args.Text)
+	}
+
+	if args.Owner != "" && args.Repo != "" {
+		qualifiers = append(qualifiers, fmt.Sprintf("repo:%s/%s", args.Owner, args.Repo))
+	}
+
+	if args.IsPullRequest {
+		qualifiers = append(qualifiers, "is:pr")
+	} else {
+		qualifiers = append(qualifiers, "is:issue")
+	}
+
+	if args.State != "" {
+		qualifiers = append(qualifiers, "state:"+args.State)
+	}
+
+	if args.Author != "" {
+		qualifiers = append(qualifiers, "author:"+args.Author)
+	}
+
+	if args.Head != "" {
+		qualifiers = append(qualifiers, "head:"+args.Head)
+	}
+
+	for _, label := range args.Labels {
+		qualifiers = append(qualifiers, fmt.Sprintf("label:%q", label))
+	}
+
+	if !args.UpdatedBefore.IsZero() {
+		qualifiers = append(qualifiers, "updated:<"+args.UpdatedBefore.Format("2006-01-02"))
+	}
+
+	return strings.Join(qualifiers, " ")
+}
+
+type SearchIssuesArgs struct {
+	MaxResults int // 0 fetches every page
+	Order      string
+	Query      string
+	Sort       string
+}
+
+// SearchIssues runs an issue/PR search query, following pagination
+func (client *Client) SearchIssues(args SearchIssuesArgs) ([]*github.Issue, error) {
+	options := &github.SearchOptions{
+		Order:       args.Order,
+		Sort:        args.Sort,
+		ListOptions: github.ListOptions{PerPage: searchPageSize},
+	}
+
+	var issues []*github.Issue
+
+	for {
+		result, response, err := client.github.Search.Issues(
+			client.context,
+			args.Query,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "searching issues")
+		}
+
+		issues = append(issues, result.Issues...)
+
+		hasReachedLimit := args.MaxResults > 0 && len(issues) >= args.MaxResults
+
+		if hasReachedLimit {
+			return issues[:args.MaxResults], nil
+		}
+
+		if response.NextPage == 0 {
+			return issues, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+type SearchCodeArgs struct {
+	MaxResults int // 0 fetches every page
+	Owner      string
+	Query      string
+	Repo       string
+}
+
+// SearchCode searches file contents in a repository, following pagination
+func (client *Client) SearchCode(args SearchCodeArgs) ([]*github.CodeResult, error) {
+	query := args.Query
+
+	if args.Owner != "" && args.Repo != "" {
+		query = fmt.Sprintf("%s repo:%s/%s", query, args.Owner, args.Repo)
+	}
+
+	options := &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: searchPageSize},
+	}
+
+	var results []*github.CodeResult
+
+	for {
+		result, response, err := client.github.Search.Code(
+			client.context,
+			query,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "searching code")
+		}
+
+		results = append(results, result.CodeResults...)
+
+		hasReachedLimit := args.MaxResults > 0 && len(results) >= args.MaxResults
+
+		if hasReachedLimit {
+			return results[:args.MaxResults], nil
+		}
+
+		if response.NextPage == 0 {
+			return results, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+type FindPullRequestForBranchArgs struct {
+	BranchName string
+	Owner      string
+	Repo       string
+	State      string
+}
+
+// FindPullRequestForBranch returns the PR opened from a branch, or nil if there is none
+func (client *Client) FindPullRequestForBranch(
+	args FindPullRequestForBranchArgs,
+) (*github.Issue, error) {
+	issues, err := client.SearchIssues(
+		SearchIssuesArgs{
+			MaxResults: 1,
+			Query: BuildIssueQuery(
+				IssueQueryArgs{
+					Head:          args.BranchName,
+					IsPullRequest: true,
+					Owner:         args.Owner,
+					Repo:          args.Repo,
+					State:         args.State,
+				},
+			),
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	return issues[0], nil
+}
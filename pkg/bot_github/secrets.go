@@ -0,0 +1,64 @@
+package botgithub
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+// ErrSecretDetected means a write was refused because the content looks
+// like it holds a credential
+var ErrSecretDetected = errors.New("content looks like it contains a secret")
+
+// CheckForSecrets refuses content with likely credentials; every write to a
+// repo goes through it so AI output can't leak a key into a commit. Only the
+// lines the write adds to previous, the file as it is now, are checked, so a
+// documented example key already in a file doesn't block every edit to it
+func CheckForSecrets(filePath, previous, content string) error {
+	existingLines := map[string]bool{}
+	for _, line := range strings.Split(previous, "\n") {
+		existingLines[line] = true
+	}
+
+	lines := strings.Split(content, "\n")
+	described := []string{}
+
+	for _, finding := range sharedUtils.ScanForSecrets(content) {
+		if existingLines[lines[finding.Line-1]] {
+			continue
+		}
+
+		described = append(described, finding.String())
+	}
+
+	if len(described) == 0 {
+		return nil
+	}
+
+	return &botErrors.ValidationError{
+		Err:     fmt.Errorf("%w: %s (%s)", ErrSecretDetected, filePath, strings.Join(described, ", ")),
+		Message: fmt.Sprintf("`%s` looks like it contains a secret (%s).", filePath, strings.Join(described, ", ")),
+	}
+}
+
+// currentContent is a file as it is at ref, for CheckForSecrets; a file that
+// doesn't exist or can't be read is empty, so all of the new content is checked
+func (client *Client) currentContent(owner, repo, ref, filePath string) string {
+	content, _, err := client.GetFileContent(
+		GetFileContentArgs{
+			Filename: filePath,
+			Owner:    owner,
+			Ref:      ref,
+			Repo:     repo,
+		},
+	)
+
+	if err != nil {
+		return ""
+	}
+
+	return content
+}
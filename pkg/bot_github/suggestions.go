@@ -0,0 +1,74 @@
+package botgithub
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+type ReplyToReviewCommentArgs struct {
+	Comment   string
+	CommentID int64
+	Owner     string
+	PrNumber  int
+	Repo      string
+}
+
+// ReplyToReviewComment answers a review comment in its own thread
+func (client *Client) ReplyToReviewComment(args ReplyToReviewCommentArgs) error {
+	_, _, err := client.github.PullRequests.CreateCommentInReplyTo(
+		client.context,
+		args.Owner,
+		args.Repo,
+		args.PrNumber,
+		args.Comment,
+		args.CommentID,
+	)
+
+	if err != nil {
+		return apiError(err, "replying to review comment")
+	}
+
+	return nil
+}
+
+// ReviewCommentLines returns the 1-based, inclusive range of lines in the new
+// version of the file that a review comment was left on
+func ReviewCommentLines(comment *github.PullRequestComment) (int, int, error) {
+	endLine := comment.GetLine()
+
+	if endLine == 0 || comment.GetSide() == "LEFT" {
+		return 0, 0, fmt.Errorf("the comment isn't on a line of the new version of the file")
+	}
+
+	startLine := comment.GetStartLine()
+	if startLine == 0 {
+		startLine = endLine
+	}
+
+	return startLine, endLine, nil
+}
+
+// SelectLines returns lines start through end (1-based, inclusive) of content
+func SelectLines(content string, startLine, endLine int) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	if startLine < 1 || endLine > len(lines) || startLine > endLine {
+		return "", fmt.Errorf("lines %d-%d are outside the file", startLine, endLine)
+	}
+
+	return strings.Join(lines[startLine-1:endLine], "\n"), nil
+}
+
+// FormatSuggestion wraps replacement lines in a suggestion block GitHub can
+// apply with one click, using a fence longer than any backtick run inside
+func FormatSuggestion(replacement string) string {
+	fence := "```"
+
+	for strings.Contains(replacement, fence) {
+		fence += "`"
+	}
+
+	return fmt.Sprintf("%ssuggestion\n%s\n%s", fence, strings.TrimRight(replacement, "\n"), fence)
+}
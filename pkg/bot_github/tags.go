@@ -0,0 +1,76 @@
+package botgithub
+
+import (
+	"github.com/google/go-github/v57/github"
+)
+
+type ListTagsArgs struct {
+	Owner string
+	Repo  string
+}
+
+// ListTags lists a repository's tags, following pagination
+func (client *Client) ListTags(args ListTagsArgs) ([]*github.RepositoryTag, error) {
+	options := &github.ListOptions{PerPage: 100}
+
+	var tags []*github.RepositoryTag
+
+	for {
+		page, response, err := client.github.Repositories.ListTags(
+			client.context,
+			args.Owner,
+			args.Repo,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "listing tags")
+		}
+
+		tags = append(tags, page...)
+
+		if response.NextPage == 0 {
+			return tags, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+type CompareCommitsArgs struct {
+	Base  string // a tag, branch or SHA
+	Head  string
+	Owner string
+	Repo  string
+}
+
+// CompareCommits lists the commits reachable from head but not from base,
+// oldest first, following pagination
+func (client *Client) CompareCommits(args CompareCommitsArgs) ([]*github.RepositoryCommit, error) {
+	options := &github.ListOptions{PerPage: 100}
+
+	var commits []*github.RepositoryCommit
+
+	for {
+		comparison, response, err := client.github.Repositories.CompareCommits(
+			client.context,
+			args.Owner,
+			args.Repo,
+			args.Base,
+			args.Head,
+			options,
+		)
+
+		if err != nil {
+			return nil, apiError(err, "comparing %s...%s", args.Base, args.Head)
+		}
+
+		commits = append(commits, comparison.Commits...)
+
+		if response.NextPage == 0 {
+			return commits, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
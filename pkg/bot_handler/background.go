@@ -0,0 +1,33 @@
+package bothandler
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// Background runs webhook work after the webhook has been answered, since
+// writing a post or a code change can take minutes of AI calls and GitHub
+// gives up on a delivery after ten seconds. The zero value is ready to use
+type Background struct {
+	waitGroup sync.WaitGroup
+}
+
+// Go runs work on its own goroutine, logging rather than crashing the whole
+// process if it panics
+func (background *Background) Go(logger *slog.Logger, work func()) {
+	background.waitGroup.Go(func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.Error("Background work panicked", "panic", recovered, "stack", string(debug.Stack()))
+			}
+		}()
+
+		work()
+	})
+}
+
+// Wait blocks until all the work started so far has finished
+func (background *Background) Wait() {
+	background.waitGroup.Wait()
+}
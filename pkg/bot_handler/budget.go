@@ -0,0 +1,34 @@
+package bothandler
+
+import (
+	"fmt"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+)
+
+// CheckBudget returns an error wrapping botAi.ErrBudgetExhausted once this
+// month's AI spend reaches the repo's or the bot's budget
+func (common *Common) CheckBudget() error {
+	repoSpend := common.Store.MonthlySpend(common.FullRepoName())
+	totalSpend := common.Store.TotalMonthlySpend()
+
+	limit := common.Config.Budget.Exhausted(repoSpend.CostUSD, repoSpend.Tokens, totalSpend.CostUSD, totalSpend.Tokens)
+	if limit == "" {
+		return nil
+	}
+
+	return &botErrors.BudgetError{
+		Err:     fmt.Errorf("%w: this month's spend reached %s", botAi.ErrBudgetExhausted, limit),
+		Message: fmt.Sprintf("this month's AI spend reached %s", limit),
+	}
+}
+
+// BudgetReason is the limit that was reached, in the user-facing wording
+func BudgetReason(err error) string {
+	if message, hasMessage := botErrors.UserMessage(err); hasMessage {
+		return message
+	}
+
+	return err.Error()
+}
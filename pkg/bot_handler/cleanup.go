@@ -0,0 +1,64 @@
+package bothandler
+
+import (
+	"regexp"
+	"strconv"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	"github.com/google/go-github/v57/github"
+)
+
+// closesIssuePattern finds the source issue in a bot PR body ("Closes #12")
+var closesIssuePattern = regexp.MustCompile(`(?i)closes #(\d+)`)
+
+// FinishJob closes the loop on a closed PR: it marks the job done, deletes
+// the bot's branch and tells the source issue how things ended, in the words
+// outcomeComment picks
+func (common *Common) FinishJob(pullRequest *github.PullRequest, outcomeComment func(*github.PullRequest) string) {
+	branch := pullRequest.GetHead().GetRef()
+	issueNumber := SourceIssueNumber(pullRequest.GetBody())
+
+	if err := common.Store.MarkJobDone(
+		botState.MarkJobDoneArgs{
+			Branch:      branch,
+			IsMerged:    pullRequest.GetMerged(),
+			IssueNumber: issueNumber,
+			PrNumber:    pullRequest.GetNumber(),
+			Repo:        common.FullRepoName(),
+		},
+	); err != nil {
+		common.Logger.Error("Marking job done failed", "error", err)
+	}
+
+	// only the branches of PRs the bot opened are its to delete
+	if !common.Store.IsBotPullRequest(common.FullRepoName(), pullRequest.GetNumber()) {
+		return
+	}
+
+	if err := common.GithubClient.DeleteBranch(
+		botGithub.DeleteBranchArgs{
+			BranchName: branch,
+			Owner:      common.Owner,
+			Repo:       common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Deleting branch failed", "branch", branch, "error", err)
+	}
+
+	if issueNumber > 0 {
+		common.CommentOnIssue(issueNumber, outcomeComment(pullRequest))
+	}
+}
+
+// SourceIssueNumber reads the issue a bot PR was generated from, or 0
+func SourceIssueNumber(prBody string) int {
+	match := closesIssuePattern.FindStringSubmatch(prBody)
+	if match == nil {
+		return 0
+	}
+
+	issueNumber, _ := strconv.Atoi(match[1])
+
+	return issueNumber
+}
@@ -0,0 +1,207 @@
+package bothandler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botMerge "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_merge"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	botTriage "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_triage"
+	"github.com/google/go-github/v57/github"
+)
+
+// Common is what the blog and code handlers share: the checks made before
+// acting on a request, labels and replies, and the PR commands that work the
+// same on posts and code. A handler builds one from its own fields whenever
+// it needs one, so the repo's settings and the logger in use always apply
+type Common struct {
+	AiClient     botAi.AIClient
+	Config       *botConfig.RepoConfig
+	GithubClient botGithub.GithubAPI
+	Logger       *slog.Logger // defaults to slog.Default()
+	Merger       *botMerge.Merger
+	Owner        string
+	Replies      *botReplies.Renderer
+	Repo         string
+	Store        *botState.Store
+	Triager      *botTriage.Triager
+}
+
+// NewCommon creates the shared helpers for one repository
+func NewCommon(args Common) *Common {
+	logger := args.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Common{
+		AiClient:     args.AiClient,
+		Config:       args.Config,
+		GithubClient: args.GithubClient,
+		Logger:       logger,
+		Merger:       args.Merger,
+		Owner:        args.Owner,
+		Replies:      args.Replies,
+		Repo:         args.Repo,
+		Store:        args.Store,
+		Triager:      args.Triager,
+	}
+}
+
+// FullRepoName is the repository as "owner/repo", the way the store keys it
+func (common *Common) FullRepoName() string {
+	return fmt.Sprintf("%s/%s", common.Owner, common.Repo)
+}
+
+func (common *Common) CommentOnPR(prNumber int, comment string) {
+	if err := common.GithubClient.CommentOnPR(
+		botGithub.CommentOnPRArgs{
+			Comment:  comment,
+			Owner:    common.Owner,
+			PrNumber: prNumber,
+			Repo:     common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Commenting on PR failed", "error", err)
+	}
+}
+
+func (common *Common) CommentOnIssue(issueNumber int, comment string) {
+	if err := common.GithubClient.CommentOnIssue(
+		botGithub.CommentOnIssueArgs{
+			Comment:     comment,
+			IssueNumber: issueNumber,
+			Owner:       common.Owner,
+			Repo:        common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Commenting on issue failed", "error", err)
+	}
+}
+
+// IsOwnComment skips comments the bot posted itself, which would otherwise
+// retrigger the handlers and loop
+func (common *Common) IsOwnComment(user *github.User) bool {
+	isOwn := common.Config.IsBotSender(user.GetLogin())
+
+	if isOwn {
+		common.Logger.Debug("Ignoring comment from bot account", "login", user.GetLogin())
+	}
+
+	return isOwn
+}
+
+// IsAuthorized reports whether a user may trigger the bot, which requires
+// write access to the repo or a place on the allowlist, and politely says
+// so on the issue or PR when they can't
+func (common *Common) IsAuthorized(login string, issueNumber int) bool {
+	if common.Config.IsAllowedUser(login) {
+		return true
+	}
+
+	permission, err := common.GithubClient.GetUserPermission(
+		botGithub.GetUserPermissionArgs{
+			Owner:    common.Owner,
+			Repo:     common.Repo,
+			Username: login,
+		},
+	)
+
+	if err != nil {
+		common.Logger.Error("Checking permission failed", "error", err)
+	}
+
+	if botGithub.HasWriteAccess(permission) {
+		return true
+	}
+
+	common.Logger.Info("Ignoring request without write access", "login", login)
+	common.CommentOnIssue(issueNumber, common.Replies.Render("not_collaborator", botReplies.Data{"Login": login}))
+
+	return false
+}
+
+// EnsureWritable checks the repository can be written to, so a request stops
+// before its first write instead of failing on a cascade of 403s. When the
+// token can't push, the problem is explained on the issue or PR; an archived
+// or disabled repo refuses comments too, so that's only logged
+func (common *Common) EnsureWritable(issueNumber int) bool {
+	err := common.GithubClient.CheckRepositoryWritable(
+		botGithub.GetRepositoryArgs{
+			Owner: common.Owner,
+			Repo:  common.Repo,
+		},
+	)
+
+	var notWritableError *botGithub.RepositoryNotWritableError
+
+	if !errors.As(err, &notWritableError) {
+		if err != nil {
+			common.Logger.Error("Checking repository state failed", "error", err)
+		}
+
+		return true
+	}
+
+	common.Logger.Warn("Skipping request, repository isn't writable", "repo", notWritableError.Repo, "reason", notWritableError.Reason)
+
+	if !notWritableError.IsLocked {
+		common.CommentOnIssue(
+			issueNumber,
+			common.Replies.Render(
+				"not_writable",
+				botReplies.Data{"Reason": notWritableError.Reason, "Repo": notWritableError.Repo},
+			),
+		)
+	}
+
+	return false
+}
+
+// RecordBotCommit counts a bot commit on a PR and remembers it for /undo
+func (common *Common) RecordBotCommit(prNumber int, branch, sha string) {
+	if err := common.Store.RecordBotCommit(common.FullRepoName(), prNumber); err != nil {
+		common.Logger.Error("Recording PR metrics failed", "error", err)
+	}
+
+	common.RecordBranchCommit(branch, sha)
+}
+
+// RecordBranchCommit remembers a bot commit for /undo without counting it,
+// e.g. the one a PR is opened with
+func (common *Common) RecordBranchCommit(branch, sha string) {
+	if err := common.Store.RecordBranchCommit(common.FullRepoName(), branch, sha); err != nil {
+		common.Logger.Error("Recording branch commit failed", "error", err)
+	}
+}
+
+// DispatchWorkflow runs the configured workflow, e.g. a preview build, on a
+// branch the bot just pushed and links the run on the PR
+func (common *Common) DispatchWorkflow(prNumber int, branch string) {
+	workflow := common.Config.DispatchWorkflow
+	if workflow == "" {
+		return
+	}
+
+	runURL, err := common.GithubClient.DispatchWorkflow(
+		botGithub.DispatchWorkflowArgs{
+			Owner:    common.Owner,
+			Ref:      branch,
+			Repo:     common.Repo,
+			Workflow: workflow,
+		},
+	)
+
+	if err != nil {
+		common.Logger.Error("Dispatching workflow failed", "error", err)
+		common.CommentOnPR(prNumber, common.CommandFailedComment(prNumber, fmt.Sprintf("start the `%s` workflow", workflow), err))
+		return
+	}
+
+	common.CommentOnPR(prNumber, fmt.Sprintf("▶️ Started the [`%s` workflow](%s) on `%s`.", workflow, runURL, branch))
+}
@@ -0,0 +1,44 @@
+package bothandler
+
+import (
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+)
+
+// FailureFooter records a failure reported on an issue or PR and returns the
+// footer for the comment reporting it, carrying the ID to look it up by
+func (common *Common) FailureFooter(number int, err error) string {
+	category := botErrors.Category(err)
+
+	failure, recordErr := common.Store.RecordFailure(
+		botState.RecordFailureArgs{
+			Category:    category,
+			Err:         err,
+			IssueNumber: number,
+			Repo:        common.FullRepoName(),
+		},
+	)
+
+	if recordErr != nil {
+		common.Logger.Error("Saving failure failed", "failure", failure.ID, "error", recordErr)
+	}
+
+	common.Logger.Info("Recorded failure", "failure", failure.ID, "category", category, "number", number, "error", err)
+
+	return "\n\n" + common.Replies.Render("failure_footer", botReplies.Data{"Category": category, "ID": failure.ID})
+}
+
+// CommandFailedComment explains that action failed on issue or PR number,
+// saying only what's safe to show there; anything but a validation error is
+// logged and recorded, and the footer gives the ID to find it by
+func (common *Common) CommandFailedComment(number int, action string, err error) string {
+	reason, _ := botErrors.UserMessage(err)
+	comment := common.Replies.Render("command_failed", botReplies.Data{"Action": action, "Reason": reason})
+
+	if botErrors.Category(err) == "validation" {
+		return comment
+	}
+
+	return comment + common.FailureFooter(number, err)
+}
@@ -0,0 +1,34 @@
+package bothandler
+
+import (
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	"github.com/google/go-github/v57/github"
+)
+
+// TriageOrExplainFormat handles an issue that isn't a request: with triage
+// on, it's labeled and acknowledged like any other issue; otherwise the
+// author is shown the request format, the formatHelp reply, in case they
+// meant to ask for the bot
+func (common *Common) TriageOrExplainFormat(issue *github.Issue, formatHelp, reason string) {
+	if !common.Config.Triage {
+		common.ExplainFormat(issue, formatHelp, reason)
+		return
+	}
+
+	if err := common.Triager.Triage(issue); err != nil {
+		common.Logger.Error("Triaging issue failed", "error", err)
+	}
+}
+
+// ExplainFormat replies on an issue the bot won't act on with the format it
+// expects, the formatHelp reply, unless format help is turned off
+func (common *Common) ExplainFormat(issue *github.Issue, formatHelp, reason string) {
+	if !common.Config.FormatHelp {
+		return
+	}
+
+	common.CommentOnIssue(
+		issue.GetNumber(),
+		common.Replies.Render(formatHelp, botReplies.Data{"Reason": reason, "TriggerLabel": common.Config.TriggerLabel}),
+	)
+}
@@ -0,0 +1,80 @@
+package bothandler
+
+import (
+	"strings"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// AddLabels applies the named labels to an issue or PR, skipping disabled (empty) ones
+func (common *Common) AddLabels(number int, labels ...string) {
+	enabled := NonEmpty(labels)
+	if len(enabled) == 0 {
+		return
+	}
+
+	if err := common.GithubClient.AddLabels(
+		botGithub.AddLabelsArgs{
+			IssueNumber: number,
+			Labels:      enabled,
+			Owner:       common.Owner,
+			Repo:        common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Labeling failed", "number", number, "error", err)
+	}
+}
+
+// RemoveLabels takes the named labels off an issue or PR, skipping disabled (empty) ones
+func (common *Common) RemoveLabels(number int, labels ...string) {
+	enabled := NonEmpty(labels)
+	if len(enabled) == 0 {
+		return
+	}
+
+	if err := common.GithubClient.RemoveLabels(
+		botGithub.RemoveLabelsArgs{
+			IssueNumber: number,
+			Labels:      enabled,
+			Owner:       common.Owner,
+			Repo:        common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Removing labels failed", "number", number, "error", err)
+	}
+}
+
+// LabelPullRequest applies the labels every new bot PR gets
+func (common *Common) LabelPullRequest(prNumber int) {
+	labels := common.Config.Labels
+	common.AddLabels(prNumber, labels.AIGenerated, labels.Content, labels.NeedsReview)
+}
+
+// HasLabel reports whether an issue carries a label; an empty name never matches
+func HasLabel(issue *github.Issue, name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, label := range botGithub.IssueLabels(issue) {
+		if strings.EqualFold(label.GetName(), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NonEmpty drops the empty names from values, e.g. labels that are turned off
+func NonEmpty(values []string) []string {
+	kept := []string{}
+
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+
+	return kept
+}
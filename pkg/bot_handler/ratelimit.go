@@ -0,0 +1,50 @@
+package bothandler
+
+import (
+	"time"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botReplies "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_replies"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+)
+
+// rateLimitWindow is the period Config.RateLimit counts triggers over
+const rateLimitWindow = time.Hour
+
+// IsWithinRateLimit counts a generation or modification against the user's
+// hourly limit, and politely says so on the issue or PR when they're over it
+func (common *Common) IsWithinRateLimit(login string, issueNumber int) bool {
+	isAllowed, retryAt, err := common.Store.RecordTrigger(
+		botState.RecordTriggerArgs{
+			Limit:  common.Config.RateLimit,
+			Login:  login,
+			Window: rateLimitWindow,
+		},
+	)
+
+	// a state file that can't be written shouldn't lock everyone out
+	if err != nil {
+		common.Logger.Error("Recording trigger failed", "login", login, "error", err)
+		return true
+	}
+
+	if isAllowed {
+		return true
+	}
+
+	common.Logger.Info("Ignoring request over the rate limit", "login", login)
+
+	common.GithubClient.CommentOnIssue(
+		botGithub.CommentOnIssueArgs{
+			Comment: common.Replies.Render(
+				"rate_limited",
+				botReplies.Data{"Limit": common.Config.RateLimit, "Login": login, "RetryAt": retryAt.UTC().Format("15:04")},
+			),
+			IssueNumber: issueNumber,
+			Owner:       common.Owner,
+			Repo:        common.Repo,
+		},
+	)
+
+	return false
+}
@@ -0,0 +1,113 @@
+package bothandler
+
+import (
+	"fmt"
+	"strings"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+const ReadyCommand = "/ready"
+
+// HandleReadyCommand takes a draft PR out of draft on request
+func (common *Common) HandleReadyCommand(prNumber int) {
+	if err := common.GithubClient.MarkReadyForReview(
+		botGithub.MarkReadyForReviewArgs{
+			Owner:    common.Owner,
+			PrNumber: prNumber,
+			Repo:     common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Marking PR ready failed", "error", err)
+		common.CommentOnPR(prNumber, common.CommandFailedComment(prNumber, "mark this PR ready for review", err))
+	}
+}
+
+// IsMaintainerApproval reports whether a review approves a bot PR and comes
+// from someone with write access; other approvals don't count, and aren't
+// worth a reply
+func (common *Common) IsMaintainerApproval(pullRequest *github.PullRequest, review *github.PullRequestReview) bool {
+	isApproval := strings.EqualFold(review.GetState(), "approved")
+	isBotPR := common.Store.IsBotPullRequest(common.FullRepoName(), pullRequest.GetNumber())
+
+	if !isApproval || !isBotPR {
+		return false
+	}
+
+	permission, err := common.GithubClient.GetUserPermission(
+		botGithub.GetUserPermissionArgs{
+			Owner:    common.Owner,
+			Repo:     common.Repo,
+			Username: review.GetUser().GetLogin(),
+		},
+	)
+
+	if err != nil {
+		common.Logger.Error("Checking permission failed", "error", err)
+		return false
+	}
+
+	return botGithub.HasWriteAccess(permission)
+}
+
+// HandleApproval clears the needs-review label once a maintainer approves a
+// bot PR, marks the PR ready if it's still a draft and, with auto-merge on,
+// merges it
+func (common *Common) HandleApproval(pullRequest *github.PullRequest, review *github.PullRequestReview) {
+	common.RemoveLabels(pullRequest.GetNumber(), common.Config.Labels.NeedsReview)
+
+	if pullRequest.GetDraft() && !common.markApprovedReady(pullRequest.GetNumber(), review.GetUser().GetLogin()) {
+		return
+	}
+
+	common.autoMerge(pullRequest)
+}
+
+// markApprovedReady takes an approved draft out of draft, reporting whether it worked
+func (common *Common) markApprovedReady(prNumber int, approver string) bool {
+	if err := common.GithubClient.MarkReadyForReview(
+		botGithub.MarkReadyForReviewArgs{
+			Owner:    common.Owner,
+			PrNumber: prNumber,
+			Repo:     common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Marking PR ready failed", "error", err)
+		return false
+	}
+
+	common.CommentOnPR(prNumber, fmt.Sprintf("✅ Approved by @%s, so I've marked this PR ready for review.", approver))
+
+	return true
+}
+
+// MergeApprovedPRs merges approved bot PRs whose checks have passed since the
+// approval came in
+func (common *Common) MergeApprovedPRs() {
+	if common.Config.AutoMerge {
+		common.Merger.MergeReady()
+	}
+}
+
+// autoMerge merges an approved bot PR straight away when its checks have
+// already passed; pending checks leave it to MergeApprovedPRs
+func (common *Common) autoMerge(pullRequest *github.PullRequest) {
+	if !common.Config.AutoMerge {
+		return
+	}
+
+	isMerged, err := common.Merger.MergeIfReady(pullRequest)
+	if err != nil {
+		common.Logger.Error("Auto-merging PR failed", "pr", pullRequest.GetNumber(), "error", err)
+		common.CommentOnPR(pullRequest.GetNumber(), common.CommandFailedComment(pullRequest.GetNumber(), "merge this approved PR", err))
+		return
+	}
+
+	if isMerged {
+		common.CommentOnPR(
+			pullRequest.GetNumber(),
+			fmt.Sprintf("🔀 Approved with passing checks, so I've merged this PR (%s) and deleted its branch.", common.Config.MergeMethod),
+		)
+	}
+}
@@ -0,0 +1,39 @@
+package bothandler
+
+import botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+
+// RequestAttention requests reviews from the configured reviewers, or the
+// person who asked for the change, and assigns them to a new bot PR
+func (common *Common) RequestAttention(prNumber int, requester string) {
+	reviewers := common.Config.Reviewers
+
+	if len(reviewers) == 0 && requester != "" {
+		reviewers = []string{requester}
+	}
+
+	if len(reviewers) == 0 {
+		return
+	}
+
+	if err := common.GithubClient.RequestReviewers(
+		botGithub.RequestReviewersArgs{
+			Owner:     common.Owner,
+			PrNumber:  prNumber,
+			Repo:      common.Repo,
+			Reviewers: reviewers,
+		},
+	); err != nil {
+		common.Logger.Error("Requesting reviewers failed", "pr", prNumber, "error", err)
+	}
+
+	if err := common.GithubClient.AddAssignees(
+		botGithub.AddAssigneesArgs{
+			Assignees:   reviewers,
+			IssueNumber: prNumber,
+			Owner:       common.Owner,
+			Repo:        common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Assigning PR failed", "pr", prNumber, "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package bothandler
+
+import (
+	"fmt"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+const SuggestCommand = "/suggest"
+
+// HandleSuggestion replies to a review comment with a suggested change to the
+// lines it was left on, instead of committing the edit
+func (common *Common) HandleSuggestion(
+	pullRequest *github.PullRequest,
+	comment *github.PullRequestComment,
+	changeRequest string,
+) {
+	reply, err := common.buildSuggestion(comment, changeRequest)
+
+	if err != nil {
+		common.Logger.Error("Suggesting change failed", "error", err)
+		reply = common.CommandFailedComment(pullRequest.GetNumber(), "suggest a change here", err)
+	}
+
+	if err := common.GithubClient.ReplyToReviewComment(
+		botGithub.ReplyToReviewCommentArgs{
+			Comment:   reply,
+			CommentID: comment.GetID(),
+			Owner:     common.Owner,
+			PrNumber:  pullRequest.GetNumber(),
+			Repo:      common.Repo,
+		},
+	); err != nil {
+		common.Logger.Error("Replying to review comment failed", "error", err)
+	}
+}
+
+func (common *Common) buildSuggestion(
+	comment *github.PullRequestComment,
+	changeRequest string,
+) (string, error) {
+	if changeRequest == "" {
+		return "", &botErrors.ValidationError{Message: "Tell me what to change, e.g. `/suggest make this shorter`."}
+	}
+
+	startLine, endLine, err := botGithub.ReviewCommentLines(comment)
+	if err != nil {
+		return "", err
+	}
+
+	// line numbers refer to the commit the comment was left on
+	content, _, err := common.GithubClient.GetFileContent(
+		botGithub.GetFileContentArgs{
+			Filename: comment.GetPath(),
+			Owner:    common.Owner,
+			Ref:      comment.GetCommitID(),
+			Repo:     common.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("getting file content: %w", err)
+	}
+
+	lines, err := botGithub.SelectLines(content, startLine, endLine)
+	if err != nil {
+		return "", err
+	}
+
+	replacement, err := common.AiClient.SuggestChange(
+		&botAi.SuggestionRequest{
+			Change:      changeRequest,
+			FileContent: content,
+			Lines:       lines,
+			Path:        comment.GetPath(),
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("AI suggestion failed: %w", err)
+	}
+
+	return botGithub.FormatSuggestion(replacement), nil
+}
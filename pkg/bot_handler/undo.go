@@ -0,0 +1,67 @@
+package bothandler
+
+import (
+	"fmt"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+)
+
+const UndoCommand = "/undo"
+
+// HandleUndoCommand reverts the bot's most recent commit on the PR branch
+func (common *Common) HandleUndoCommand(prNumber int) {
+	revertSHA, err := common.undoLastCommit(prNumber)
+	if err != nil {
+		common.Logger.Error("Undoing last commit failed", "error", err)
+		common.CommentOnPR(prNumber, common.CommandFailedComment(prNumber, "undo that", err))
+		return
+	}
+
+	common.CommentOnPR(prNumber, fmt.Sprintf("↩️ Reverted my last change in %s.", revertSHA))
+}
+
+// undoLastCommit reverts the latest bot commit on the PR that hasn't already been
+// undone, so repeated /undo steps further back, and returns the revert's SHA
+func (common *Common) undoLastCommit(prNumber int) (string, error) {
+	pullRequest, err := common.GithubClient.GetPullRequest(
+		botGithub.GetPullRequestArgs{
+			Owner:    common.Owner,
+			PrNumber: prNumber,
+			Repo:     common.Repo,
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("getting PR: %w", err)
+	}
+
+	branch := pullRequest.GetHead().GetRef()
+
+	commitSHA, isFound := common.Store.LastBranchCommit(common.FullRepoName(), branch)
+	if !isFound {
+		return "", &botErrors.ValidationError{Message: "I haven't made any changes on this branch that can be undone."}
+	}
+
+	revertSHA, err := common.GithubClient.RevertCommit(
+		botGithub.RevertCommitArgs{
+			Branch: branch,
+			Owner:  common.Owner,
+			Repo:   common.Repo,
+			Sha:    commitSHA,
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := common.Store.ForgetBranchCommit(common.FullRepoName(), branch, commitSHA); err != nil {
+		return "", fmt.Errorf("updating undo history: %w", err)
+	}
+
+	common.RecordBotCommit(prNumber, branch, "")
+	common.DispatchWorkflow(prNumber, branch)
+
+	return revertSHA, nil
+}
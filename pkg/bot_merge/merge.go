@@ -0,0 +1,278 @@
+package botmerge
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	"github.com/google/go-github/v57/github"
+)
+
+// passingConclusions are the check run conclusions that don't block a merge
+var passingConclusions = []string{"neutral", "skipped", "success"}
+
+// Merger merges approved bot PRs once their checks pass, then deletes their branches
+type Merger struct {
+	GithubClient     botGithub.GithubAPI
+	Logger           *slog.Logger // defaults to slog.Default()
+	Method           string       // one of the botGithub.MergeMethod constants
+	Owner            string
+	Repo             string
+	RequiredWorkflow string // an Actions workflow that must have succeeded on the head commit, e.g. the self-update checks
+	Store            *botState.Store
+}
+
+// NewMerger creates a merger for one repository
+func NewMerger(args Merger) *Merger {
+	logger := args.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Merger{
+		GithubClient:     args.GithubClient,
+		Logger:           logger,
+		Method:           args.Method,
+		Owner:            args.Owner,
+		Repo:             args.Repo,
+		RequiredWorkflow: args.RequiredWorkflow,
+		Store:            args.Store,
+	}
+}
+
+// WithLogger returns a copy of the merger that logs to logger
+func (merger *Merger) WithLogger(logger *slog.Logger) *Merger {
+	scoped := *merger
+	scoped.Logger = logger
+
+	return &scoped
+}
+
+// WithRequiredWorkflow returns a copy of the merger that only merges PRs
+// whose head commit passed workflow; an empty workflow requires none
+func (merger *Merger) WithRequiredWorkflow(workflow string) *Merger {
+	scoped := *merger
+	scoped.RequiredWorkflow = workflow
+
+	return &scoped
+}
+
+// MergeIfReady merges a bot PR that a maintainer approved and whose checks
+// all passed, reporting whether it was merged
+func (merger *Merger) MergeIfReady(pullRequest *github.PullRequest) (bool, error) {
+	if !merger.isBotPR(pullRequest) || pullRequest.GetState() != "open" {
+		return false, nil
+	}
+
+	isApproved, err := merger.isApproved(pullRequest.GetNumber(), pullRequest.GetHead().GetSHA())
+	if err != nil || !isApproved {
+		return false, err
+	}
+
+	isPassing, err := merger.isPassing(pullRequest.GetHead().GetSHA())
+	if err != nil || !isPassing {
+		return false, err
+	}
+
+	hasPassedWorkflow, err := merger.hasPassedRequiredWorkflow(pullRequest.GetHead().GetSHA())
+	if err != nil || !hasPassedWorkflow {
+		return false, err
+	}
+
+	if err := merger.GithubClient.MergePullRequest(
+		botGithub.MergePullRequestArgs{
+			Method:   merger.Method,
+			Owner:    merger.Owner,
+			PrNumber: pullRequest.GetNumber(),
+			Repo:     merger.Repo,
+		},
+	); err != nil {
+		return false, err
+	}
+
+	if err := merger.GithubClient.DeleteBranch(
+		botGithub.DeleteBranchArgs{
+			BranchName: pullRequest.GetHead().GetRef(),
+			Owner:      merger.Owner,
+			Repo:       merger.Repo,
+		},
+	); err != nil {
+		merger.Logger.Error("Deleting branch failed", "branch", pullRequest.GetHead().GetRef(), "error", err)
+	}
+
+	return true, nil
+}
+
+// MergeReady merges the open bot PRs that have become ready, e.g. when CI
+// finished after the approval came in. Drafts are left alone
+func (merger *Merger) MergeReady() {
+	pullRequests, err := merger.GithubClient.ListOpenPullRequests(
+		botGithub.ListOpenPullRequestsArgs{
+			Owner: merger.Owner,
+			Repo:  merger.Repo,
+		},
+	)
+
+	if err != nil {
+		merger.Logger.Error("Listing PRs to merge failed", "error", err)
+		return
+	}
+
+	for _, pullRequest := range pullRequests {
+		if pullRequest.GetDraft() {
+			continue
+		}
+
+		if _, err := merger.MergeIfReady(pullRequest); err != nil {
+			merger.Logger.Error("Auto-merging PR failed", "pr", pullRequest.GetNumber(), "error", err)
+		}
+	}
+}
+
+// isBotPR reports whether the bot opened the PR
+func (merger *Merger) isBotPR(pullRequest *github.PullRequest) bool {
+	return merger.Store.IsBotPullRequest(fmt.Sprintf("%s/%s", merger.Owner, merger.Repo), pullRequest.GetNumber())
+}
+
+// isApproved reports whether someone with write access approved the PR's
+// head commit and no one with write access has changes requested, going by
+// each reviewer's latest review. An approval of an earlier commit doesn't
+// count, since the bot may have pushed AI-written changes after it
+func (merger *Merger) isApproved(prNumber int, headSHA string) (bool, error) {
+	reviews, err := merger.GithubClient.ListPullRequestReviews(
+		botGithub.ListPullRequestReviewsArgs{
+			Owner:    merger.Owner,
+			PrNumber: prNumber,
+			Repo:     merger.Repo,
+		},
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	// reviews come oldest first, so later ones replace earlier ones
+	latestStates := map[string]string{}
+
+	for _, review := range reviews {
+		state := strings.ToUpper(review.GetState())
+
+		if state == "APPROVED" && review.GetCommitID() != headSHA {
+			state = "STALE"
+		}
+
+		if state == "APPROVED" || state == "CHANGES_REQUESTED" || state == "DISMISSED" || state == "STALE" {
+			latestStates[review.GetUser().GetLogin()] = state
+		}
+	}
+
+	isApproved := false
+
+	for login, state := range latestStates {
+		if state == "DISMISSED" || state == "STALE" {
+			continue
+		}
+
+		permission, err := merger.GithubClient.GetUserPermission(
+			botGithub.GetUserPermissionArgs{
+				Owner:    merger.Owner,
+				Repo:     merger.Repo,
+				Username: login,
+			},
+		)
+
+		if err != nil {
+			return false, err
+		}
+
+		if !botGithub.HasWriteAccess(permission) {
+			continue
+		}
+
+		if state == "CHANGES_REQUESTED" {
+			return false, nil
+		}
+
+		isApproved = true
+	}
+
+	return isApproved, nil
+}
+
+// isPassing reports whether every check run and commit status on a commit
+// has finished without failing. A commit with none hasn't passed yet, since CI
+// takes a moment to report on a new push; MergeReady tries again later
+func (merger *Merger) isPassing(sha string) (bool, error) {
+	checkRuns, err := merger.GithubClient.ListCheckRuns(
+		botGithub.ListCheckRunsArgs{
+			Owner: merger.Owner,
+			Ref:   sha,
+			Repo:  merger.Repo,
+		},
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, checkRun := range checkRuns {
+		if checkRun.GetStatus() != "completed" || !slices.Contains(passingConclusions, checkRun.GetConclusion()) {
+			return false, nil
+		}
+	}
+
+	combinedStatus, err := merger.GithubClient.GetCombinedStatus(
+		botGithub.GetCombinedStatusArgs{
+			Owner: merger.Owner,
+			Ref:   sha,
+			Repo:  merger.Repo,
+		},
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, status := range combinedStatus.Statuses {
+		if status.GetState() != "success" {
+			return false, nil
+		}
+	}
+
+	return len(checkRuns) > 0 || len(combinedStatus.Statuses) > 0, nil
+}
+
+// hasPassedRequiredWorkflow reports whether a run of RequiredWorkflow on a
+// commit completed successfully. Its check runs alone aren't enough, since a
+// run that failed to start or hasn't started yet leaves none behind
+func (merger *Merger) hasPassedRequiredWorkflow(sha string) (bool, error) {
+	if merger.RequiredWorkflow == "" {
+		return true, nil
+	}
+
+	workflowRuns, err := merger.GithubClient.ListWorkflowRuns(
+		botGithub.ListWorkflowRunsArgs{
+			HeadSHA:  sha,
+			Owner:    merger.Owner,
+			Repo:     merger.Repo,
+			Workflow: merger.RequiredWorkflow,
+		},
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, workflowRun := range workflowRuns {
+		if workflowRun.GetStatus() == "completed" && workflowRun.GetConclusion() == "success" {
+			return true, nil
+		}
+	}
+
+	merger.Logger.Info("Not merging until the required workflow passes", "workflow", merger.RequiredWorkflow, "sha", sha)
+
+	return false, nil
+}
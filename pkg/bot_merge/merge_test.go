@@ -0,0 +1,115 @@
+package botmerge
+
+import (
+	"testing"
+
+	botGithubTest "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github/botgithubtest"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	"github.com/google/go-github/v57/github"
+)
+
+const testHeadSHA = "head-sha"
+
+// newApprovedPR sets up a bot PR whose head commit a maintainer approved and
+// whose CI passed, returning a merger for its repo
+func newApprovedPR(t *testing.T, githubClient *botGithubTest.MockClient) (*Merger, *github.PullRequest) {
+	t.Helper()
+
+	store, err := botState.NewStore("")
+	if err != nil {
+		t.Fatalf("creating store: %v", err)
+	}
+
+	pullRequest := &github.PullRequest{
+		Head:   &github.PullRequestBranch{Ref: github.String("ai-code-1"), SHA: github.String(testHeadSHA)},
+		Number: github.Int(1),
+		State:  github.String("open"),
+	}
+
+	githubClient.Branches["ai-code-1"] = map[string]string{}
+	githubClient.PullRequests = append(githubClient.PullRequests, pullRequest)
+	githubClient.Reviews[1] = []*github.PullRequestReview{
+		{CommitID: github.String(testHeadSHA), State: github.String("APPROVED"), User: &github.User{Login: github.String("maintainer")}},
+	}
+	githubClient.CheckRuns[testHeadSHA] = []*github.CheckRun{
+		{Conclusion: github.String("success"), Name: github.String("test"), Status: github.String("completed")},
+	}
+
+	if err := store.RecordPullRequestOpened(botState.RecordPullRequestOpenedArgs{Number: 1, Repo: "owner/repo"}); err != nil {
+		t.Fatalf("recording PR: %v", err)
+	}
+
+	return NewMerger(Merger{GithubClient: githubClient, Owner: "owner", Repo: "repo", Store: store}), pullRequest
+}
+
+func TestMergeIfReadyRequiredWorkflow(t *testing.T) {
+	tests := []struct {
+		name         string
+		runs         []*github.WorkflowRun
+		wantIsMerged bool
+	}{
+		{name: "no run", wantIsMerged: false},
+		{
+			name:         "run in progress",
+			runs:         []*github.WorkflowRun{{HeadSHA: github.String(testHeadSHA), Status: github.String("in_progress")}},
+			wantIsMerged: false,
+		},
+		{
+			name:         "failed run",
+			runs:         []*github.WorkflowRun{{Conclusion: github.String("failure"), HeadSHA: github.String(testHeadSHA), Status: github.String("completed")}},
+			wantIsMerged: false,
+		},
+		{
+			name:         "passed on an earlier commit",
+			runs:         []*github.WorkflowRun{{Conclusion: github.String("success"), HeadSHA: github.String("earlier-sha"), Status: github.String("completed")}},
+			wantIsMerged: false,
+		},
+		{
+			name:         "passed run",
+			runs:         []*github.WorkflowRun{{Conclusion: github.String("success"), HeadSHA: github.String(testHeadSHA), Status: github.String("completed")}},
+			wantIsMerged: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			githubClient := botGithubTest.NewMockClient(nil)
+			githubClient.WorkflowRuns["checks.yml"] = test.runs
+
+			merger, pullRequest := newApprovedPR(t, githubClient)
+
+			isMerged, err := merger.WithRequiredWorkflow("checks.yml").MergeIfReady(pullRequest)
+			if err != nil {
+				t.Fatalf("merging: %v", err)
+			}
+
+			if isMerged != test.wantIsMerged {
+				t.Errorf("got merged %t, want %t", isMerged, test.wantIsMerged)
+			}
+		})
+	}
+}
+
+func TestMergeIfReadyWaitsForChecks(t *testing.T) {
+	githubClient := botGithubTest.NewMockClient(nil)
+	merger, pullRequest := newApprovedPR(t, githubClient)
+
+	delete(githubClient.CheckRuns, testHeadSHA)
+
+	isMerged, err := merger.MergeIfReady(pullRequest)
+	if err != nil {
+		t.Fatalf("merging: %v", err)
+	}
+
+	if isMerged {
+		t.Error("merged before any checks reported")
+	}
+
+	githubClient.CheckRuns[testHeadSHA] = []*github.CheckRun{
+		{Conclusion: github.String("success"), Name: github.String("test"), Status: github.String("completed")},
+	}
+
+	if isMerged, _ := merger.MergeIfReady(pullRequest); !isMerged {
+		t.Error("not merged once the checks passed")
+	}
+}
@@ -0,0 +1,39 @@
+package botnotify
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// maxDiscordContent is the longest message Discord accepts
+const maxDiscordContent = 2000
+
+// discordNotifier posts to a Discord channel webhook
+type discordNotifier struct {
+	client     *http.Client
+	webhookURL string
+}
+
+func (notifier *discordNotifier) Notify(event Event) error {
+	content := fmt.Sprintf("**%s** · %s", event.Repo, event.Text)
+
+	// the link goes on its own line in angle brackets so Discord doesn't embed a preview
+	if event.URL != "" {
+		content += fmt.Sprintf("\n<%s>", event.URL)
+	}
+
+	if len(content) > maxDiscordContent {
+		content = content[:maxDiscordContent]
+	}
+
+	payload := map[string]any{
+		"allowed_mentions": map[string]any{"parse": []string{}},
+		"content":          content,
+	}
+
+	if err := postJSON(notifier.client, notifier.webhookURL, payload); err != nil {
+		return fmt.Errorf("posting to Discord: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package botnotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+)
+
+// Event types a repo can subscribe to
+const (
+	EventBudgetExhausted  = "budget_exhausted"
+	EventGenerationFailed = "generation_failed"
+	EventPRCreated        = "pr_created"
+	EventPublished        = "published"
+)
+
+// webhookTimeout bounds a call to a chat webhook
+const webhookTimeout = 10 * time.Second
+
+// Event is something the bot did that's worth a chat message
+type Event struct {
+	Repo string // owner/repo
+	Text string // one line, e.g. "Opened PR #12: Add blog post: Go generics"
+	Type string // one of the Event constants
+	URL  string // where to look, e.g. the PR; may be empty
+}
+
+// Notifier delivers events to one chat channel
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// Dispatcher sends the event types a repo subscribed to to each of its notifiers
+type Dispatcher struct {
+	Events    []string
+	Notifiers []Notifier
+}
+
+// NewDispatcher creates a dispatcher for the webhooks a repo configured
+func NewDispatcher(config botConfig.Notifications) *Dispatcher {
+	client := &http.Client{Timeout: webhookTimeout}
+	notifiers := []Notifier{}
+
+	if config.SlackWebhook != "" {
+		notifiers = append(notifiers, &slackNotifier{client: client, webhookURL: config.SlackWebhook})
+	}
+
+	if config.DiscordWebhook != "" {
+		notifiers = append(notifiers, &discordNotifier{client: client, webhookURL: config.DiscordWebhook})
+	}
+
+	return &Dispatcher{
+		Events:    config.Events,
+		Notifiers: notifiers,
+	}
+}
+
+// Wants reports whether an event type would be delivered anywhere, so
+// callers can skip work that only feeds a notification
+func (dispatcher *Dispatcher) Wants(eventType string) bool {
+	return len(dispatcher.Notifiers) > 0 && slices.Contains(dispatcher.Events, eventType)
+}
+
+// Send delivers an event to every notifier, if the repo subscribed to its
+// type. One notifier failing doesn't stop the others; their errors are joined
+func (dispatcher *Dispatcher) Send(event Event) error {
+	if !dispatcher.Wants(event.Type) {
+		return nil
+	}
+
+	errs := []error{}
+
+	for _, notifier := range dispatcher.Notifiers {
+		if err := notifier.Notify(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// postJSON sends a webhook payload, treating any non-2xx status as an error
+func postJSON(client *http.Client, webhookURL string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+
+	response, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", response.Status)
+	}
+
+	return nil
+}
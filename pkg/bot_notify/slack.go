@@ -0,0 +1,30 @@
+package botnotify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// slackEscaper escapes the characters Slack's mrkdwn treats as markup
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackNotifier posts to a Slack incoming webhook
+type slackNotifier struct {
+	client     *http.Client
+	webhookURL string
+}
+
+func (notifier *slackNotifier) Notify(event Event) error {
+	text := fmt.Sprintf("*%s* · %s", event.Repo, slackEscaper.Replace(event.Text))
+
+	if event.URL != "" {
+		text += fmt.Sprintf(" <%s|View>", event.URL)
+	}
+
+	if err := postJSON(notifier.client, notifier.webhookURL, map[string]any{"text": text}); err != nil {
+		return fmt.Errorf("posting to Slack: %w", err)
+	}
+
+	return nil
+}
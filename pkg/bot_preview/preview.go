@@ -0,0 +1,236 @@
+package botpreview
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	botState "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_state"
+	sharedUtils "github.com/frankmeza/frankmeza-anthropic-bot/pkg/shared_utils"
+)
+
+const ApplyCommand = "/apply"
+
+// pendingChangeLifetime is how long a preview waits for confirmation before it's dropped
+const pendingChangeLifetime = 7 * 24 * time.Hour
+
+// maxPreviewDiff keeps a preview comment under GitHub's comment size limit
+const maxPreviewDiff = 60000
+
+// Previewer posts a diff preview of an AI edit and commits it only once the
+// requester confirms with a 👍 on the preview or an /apply comment
+type Previewer struct {
+	GithubClient botGithub.GithubAPI
+	Logger       *slog.Logger // defaults to slog.Default()
+	Owner        string
+	Repo         string
+	Store        *botState.Store
+}
+
+// NewPreviewer creates a previewer for one repository
+func NewPreviewer(args Previewer) *Previewer {
+	logger := args.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Previewer{
+		GithubClient: args.GithubClient,
+		Logger:       logger,
+		Owner:        args.Owner,
+		Repo:         args.Repo,
+		Store:        args.Store,
+	}
+}
+
+// WithLogger returns a copy of the previewer that logs to logger
+func (previewer *Previewer) WithLogger(logger *slog.Logger) *Previewer {
+	scoped := *previewer
+	scoped.Logger = logger
+
+	return &scoped
+}
+
+type ProposeArgs struct {
+	Author     string
+	Branch     string
+	CommentID  int64 // the comment that asked for the change
+	Content    string
+	Message    string
+	OldContent string
+	Path       string
+	PrNumber   int
+	Sha        string
+}
+
+// Propose posts the diff preview and stores the change until it's confirmed
+func (previewer *Previewer) Propose(args ProposeArgs) error {
+	diff := sharedUtils.UnifiedDiff(args.Path, args.OldContent, args.Content)
+	if diff == "" {
+		return &botErrors.ValidationError{Message: fmt.Sprintf("The change to `%s` came back identical to the current file.", args.Path)}
+	}
+
+	preview, err := previewer.GithubClient.CreateComment(
+		botGithub.CreateCommentArgs{
+			Comment: fmt.Sprintf(
+				"👀 **Preview of the requested change to `%s`**\n\n```diff\n%s```\n\n@%s react with 👍 or reply `%s` to commit it.",
+				args.Path,
+				sharedUtils.TruncateText(diff, maxPreviewDiff),
+				args.Author,
+				ApplyCommand,
+			),
+			IssueNumber: args.PrNumber,
+			Owner:       previewer.Owner,
+			Repo:        previewer.Repo,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("posting preview: %w", err)
+	}
+
+	return previewer.Store.SavePendingChange(
+		botState.PendingChange{
+			Author:           args.Author,
+			Branch:           args.Branch,
+			CommentID:        args.CommentID,
+			Content:          args.Content,
+			CreatedAt:        time.Now(),
+			Message:          args.Message,
+			Path:             args.Path,
+			PrNumber:         args.PrNumber,
+			PreviewCommentID: preview.GetID(),
+			Repo:             previewer.fullRepoName(),
+			Sha:              args.Sha,
+		},
+	)
+}
+
+// Apply commits the author's latest pending change on a PR, for /apply
+func (previewer *Previewer) Apply(prNumber int, author string) error {
+	change, isFound := previewer.Store.LatestPendingChange(previewer.fullRepoName(), prNumber, author)
+	if !isFound {
+		return &botErrors.ValidationError{Message: fmt.Sprintf("There's no pending change from @%s on this PR.", author)}
+	}
+
+	return previewer.commit(change)
+}
+
+// ApplyApproved commits every pending change whose preview the requester
+// gave a 👍, and drops previews nobody confirmed in time. GitHub doesn't send
+// webhooks for reactions, so this runs on a schedule
+func (previewer *Previewer) ApplyApproved() {
+	for _, change := range previewer.Store.PendingChanges(previewer.fullRepoName()) {
+		if time.Since(change.CreatedAt) > pendingChangeLifetime {
+			previewer.discard(change)
+			continue
+		}
+
+		isApproved, err := previewer.isApproved(change)
+		if err != nil {
+			previewer.Logger.Error("Checking preview reactions failed", "error", err)
+			continue
+		}
+
+		if !isApproved {
+			continue
+		}
+
+		if err := previewer.commit(change); err != nil {
+			previewer.Logger.Error("Applying previewed change failed", "error", err)
+			previewer.commentOnPR(change.PrNumber, fmt.Sprintf("⚠️ I couldn't apply the previewed change to `%s`. %s", change.Path, botErrors.UserMessageOrLogs(err)))
+		}
+	}
+}
+
+// isApproved reports whether the requester reacted 👍 to the preview
+func (previewer *Previewer) isApproved(change botState.PendingChange) (bool, error) {
+	reactions, err := previewer.GithubClient.ListIssueCommentReactions(
+		botGithub.ListIssueCommentReactionsArgs{
+			CommentID: change.PreviewCommentID,
+			Owner:     previewer.Owner,
+			Repo:      previewer.Repo,
+		},
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, reaction := range reactions {
+		if reaction.GetContent() == "+1" && reaction.GetUser().GetLogin() == change.Author {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// commit writes the change to the branch; it fails if the file moved on
+// since the preview, so a stale edit never overwrites newer work
+func (previewer *Previewer) commit(change botState.PendingChange) error {
+	commitSHA, err := previewer.GithubClient.UpdateFile(
+		botGithub.UpdateFileArgs{
+			Branch:   change.Branch,
+			Content:  change.Content,
+			Filename: change.Path,
+			Message:  change.Message,
+			Owner:    previewer.Owner,
+			Repo:     previewer.Repo,
+			Sha:      change.Sha,
+		},
+	)
+
+	if err != nil {
+		previewer.discard(change)
+		return fmt.Errorf("%w (the file may have changed since the preview; please ask again)", err)
+	}
+
+	if err := previewer.Store.RecordBotCommit(previewer.fullRepoName(), change.PrNumber); err != nil {
+		previewer.Logger.Error("Recording PR metrics failed", "error", err)
+	}
+
+	if err := previewer.Store.RecordBranchCommit(previewer.fullRepoName(), change.Branch, commitSHA); err != nil {
+		previewer.Logger.Error("Recording branch commit failed", "error", err)
+	}
+
+	previewer.discard(change)
+
+	if err := previewer.GithubClient.ReactToIssueComment(
+		botGithub.ReactToIssueCommentArgs{
+			CommentID: change.PreviewCommentID,
+			Owner:     previewer.Owner,
+			Reaction:  "rocket",
+			Repo:      previewer.Repo,
+		},
+	); err != nil {
+		previewer.Logger.Error("Reacting to preview comment failed", "error", err)
+	}
+
+	return nil
+}
+
+func (previewer *Previewer) discard(change botState.PendingChange) {
+	if err := previewer.Store.DeletePendingChange(change); err != nil {
+		previewer.Logger.Error("Discarding pending change failed", "error", err)
+	}
+}
+
+func (previewer *Previewer) commentOnPR(prNumber int, comment string) {
+	if err := previewer.GithubClient.CommentOnPR(
+		botGithub.CommentOnPRArgs{
+			Comment:  comment,
+			Owner:    previewer.Owner,
+			PrNumber: prNumber,
+			Repo:     previewer.Repo,
+		},
+	); err != nil {
+		previewer.Logger.Error("Commenting on PR failed", "error", err)
+	}
+}
+
+func (previewer *Previewer) fullRepoName() string {
+	return fmt.Sprintf("%s/%s", previewer.Owner, previewer.Repo)
+}
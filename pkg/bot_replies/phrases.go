@@ -0,0 +1,118 @@
+package botreplies
+
+import (
+	"cmp"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// phrase translates English text; a pattern built from a phrase with %s in
+// it matches any text there, which is translated in turn
+type phrase struct {
+	pattern     *regexp.Regexp
+	translation string
+}
+
+// catalog is one language's phrases, exact ones by their English wording
+type catalog struct {
+	exact    map[string]string
+	patterns []phrase
+}
+
+// mustParsePhrases parses each language's phrases.yaml, keyed by language
+func mustParsePhrases() map[string]catalog {
+	parsed := map[string]catalog{}
+
+	files, err := fs.Glob(defaultFiles, "templates/*/phrases.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, file := range files {
+		source, err := defaultFiles.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+
+		translations := map[string]string{}
+		if err := yaml.Unmarshal(source, &translations); err != nil {
+			panic(err)
+		}
+
+		parsed[path.Base(path.Dir(file))] = newCatalog(translations)
+	}
+
+	return parsed
+}
+
+// newCatalog splits translations into exact phrases and patterns, trying
+// the longest patterns first so "this repo's %s-token monthly budget" wins
+// over a shorter one that also matches
+func newCatalog(translations map[string]string) catalog {
+	built := catalog{exact: map[string]string{}}
+
+	for english, translation := range translations {
+		if !strings.Contains(english, "%s") {
+			built.exact[english] = translation
+			continue
+		}
+
+		quoted := strings.Split(english, "%s")
+		for index := range quoted {
+			quoted[index] = regexp.QuoteMeta(quoted[index])
+		}
+
+		built.patterns = append(
+			built.patterns,
+			phrase{
+				pattern:     regexp.MustCompile("^" + strings.Join(quoted, "(.+?)") + "$"),
+				translation: translation,
+			},
+		)
+	}
+
+	slices.SortFunc(built.patterns, func(a, b phrase) int {
+		return cmp.Or(
+			cmp.Compare(len(b.pattern.String()), len(a.pattern.String())),
+			strings.Compare(a.pattern.String(), b.pattern.String()),
+		)
+	})
+
+	return built
+}
+
+// translate looks text up in a language's phrases, trying its base language
+// after a regional one; text without a translation is returned as it is
+func translate(language, text string) string {
+	for _, candidate := range fallbackLanguages(language) {
+		phrases, hasPhrases := phrases[candidate]
+		if !hasPhrases {
+			continue
+		}
+
+		if translation, isKnown := phrases.exact[text]; isKnown {
+			return translation
+		}
+
+		for _, phrase := range phrases.patterns {
+			matches := phrase.pattern.FindStringSubmatch(text)
+			if matches == nil {
+				continue
+			}
+
+			translation := phrase.translation
+			for _, match := range matches[1:] {
+				translation = strings.Replace(translation, "%s", translate(language, match), 1)
+			}
+
+			return translation
+		}
+	}
+
+	return text
+}
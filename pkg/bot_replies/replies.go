@@ -0,0 +1,435 @@
+package botreplies
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	botErrors "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_errors"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+)
+
+// RepoDir is where a target repo keeps its overrides, one <name>.tmpl per
+// reply, or <name>.<language>.tmpl for a reply in one language
+const RepoDir = ".anthropic-bot/templates"
+
+// DefaultLanguage is what replies are written in when no language is asked
+// for, and what a language without a reply of its own falls back to
+const DefaultLanguage = "en"
+
+// repoRefreshInterval is how long overrides read from the target repo are
+// used before they're read again, so edits to them apply without a restart
+const repoRefreshInterval = 5 * time.Minute
+
+// defaultFiles are the bot's own replies, a directory per language with one
+// text/template per file; the comment at the top of each lists the fields
+// it's given. Besides DefaultLanguage's, a directory has a phrases.yaml
+// translating the English reasons and actions replies are given
+//
+//go:embed templates
+var defaultFiles embed.FS
+
+// languagePattern matches a language code like "es", "pt-br" or "zh-hant"
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+var (
+	defaults = mustParseDefaults()
+	phrases  = mustParsePhrases()
+)
+
+// Data is what a reply is rendered with. A template that uses a field the
+// reply doesn't have fails, and the default is used instead
+type Data map[string]any
+
+// Renderer renders the replies the bot comments, using a repo's overrides
+// where it has them and the defaults otherwise
+type Renderer struct {
+	Dir          string              // overrides on the bot's host; empty for none
+	GithubClient botGithub.GithubAPI // reads RepoDir overrides from the target repo; nil for none
+	Language     string              // language code to reply in, e.g. "es"; empty for DefaultLanguage
+	Logger       *slog.Logger        // defaults to slog.Default()
+	Owner        string
+	Repo         string
+
+	dirOverrides map[string]*template.Template
+	repoCache    *repoOverrides
+}
+
+// repoOverrides caches the target repo's overrides; it's shared by the
+// copies WithLogger and In make
+type repoOverrides struct {
+	loadedAt  time.Time
+	mutex     sync.Mutex
+	templates map[string]*template.Template
+}
+
+// NewRenderer creates a renderer for one repository, reading the overrides
+// in Dir straight away. An override that doesn't parse, or that's named for
+// no reply, is logged and left out
+func NewRenderer(args Renderer) *Renderer {
+	logger := args.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	renderer := &Renderer{
+		Dir:          args.Dir,
+		GithubClient: args.GithubClient,
+		Language:     normalizeLanguage(args.Language),
+		Logger:       logger,
+		Owner:        args.Owner,
+		Repo:         args.Repo,
+		dirOverrides: map[string]*template.Template{},
+		repoCache:    &repoOverrides{},
+	}
+
+	if args.Dir != "" {
+		sources, err := ReadDir(args.Dir)
+		if err != nil {
+			logger.Error("Reading reply templates failed", "dir", args.Dir, "error", err)
+		}
+
+		renderer.dirOverrides = renderer.parseOverrides(sources, args.Dir)
+	}
+
+	return renderer
+}
+
+// WithLogger returns a copy of the renderer that logs to logger
+func (renderer *Renderer) WithLogger(logger *slog.Logger) *Renderer {
+	scoped := *renderer
+	scoped.Logger = logger
+
+	return &scoped
+}
+
+// In returns a copy of the renderer that replies in language, e.g. a post's;
+// an empty or malformed language keeps the renderer's own
+func (renderer *Renderer) In(language string) *Renderer {
+	language = normalizeLanguage(language)
+	if language == "" {
+		return renderer
+	}
+
+	scoped := *renderer
+	scoped.Language = language
+
+	return &scoped
+}
+
+// Render renders a reply from the repo's override, the host's override or
+// the default, in that order, falling back to the next when one fails. In
+// each, a reply in the renderer's language is preferred to one for any
+// language, and a default in the language to DefaultLanguage's
+func (renderer *Renderer) Render(name string, data Data) string {
+	languages := fallbackLanguages(renderer.Language)
+
+	overrides := []*template.Template{}
+	for _, from := range []map[string]*template.Template{renderer.repoOverrides(), renderer.dirOverrides} {
+		for _, language := range languages {
+			overrides = append(overrides, from[name+"."+language])
+		}
+
+		overrides = append(overrides, from[name])
+	}
+
+	for _, override := range overrides {
+		if override == nil {
+			continue
+		}
+
+		rendered, err := execute(override, renderer.Language, data)
+		if err == nil {
+			return rendered
+		}
+
+		renderer.Logger.Warn("Rendering reply template override failed, using the default", "template", name, "error", err)
+	}
+
+	for _, language := range append(languages, DefaultLanguage) {
+		tmpl, isDefault := defaults[language][name]
+		if !isDefault {
+			continue
+		}
+
+		rendered, err := execute(tmpl, language, data)
+		if err == nil {
+			return rendered
+		}
+
+		// a broken default is a bug; the tests catch it before it ships
+		renderer.Logger.Error("Rendering reply template failed", "template", name, "language", language, "error", err)
+	}
+
+	return ""
+}
+
+// Names lists the replies that can be overridden
+func Names() []string {
+	return slices.Sorted(maps.Keys(defaults[DefaultLanguage]))
+}
+
+// Languages lists the languages the bot has replies in
+func Languages() []string {
+	return slices.Sorted(maps.Keys(defaults))
+}
+
+// Check parses override sources keyed by reply name, e.g. a RepoDir or
+// REPLIES_DIR's files, returning every problem found
+func Check(sources map[string]string) error {
+	problems := []error{}
+
+	for _, name := range slices.Sorted(maps.Keys(sources)) {
+		if err := checkName(name); err != nil {
+			problems = append(problems, fmt.Errorf("%s.tmpl: %w", name, err))
+			continue
+		}
+
+		if _, err := parse(name, sources[name]); err != nil {
+			problems = append(problems, fmt.Errorf("%s.tmpl: %w", name, err))
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// CheckLanguage checks the bot has replies in a language, or in its base
+// language for a regional one, rather than falling back to DefaultLanguage's
+func CheckLanguage(language string) error {
+	normalized := normalizeLanguage(language)
+	if normalized == "" {
+		return fmt.Errorf("%q isn't a language code", language)
+	}
+
+	for _, candidate := range fallbackLanguages(normalized) {
+		if _, hasReplies := defaults[candidate]; hasReplies {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("there are no replies in %q, only in %s", language, strings.Join(Languages(), ", "))
+}
+
+// checkName checks an override is named for a reply, and for a language
+// code when it's for one language
+func checkName(name string) error {
+	reply, language, isForLanguage := strings.Cut(name, ".")
+
+	if _, isReply := defaults[DefaultLanguage][reply]; !isReply {
+		return fmt.Errorf("there's no %q reply to override", reply)
+	}
+
+	if isForLanguage && !languagePattern.MatchString(language) {
+		return fmt.Errorf("%q isn't a language code", language)
+	}
+
+	return nil
+}
+
+// repoOverrides returns the target repo's overrides, reading RepoDir again
+// once repoRefreshInterval has passed
+func (renderer *Renderer) repoOverrides() map[string]*template.Template {
+	if renderer.GithubClient == nil {
+		return nil
+	}
+
+	overrides := renderer.repoCache
+
+	overrides.mutex.Lock()
+	defer overrides.mutex.Unlock()
+
+	if time.Since(overrides.loadedAt) >= repoRefreshInterval {
+		overrides.templates = renderer.parseOverrides(renderer.readRepo(), RepoDir)
+		overrides.loadedAt = time.Now()
+	}
+
+	return overrides.templates
+}
+
+// readRepo reads the <name>.tmpl files in the target repo's RepoDir on its
+// default branch; a repo without the directory has no overrides
+func (renderer *Renderer) readRepo() map[string]string {
+	sources := map[string]string{}
+
+	entries, err := renderer.GithubClient.ListDirectory(
+		botGithub.ListDirectoryArgs{
+			Owner: renderer.Owner,
+			Path:  RepoDir,
+			Repo:  renderer.Repo,
+		},
+	)
+
+	var githubErr *botErrors.GitHubError
+	if errors.As(err, &githubErr) && githubErr.StatusCode == http.StatusNotFound {
+		return sources
+	}
+
+	if err != nil {
+		renderer.Logger.Warn("Listing reply templates in the repo failed", "error", err)
+		return sources
+	}
+
+	for _, entry := range entries {
+		name, isTemplate := strings.CutSuffix(entry.GetName(), ".tmpl")
+		if entry.GetType() != "file" || !isTemplate {
+			continue
+		}
+
+		content, _, err := renderer.GithubClient.GetFileContent(
+			botGithub.GetFileContentArgs{
+				Filename: path.Join(RepoDir, entry.GetName()),
+				Owner:    renderer.Owner,
+				Repo:     renderer.Repo,
+			},
+		)
+
+		if err != nil {
+			renderer.Logger.Warn("Reading reply template from the repo failed", "template", name, "error", err)
+			continue
+		}
+
+		sources[name] = content
+	}
+
+	return sources
+}
+
+// parseOverrides parses the override sources that are valid, logging the rest
+func (renderer *Renderer) parseOverrides(sources map[string]string, from string) map[string]*template.Template {
+	parsed := map[string]*template.Template{}
+
+	for name, source := range sources {
+		if err := checkName(name); err != nil {
+			renderer.Logger.Warn("Ignoring reply template for an unknown reply", "template", name, "from", from, "error", err)
+			continue
+		}
+
+		tmpl, err := parse(name, source)
+		if err != nil {
+			renderer.Logger.Warn("Ignoring reply template that doesn't parse", "template", name, "from", from, "error", err)
+			continue
+		}
+
+		parsed[name] = tmpl
+	}
+
+	return parsed
+}
+
+// ReadDir reads the <name>.tmpl overrides in a directory on the bot's host
+func ReadDir(dir string) (map[string]string, error) {
+	sources := map[string]string{}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", dir, err)
+	}
+
+	for _, match := range matches {
+		content, err := os.ReadFile(match)
+		if err != nil {
+			return nil, fmt.Errorf("reading reply template: %w", err)
+		}
+
+		sources[strings.TrimSuffix(filepath.Base(match), ".tmpl")] = string(content)
+	}
+
+	return sources, nil
+}
+
+// mustParseDefaults parses the embedded templates, keyed by language and
+// then file name
+func mustParseDefaults() map[string]map[string]*template.Template {
+	parsed := map[string]map[string]*template.Template{}
+
+	files, err := fs.Glob(defaultFiles, "templates/*/*.tmpl")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, file := range files {
+		source, err := defaultFiles.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+
+		language := path.Base(path.Dir(file))
+		if parsed[language] == nil {
+			parsed[language] = map[string]*template.Template{}
+		}
+
+		name := strings.TrimSuffix(path.Base(file), ".tmpl")
+		parsed[language][name] = template.Must(parse(name, string(source)))
+	}
+
+	return parsed
+}
+
+// parse parses one reply, with the functions every reply may use; t is
+// bound to the reply's language when it's executed
+func parse(name, source string) (*template.Template, error) {
+	return template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"join": strings.Join, "t": func(text string) string { return text }}).
+		Parse(source)
+}
+
+// execute renders a reply in a language without the trailing newline its
+// file ends with
+func execute(tmpl *template.Template, language string, data Data) (string, error) {
+	// cloned so renders in different languages don't share t
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	tmpl.Funcs(template.FuncMap{"t": func(text string) string { return translate(language, text) }})
+
+	var rendered bytes.Buffer
+
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(rendered.String(), "\n"), nil
+}
+
+// normalizeLanguage lowercases a language code, returning "" when it isn't one
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+
+	if !languagePattern.MatchString(language) {
+		return ""
+	}
+
+	return language
+}
+
+// fallbackLanguages is a language code followed by its base language, e.g.
+// "pt-br" and then "pt", or DefaultLanguage for no language
+func fallbackLanguages(language string) []string {
+	if language == "" {
+		return []string{DefaultLanguage}
+	}
+
+	base, _, hasRegion := strings.Cut(language, "-")
+	if !hasRegion {
+		return []string{language}
+	}
+
+	return []string{language, base}
+}
@@ -0,0 +1,229 @@
+package botreplies
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	botGithubTest "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github/botgithubtest"
+)
+
+// writeOverrides writes <name>.tmpl files to a temporary directory
+func writeOverrides(t *testing.T, sources map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, source := range sources {
+		if err := os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte(source), 0o644); err != nil {
+			t.Fatalf("writing override: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestRenderPrecedence(t *testing.T) {
+	data := Data{"Login": "octocat"}
+
+	dir := writeOverrides(t, map[string]string{"not_collaborator": "Host says hi to @{{.Login}}.\n"})
+	githubClient := botGithubTest.NewMockClient(
+		map[string]string{RepoDir + "/not_collaborator.tmpl": "Repo says hi to @{{.Login}}.\n"},
+	)
+
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		want     string
+	}{
+		{
+			name:     "default",
+			renderer: NewRenderer(Renderer{}),
+			want:     "Thanks @octocat! I only act on requests from collaborators with write access",
+		},
+		{
+			name:     "host override",
+			renderer: NewRenderer(Renderer{Dir: dir}),
+			want:     "Host says hi to @octocat.",
+		},
+		{
+			name:     "repo override wins",
+			renderer: NewRenderer(Renderer{Dir: dir, GithubClient: githubClient, Owner: "owner", Repo: "repo"}),
+			want:     "Repo says hi to @octocat.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.renderer.Render("not_collaborator", data)
+
+			if !strings.HasPrefix(got, test.want) || strings.HasSuffix(got, "\n") {
+				t.Errorf("got %q, want it to start with %q and have no trailing newline", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBrokenOverridesFallBack(t *testing.T) {
+	dir := writeOverrides(
+		t,
+		map[string]string{
+			"change_failed":    "{{.Reason",                 // doesn't parse
+			"not_collaborator": "Hi {{.Nickname}}!",         // names a field the reply doesn't have
+			"no_such_reply":    "Nothing renders this one.", // overrides nothing
+		},
+	)
+
+	renderer := NewRenderer(Renderer{Dir: dir})
+
+	if got := renderer.Render("not_collaborator", Data{"Login": "octocat"}); !strings.HasPrefix(got, "Thanks @octocat!") {
+		t.Errorf("got %q, want the default", got)
+	}
+
+	if got := renderer.Render("change_failed", Data{"Reason": ""}); got != "Sorry, I had trouble making that change. Could you be more specific?" {
+		t.Errorf("got %q, want the default", got)
+	}
+
+	sources, err := ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading overrides: %v", err)
+	}
+
+	err = Check(sources)
+	if err == nil {
+		t.Fatal("checking broken overrides succeeded, want errors")
+	}
+
+	for _, want := range []string{"change_failed.tmpl", "no_such_reply.tmpl"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got %q, want it to mention %s", err, want)
+		}
+	}
+}
+
+func TestFormatHelpTriggerLabel(t *testing.T) {
+	renderer := NewRenderer(Renderer{})
+
+	without := renderer.Render("code_format_help", Data{"Reason": "the title doesn't start with \"Code:\"", "TriggerLabel": ""})
+	if strings.Contains(without, "label") || !strings.HasSuffix(without, "```") {
+		t.Errorf("got %q, want it to end with the format and no label hint", without)
+	}
+
+	with := renderer.Render("code_format_help", Data{"Reason": "no body", "TriggerLabel": "ai-code"})
+	if !strings.HasSuffix(with, "```\n\nAlternatively, add the `ai-code` label to an issue describing the change.") {
+		t.Errorf("got %q, want the label hint after the format", with)
+	}
+}
+
+func TestRenderInLanguage(t *testing.T) {
+	spanish := NewRenderer(Renderer{Language: "es"})
+
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		reply    string
+		data     Data
+		want     string
+	}{
+		{
+			name:     "reasons are translated",
+			renderer: spanish,
+			reply:    "code_budget_exhausted",
+			data:     Data{"Reason": "this month's AI spend reached this repo's $5.00 monthly budget"},
+			want:     "💸 Ahora mismo no puedo hacer este cambio: el gasto en IA de este mes ha alcanzado el presupuesto mensual de $5.00 de este repositorio.",
+		},
+		{
+			name:     "text without a translation is kept",
+			renderer: spanish,
+			reply:    "command_failed",
+			data:     Data{"Action": "undo that", "Reason": "The branch is gone."},
+			want:     "Lo siento, no he podido deshacer eso. The branch is gone.",
+		},
+		{
+			name:     "a regional language uses its base language",
+			renderer: NewRenderer(Renderer{}).In("es-MX"),
+			reply:    "not_collaborator",
+			data:     Data{"Login": "octocat"},
+			want:     "¡Gracias, @octocat!",
+		},
+		{
+			name:     "a language without replies uses English",
+			renderer: NewRenderer(Renderer{}).In("fr"),
+			reply:    "not_collaborator",
+			data:     Data{"Login": "octocat"},
+			want:     "Thanks @octocat!",
+		},
+		{
+			name:     "no language keeps the repo's",
+			renderer: spanish.In(""),
+			reply:    "not_collaborator",
+			data:     Data{"Login": "octocat"},
+			want:     "¡Gracias, @octocat!",
+		},
+		{
+			name: "an override for the language wins over one for any language",
+			renderer: NewRenderer(
+				Renderer{
+					Dir: writeOverrides(
+						t,
+						map[string]string{
+							"not_collaborator":    "Hi @{{.Login}}.",
+							"not_collaborator.es": "Hola @{{.Login}}.",
+						},
+					),
+					Language: "es",
+				},
+			),
+			reply: "not_collaborator",
+			data:  Data{"Login": "octocat"},
+			want:  "Hola @octocat.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.renderer.Render(test.reply, test.data); !strings.HasPrefix(got, test.want) {
+				t.Errorf("got %q, want it to start with %q", got, test.want)
+			}
+		})
+	}
+}
+
+// fieldPattern finds the fields a reply lists in its header comment
+var fieldPattern = regexp.MustCompile(`\.([A-Z][A-Za-z]*)`)
+
+func TestEveryLanguageRendersEveryReply(t *testing.T) {
+	for _, name := range Names() {
+		source, err := defaultFiles.ReadFile("templates/" + DefaultLanguage + "/" + name + ".tmpl")
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+
+		header, _, _ := strings.Cut(string(source), "\n")
+
+		data := Data{}
+		for _, field := range fieldPattern.FindAllStringSubmatch(header, -1) {
+			data[field[1]] = "x"
+		}
+
+		for _, list := range []string{"Files", "Tags"} {
+			if _, isField := data[list]; isField {
+				data[list] = []string{"x"}
+			}
+		}
+
+		for _, language := range Languages() {
+			tmpl, isDefault := defaults[language][name]
+			if !isDefault {
+				t.Errorf("%s has no %s reply", language, name)
+				continue
+			}
+
+			if _, err := execute(tmpl, language, data); err != nil {
+				t.Errorf("rendering %s in %s with the fields %s lists: %v", name, language, DefaultLanguage, err)
+			}
+		}
+	}
+}
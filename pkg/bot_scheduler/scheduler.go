@@ -0,0 +1,72 @@
+package botscheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a task the scheduler runs on a fixed interval
+type Job struct {
+	Interval time.Duration
+	Name     string
+	Run      func()
+}
+
+// Scheduler runs registered jobs in the background until its context ends
+type Scheduler struct {
+	jobs []Job
+}
+
+// NewScheduler creates a scheduler with no jobs
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job; jobs must be added before Start
+func (scheduler *Scheduler) Add(job Job) {
+	scheduler.jobs = append(scheduler.jobs, job)
+}
+
+// Start runs every job on its own ticker and blocks until ctx is cancelled
+func (scheduler *Scheduler) Start(ctx context.Context) {
+	var waitGroup sync.WaitGroup
+
+	for _, job := range scheduler.jobs {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+			runJob(ctx, job)
+		}()
+	}
+
+	waitGroup.Wait()
+}
+
+// runJob ticks a single job, keeping one failing run from stopping the others
+func runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	slog.Info("Scheduled job", "job", job.Name, "interval", job.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						slog.Error("Scheduled job panicked", "job", job.Name, "panic", recovered)
+					}
+				}()
+
+				job.Run()
+			}()
+		}
+	}
+}
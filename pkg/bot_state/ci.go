@@ -0,0 +1,51 @@
+package botstate
+
+import (
+	"sort"
+	"time"
+)
+
+// CIWatch is a bot commit whose CI result hasn't been reported yet
+type CIWatch struct {
+	Branch       string    `json:"branch"`
+	CommentID    int64     `json:"comment_id,omitempty"` // the review comment that asked for the change; 0 for new PRs
+	IsFixAttempt bool      `json:"is_fix_attempt"`       // the commit is the bot's attempt to fix a failed run
+	PrNumber     int       `json:"pr_number"`
+	Repo         string    `json:"repo"`
+	SHA          string    `json:"sha"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// WatchCI starts waiting for CI on a PR's latest bot commit, replacing any
+// earlier watch on the PR
+func (store *Store) WatchCI(watch CIWatch) error {
+	return store.update(func(data *storeData) {
+		data.CIWatches[pullRequestKey(watch.Repo, watch.PrNumber)] = &watch
+	})
+}
+
+// StopWatchingCI drops the watch on a PR
+func (store *Store) StopWatchingCI(repo string, prNumber int) error {
+	return store.update(func(data *storeData) {
+		delete(data.CIWatches, pullRequestKey(repo, prNumber))
+	})
+}
+
+// CIWatches returns the commits in repo still waiting for CI, oldest first
+func (store *Store) CIWatches(repo string) []CIWatch {
+	watches := []CIWatch{}
+
+	store.read(func(data *storeData) {
+		for _, watch := range data.CIWatches {
+			if watch.Repo == repo {
+				watches = append(watches, *watch)
+			}
+		}
+	})
+
+	sort.Slice(watches, func(i, j int) bool {
+		return watches[i].StartedAt.Before(watches[j].StartedAt)
+	})
+
+	return watches
+}
@@ -0,0 +1,60 @@
+package botstate
+
+import (
+	"fmt"
+)
+
+// branchKey identifies a branch across repositories
+func branchKey(repo, branch string) string {
+	return fmt.Sprintf("%s@%s", repo, branch)
+}
+
+// RecordBranchCommit remembers a commit the bot pushed to a branch, so it can be undone
+func (store *Store) RecordBranchCommit(repo, branch, sha string) error {
+	if sha == "" {
+		return nil
+	}
+
+	return store.update(func(data *storeData) {
+		key := branchKey(repo, branch)
+		data.BranchCommits[key] = append(data.BranchCommits[key], sha)
+	})
+}
+
+// LastBranchCommit returns the most recent bot commit on a branch that hasn't been undone
+func (store *Store) LastBranchCommit(repo, branch string) (string, bool) {
+	var sha string
+
+	store.read(func(data *storeData) {
+		commits := data.BranchCommits[branchKey(repo, branch)]
+
+		if len(commits) > 0 {
+			sha = commits[len(commits)-1]
+		}
+	})
+
+	return sha, sha != ""
+}
+
+// ForgetBranchCommit drops a commit from a branch's undo history
+func (store *Store) ForgetBranchCommit(repo, branch, sha string) error {
+	return store.update(func(data *storeData) {
+		key := branchKey(repo, branch)
+		remaining := []string{}
+
+		for _, commit := range data.BranchCommits[key] {
+			if commit != sha {
+				remaining = append(remaining, commit)
+			}
+		}
+
+		data.BranchCommits[key] = remaining
+	})
+}
+
+// ForgetBranch drops a branch's whole undo history, e.g. once its PR is closed
+func (store *Store) ForgetBranch(repo, branch string) error {
+	return store.update(func(data *storeData) {
+		delete(data.BranchCommits, branchKey(repo, branch))
+	})
+}
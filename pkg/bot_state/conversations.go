@@ -0,0 +1,46 @@
+package botstate
+
+import "time"
+
+// maxConversationTurns caps how much earlier feedback is replayed to the AI
+const maxConversationTurns = 10
+
+// ConversationTurn is one change request on a PR and how the bot answered it
+type ConversationTurn struct {
+	CreatedAt time.Time `json:"created_at"`
+	Path      string    `json:"path"`
+	Request   string    `json:"request"`
+	Response  string    `json:"response"`
+}
+
+// AppendConversationTurn adds a turn to a PR's history, keeping only the most recent ones
+func (store *Store) AppendConversationTurn(repo string, prNumber int, turn ConversationTurn) error {
+	return store.update(func(data *storeData) {
+		key := pullRequestKey(repo, prNumber)
+
+		turns := append(data.Conversations[key], turn)
+		if len(turns) > maxConversationTurns {
+			turns = turns[len(turns)-maxConversationTurns:]
+		}
+
+		data.Conversations[key] = turns
+	})
+}
+
+// ConversationHistory returns a PR's earlier turns, oldest first
+func (store *Store) ConversationHistory(repo string, prNumber int) []ConversationTurn {
+	turns := []ConversationTurn{}
+
+	store.read(func(data *storeData) {
+		turns = append(turns, data.Conversations[pullRequestKey(repo, prNumber)]...)
+	})
+
+	return turns
+}
+
+// ForgetConversation drops a PR's history once it's closed
+func (store *Store) ForgetConversation(repo string, prNumber int) error {
+	return store.update(func(data *storeData) {
+		delete(data.Conversations, pullRequestKey(repo, prNumber))
+	})
+}
@@ -0,0 +1,202 @@
+package botstate
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// IssueCost is what the AI calls made for one issue or PR have cost. Number
+// 0 collects a repo's calls that weren't for any one issue, and an empty
+// repo the calls made outside any repo
+type IssueCost struct {
+	CostUSD      float64 `json:"cost_usd"`
+	InputTokens  int64   `json:"input_tokens"`
+	Number       int     `json:"number"`
+	OutputTokens int64   `json:"output_tokens"`
+	Repo         string  `json:"repo"`
+}
+
+// MonthlySpend is what the AI calls for one repo cost in one calendar month (UTC)
+type MonthlySpend struct {
+	CostUSD float64 `json:"cost_usd"`
+	Month   string  `json:"month"` // YYYY-MM
+	Repo    string  `json:"repo"`
+	Tokens  int64   `json:"tokens"` // input and output
+}
+
+// spendMonthLayout buckets spend by UTC calendar month
+const spendMonthLayout = "2006-01"
+
+type RecordUsageArgs struct {
+	CostUSD      float64
+	InputTokens  int64
+	Model        string
+	Number       int
+	OutputTokens int64
+	Repo         string // owner/repo
+}
+
+// RecordUsage adds what one API call used to today's total for the model, to
+// the running cost of the issue or PR it was for and to the repo's spend this month
+func (store *Store) RecordUsage(args RecordUsageArgs) error {
+	now := time.Now().UTC()
+	date := now.Format(usageDateLayout)
+	month := now.Format(spendMonthLayout)
+
+	return store.update(func(data *storeData) {
+		usageKey := date + "/" + args.Model
+
+		usage, exists := data.TokenUsage[usageKey]
+		if !exists {
+			usage = &TokenUsage{Date: date, Model: args.Model}
+			data.TokenUsage[usageKey] = usage
+		}
+
+		usage.CostUSD += args.CostUSD
+		usage.InputTokens += args.InputTokens
+		usage.OutputTokens += args.OutputTokens
+
+		costKey := pullRequestKey(args.Repo, args.Number)
+
+		cost, exists := data.Costs[costKey]
+		if !exists {
+			cost = &IssueCost{Number: args.Number, Repo: args.Repo}
+			data.Costs[costKey] = cost
+		}
+
+		cost.CostUSD += args.CostUSD
+		cost.InputTokens += args.InputTokens
+		cost.OutputTokens += args.OutputTokens
+
+		spendKey := month + "/" + args.Repo
+
+		spend, exists := data.MonthlySpend[spendKey]
+		if !exists {
+			spend = &MonthlySpend{Month: month, Repo: args.Repo}
+			data.MonthlySpend[spendKey] = spend
+		}
+
+		spend.CostUSD += args.CostUSD
+		spend.Tokens += args.InputTokens + args.OutputTokens
+	})
+}
+
+// MonthlySpend returns what a repo has spent so far this month
+func (store *Store) MonthlySpend(repo string) MonthlySpend {
+	month := time.Now().UTC().Format(spendMonthLayout)
+	spend := MonthlySpend{Month: month, Repo: repo}
+
+	store.read(func(data *storeData) {
+		if saved, exists := data.MonthlySpend[month+"/"+repo]; exists {
+			spend = *saved
+		}
+	})
+
+	return spend
+}
+
+// TotalMonthlySpend returns what the bot has spent so far this month across
+// every repo; its Repo is empty
+func (store *Store) TotalMonthlySpend() MonthlySpend {
+	month := time.Now().UTC().Format(spendMonthLayout)
+	total := MonthlySpend{Month: month}
+
+	store.read(func(data *storeData) {
+		for _, spend := range data.MonthlySpend {
+			if spend.Month == month {
+				total.CostUSD += spend.CostUSD
+				total.Tokens += spend.Tokens
+			}
+		}
+	})
+
+	return total
+}
+
+// IssueCost returns what has been spent on an issue or PR so far
+func (store *Store) IssueCost(repo string, number int) IssueCost {
+	cost := IssueCost{Number: number, Repo: repo}
+
+	store.read(func(data *storeData) {
+		if saved, exists := data.Costs[pullRequestKey(repo, number)]; exists {
+			cost = *saved
+		}
+	})
+
+	return cost
+}
+
+// CostSummary is everything the bot has spent on AI calls
+type CostSummary struct {
+	ByModel  []TokenUsage       `json:"by_model"` // summed over all days
+	ByRepo   map[string]float64 `json:"by_repo"`
+	Issues   []IssueCost        `json:"issues"` // most expensive first
+	TotalUSD float64            `json:"total_usd"`
+}
+
+// CostSummary totals the recorded spend by model, repo and issue
+func (store *Store) CostSummary() CostSummary {
+	summary := CostSummary{ByRepo: map[string]float64{}}
+	byModel := map[string]*TokenUsage{}
+
+	store.read(func(data *storeData) {
+		for _, cost := range data.Costs {
+			summary.ByRepo[cost.Repo] += cost.CostUSD
+			summary.Issues = append(summary.Issues, *cost)
+			summary.TotalUSD += cost.CostUSD
+		}
+
+		for _, usage := range data.TokenUsage {
+			total, exists := byModel[usage.Model]
+			if !exists {
+				total = &TokenUsage{Model: usage.Model}
+				byModel[usage.Model] = total
+			}
+
+			total.CostUSD += usage.CostUSD
+			total.InputTokens += usage.InputTokens
+			total.OutputTokens += usage.OutputTokens
+		}
+	})
+
+	for _, model := range slices.Sorted(maps.Keys(byModel)) {
+		summary.ByModel = append(summary.ByModel, *byModel[model])
+	}
+
+	slices.SortFunc(summary.Issues, func(a, b IssueCost) int {
+		if byCost := cmp.Compare(b.CostUSD, a.CostUSD); byCost != 0 {
+			return byCost
+		}
+
+		return strings.Compare(pullRequestKey(a.Repo, a.Number), pullRequestKey(b.Repo, b.Number))
+	})
+
+	return summary
+}
+
+// FormatCostMetrics renders a summary in the Prometheus text format, so the
+// spend can be scraped and graphed
+func FormatCostMetrics(summary CostSummary) string {
+	var metrics strings.Builder
+
+	metrics.WriteString("# HELP bot_ai_cost_usd_total Estimated AI spend in US dollars.\n")
+	metrics.WriteString("# TYPE bot_ai_cost_usd_total counter\n")
+
+	for _, repo := range slices.Sorted(maps.Keys(summary.ByRepo)) {
+		metrics.WriteString(fmt.Sprintf("bot_ai_cost_usd_total{repo=%q} %g\n", repo, summary.ByRepo[repo]))
+	}
+
+	metrics.WriteString("# HELP bot_ai_tokens_total Tokens sent to and received from each model.\n")
+	metrics.WriteString("# TYPE bot_ai_tokens_total counter\n")
+
+	for _, usage := range summary.ByModel {
+		metrics.WriteString(fmt.Sprintf("bot_ai_tokens_total{model=%q,direction=\"input\"} %d\n", usage.Model, usage.InputTokens))
+		metrics.WriteString(fmt.Sprintf("bot_ai_tokens_total{model=%q,direction=\"output\"} %d\n", usage.Model, usage.OutputTokens))
+	}
+
+	return metrics.String()
+}
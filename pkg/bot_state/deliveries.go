@@ -0,0 +1,116 @@
+package botstate
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// maxDeliveries is how many webhook payloads are kept for replay; older ones
+// are dropped so they don't pile up on disk
+const maxDeliveries = 100
+
+// deliveryIDPattern matches the delivery IDs that are safe to use as file
+// names: GitHub's UUIDs and the bot's own random hex IDs
+var deliveryIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// Delivery is a webhook as GitHub sent it, kept so it can be replayed. Only
+// the metadata goes in the state file; each payload is kept in a file of its
+// own beside it, or in memory for a store without a path
+type Delivery struct {
+	Event      string    `json:"event"` // the X-GitHub-Event header
+	ID         string    `json:"id"`    // the X-GitHub-Delivery header
+	Payload    string    `json:"-"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// SaveDelivery keeps a webhook payload, dropping the oldest once there are
+// more than maxDeliveries
+func (store *Store) SaveDelivery(delivery Delivery) error {
+	if !deliveryIDPattern.MatchString(delivery.ID) {
+		return fmt.Errorf("delivery ID %q can't be used as a file name", delivery.ID)
+	}
+
+	if store.path != "" {
+		if err := os.MkdirAll(store.deliveriesDir(), 0o700); err != nil {
+			return fmt.Errorf("creating deliveries directory: %w", err)
+		}
+
+		if err := os.WriteFile(store.deliveryPath(delivery.ID), []byte(delivery.Payload), 0o600); err != nil {
+			return fmt.Errorf("writing delivery payload: %w", err)
+		}
+
+		delivery.Payload = ""
+	}
+
+	var dropped []string
+
+	err := store.update(func(data *storeData) {
+		data.Deliveries[delivery.ID] = &delivery
+
+		if len(data.Deliveries) <= maxDeliveries {
+			return
+		}
+
+		oldest := slices.SortedFunc(maps.Values(data.Deliveries), func(a, b *Delivery) int {
+			return a.ReceivedAt.Compare(b.ReceivedAt)
+		})
+
+		for _, saved := range oldest[:len(oldest)-maxDeliveries] {
+			delete(data.Deliveries, saved.ID)
+			dropped = append(dropped, saved.ID)
+		}
+	})
+
+	if store.path != "" {
+		for _, id := range dropped {
+			if err := os.Remove(store.deliveryPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("removing delivery payload: %w", err)
+			}
+		}
+	}
+
+	return err
+}
+
+// Delivery returns a kept webhook payload by its delivery ID
+func (store *Store) Delivery(id string) (Delivery, bool) {
+	var delivery Delivery
+	exists := false
+
+	store.read(func(data *storeData) {
+		if saved, isSaved := data.Deliveries[id]; isSaved {
+			delivery, exists = *saved, true
+		}
+	})
+
+	if !exists || store.path == "" {
+		return delivery, exists
+	}
+
+	// the metadata of deliveries kept before payloads had files of their own
+	// outlives the payload
+	payload, err := os.ReadFile(store.deliveryPath(id))
+	if err != nil {
+		return Delivery{}, false
+	}
+
+	delivery.Payload = string(payload)
+
+	return delivery, true
+}
+
+// deliveriesDir is the directory beside the state file holding the payloads
+func (store *Store) deliveriesDir() string {
+	return store.path + ".deliveries"
+}
+
+// deliveryPath is the file a delivery's payload is kept in
+func (store *Store) deliveryPath(id string) string {
+	return filepath.Join(store.deliveriesDir(), id+".json")
+}
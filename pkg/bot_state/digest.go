@@ -0,0 +1,223 @@
+package botstate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// editRetention is how long edits are kept for digests
+const editRetention = 90 * 24 * time.Hour
+
+// usageDateLayout buckets token usage by UTC day
+const usageDateLayout = "2006-01-02"
+
+// EditRecord is a commit the bot pushed to a PR after opening it
+type EditRecord struct {
+	At     time.Time `json:"at"`
+	Number int       `json:"number"`
+	Repo   string    `json:"repo"`
+}
+
+// TokenUsage is the tokens one model used on one day
+type TokenUsage struct {
+	CostUSD      float64 `json:"cost_usd"`
+	Date         string  `json:"date"` // YYYY-MM-DD, UTC
+	InputTokens  int64   `json:"input_tokens"`
+	Model        string  `json:"model"`
+	OutputTokens int64   `json:"output_tokens"`
+}
+
+// DigestIssue is the issue a week's digest is written to
+type DigestIssue struct {
+	IsFinal bool `json:"is_final"` // the week is over and the issue has its last update
+	Number  int  `json:"number"`
+}
+
+// pruneEdits drops edits too old to appear in a digest
+func pruneEdits(edits []EditRecord) []EditRecord {
+	cutoff := time.Now().Add(-editRetention)
+
+	return slices.DeleteFunc(edits, func(edit EditRecord) bool {
+		return edit.At.Before(cutoff)
+	})
+}
+
+// digestIssueKey identifies a repo's digest issue for the week starting at weekStart
+func digestIssueKey(repo string, weekStart time.Time) string {
+	return repo + "@" + weekStart.UTC().Format(usageDateLayout)
+}
+
+// DigestIssue returns the digest issue opened for a week, if there is one
+func (store *Store) DigestIssue(repo string, weekStart time.Time) (DigestIssue, bool) {
+	var issue DigestIssue
+	exists := false
+
+	store.read(func(data *storeData) {
+		if saved, isSaved := data.DigestIssues[digestIssueKey(repo, weekStart)]; isSaved {
+			issue, exists = *saved, true
+		}
+	})
+
+	return issue, exists
+}
+
+// SaveDigestIssue remembers the digest issue for a week
+func (store *Store) SaveDigestIssue(repo string, weekStart time.Time, issue DigestIssue) error {
+	return store.update(func(data *storeData) {
+		data.DigestIssues[digestIssueKey(repo, weekStart)] = &issue
+	})
+}
+
+// Digest is the bot's activity across all repos over a period
+type Digest struct {
+	ClosedByOutcome map[string]int
+	EditedPRs       int
+	Edits           int
+	Opened          []PullRequestRecord
+	Since           time.Time
+	TokenUsage      []TokenUsage // summed per model
+	Until           time.Time
+}
+
+// Digest collects the PRs opened and closed, edits pushed and tokens used
+// between since and until. Token usage is counted by whole UTC days.
+func (store *Store) Digest(since, until time.Time) Digest {
+	digest := Digest{
+		ClosedByOutcome: map[string]int{},
+		Since:           since,
+		Until:           until,
+	}
+
+	isInPeriod := func(at time.Time) bool {
+		return !at.Before(since) && at.Before(until)
+	}
+
+	firstDay := since.UTC().Format(usageDateLayout)
+	lastDay := until.UTC().Format(usageDateLayout)
+
+	store.read(func(data *storeData) {
+		for _, record := range data.PullRequests {
+			if isInPeriod(record.OpenedAt) {
+				digest.Opened = append(digest.Opened, *record)
+			}
+
+			if isInPeriod(record.ClosedAt) {
+				digest.ClosedByOutcome[record.Outcome]++
+			}
+		}
+
+		editedPRs := map[string]bool{}
+
+		for _, edit := range data.Edits {
+			if isInPeriod(edit.At) {
+				digest.Edits++
+				editedPRs[pullRequestKey(edit.Repo, edit.Number)] = true
+			}
+		}
+
+		digest.EditedPRs = len(editedPRs)
+
+		byModel := map[string]*TokenUsage{}
+
+		for _, usage := range data.TokenUsage {
+			if usage.Date < firstDay || usage.Date >= lastDay {
+				continue
+			}
+
+			total, exists := byModel[usage.Model]
+			if !exists {
+				total = &TokenUsage{Model: usage.Model}
+				byModel[usage.Model] = total
+			}
+
+			total.CostUSD += usage.CostUSD
+			total.InputTokens += usage.InputTokens
+			total.OutputTokens += usage.OutputTokens
+		}
+
+		for _, total := range byModel {
+			digest.TokenUsage = append(digest.TokenUsage, *total)
+		}
+	})
+
+	slices.SortFunc(digest.Opened, func(a, b PullRequestRecord) int {
+		return a.OpenedAt.Compare(b.OpenedAt)
+	})
+
+	slices.SortFunc(digest.TokenUsage, func(a, b TokenUsage) int {
+		return strings.Compare(a.Model, b.Model)
+	})
+
+	return digest
+}
+
+// FormatDigest renders a digest as the markdown body of a digest issue
+func FormatDigest(digest Digest) string {
+	var body strings.Builder
+
+	openedByType := map[string]int{}
+	for _, record := range digest.Opened {
+		openedByType[record.ContentType]++
+	}
+
+	body.WriteString(fmt.Sprintf(
+		"Bot activity from %s to %s (UTC).\n\n",
+		digest.Since.UTC().Format("Mon Jan 2"),
+		digest.Until.UTC().Add(-time.Second).Format("Mon Jan 2, 2006"),
+	))
+
+	body.WriteString("### Activity\n\n")
+	body.WriteString(fmt.Sprintf("- **Posts generated:** %d\n", openedByType[ContentTypeBlog]))
+	body.WriteString(fmt.Sprintf("- **Write-ups generated:** %d\n", openedByType[ContentTypeWriteUp]))
+	body.WriteString(fmt.Sprintf("- **Code PRs opened:** %d\n", openedByType[ContentTypeCode]))
+	body.WriteString(fmt.Sprintf("- **Edits applied:** %d across %d PRs\n", digest.Edits, digest.EditedPRs))
+	body.WriteString(fmt.Sprintf(
+		"- **PRs closed:** %d merged, %d merged after heavy edits, %d closed unmerged\n",
+		digest.ClosedByOutcome[OutcomeMerged],
+		digest.ClosedByOutcome[OutcomeMergedAndEdits],
+		digest.ClosedByOutcome[OutcomeClosed],
+	))
+
+	if len(digest.Opened) > 0 {
+		body.WriteString("\n### Opened\n\n")
+
+		for _, record := range digest.Opened {
+			body.WriteString(fmt.Sprintf("- %s#%d (%s, %s)\n", record.Repo, record.Number, record.ContentType, record.Outcome))
+		}
+	}
+
+	body.WriteString("\n### Token spend\n\n")
+
+	if len(digest.TokenUsage) == 0 {
+		body.WriteString("No API calls this week.\n")
+		return body.String()
+	}
+
+	body.WriteString("| Model | Input tokens | Output tokens | Cost |\n")
+	body.WriteString("|-------|-------------:|--------------:|-----:|\n")
+
+	var totalInput, totalOutput int64
+	var totalCost float64
+
+	for _, usage := range digest.TokenUsage {
+		body.WriteString(fmt.Sprintf(
+			"| `%s` | %d | %d | ~$%.2f |\n",
+			usage.Model,
+			usage.InputTokens,
+			usage.OutputTokens,
+			usage.CostUSD,
+		))
+
+		totalInput += usage.InputTokens
+		totalOutput += usage.OutputTokens
+		totalCost += usage.CostUSD
+	}
+
+	if len(digest.TokenUsage) > 1 {
+		body.WriteString(fmt.Sprintf("| **Total** | %d | %d | ~$%.2f |\n", totalInput, totalOutput, totalCost))
+	}
+
+	return body.String()
+}
@@ -0,0 +1,109 @@
+package botstate
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"time"
+)
+
+// maxFailures is how many failures are kept for lookup by ID; older ones are
+// dropped so the state file stays small
+const maxFailures = 200
+
+// Failure is a request the bot couldn't carry out, kept under the short ID
+// its failure comment shows so a report like "job ab12cd failed" can be
+// traced to the error and, through the ID in the logs, the delivery
+type Failure struct {
+	Category    string    `json:"category"` // e.g. "github", "ai" or "budget"
+	Error       string    `json:"error"`    // the operator message
+	ID          string    `json:"id"`
+	IssueNumber int       `json:"issue_number"` // the issue or PR the failure was reported on
+	OccurredAt  time.Time `json:"occurred_at"`
+	Repo        string    `json:"repo"`
+}
+
+type RecordFailureArgs struct {
+	Category    string
+	Err         error
+	IssueNumber int
+	Repo        string // owner/repo
+}
+
+// RecordFailure keeps a failure under a new short ID, dropping the oldest once
+// there are more than maxFailures. The failure is returned even when saving
+// it fails, so its ID can still be shown and logged
+func (store *Store) RecordFailure(args RecordFailureArgs) (Failure, error) {
+	failure := Failure{
+		Category:    args.Category,
+		Error:       args.Err.Error(),
+		IssueNumber: args.IssueNumber,
+		OccurredAt:  time.Now().UTC(),
+		Repo:        args.Repo,
+	}
+
+	err := store.update(func(data *storeData) {
+		failure.ID = newFailureID(data.Failures)
+		data.Failures[failure.ID] = &failure
+
+		if len(data.Failures) <= maxFailures {
+			return
+		}
+
+		oldest := slices.SortedFunc(maps.Values(data.Failures), func(a, b *Failure) int {
+			return a.OccurredAt.Compare(b.OccurredAt)
+		})
+
+		for _, saved := range oldest[:len(oldest)-maxFailures] {
+			delete(data.Failures, saved.ID)
+		}
+	})
+
+	return failure, err
+}
+
+// Failure returns a kept failure by its ID
+func (store *Store) Failure(id string) (Failure, bool) {
+	var failure Failure
+	exists := false
+
+	store.read(func(data *storeData) {
+		if saved, isSaved := data.Failures[id]; isSaved {
+			failure, exists = *saved, true
+		}
+	})
+
+	return failure, exists
+}
+
+// Failures returns every kept failure, newest first
+func (store *Store) Failures() []Failure {
+	failures := []Failure{}
+
+	store.read(func(data *storeData) {
+		for _, failure := range data.Failures {
+			failures = append(failures, *failure)
+		}
+	})
+
+	slices.SortFunc(failures, func(a, b Failure) int {
+		return b.OccurredAt.Compare(a.OccurredAt)
+	})
+
+	return failures
+}
+
+// newFailureID returns 6 random hex characters not already in use; short
+// enough to quote, and with few enough failures kept that collisions are rare
+func newFailureID(failures map[string]*Failure) string {
+	for {
+		random := make([]byte, 3)
+		rand.Read(random)
+
+		id := hex.EncodeToString(random)
+		if _, isTaken := failures[id]; !isTaken {
+			return id
+		}
+	}
+}
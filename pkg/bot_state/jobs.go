@@ -0,0 +1,65 @@
+package botstate
+
+import "time"
+
+// Job is a finished bot request: the issue it came from and how its PR ended
+type Job struct {
+	Branch      string    `json:"branch"`
+	DoneAt      time.Time `json:"done_at"`
+	IsMerged    bool      `json:"is_merged"`
+	IssueNumber int       `json:"issue_number,omitempty"` // 0 when the PR had no source issue
+	PrNumber    int       `json:"pr_number"`
+	Repo        string    `json:"repo"`
+}
+
+type MarkJobDoneArgs struct {
+	Branch      string
+	IsMerged    bool
+	IssueNumber int
+	PrNumber    int
+	Repo        string // owner/repo
+}
+
+// MarkJobDone records a closed PR's job as finished and drops the working
+// state kept for it: undo history, conversation, issue request, previews,
+// any scheduled publish and any CI watch
+func (store *Store) MarkJobDone(args MarkJobDoneArgs) error {
+	return store.update(func(data *storeData) {
+		prKey := pullRequestKey(args.Repo, args.PrNumber)
+
+		data.Jobs[prKey] = &Job{
+			Branch:      args.Branch,
+			DoneAt:      time.Now(),
+			IsMerged:    args.IsMerged,
+			IssueNumber: args.IssueNumber,
+			PrNumber:    args.PrNumber,
+			Repo:        args.Repo,
+		}
+
+		delete(data.BranchCommits, branchKey(args.Repo, args.Branch))
+		delete(data.CIWatches, prKey)
+		delete(data.Conversations, prKey)
+		delete(data.ScheduledPublishes, prKey)
+
+		if args.IssueNumber > 0 {
+			delete(data.IssueRequests, issueRequestKey(args.Repo, args.IssueNumber))
+		}
+
+		for key, change := range data.PendingChanges {
+			if change.Repo == args.Repo && change.PrNumber == args.PrNumber {
+				delete(data.PendingChanges, key)
+			}
+		}
+	})
+}
+
+// IsJobDone reports whether the job behind a PR has been marked done
+func (store *Store) IsJobDone(repo string, prNumber int) bool {
+	isDone := false
+
+	store.read(func(data *storeData) {
+		_, isDone = data.Jobs[pullRequestKey(repo, prNumber)]
+	})
+
+	return isDone
+}
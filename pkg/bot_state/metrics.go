@@ -0,0 +1,214 @@
+package botstate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Content types of bot-created pull requests
+const (
+	ContentTypeBlog    = "blog"
+	ContentTypeCode    = "code"
+	ContentTypeWriteUp = "write-up"
+)
+
+// Lifecycle outcomes of bot-created pull requests
+const (
+	OutcomeOpen           = "open"
+	OutcomeMerged         = "merged"
+	OutcomeMergedAndEdits = "merged-heavily-edited"
+	OutcomeClosed         = "closed-unmerged"
+)
+
+// heavyEditCommits is how many human commits make a merged PR count as heavily edited
+const heavyEditCommits = 2
+
+// PullRequestRecord tracks one bot-created PR from open to close
+type PullRequestRecord struct {
+	BotCommits    int       `json:"bot_commits"`
+	ClosedAt      time.Time `json:"closed_at,omitzero"`
+	ContentType   string    `json:"content_type"`
+	HumanCommits  int       `json:"human_commits"`
+	Number        int       `json:"number"`
+	OpenedAt      time.Time `json:"opened_at"`
+	Outcome       string    `json:"outcome"`
+	PromptVersion string    `json:"prompt_version"`
+	Repo          string    `json:"repo"`
+}
+
+type RecordPullRequestOpenedArgs struct {
+	ContentType   string
+	Number        int
+	PromptVersion string
+	Repo          string // owner/repo
+}
+
+// RecordPullRequestOpened starts tracking a PR the bot just created, counting
+// the commit it was opened with; later commits go through RecordBotCommit
+func (store *Store) RecordPullRequestOpened(args RecordPullRequestOpenedArgs) error {
+	return store.update(func(data *storeData) {
+		data.PullRequests[pullRequestKey(args.Repo, args.Number)] = &PullRequestRecord{
+			BotCommits:    1,
+			ContentType:   args.ContentType,
+			Number:        args.Number,
+			OpenedAt:      time.Now(),
+			Outcome:       OutcomeOpen,
+			PromptVersion: args.PromptVersion,
+			Repo:          args.Repo,
+		}
+	})
+}
+
+// PullRequest returns the record of a PR the bot created
+func (store *Store) PullRequest(repo string, number int) (PullRequestRecord, bool) {
+	var record PullRequestRecord
+
+	found := false
+
+	store.read(func(data *storeData) {
+		if stored, exists := data.PullRequests[pullRequestKey(repo, number)]; exists {
+			record, found = *stored, true
+		}
+	})
+
+	return record, found
+}
+
+// IsBotPullRequest reports whether the bot opened a PR, going by its own
+// records rather than the branch name, which anyone can copy
+func (store *Store) IsBotPullRequest(repo string, number int) bool {
+	_, isBotPR := store.PullRequest(repo, number)
+
+	return isBotPR
+}
+
+// RecordBotCommit counts a commit the bot pushed to one of its PRs, logging
+// it as an edit for the weekly digest
+func (store *Store) RecordBotCommit(repo string, number int) error {
+	return store.update(func(data *storeData) {
+		record, exists := data.PullRequests[pullRequestKey(repo, number)]
+		if !exists {
+			return
+		}
+
+		record.BotCommits++
+		data.Edits = append(pruneEdits(data.Edits), EditRecord{
+			At:     time.Now(),
+			Number: number,
+			Repo:   repo,
+		})
+	})
+}
+
+type RecordPullRequestClosedArgs struct {
+	IsMerged     bool
+	Number       int
+	Repo         string
+	TotalCommits int
+}
+
+// RecordPullRequestClosed stores how a tracked PR ended; untracked PRs are ignored
+func (store *Store) RecordPullRequestClosed(args RecordPullRequestClosedArgs) error {
+	return store.update(func(data *storeData) {
+		record, exists := data.PullRequests[pullRequestKey(args.Repo, args.Number)]
+		if !exists {
+			return
+		}
+
+		record.ClosedAt = time.Now()
+		record.HumanCommits = max(args.TotalCommits-record.BotCommits, 0)
+
+		switch {
+		case !args.IsMerged:
+			record.Outcome = OutcomeClosed
+		case record.HumanCommits >= heavyEditCommits:
+			record.Outcome = OutcomeMergedAndEdits
+		default:
+			record.Outcome = OutcomeMerged
+		}
+	})
+}
+
+// LifecycleSummary aggregates PR outcomes for one content type and prompt version
+type LifecycleSummary struct {
+	ContentType   string
+	Outcomes      map[string]int
+	PromptVersion string
+	Total         int
+}
+
+// LifecycleSummaries groups tracked PRs by content type and prompt version
+func (store *Store) LifecycleSummaries() []LifecycleSummary {
+	grouped := map[string]*LifecycleSummary{}
+
+	store.read(func(data *storeData) {
+		for _, record := range data.PullRequests {
+			groupKey := record.ContentType + "/" + record.PromptVersion
+
+			summary, exists := grouped[groupKey]
+			if !exists {
+				summary = &LifecycleSummary{
+					ContentType:   record.ContentType,
+					Outcomes:      map[string]int{},
+					PromptVersion: record.PromptVersion,
+				}
+
+				grouped[groupKey] = summary
+			}
+
+			summary.Outcomes[record.Outcome]++
+			summary.Total++
+		}
+	})
+
+	summaries := []LifecycleSummary{}
+	for _, summary := range grouped {
+		summaries = append(summaries, *summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].ContentType != summaries[j].ContentType {
+			return summaries[i].ContentType < summaries[j].ContentType
+		}
+
+		return summaries[i].PromptVersion < summaries[j].PromptVersion
+	})
+
+	return summaries
+}
+
+// FormatLifecycleReport renders summaries as a plain-text table
+func FormatLifecycleReport(summaries []LifecycleSummary) string {
+	var report strings.Builder
+
+	report.WriteString(fmt.Sprintf(
+		"%-10s %-10s %6s %6s %8s %8s %8s %7s\n",
+		"type", "prompt", "total", "open", "merged", "edited", "closed", "merge%",
+	))
+
+	for _, summary := range summaries {
+		merged := summary.Outcomes[OutcomeMerged] + summary.Outcomes[OutcomeMergedAndEdits]
+		finished := summary.Total - summary.Outcomes[OutcomeOpen]
+
+		mergeRate := 0.0
+		if finished > 0 {
+			mergeRate = 100 * float64(merged) / float64(finished)
+		}
+
+		report.WriteString(fmt.Sprintf(
+			"%-10s %-10s %6d %6d %8d %8d %8d %6.0f%%\n",
+			summary.ContentType,
+			summary.PromptVersion,
+			summary.Total,
+			summary.Outcomes[OutcomeOpen],
+			summary.Outcomes[OutcomeMerged],
+			summary.Outcomes[OutcomeMergedAndEdits],
+			summary.Outcomes[OutcomeClosed],
+			mergeRate,
+		))
+	}
+
+	return report.String()
+}
@@ -0,0 +1,76 @@
+package botstate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PendingChange is an AI edit previewed on a PR, waiting for the requester to
+// confirm it with a 👍 or /apply before it's committed
+type PendingChange struct {
+	Author           string    `json:"author"`
+	Branch           string    `json:"branch"`
+	CommentID        int64     `json:"comment_id"` // the comment that asked for the change
+	Content          string    `json:"content"`
+	CreatedAt        time.Time `json:"created_at"`
+	Message          string    `json:"message"`
+	Path             string    `json:"path"`
+	PrNumber         int       `json:"pr_number"`
+	PreviewCommentID int64     `json:"preview_comment_id"`
+	Repo             string    `json:"repo"`
+	Sha              string    `json:"sha"` // blob SHA the change was made against
+}
+
+// pendingChangeKey identifies a pending change by its PR and requesting comment
+func pendingChangeKey(repo string, prNumber int, commentID int64) string {
+	return fmt.Sprintf("%s/%d", pullRequestKey(repo, prNumber), commentID)
+}
+
+// SavePendingChange stores a previewed change until it's applied or discarded
+func (store *Store) SavePendingChange(change PendingChange) error {
+	return store.update(func(data *storeData) {
+		data.PendingChanges[pendingChangeKey(change.Repo, change.PrNumber, change.CommentID)] = &change
+	})
+}
+
+// DeletePendingChange discards a pending change
+func (store *Store) DeletePendingChange(change PendingChange) error {
+	return store.update(func(data *storeData) {
+		delete(data.PendingChanges, pendingChangeKey(change.Repo, change.PrNumber, change.CommentID))
+	})
+}
+
+// PendingChanges returns every pending change for repo, oldest first
+func (store *Store) PendingChanges(repo string) []PendingChange {
+	changes := []PendingChange{}
+
+	store.read(func(data *storeData) {
+		for _, change := range data.PendingChanges {
+			if change.Repo == repo {
+				changes = append(changes, *change)
+			}
+		}
+	})
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].CreatedAt.Before(changes[j].CreatedAt)
+	})
+
+	return changes
+}
+
+// LatestPendingChange returns the author's most recent pending change on a PR
+func (store *Store) LatestPendingChange(repo string, prNumber int, author string) (PendingChange, bool) {
+	var latest PendingChange
+	isFound := false
+
+	for _, change := range store.PendingChanges(repo) {
+		if change.PrNumber == prNumber && change.Author == author {
+			latest = change
+			isFound = true
+		}
+	}
+
+	return latest, isFound
+}
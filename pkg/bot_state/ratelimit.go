@@ -0,0 +1,41 @@
+package botstate
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// RecordTriggerArgs is one user asking the bot to generate or modify something
+type RecordTriggerArgs struct {
+	Limit  int // most triggers allowed within Window; 0 is no limit
+	Login  string
+	Window time.Duration
+}
+
+// RecordTrigger counts a trigger against a user's limit, across every repo,
+// and reports whether it's allowed. When it isn't, it isn't counted, and
+// retryAt is when the oldest trigger in the window expires.
+func (store *Store) RecordTrigger(args RecordTriggerArgs) (isAllowed bool, retryAt time.Time, err error) {
+	now := time.Now()
+	key := strings.ToLower(args.Login)
+
+	err = store.update(func(data *storeData) {
+		cutoff := now.Add(-args.Window)
+
+		triggers := slices.DeleteFunc(data.Triggers[key], func(at time.Time) bool {
+			return !at.After(cutoff)
+		})
+
+		if args.Limit > 0 && len(triggers) >= args.Limit {
+			data.Triggers[key] = triggers
+			retryAt = triggers[len(triggers)-args.Limit].Add(args.Window)
+			return
+		}
+
+		data.Triggers[key] = append(triggers, now)
+		isAllowed = true
+	})
+
+	return isAllowed, retryAt, err
+}
@@ -0,0 +1,67 @@
+package botstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IssueRequest is a content request still being refined on its issue, before
+// any PR exists. The request itself is kept as JSON so each handler can store
+// its own request type
+type IssueRequest struct {
+	IssueNumber int             `json:"issue_number"`
+	Repo        string          `json:"repo"`
+	Request     json.RawMessage `json:"request"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func issueRequestKey(repo string, issueNumber int) string {
+	return fmt.Sprintf("%s#%d", repo, issueNumber)
+}
+
+// SaveIssueRequest stores the latest version of an issue's request
+func (store *Store) SaveIssueRequest(repo string, issueNumber int, request any) error {
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	return store.update(func(data *storeData) {
+		data.IssueRequests[issueRequestKey(repo, issueNumber)] = &IssueRequest{
+			IssueNumber: issueNumber,
+			Repo:        repo,
+			Request:     encoded,
+			UpdatedAt:   time.Now(),
+		}
+	})
+}
+
+// LoadIssueRequest decodes an issue's stored request into request, reporting
+// whether there was one
+func (store *Store) LoadIssueRequest(repo string, issueNumber int, request any) (bool, error) {
+	var encoded json.RawMessage
+
+	store.read(func(data *storeData) {
+		if stored, isFound := data.IssueRequests[issueRequestKey(repo, issueNumber)]; isFound {
+			encoded = stored.Request
+		}
+	})
+
+	if encoded == nil {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(encoded, request); err != nil {
+		return false, fmt.Errorf("decoding request: %w", err)
+	}
+
+	return true, nil
+}
+
+// DeleteIssueRequest forgets an issue's request once its PR is opened
+func (store *Store) DeleteIssueRequest(repo string, issueNumber int) error {
+	return store.update(func(data *storeData) {
+		delete(data.IssueRequests, issueRequestKey(repo, issueNumber))
+	})
+}
@@ -0,0 +1,61 @@
+package botstate
+
+import (
+	"sort"
+	"time"
+)
+
+// ScheduledPublish is a blog PR queued to be published and merged at a set time
+type ScheduledPublish struct {
+	CreatedAt   time.Time `json:"created_at"`
+	PrNumber    int       `json:"pr_number"`
+	PublishAt   time.Time `json:"publish_at"`
+	Repo        string    `json:"repo"`
+	RequestedBy string    `json:"requested_by"`
+}
+
+type SchedulePublishArgs struct {
+	PrNumber    int
+	PublishAt   time.Time
+	Repo        string
+	RequestedBy string
+}
+
+// SchedulePublish queues a PR for publishing, replacing any earlier schedule for it
+func (store *Store) SchedulePublish(args SchedulePublishArgs) error {
+	return store.update(func(data *storeData) {
+		data.ScheduledPublishes[pullRequestKey(args.Repo, args.PrNumber)] = &ScheduledPublish{
+			CreatedAt:   time.Now(),
+			PrNumber:    args.PrNumber,
+			PublishAt:   args.PublishAt,
+			Repo:        args.Repo,
+			RequestedBy: args.RequestedBy,
+		}
+	})
+}
+
+// CancelScheduledPublish removes a PR's schedule, if it has one
+func (store *Store) CancelScheduledPublish(repo string, prNumber int) error {
+	return store.update(func(data *storeData) {
+		delete(data.ScheduledPublishes, pullRequestKey(repo, prNumber))
+	})
+}
+
+// DueScheduledPublishes returns the schedules for repo whose time has come, oldest first
+func (store *Store) DueScheduledPublishes(repo string, now time.Time) []ScheduledPublish {
+	due := []ScheduledPublish{}
+
+	store.read(func(data *storeData) {
+		for _, schedule := range data.ScheduledPublishes {
+			if schedule.Repo == repo && !schedule.PublishAt.After(now) {
+				due = append(due, *schedule)
+			}
+		}
+	})
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].PublishAt.Before(due[j].PublishAt)
+	})
+
+	return due
+}
@@ -0,0 +1,175 @@
+package botstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Store persists bot state as a single JSON file so it survives restarts
+type Store struct {
+	data  storeData
+	mutex sync.Mutex
+	path  string
+}
+
+// storeData is the on-disk shape of the store
+type storeData struct {
+	BranchCommits      map[string][]string           `json:"branch_commits"`
+	CIWatches          map[string]*CIWatch           `json:"ci_watches"`
+	Conversations      map[string][]ConversationTurn `json:"conversations"`
+	Costs              map[string]*IssueCost         `json:"costs"`
+	Deliveries         map[string]*Delivery          `json:"deliveries"`
+	DigestIssues       map[string]*DigestIssue       `json:"digest_issues"`
+	Edits              []EditRecord                  `json:"edits"`
+	Failures           map[string]*Failure           `json:"failures"`
+	IssueRequests      map[string]*IssueRequest      `json:"issue_requests"`
+	Jobs               map[string]*Job               `json:"jobs"`
+	MonthlySpend       map[string]*MonthlySpend      `json:"monthly_spend"`
+	PendingChanges     map[string]*PendingChange     `json:"pending_changes"`
+	PullRequests       map[string]*PullRequestRecord `json:"pull_requests"`
+	ScheduledPublishes map[string]*ScheduledPublish  `json:"scheduled_publishes"`
+	TokenUsage         map[string]*TokenUsage        `json:"token_usage"`
+	Triggers           map[string][]time.Time        `json:"triggers"` // by lowercased login, for rate limiting
+}
+
+// NewStore loads the store at path, starting empty if the file doesn't exist yet.
+// An empty path keeps state in memory only.
+func NewStore(path string) (*Store, error) {
+	store := &Store{path: path}
+	store.data.initialize()
+
+	if path == "" {
+		return store, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &store.data); err != nil {
+		return nil, fmt.Errorf("decoding state file: %w", err)
+	}
+
+	store.data.initialize()
+
+	return store, nil
+}
+
+// initialize fills in maps missing from state files written by older versions
+func (data *storeData) initialize() {
+	if data.BranchCommits == nil {
+		data.BranchCommits = map[string][]string{}
+	}
+
+	if data.CIWatches == nil {
+		data.CIWatches = map[string]*CIWatch{}
+	}
+
+	if data.Conversations == nil {
+		data.Conversations = map[string][]ConversationTurn{}
+	}
+
+	if data.Costs == nil {
+		data.Costs = map[string]*IssueCost{}
+	}
+
+	if data.Deliveries == nil {
+		data.Deliveries = map[string]*Delivery{}
+	}
+
+	if data.DigestIssues == nil {
+		data.DigestIssues = map[string]*DigestIssue{}
+	}
+
+	if data.Failures == nil {
+		data.Failures = map[string]*Failure{}
+	}
+
+	if data.IssueRequests == nil {
+		data.IssueRequests = map[string]*IssueRequest{}
+	}
+
+	if data.Jobs == nil {
+		data.Jobs = map[string]*Job{}
+	}
+
+	if data.MonthlySpend == nil {
+		data.MonthlySpend = map[string]*MonthlySpend{}
+	}
+
+	if data.PendingChanges == nil {
+		data.PendingChanges = map[string]*PendingChange{}
+	}
+
+	if data.PullRequests == nil {
+		data.PullRequests = map[string]*PullRequestRecord{}
+	}
+
+	if data.ScheduledPublishes == nil {
+		data.ScheduledPublishes = map[string]*ScheduledPublish{}
+	}
+
+	if data.TokenUsage == nil {
+		data.TokenUsage = map[string]*TokenUsage{}
+	}
+
+	if data.Triggers == nil {
+		data.Triggers = map[string][]time.Time{}
+	}
+}
+
+// update applies a change under the lock and writes the result to disk
+func (store *Store) update(change func(data *storeData)) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	change(&store.data)
+
+	return store.save()
+}
+
+// read runs a read-only view of the data under the lock
+func (store *Store) read(view func(data *storeData)) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	view(&store.data)
+}
+
+// save writes the store atomically; callers must hold the lock
+func (store *Store) save() error {
+	if store.path == "" {
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(store.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	tempPath := store.path + ".tmp"
+
+	if err := os.WriteFile(tempPath, raw, 0o600); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+
+	if err := os.Rename(tempPath, store.path); err != nil {
+		return fmt.Errorf("replacing state file: %w", err)
+	}
+
+	return nil
+}
+
+// pullRequestKey identifies a PR across repositories
+func pullRequestKey(repo string, number int) string {
+	return fmt.Sprintf("%s#%d", repo, number)
+}
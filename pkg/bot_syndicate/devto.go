@@ -0,0 +1,72 @@
+package botsyndicate
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// devToArticlesURL creates articles on dev.to
+const devToArticlesURL = "https://dev.to/api/articles"
+
+// maxDevToTags is the most tags dev.to accepts on an article
+const maxDevToTags = 4
+
+// devTo publishes through the dev.to (Forem) API
+type devTo struct {
+	apiKey      string
+	client      *http.Client
+	isPublished bool
+}
+
+func (platform *devTo) Publish(article *Article) (string, error) {
+	request, err := http.NewRequest(http.MethodPost, devToArticlesURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("api-key", platform.apiKey)
+
+	body := map[string]any{
+		"article": map[string]any{
+			"body_markdown": article.Markdown,
+			"canonical_url": article.CanonicalURL,
+			"description":   article.Description,
+			"published":     platform.isPublished,
+			"tags":          devToTags(article.Tags),
+			"title":         article.Title,
+		},
+	}
+
+	created := struct {
+		URL string `json:"url"`
+	}{}
+
+	if err := callJSON(platform.client, request, body, &created); err != nil {
+		return "", fmt.Errorf("creating dev.to article: %w", err)
+	}
+
+	return created.URL, nil
+}
+
+// devToTags keeps the first tags dev.to allows, which must be lowercase
+// letters and digits only
+func devToTags(tags []string) []string {
+	cleaned := []string{}
+
+	for _, tag := range tags {
+		tag = strings.Map(func(char rune) rune {
+			if (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') {
+				return char
+			}
+
+			return -1
+		}, strings.ToLower(tag))
+
+		if tag != "" && len(cleaned) < maxDevToTags {
+			cleaned = append(cleaned, tag)
+		}
+	}
+
+	return cleaned
+}
@@ -0,0 +1,87 @@
+package botsyndicate
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// mediumAPIURL is the root of Medium's API
+const mediumAPIURL = "https://api.medium.com/v1"
+
+// maxMediumTags is the most tags Medium accepts on a post
+const maxMediumTags = 5
+
+// medium publishes through Medium's API with an integration token
+type medium struct {
+	client      *http.Client
+	isPublished bool
+	token       string
+}
+
+func (platform *medium) Publish(article *Article) (string, error) {
+	userID, err := platform.userID()
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/users/%s/posts", mediumAPIURL, userID), nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+platform.token)
+
+	publishStatus := "draft"
+	if platform.isPublished {
+		publishStatus = "public"
+	}
+
+	tags := article.Tags
+	if len(tags) > maxMediumTags {
+		tags = tags[:maxMediumTags]
+	}
+
+	// Medium shows the content as-is, so the title goes in as a heading
+	body := map[string]any{
+		"canonicalUrl":  article.CanonicalURL,
+		"content":       fmt.Sprintf("# %s\n\n%s", article.Title, article.Markdown),
+		"contentFormat": "markdown",
+		"publishStatus": publishStatus,
+		"tags":          tags,
+		"title":         article.Title,
+	}
+
+	created := struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}{}
+
+	if err := callJSON(platform.client, request, body, &created); err != nil {
+		return "", fmt.Errorf("creating Medium post: %w", err)
+	}
+
+	return created.Data.URL, nil
+}
+
+// userID looks up the Medium account the token belongs to
+func (platform *medium) userID() (string, error) {
+	request, err := http.NewRequest(http.MethodGet, mediumAPIURL+"/me", nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+platform.token)
+
+	me := struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}{}
+
+	if err := callJSON(platform.client, request, nil, &me); err != nil {
+		return "", fmt.Errorf("getting Medium user: %w", err)
+	}
+
+	return me.Data.ID, nil
+}
@@ -0,0 +1,183 @@
+package botsyndicate
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// blueskyAPIURL is the XRPC root of the main Bluesky server
+	blueskyAPIURL = "https://bsky.social/xrpc"
+
+	// xTweetsURL creates posts on X
+	xTweetsURL = "https://api.twitter.com/2/tweets"
+)
+
+// linkPattern finds the links in a status, which Bluesky only makes
+// clickable when they're marked up as facets
+var linkPattern = regexp.MustCompile(`https?://[^\s]+[^\s.,;:!?)"']`)
+
+// Announcer posts a status to one social network account
+type Announcer interface {
+	// Announce posts text and returns the new post's URL
+	Announce(text string) (string, error)
+}
+
+// NewMastodonAnnouncer posts to a Mastodon account with an access token that
+// has the write:statuses scope
+func NewMastodonAnnouncer(server, token string) Announcer {
+	return &mastodon{
+		client: &http.Client{Timeout: requestTimeout},
+		server: strings.TrimSuffix(server, "/"),
+		token:  token,
+	}
+}
+
+// NewBlueskyAnnouncer posts to a Bluesky account with an app password
+func NewBlueskyAnnouncer(handle, appPassword string) Announcer {
+	return &bluesky{
+		appPassword: appPassword,
+		client:      &http.Client{Timeout: requestTimeout},
+		handle:      handle,
+	}
+}
+
+// NewXAnnouncer posts to an X account with an OAuth 2.0 user access token
+// that has the tweet.write scope
+func NewXAnnouncer(token string) Announcer {
+	return &xAccount{
+		client: &http.Client{Timeout: requestTimeout},
+		token:  token,
+	}
+}
+
+type mastodon struct {
+	client *http.Client
+	server string
+	token  string
+}
+
+func (account *mastodon) Announce(text string) (string, error) {
+	request, err := http.NewRequest(http.MethodPost, account.server+"/api/v1/statuses", nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+account.token)
+
+	status := struct {
+		URL string `json:"url"`
+	}{}
+
+	if err := callJSON(account.client, request, map[string]any{"status": text}, &status); err != nil {
+		return "", fmt.Errorf("posting to Mastodon: %w", err)
+	}
+
+	return status.URL, nil
+}
+
+type bluesky struct {
+	appPassword string
+	client      *http.Client
+	handle      string
+}
+
+func (account *bluesky) Announce(text string) (string, error) {
+	session := struct {
+		AccessJwt string `json:"accessJwt"`
+		Did       string `json:"did"`
+	}{}
+
+	sessionRequest, err := http.NewRequest(http.MethodPost, blueskyAPIURL+"/com.atproto.server.createSession", nil)
+	if err != nil {
+		return "", err
+	}
+
+	if err := callJSON(
+		account.client,
+		sessionRequest,
+		map[string]any{"identifier": account.handle, "password": account.appPassword},
+		&session,
+	); err != nil {
+		return "", fmt.Errorf("signing in to Bluesky: %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, blueskyAPIURL+"/com.atproto.repo.createRecord", nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+session.AccessJwt)
+
+	record := map[string]any{
+		"$type":     "app.bsky.feed.post",
+		"createdAt": time.Now().UTC().Format(time.RFC3339),
+		"text":      text,
+	}
+
+	if facets := blueskyLinkFacets(text); len(facets) > 0 {
+		record["facets"] = facets
+	}
+
+	created := struct {
+		URI string `json:"uri"`
+	}{}
+
+	if err := callJSON(
+		account.client,
+		request,
+		map[string]any{"collection": "app.bsky.feed.post", "record": record, "repo": session.Did},
+		&created,
+	); err != nil {
+		return "", fmt.Errorf("posting to Bluesky: %w", err)
+	}
+
+	// the record's URI ends with the post's key, e.g. at://did:plc:abc/app.bsky.feed.post/3k2a
+	return fmt.Sprintf("https://bsky.app/profile/%s/post/%s", account.handle, path.Base(created.URI)), nil
+}
+
+// blueskyLinkFacets marks up the links in a post by their UTF-8 byte offsets
+func blueskyLinkFacets(text string) []map[string]any {
+	facets := []map[string]any{}
+
+	for _, location := range linkPattern.FindAllStringIndex(text, -1) {
+		facets = append(facets, map[string]any{
+			"features": []map[string]any{
+				{"$type": "app.bsky.richtext.facet#link", "uri": text[location[0]:location[1]]},
+			},
+			"index": map[string]any{"byteEnd": location[1], "byteStart": location[0]},
+		})
+	}
+
+	return facets
+}
+
+type xAccount struct {
+	client *http.Client
+	token  string
+}
+
+func (account *xAccount) Announce(text string) (string, error) {
+	request, err := http.NewRequest(http.MethodPost, xTweetsURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+account.token)
+
+	created := struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}{}
+
+	if err := callJSON(account.client, request, map[string]any{"text": text}, &created); err != nil {
+		return "", fmt.Errorf("posting to X: %w", err)
+	}
+
+	return "https://x.com/i/web/status/" + created.Data.ID, nil
+}
@@ -0,0 +1,93 @@
+package botsyndicate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Platform names, as given to /crosspost and used in frontmatter keys
+const (
+	PlatformDevTo  = "devto"
+	PlatformMedium = "medium"
+)
+
+// PlatformNames lists the platforms posts can be cross-posted to
+var PlatformNames = []string{PlatformDevTo, PlatformMedium}
+
+// requestTimeout bounds a call to a platform's API
+const requestTimeout = 30 * time.Second
+
+// maxResponseBytes caps how much of an API reply is read
+const maxResponseBytes = 1 << 20
+
+// Article is a published blog post in the form the platforms take it
+type Article struct {
+	CanonicalURL string // the original post, so search engines credit the blog
+	Description  string
+	Markdown     string // the post body, without frontmatter
+	Tags         []string
+	Title        string
+}
+
+// Platform publishes articles on one site
+type Platform interface {
+	// Publish creates the article and returns its URL on the platform
+	Publish(article *Article) (string, error)
+}
+
+// NewPlatform creates the adapter for a platform by name. Articles are
+// created as drafts on the platform unless isPublished is set
+func NewPlatform(name, token string, isPublished bool) (Platform, error) {
+	client := &http.Client{Timeout: requestTimeout}
+
+	switch name {
+	case PlatformDevTo:
+		return &devTo{apiKey: token, client: client, isPublished: isPublished}, nil
+	case PlatformMedium:
+		return &medium{client: client, isPublished: isPublished, token: token}, nil
+	default:
+		return nil, fmt.Errorf("unknown platform %q", name)
+	}
+}
+
+// callJSON sends a JSON request and decodes the JSON reply into result,
+// treating any status other than 200 or 201 as an error
+func callJSON(client *http.Client, request *http.Request, body, result any) error {
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+
+		request.Body = io.NopCloser(bytes.NewReader(encoded))
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	reply, err := io.ReadAll(io.LimitReader(response.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%s: %s", response.Status, bytes.TrimSpace(reply))
+	}
+
+	if err := json.Unmarshal(reply, result); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package bottriage
+
+import (
+	"fmt"
+
+	botAi "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_ai"
+	botConfig "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_config"
+	botGithub "github.com/frankmeza/frankmeza-anthropic-bot/pkg/bot_github"
+	"github.com/google/go-github/v57/github"
+)
+
+// Triager classifies issues that aren't requests for the bot, labels them
+// and posts a short acknowledgment
+type Triager struct {
+	AiClient     botAi.AIClient
+	GithubClient botGithub.GithubAPI
+	Labels       botConfig.Labels
+	Owner        string
+	Repo         string
+}
+
+// NewTriager creates a triager for one repository
+func NewTriager(args Triager) *Triager {
+	return &Triager{
+		AiClient:     args.AiClient,
+		GithubClient: args.GithubClient,
+		Labels:       args.Labels,
+		Owner:        args.Owner,
+		Repo:         args.Repo,
+	}
+}
+
+// Triage labels an issue as a bug, question or feature and acknowledges it
+func (triager *Triager) Triage(issue *github.Issue) error {
+	triage, err := triager.AiClient.TriageIssue(
+		&botAi.IssueTriageRequest{
+			Body:       issue.GetBody(),
+			Repository: fmt.Sprintf("%s/%s", triager.Owner, triager.Repo),
+			Title:      issue.GetTitle(),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("triaging issue #%d: %w", issue.GetNumber(), err)
+	}
+
+	if label := triager.categoryLabel(triage.Category); label != "" {
+		if err := triager.GithubClient.AddLabels(
+			botGithub.AddLabelsArgs{
+				IssueNumber: issue.GetNumber(),
+				Labels:      []string{label},
+				Owner:       triager.Owner,
+				Repo:        triager.Repo,
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return triager.GithubClient.CommentOnIssue(
+		botGithub.CommentOnIssueArgs{
+			Comment:     triage.Acknowledgment,
+			IssueNumber: issue.GetNumber(),
+			Owner:       triager.Owner,
+			Repo:        triager.Repo,
+		},
+	)
+}
+
+// categoryLabel names the label for a category; empty means no label
+func (triager *Triager) categoryLabel(category string) string {
+	switch category {
+	case botAi.IssueCategoryBug:
+		return triager.Labels.Bug
+	case botAi.IssueCategoryFeature:
+		return triager.Labels.Feature
+	case botAi.IssueCategoryQuestion:
+		return triager.Labels.Question
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,176 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is how many unchanged lines surround each hunk
+const diffContextLines = 3
+
+// diffEdit is one line of a diff: ' ' kept, '-' removed or '+' added, along
+// with the 0-based old and new line positions before it
+type diffEdit struct {
+	kind        byte
+	newPosition int
+	oldPosition int
+	text        string
+}
+
+// UnifiedDiff renders the line changes from oldText to newText as a unified
+// diff, returning "" when nothing changed
+func UnifiedDiff(path, oldText, newText string) string {
+	edits := diffLines(strings.Split(oldText, "\n"), strings.Split(newText, "\n"))
+
+	var hunks strings.Builder
+
+	for start := 0; start < len(edits); {
+		for start < len(edits) && edits[start].kind == ' ' {
+			start++
+		}
+
+		if start == len(edits) {
+			break
+		}
+
+		// extend the hunk while the next change is close enough to share context
+		lastChange := start
+
+		for end := start; end < len(edits) && end-lastChange <= 2*diffContextLines; end++ {
+			if edits[end].kind != ' ' {
+				lastChange = end
+			}
+		}
+
+		hunkStart := max(0, start-diffContextLines)
+		hunkEnd := min(len(edits), lastChange+diffContextLines+1)
+
+		writeHunk(&hunks, edits[hunkStart:hunkEnd])
+		start = hunkEnd
+	}
+
+	if hunks.Len() == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("--- a/%s\n+++ b/%s\n%s", path, path, hunks.String())
+}
+
+func writeHunk(hunks *strings.Builder, edits []diffEdit) {
+	oldCount, newCount := 0, 0
+
+	for _, edit := range edits {
+		if edit.kind != '+' {
+			oldCount++
+		}
+
+		if edit.kind != '-' {
+			newCount++
+		}
+	}
+
+	hunks.WriteString(fmt.Sprintf(
+		"@@ -%d,%d +%d,%d @@\n",
+		hunkStartLine(edits[0].oldPosition, oldCount),
+		oldCount,
+		hunkStartLine(edits[0].newPosition, newCount),
+		newCount,
+	))
+
+	for _, edit := range edits {
+		hunks.WriteByte(edit.kind)
+		hunks.WriteString(edit.text)
+		hunks.WriteByte('\n')
+	}
+}
+
+// hunkStartLine follows the unified diff convention of numbering an empty
+// side by the line before it
+func hunkStartLine(position, count int) int {
+	if count == 0 {
+		return position
+	}
+
+	return position + 1
+}
+
+// diffLines finds a minimal line edit script using the longest common subsequence,
+// after trimming the common prefix and suffix to keep the table small
+func diffLines(oldLines, newLines []string) []diffEdit {
+	prefix := 0
+
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	oldMiddle := oldLines[prefix : len(oldLines)-suffix]
+	newMiddle := newLines[prefix : len(newLines)-suffix]
+
+	// common[i][j] is the LCS length of oldMiddle[i:] and newMiddle[j:]
+	common := make([][]int, len(oldMiddle)+1)
+
+	for i := range common {
+		common[i] = make([]int, len(newMiddle)+1)
+	}
+
+	for i := len(oldMiddle) - 1; i >= 0; i-- {
+		for j := len(newMiddle) - 1; j >= 0; j-- {
+			if oldMiddle[i] == newMiddle[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	edits := []diffEdit{}
+	oldPosition, newPosition := 0, 0
+
+	add := func(kind byte, text string) {
+		edits = append(edits, diffEdit{kind: kind, newPosition: newPosition, oldPosition: oldPosition, text: text})
+
+		if kind != '+' {
+			oldPosition++
+		}
+
+		if kind != '-' {
+			newPosition++
+		}
+	}
+
+	for _, line := range oldLines[:prefix] {
+		add(' ', line)
+	}
+
+	i, j := 0, 0
+
+	for i < len(oldMiddle) || j < len(newMiddle) {
+		switch {
+		case i < len(oldMiddle) && j < len(newMiddle) && oldMiddle[i] == newMiddle[j]:
+			add(' ', oldMiddle[i])
+			i++
+			j++
+
+		case j == len(newMiddle) || (i < len(oldMiddle) && common[i+1][j] >= common[i][j+1]):
+			add('-', oldMiddle[i])
+			i++
+
+		default:
+			add('+', newMiddle[j])
+			j++
+		}
+	}
+
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		add(' ', line)
+	}
+
+	return edits
+}
@@ -0,0 +1,127 @@
+package shared
+
+import (
+	"regexp"
+	"strings"
+)
+
+// IssueForm holds the fields of an issue created from a GitHub issue form,
+// keyed by normalized heading
+type IssueForm map[string]string
+
+// formHeadingPattern matches the "### Field" headings issue forms render
+var formHeadingPattern = regexp.MustCompile(`^###\s+(.+?)\s*$`)
+
+// formListItemPattern strips bullets, numbering and checkboxes from list lines
+var formListItemPattern = regexp.MustCompile(`^(?:[-*+]\s+(?:\[[ xX]\]\s+)?|\d+[.)]\s+)`)
+
+// formNoResponse is what GitHub renders for optional fields left empty
+const formNoResponse = "_No response_"
+
+// ParseIssueForm reads an issue body rendered from an issue form, reporting
+// false when the body isn't one (its first line must be a "### " heading)
+func ParseIssueForm(body string) (IssueForm, bool) {
+	form := IssueForm{}
+	heading := ""
+	values := []string{}
+
+	save := func() {
+		if heading == "" {
+			return
+		}
+
+		value := strings.TrimSpace(strings.Join(values, "\n"))
+		if value == formNoResponse {
+			value = ""
+		}
+
+		form[heading] = value
+	}
+
+	for line := range strings.SplitSeq(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		match := formHeadingPattern.FindStringSubmatch(line)
+
+		if match != nil {
+			save()
+			heading = normalizeFormHeading(match[1])
+			values = []string{}
+
+			continue
+		}
+
+		// text before the first heading means this isn't a form
+		if heading == "" && strings.TrimSpace(line) != "" {
+			return nil, false
+		}
+
+		values = append(values, line)
+	}
+
+	save()
+
+	return form, len(form) > 0
+}
+
+// Value returns the first non-empty field among names
+func (form IssueForm) Value(names ...string) string {
+	for _, name := range names {
+		if value := form[normalizeFormHeading(name)]; value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// List splits a field into items, one per line or comma-separated, without
+// bullets, numbering or checkboxes
+func (form IssueForm) List(names ...string) []string {
+	items := []string{}
+
+	for line := range strings.SplitSeq(form.Value(names...), "\n") {
+		line = formListItemPattern.ReplaceAllString(strings.TrimSpace(line), "")
+
+		for item := range strings.SplitSeq(line, ",") {
+			if trimmed := strings.TrimSpace(item); trimmed != "" {
+				items = append(items, trimmed)
+			}
+		}
+	}
+
+	return items
+}
+
+// Lines splits a field into one item per line, without bullets or numbering,
+// keeping commas inside each line
+func (form IssueForm) Lines(names ...string) []string {
+	items := []string{}
+
+	for line := range strings.SplitSeq(form.Value(names...), "\n") {
+		if trimmed := formListItemPattern.ReplaceAllString(strings.TrimSpace(line), ""); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+
+	return items
+}
+
+// Bool reads a yes/no field, also accepting a ticked checkbox; isSet is false
+// when the field is missing or empty
+func (form IssueForm) Bool(names ...string) (value bool, isSet bool) {
+	raw := strings.ToLower(strings.TrimSpace(form.Value(names...)))
+
+	if raw == "" {
+		return false, false
+	}
+
+	isChecked := strings.HasPrefix(raw, "- [x]")
+	isYes := raw == "true" || raw == "yes" || raw == "y"
+
+	return isChecked || isYes, true
+}
+
+// normalizeFormHeading lowercases a heading and drops trailing punctuation,
+// so "Target path?" and "target path" match
+func normalizeFormHeading(heading string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(heading)), "?:. ")
+}
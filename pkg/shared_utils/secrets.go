@@ -0,0 +1,130 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// secretPattern is a known credential format
+type secretPattern struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+var secretPatterns = []secretPattern{
+	{kind: "AWS access key", pattern: regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`)},
+	{kind: "GitHub token", pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{kind: "GitHub token", pattern: regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`)},
+	{kind: "Anthropic API key", pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{20,}`)},
+	{kind: "OpenAI API key", pattern: regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9]{32,}\b`)},
+	{kind: "Slack token", pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{kind: "Google API key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{kind: "private key", pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// secretAssignmentPattern finds string literals assigned to secret-sounding names
+var secretAssignmentPattern = regexp.MustCompile(
+	`(?i)(api[_-]?key|secret|token|password|passwd|credential)["']?\s*(?::=|[:=])\s*["']([^"'\s]{16,})["']`,
+)
+
+// minSecretEntropy is the bits per character above which an assigned value
+// looks random enough to be a real credential rather than a placeholder
+const minSecretEntropy = 3.5
+
+// placeholderWords mark example values that are safe to commit
+var placeholderWords = []string{"example", "your", "xxx", "placeholder", "changeme", "dummy", "fake", "redacted", "${", "<"}
+
+// SecretFinding is a line that looks like it holds a credential. It never
+// includes the value itself, so it's safe to report in a comment
+type SecretFinding struct {
+	Kind string
+	Line int
+}
+
+func (finding SecretFinding) String() string {
+	return fmt.Sprintf("%s on line %d", finding.Kind, finding.Line)
+}
+
+// ScanForSecrets looks for API keys, tokens and private keys in content
+func ScanForSecrets(content string) []SecretFinding {
+	findings := []SecretFinding{}
+
+	for index, line := range strings.Split(content, "\n") {
+		lineNumber := index + 1
+
+		for _, known := range secretPatterns {
+			if known.pattern.MatchString(line) {
+				findings = append(findings, SecretFinding{Kind: known.kind, Line: lineNumber})
+			}
+		}
+
+		for _, match := range secretAssignmentPattern.FindAllStringSubmatch(line, -1) {
+			value := match[2]
+
+			if !isPlaceholder(value) && shannonEntropy(value) >= minSecretEntropy {
+				findings = append(findings, SecretFinding{Kind: "high-entropy " + strings.ToLower(match[1]), Line: lineNumber})
+			}
+		}
+	}
+
+	return findings
+}
+
+func isPlaceholder(value string) bool {
+	lowerValue := strings.ToLower(value)
+
+	for _, word := range placeholderWords {
+		if strings.Contains(lowerValue, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shannonEntropy returns the average bits of information per character
+func shannonEntropy(value string) float64 {
+	counts := map[rune]int{}
+
+	for _, character := range value {
+		counts[character]++
+	}
+
+	entropy := 0.0
+	length := float64(len([]rune(value)))
+
+	for _, count := range counts {
+		probability := float64(count) / length
+		entropy -= probability * math.Log2(probability)
+	}
+
+	return entropy
+}
+
+// privateKeyBlockPattern finds a whole PEM private key, so none of it is kept
+var privateKeyBlockPattern = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+// redactedValue stands in for a secret that was removed
+const redactedValue = "[redacted]"
+
+// RedactSecrets replaces what ScanForSecrets would find with "[redacted]",
+// leaving the rest of content as it was
+func RedactSecrets(content string) string {
+	content = privateKeyBlockPattern.ReplaceAllString(content, redactedValue)
+
+	for _, known := range secretPatterns {
+		content = known.pattern.ReplaceAllString(content, redactedValue)
+	}
+
+	return secretAssignmentPattern.ReplaceAllStringFunc(content, func(assignment string) string {
+		value := secretAssignmentPattern.FindStringSubmatch(assignment)[2]
+
+		if isPlaceholder(value) || shannonEntropy(value) < minSecretEntropy {
+			return assignment
+		}
+
+		return strings.Replace(assignment, value, redactedValue, 1)
+	})
+}
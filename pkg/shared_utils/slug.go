@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations spell out letters that don't decompose into a base
+// letter plus accents
+var transliterations = map[rune]string{
+	'æ': "ae",
+	'ð': "d",
+	'đ': "d",
+	'ħ': "h",
+	'ı': "i",
+	'ł': "l",
+	'ø': "o",
+	'œ': "oe",
+	'ß': "ss",
+	'þ': "th",
+}
+
+// Slugify turns text into a lowercase slug joined by separator, e.g.
+// "Crème Brûlée & Go!" becomes "creme-brulee-go" with "-". Accented Latin
+// letters lose their accents; letters of other scripts are kept as they are
+func Slugify(text, separator string) string {
+	var slug strings.Builder
+	pendingSeparator := false
+	isAfterLatin := false
+
+	for _, r := range norm.NFKD.String(strings.ToLower(text)) {
+		// combining accents left over from decomposing "é" into "e" + "´";
+		// marks on other scripts, like kana voicing marks, are kept
+		if unicode.Is(unicode.M, r) && isAfterLatin {
+			continue
+		}
+
+		isAfterLatin = unicode.Is(unicode.Latin, r)
+		spelled, isTransliterated := transliterations[r]
+
+		switch {
+		case isTransliterated:
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r):
+			spelled = string(r)
+		default:
+			pendingSeparator = slug.Len() > 0
+			continue
+		}
+
+		if pendingSeparator {
+			slug.WriteString(separator)
+			pendingSeparator = false
+		}
+
+		slug.WriteString(spelled)
+	}
+
+	// put kept letters and their marks back together
+	return norm.NFC.String(slug.String())
+}
+
+// UniqueSlug returns slug, or slug with the first free numeric suffix
+// ("-2", "-3", ...) when isTaken reports it's already in use
+func UniqueSlug(slug, separator string, isTaken func(candidate string) bool) string {
+	candidate := slug
+
+	for suffix := 2; isTaken(candidate); suffix++ {
+		candidate = fmt.Sprintf("%s%s%d", slug, separator, suffix)
+	}
+
+	return candidate
+}
@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// TransportOptions routes the bot's API calls on networks that need it
+type TransportOptions struct {
+	CABundle string // PEM file of certificates to trust besides the system's, e.g. a TLS-inspecting proxy's
+	ProxyURL string // e.g. "http://proxy.internal:3128"; empty for HTTPS_PROXY and the other standard variables
+}
+
+// NewTransport builds the transport the GitHub and AI clients send their
+// requests through, or returns nil when no option is set, so they keep
+// http.DefaultTransport
+func NewTransport(options TransportOptions) (http.RoundTripper, error) {
+	if options.CABundle == "" && options.ProxyURL == "" {
+		return nil, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("proxy URL %q isn't a URL like http://proxy.internal:3128", options.ProxyURL)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if options.CABundle != "" {
+		rootCAs, err := certPool(options.CABundle)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: rootCAs}
+	}
+
+	return transport, nil
+}
+
+// certPool is the system's certificates plus those in a PEM file
+func certPool(caBundle string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("the CA bundle has no PEM certificates")
+	}
+
+	return pool, nil
+}
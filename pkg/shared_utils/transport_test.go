@@ -0,0 +1,87 @@
+package shared
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTransportTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	if err := os.WriteFile(caBundle, certificate, 0o644); err != nil {
+		t.Fatalf("writing CA bundle: %v", err)
+	}
+
+	if _, err := (&http.Client{}).Get(server.URL); err == nil {
+		t.Fatal("the test server's certificate was trusted without the bundle")
+	}
+
+	transport, err := NewTransport(TransportOptions{CABundle: caBundle})
+	if err != nil {
+		t.Fatalf("building transport: %v", err)
+	}
+
+	response, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("calling the server with the bundle trusted: %v", err)
+	}
+
+	response.Body.Close()
+}
+
+func TestNewTransportUsesProxy(t *testing.T) {
+	proxied := ""
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		proxied = request.URL.String()
+	}))
+	defer proxy.Close()
+
+	transport, err := NewTransport(TransportOptions{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("building transport: %v", err)
+	}
+
+	response, err := (&http.Client{Transport: transport}).Get("http://api.example.invalid/rate_limit")
+	if err != nil {
+		t.Fatalf("calling through the proxy: %v", err)
+	}
+
+	response.Body.Close()
+
+	if proxied != "http://api.example.invalid/rate_limit" {
+		t.Errorf("the proxy got %q, want the request's URL", proxied)
+	}
+}
+
+func TestNewTransportRejectsBadOptions(t *testing.T) {
+	emptyBundle := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(emptyBundle, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatalf("writing CA bundle: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options TransportOptions
+	}{
+		{name: "proxy without a host", options: TransportOptions{ProxyURL: "proxy.internal"}},
+		{name: "missing CA bundle", options: TransportOptions{CABundle: filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "CA bundle without certificates", options: TransportOptions{CABundle: emptyBundle}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewTransport(test.options); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
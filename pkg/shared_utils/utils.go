@@ -1,15 +1,32 @@
 package shared
 
-import "github.com/anthropics/anthropic-sdk-go"
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
 
-func CreateMessageParams(prompt string) anthropic.MessageNewParams {
-	return anthropic.MessageNewParams{
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// CreateMessageParams builds a single-turn request, with the system prompt
+// sent separately from the user content when one is given
+func CreateMessageParams(systemPrompt, prompt string) anthropic.MessageNewParams {
+	params := anthropic.MessageNewParams{
 		MaxTokens: 5000,
 		Messages: []anthropic.MessageParam{
 			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
 		},
 		Model: anthropic.ModelClaude3_7Sonnet20250219,
 	}
+
+	if systemPrompt != "" {
+		params.System = []anthropic.TextBlockParam{
+			{Text: systemPrompt},
+		}
+	}
+
+	return params
 }
 
 func TruncateText(textString string, limit int) string {
@@ -20,14 +37,42 @@ func TruncateText(textString string, limit int) string {
 	return textString[:limit] + "..."
 }
 
-func IsRuneAlphabetical(r rune) bool {
-	return r >= 'a' && r <= 'z'
+// CodeBlock fences content for a comment, using a fence longer than any
+// backtick run inside so the content can't close it early
+func CodeBlock(language, content string) string {
+	fence := "```"
+
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+
+	return fmt.Sprintf("%s%s\n%s\n%s", fence, language, strings.TrimRight(content, "\n"), fence)
 }
 
-func IsRuneNumerical(r rune) bool {
-	return r >= '0' && r <= '9'
+// CleanRepoPath normalizes a user- or AI-supplied path to repo-relative form,
+// resolving ".." so it can't point outside the repo
+func CleanRepoPath(filePath string) string {
+	slashed := strings.ReplaceAll(strings.TrimSpace(filePath), "\\", "/")
+	cleaned := path.Clean("/" + slashed)
+
+	return strings.TrimPrefix(cleaned, "/")
+}
+
+// SandboxEnvironment is the only part of the bot's environment that commands
+// run on generated code inherit, so that code can't read the API tokens
+var SandboxEnvironment = []string{
+	"GOCACHE", "GOFLAGS", "GOMODCACHE", "GOPATH", "GOPROXY", "HOME", "PATH",
 }
 
-func IsRuneDashCharacter(r rune) bool {
-	return r == '-'
+// FilterEnvironment copies only the named variables from the bot's environment
+func FilterEnvironment(names []string) []string {
+	environment := []string{}
+
+	for _, name := range names {
+		if value, isSet := os.LookupEnv(name); isSet {
+			environment = append(environment, name+"="+value)
+		}
+	}
+
+	return environment
 }